@@ -0,0 +1,47 @@
+package admin
+
+import "github.com/princecee/escrow-api/internal/services"
+
+type searchUsersQueryDto struct {
+	Page        int    `json:"page" validate:"number,min=1"`
+	PageSize    int    `json:"page_size" validate:"number,min=1,max=100"`
+	Query       string `json:"q" validate:"omitempty,max=255"`
+	Role        string `json:"role" validate:"omitempty,oneof=user support admin"`
+	AccountType string `json:"account_type" validate:"omitempty,oneof=personal business"`
+}
+
+type setRoleDto struct {
+	Role string `json:"role" validate:"required,oneof=user support admin"`
+}
+
+type setBusinessTierDto struct {
+	Tier string `json:"tier" validate:"required,oneof=standard growth enterprise"`
+}
+
+type getTransactionsQueryDto struct {
+	Page     int    `json:"page" validate:"number,min=1"`
+	PageSize int    `json:"page_size" validate:"number,min=1,max=100"`
+	Status   string `json:"status" validate:"omitempty,oneof=Sent-Awaiting Pending-Payment Pending-Delivery Disputed Canceled Completed"`
+	Type     string `json:"type" validate:"omitempty,oneof=Product Service Crypto"`
+	BuyerId  string `json:"buyer_id" validate:"omitempty,uuid"`
+	SellerId string `json:"seller_id" validate:"omitempty,uuid"`
+}
+
+type adjustBalanceDto = services.AdjustBalanceInput
+
+type getDisputesQueryDto struct {
+	Page     int    `json:"page" validate:"number,min=1"`
+	PageSize int    `json:"page_size" validate:"number,min=1,max=100"`
+	Status   string `json:"status" validate:"omitempty,oneof=Open Resolved"`
+}
+
+type resolveDisputeDto = services.ResolveDisputeInput
+
+type getAuditLogsQueryDto struct {
+	Page       int    `json:"page" validate:"number,min=1"`
+	PageSize   int    `json:"page_size" validate:"number,min=1,max=100"`
+	ActorId    string `json:"actor_id" validate:"omitempty,uuid"`
+	Action     string `json:"action" validate:"omitempty,max=100"`
+	EntityType string `json:"entity_type" validate:"omitempty,max=50"`
+	EntityId   string `json:"entity_id" validate:"omitempty,max=100"`
+}
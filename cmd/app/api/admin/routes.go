@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/princecee/escrow-api/cmd/app/middlewares"
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/services"
+)
+
+func AdminRouter(c config.IConfig) chi.Router {
+	h := adminHandler{c, services.NewAdminService(c)}
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(c))
+
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.RequirePermission(models.PermissionReadUsers))
+
+		r.Get("/users", h.searchUsers)
+		r.Get("/users/{user_id}", h.getUser)
+	})
+
+	r.With(middlewares.RequirePermission(models.PermissionManageRoles)).
+		Put("/users/{user_id}/role", h.setRole)
+
+	r.With(middlewares.RequirePermission(models.PermissionManageBusinesses)).
+		Put("/businesses/{business_id}/tier", h.setBusinessTier)
+
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.RequirePermission(models.PermissionReadTransactions))
+
+		r.Get("/transactions", h.getTransactions)
+		r.Get("/transactions/{transaction_id}", h.getTransaction)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.RequirePermission(models.PermissionFreezeWallets))
+
+		r.Post("/wallets/{wallet_id}/freeze", h.freezeWallet)
+		r.Post("/wallets/{wallet_id}/unfreeze", h.unfreezeWallet)
+	})
+
+	r.With(middlewares.RequirePermission(models.PermissionAdjustWallets)).
+		Post("/wallets/{wallet_id}/adjust", h.adjustBalance)
+
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.RequirePermission(models.PermissionResolveDisputes))
+
+		r.Get("/disputes", h.getDisputes)
+		r.Post("/disputes/{dispute_id}/resolve", h.resolveDispute)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.RequirePermission(models.PermissionReadAuditLogs))
+
+		r.Get("/audit-logs", h.getAuditLogs)
+		r.Get("/audit-logs/verify", h.verifyAuditLogs)
+	})
+
+	return r
+}
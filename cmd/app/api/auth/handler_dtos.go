@@ -1,19 +1,10 @@
 package auth
 
 import (
-	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/princecee/escrow-api/internal/services"
 )
 
-type signUpDto struct {
-	AccountType  *string         `json:"account_type" validate:"omitempty,oneof=personal business"`
-	Email        *string         `json:"email" validate:"required,email"`
-	PhoneNumber  *string         `json:"phone_number" validate:"omitempty,min=8"`
-	FirstName    *string         `json:"first_name" validate:"omitempty,alpha"`
-	LastName     *string         `json:"last_name" validate:"omitempty,alpha"`
-	Password     *string         `json:"password" validate:"omitempty,min=8"`
-	BusinessName *string         `json:"business_name" validate:"omitempty"`
-	RegStage     *utils.RegStage `json:"reg_stage" validate:"required,numeric,oneof=1 2 3"`
-}
+type signUpDto = services.SignUpInput
 
 type verifyCodeDto struct {
 	Email   string `json:"email" validate:"required"`
@@ -21,10 +12,7 @@ type verifyCodeDto struct {
 	OtpType string `json:"otp_type" validate:"required,oneof=sms email reset_password"`
 }
 
-type signInDto struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
-}
+type signInDto = services.SignInInput
 
 type forgotPasswordDto struct {
 	Email string `json:"email" validate:"required,email"`
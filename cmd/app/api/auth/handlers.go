@@ -2,42 +2,47 @@ package auth
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/princecee/escrow-api/cmd/app/pkg/response"
 	"github.com/princecee/escrow-api/config"
 	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/services"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
 	"github.com/princecee/escrow-api/pkg/json"
-	"github.com/princecee/escrow-api/pkg/jwt"
 	"github.com/princecee/escrow-api/pkg/push"
 	"github.com/princecee/escrow-api/pkg/utils"
 	"github.com/princecee/escrow-api/pkg/validator"
 	"github.com/rs/zerolog"
-	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	VerifyEmailSubject = "Email verification"
+	VerifyEmailSubject = services.VerifyEmailSubject
 	RegStage1Msg       = "verify your email"
 	RegStage2Msg       = "verify your phone number"
 	RegStage3Msg       = "sign up successful"
+	NewSignInSubject   = services.NewSignInSubject
 )
 
+var errInvalidCode = services.Invalid(errors.New("invalid or expired code"))
+
 type IConfig interface{}
 
 type authHandler struct {
-	c config.IConfig
+	c    config.IConfig
+	auth *services.AuthService
 }
 
 func (h *authHandler) signUp(w http.ResponseWriter, r *http.Request) {
 	body := new(signUpDto)
 	resp := response.ApiResponse{}
-	env := h.c.Getenv("ENVIRONMENT")
+	env := h.c.GetSettings().Environment
 
 	err := json.ReadJSON(r.Body, body)
 	defer r.Body.Close()
@@ -56,368 +61,35 @@ func (h *authHandler) signUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userRepo := h.c.GetUserRepository()
-	businessRepo := h.c.GetBusinessRepository()
-	authRepo := h.c.GetAuthRepository()
-	otpRepo := h.c.GetOtpRepository()
-	tokenRepo := h.c.GetTokenRepository()
-	walletRepo := h.c.GetWalletRepository()
-
-	user := new(models.User)
-	user, err = userRepo.GetByEmail(*body.Email, nil)
-	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusBadRequest)
-		return
-	}
-
-	if user != nil {
-		switch user.RegStage {
-		case int(utils.RegStage1):
-			if !user.IsEmailVerified {
-				otp := &models.Otp{
-					UserID:    user.ID,
-					Code:      utils.GenerateRandomNumber(),
-					IsUsed:    false,
-					OtpType:   models.EmailOtpType,
-					ExpiresIn: time.Now().Add(models.OtpExpiresIn * time.Minute),
-				}
-
-				err = otpRepo.Create(otp, nil)
-				if err != nil {
-					resp.Message = err.Error()
-					response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-					return
-				}
-
-				utils.Background(func() {
-					err = h.c.GetPush().SendEmail(&push.Email{
-						To:      []string{user.Email},
-						Subject: VerifyEmailSubject,
-						Text:    fmt.Sprintf("Use code %s to verify your email", otp.Code),
-						Html:    fmt.Sprintf("<p>Use code %s to verify your email</p>", otp.Code),
-					})
-
-					if err != nil {
-						h.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
-					}
-				})
-
-				resp.Message = RegStage1Msg
-				if env == "development" || env == "test" {
-					resp.Data = map[string]any{
-						"code": otp.Code,
-						"user": user,
-					}
-				} else {
-					resp.Data = map[string]any{
-						"user": user,
-					}
-				}
-
-				response.SendResponse(w, resp)
-				return
-			}
-
-		case int(utils.RegStage2):
-			if !user.IsPhoneNumberVerified {
-				otp := &models.Otp{
-					UserID:    user.ID,
-					Code:      utils.GenerateRandomNumber(),
-					IsUsed:    false,
-					OtpType:   models.SmsOtpType,
-					ExpiresIn: time.Now().Add(models.OtpExpiresIn * time.Minute),
-				}
-
-				err = otpRepo.Create(otp, nil)
-				if err != nil {
-					resp.Message = err.Error()
-					response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-					return
-				}
-
-				utils.Background(func() {
-					h.c.GetPush().SendSMS(&push.Sms{
-						Phone:   user.PhoneNumber.String,
-						Message: fmt.Sprintf("Use code %s to verify your phone number", otp.Code),
-					})
-				})
-
-				resp.Message = RegStage2Msg
-				if env == "development" || env == "test" {
-					utils.Background(func() {
-						err = h.c.GetPush().SendEmail(&push.Email{
-							To:      []string{user.Email},
-							Subject: VerifyEmailSubject,
-							Text:    fmt.Sprintf("Use code %s to verify your phone number", otp.Code),
-							Html:    fmt.Sprintf("<p>Use code %s to verify your phone number</p>", otp.Code),
-						})
-
-						if err != nil {
-							h.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
-						}
-					})
-
-					resp.Data = map[string]any{
-						"code": otp.Code,
-						"user": user,
-					}
-				} else {
-					resp.Data = map[string]any{
-						"code": otp.Code,
-					}
-				}
-
-				response.SendResponse(w, resp)
-				return
-			}
-
-		case int(utils.RegStage3):
-			resp.Message = "account already exists"
-			response.SendErrorResponse(w, resp, http.StatusBadRequest)
-			return
-		}
-	}
-
-	tx, err := h.c.GetDB().Begin(context.Background())
-	defer tx.Rollback(context.Background())
-
+	result, err := h.auth.SignUp(r.Context(), body)
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
-	switch *body.RegStage {
-	case utils.RegStage1:
-		var business *models.Business
-		if *body.AccountType == "business" {
-			business = &models.Business{
-				Name:  *body.BusinessName,
-				Email: *body.Email,
-			}
-
-			err = businessRepo.Create(business, tx)
-			if err != nil {
-				resp.Message = err.Error()
-				response.SendErrorResponse(w, resp, http.StatusBadRequest)
-				return
-			}
-		}
-
-		user = &models.User{
-			Email:       *body.Email,
-			AccountType: *body.AccountType,
-			RegStage:    int(*body.RegStage),
-		}
-
-		if business != nil {
-			user.BusinessID = &business.ID
-			user.Business = business
-		}
-
-		err := userRepo.Create(user, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusBadRequest)
-			return
-		}
-
-		otp := &models.Otp{
-			UserID:    user.ID,
-			Code:      utils.GenerateRandomNumber(),
-			IsUsed:    false,
-			OtpType:   models.EmailOtpType,
-			ExpiresIn: time.Now().Add(models.OtpExpiresIn * time.Minute),
-		}
-
-		err = otpRepo.Create(otp, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
-		utils.Background(func() {
-			err = h.c.GetPush().SendEmail(&push.Email{
-				To:      []string{user.Email},
-				Subject: VerifyEmailSubject,
-				Text:    fmt.Sprintf("Use code %s to verify your email", otp.Code),
-				Html:    fmt.Sprintf("<p>Use code %s to verify your email</p>", otp.Code),
-			})
-
-			if err != nil {
-				h.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
-			}
-		})
-
+	switch result.Stage {
+	case utils.RegStage1, utils.RegStage2:
 		resp.Message = RegStage1Msg
-
-		if env == "development" || env == "test" {
-			resp.Data = map[string]any{
-				"code": otp.Code,
-				"user": user,
-			}
-		} else {
-			resp.Data = map[string]any{
-				"user": user,
-			}
-		}
-	case utils.RegStage2:
-		user.PhoneNumber = models.NullString{NullString: sql.NullString{String: *body.PhoneNumber, Valid: true}}
-		user.RegStage = int(*body.RegStage)
-
-		err = userRepo.Update(user, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusBadRequest)
-			return
-		}
-
-		otp := &models.Otp{
-			UserID:    user.ID,
-			Code:      utils.GenerateRandomNumber(),
-			IsUsed:    false,
-			OtpType:   models.SmsOtpType,
-			ExpiresIn: time.Now().Add(models.OtpExpiresIn * time.Minute),
+		if result.Stage == utils.RegStage2 {
+			resp.Message = RegStage2Msg
 		}
-		err = otpRepo.Create(otp, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
-		utils.Background(func() {
-			h.c.GetPush().SendSMS(&push.Sms{
-				Phone:   user.PhoneNumber.String,
-				Message: fmt.Sprintf("Use code %s to verify your phone number", otp.Code),
-			})
-		})
 
-		resp.Message = RegStage2Msg
+		resp.Data = map[string]any{"user": result.User}
 		if env == "development" || env == "test" {
-			utils.Background(func() {
-				err = h.c.GetPush().SendEmail(&push.Email{
-					To:      []string{user.Email},
-					Subject: VerifyEmailSubject,
-					Text:    fmt.Sprintf("Use code %s to verify your phone number", otp.Code),
-					Html:    fmt.Sprintf("<p>Use code %s to verify your phone number</p>", otp.Code),
-				})
-
-				if err != nil {
-					h.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
-				}
-			})
-
 			resp.Data = map[string]any{
-				"code": otp.Code,
-				"user": user,
-			}
-		} else {
-			resp.Data = map[string]any{
-				"user": user,
+				"code": result.Code,
+				"user": result.User,
 			}
 		}
 	case utils.RegStage3:
-		user.FirstName = models.NullString{NullString: sql.NullString{String: *body.FirstName, Valid: true}}
-		user.LastName = models.NullString{NullString: sql.NullString{String: *body.LastName, Valid: true}}
-		user.RegStage = int(*body.RegStage)
-
-		err = userRepo.Update(user, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusBadRequest)
-			return
-		}
-
-		hashPwd, err := utils.GeneratePasswordHash(*body.Password)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
-		auth := &models.Auth{
-			UserID:   &user.ID,
-			Password: string(hashPwd),
-		}
-
-		err = authRepo.Create(auth, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusBadRequest)
-			return
-		}
-
-		wallet := &models.Wallet{AccountType: user.AccountType}
-		if user.AccountType == models.PersonalAccountType {
-			wallet.Identifier = user.ID
-		} else {
-			wallet.Identifier = *user.BusinessID
-		}
-
-		err = walletRepo.Create(wallet, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
-		accessTokenStr, _ := jwt.GenerateToken(&jwt.TokenClaims{
-			UserID:    user.ID,
-			Email:     user.Email,
-			TokenType: string(models.AccessToken),
-		})
-
-		refreshTokenStr, _ := jwt.GenerateToken(&jwt.TokenClaims{
-			UserID:    user.ID,
-			Email:     user.Email,
-			TokenType: string(models.RefreshToken),
-		})
-
-		accessToken := &models.Token{
-			Hash:      accessTokenStr,
-			UserID:    user.ID,
-			InUse:     true,
-			TokenType: models.AccessToken,
-		}
-		refreshToken := &models.Token{
-			Hash:      refreshTokenStr,
-			UserID:    user.ID,
-			InUse:     true,
-			TokenType: models.RefreshToken,
-		}
-
-		err = tokenRepo.Create(accessToken, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
-		err = tokenRepo.Create(refreshToken, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
 		resp.Message = RegStage3Msg
-		resp.Data = map[string]any{"user": user}
+		resp.Data = map[string]any{"user": result.User}
 		resp.Meta = response.ApiResponseMeta{
-			AccessToken:  accessTokenStr,
-			RefreshToken: refreshTokenStr,
+			AccessToken:  result.AccessToken,
+			RefreshToken: result.RefreshToken,
 		}
 	}
 
-	err = tx.Commit(context.Background())
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
-
 	response.SendResponse(w, resp)
 }
 
@@ -425,10 +97,6 @@ func (h *authHandler) signIn(w http.ResponseWriter, r *http.Request) {
 	resp := response.ApiResponse{}
 	body := new(signInDto)
 
-	userRepo := h.c.GetUserRepository()
-	authRepo := h.c.GetAuthRepository()
-	tokenRepo := h.c.GetTokenRepository()
-
 	err := json.ReadJSON(r.Body, body)
 	defer r.Body.Close()
 
@@ -438,102 +106,36 @@ func (h *authHandler) signIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := userRepo.GetByEmail(body.Email, nil)
-	if err != nil {
-		switch {
-		case errors.Is(err, pgx.ErrNoRows):
-			resp.Message = "account doesn't exist"
-		default:
-			resp.Message = err.Error()
-		}
-
-		response.SendErrorResponse(w, resp, http.StatusBadRequest)
-		return
-	}
-
-	if !user.IsEmailVerified {
-		resp.Message = "email not verified"
-		response.SendErrorResponse(w, resp, http.StatusForbidden)
-		return
-	}
-	if !user.IsPhoneNumberVerified {
-		resp.Message = "phone number not verified"
-		response.SendErrorResponse(w, resp, http.StatusForbidden)
-		return
-	}
+	body.IPAddress = utils.GetIPAddress(r)
+	body.UserAgent = r.UserAgent()
 
-	auth, err := authRepo.GetByUserId(user.ID, nil)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+	result, err := h.auth.SignIn(r.Context(), body)
+	var locked *services.LockedOutError
+	if errors.As(err, &locked) {
+		w.Header().Set("Retry-After", strconv.Itoa(locked.Seconds()))
+		resp.Message = locked.Error()
+		response.SendErrorResponse(w, resp, http.StatusTooManyRequests)
 		return
 	}
-
-	err = utils.ComparePassword(body.Password, []byte(auth.Password))
 	if err != nil {
-		switch {
-		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
-			resp.Message = "invalid sign in credentials"
-		default:
-			resp.Message = err.Error()
-		}
-
-		response.SendErrorResponse(w, resp, http.StatusBadRequest)
-		return
-	}
-
-	accessTokenStr, _ := jwt.GenerateToken(&jwt.TokenClaims{
-		UserID:    user.ID,
-		Email:     user.Email,
-		TokenType: string(models.AccessToken),
-	})
-
-	refreshTokenStr, _ := jwt.GenerateToken(&jwt.TokenClaims{
-		UserID:    user.ID,
-		Email:     user.Email,
-		TokenType: string(models.RefreshToken),
-	})
-
-	accessToken := &models.Token{
-		Hash:      accessTokenStr,
-		UserID:    user.ID,
-		InUse:     true,
-		TokenType: models.AccessToken,
-	}
-	refreshToken := &models.Token{
-		Hash:      refreshTokenStr,
-		UserID:    user.ID,
-		InUse:     true,
-		TokenType: models.RefreshToken,
-	}
-
-	err = tokenRepo.Create(accessToken, nil)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
-
-	err = tokenRepo.Create(refreshToken, nil)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
 	resp.Message = "signed in successfully"
 	resp.Meta = response.ApiResponseMeta{
-		AccessToken:  accessTokenStr,
-		RefreshToken: refreshTokenStr,
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
 	}
 
 	response.SendResponse(w, resp)
 }
 
 func (h *authHandler) resendCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 	body := new(resendCodeOTPDto)
-	env := h.c.Getenv("ENVIRONMENT")
+	env := h.c.GetSettings().Environment
 
 	err := json.ReadJSON(r.Body, body)
 	defer r.Body.Close()
@@ -549,14 +151,14 @@ func (h *authHandler) resendCode(w http.ResponseWriter, r *http.Request) {
 
 	user := new(models.User)
 	if body.OtpType == models.SmsOtpType {
-		user, err = userRepo.GetByPhoneNumber(body.Identifier, nil)
+		user, err = userRepo.GetByPhoneNumber(ctx, body.Identifier, nil)
 		if err != nil {
 			resp.Message = err.Error()
 			response.SendErrorResponse(w, resp, http.StatusBadRequest)
 			return
 		}
 	} else {
-		user, err = userRepo.GetByEmail(body.Identifier, nil)
+		user, err = userRepo.GetByEmail(ctx, body.Identifier, nil)
 		if err != nil {
 			resp.Message = err.Error()
 			response.SendErrorResponse(w, resp, http.StatusBadRequest)
@@ -571,7 +173,7 @@ func (h *authHandler) resendCode(w http.ResponseWriter, r *http.Request) {
 		OtpType:   body.OtpType,
 		ExpiresIn: time.Now().Add(models.OtpExpiresIn * time.Minute),
 	}
-	err = otpRepo.Create(otp, nil)
+	err = otpRepo.Create(ctx, otp, nil)
 	if err != nil {
 		resp.Message = err.Error()
 		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
@@ -580,15 +182,15 @@ func (h *authHandler) resendCode(w http.ResponseWriter, r *http.Request) {
 
 	switch body.OtpType {
 	case models.SmsOtpType:
-		utils.Background(func() {
-			h.c.GetPush().SendSMS(&push.Sms{
+		utils.Background(ctx, func(ctx context.Context) {
+			h.c.GetPush().SendSMS(ctx, &push.Sms{
 				Phone:   user.PhoneNumber.String,
 				Message: fmt.Sprintf("Use code %s to verify your phone number", otp.Code),
 			})
 		})
 	case models.EmailOtpType:
-		utils.Background(func() {
-			err = h.c.GetPush().SendEmail(&push.Email{
+		utils.Background(ctx, func(ctx context.Context) {
+			err = h.c.GetPush().SendEmail(ctx, &push.Email{
 				To:      []string{user.Email},
 				Subject: VerifyEmailSubject,
 				Text:    fmt.Sprintf("Use code %s to verify your email", otp.Code),
@@ -600,8 +202,8 @@ func (h *authHandler) resendCode(w http.ResponseWriter, r *http.Request) {
 			}
 		})
 	case models.ResetPasswordType:
-		utils.Background(func() {
-			err = h.c.GetPush().SendEmail(&push.Email{
+		utils.Background(ctx, func(ctx context.Context) {
+			err = h.c.GetPush().SendEmail(ctx, &push.Email{
 				To:      []string{user.Email},
 				Subject: VerifyEmailSubject,
 				Text:    fmt.Sprintf("Use code %s to verify your email", otp.Code),
@@ -635,6 +237,7 @@ func (h *authHandler) resendCode(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *authHandler) verifyCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 	body := new(verifyCodeDto)
 
@@ -642,7 +245,6 @@ func (h *authHandler) verifyCode(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	userRepo := h.c.GetUserRepository()
-	otpRepo := h.c.GetOtpRepository()
 
 	if err != nil {
 		resp.Message = err.Error()
@@ -658,7 +260,7 @@ func (h *authHandler) verifyCode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := userRepo.GetByEmail(body.Email, nil)
+	user, err := userRepo.GetByEmail(ctx, body.Email, nil)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -671,69 +273,59 @@ func (h *authHandler) verifyCode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
-	tx, _ := h.c.GetDB().Begin(ctx)
-	defer tx.Rollback(ctx)
+	err = uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		otpRepo := u.GetOtpRepository()
 
-	otp, err := otpRepo.GetOneByWhere(`
-		WHERE
-			code = $1
-			AND is_used = $2
-			AND user_id = $3
-			AND expires_in >= $4
-			AND otp_type = $5
-	`, []any{body.Code, false, user.ID, time.Now(), body.OtpType}, tx)
+		otp, err := otpRepo.GetOneByWhere(ctx, `
+			WHERE
+				code = $1
+				AND is_used = $2
+				AND user_id = $3
+				AND expires_in >= $4
+				AND otp_type = $5
+		`, []any{body.Code, false, user.ID, time.Now(), body.OtpType}, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errInvalidCode
+		}
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		switch {
-		case errors.Is(err, pgx.ErrNoRows):
-			resp.Message = "invalid or expired code"
-		default:
-			resp.Message = err.Error()
+		otp.IsUsed = true
+		switch body.OtpType {
+		case "sms":
+			user.IsPhoneNumberVerified = true
+		case "email":
+			user.IsEmailVerified = true
 		}
 
-		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		err = u.GetUserRepository().Update(ctx, user, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		return otpRepo.Update(ctx, otp, u.Tx)
+	})
+	if err != nil {
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
-	otp.IsUsed = true
 	switch body.OtpType {
 	case "sms":
-		user.IsPhoneNumberVerified = true
 		resp.Message = "phone number verified successfully"
 	case "email":
-		user.IsEmailVerified = true
 		resp.Message = "email verified successfully"
 	case "reset_password":
 		resp.Message = "otp verified successfully"
 	}
 
-	err = userRepo.Update(user, tx)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
-
-	err = otpRepo.Update(otp, tx)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
-
-	err = tx.Commit(ctx)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
-
 	resp.Data = user
 	response.SendResponse(w, resp)
 }
 
 func (h *authHandler) resetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 	body := new(forgotPasswordDto)
 	defer r.Body.Close()
@@ -748,7 +340,7 @@ func (h *authHandler) resetPassword(w http.ResponseWriter, r *http.Request) {
 	userRepo := h.c.GetUserRepository()
 	otpRepo := h.c.GetOtpRepository()
 
-	user, err := userRepo.GetByEmail(body.Email, nil)
+	user, err := userRepo.GetByEmail(ctx, body.Email, nil)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -776,8 +368,8 @@ func (h *authHandler) resetPassword(w http.ResponseWriter, r *http.Request) {
 		OtpType:   models.ResetPasswordType,
 	}
 
-	utils.Background(func() {
-		err = h.c.GetPush().SendEmail(&push.Email{
+	utils.Background(ctx, func(ctx context.Context) {
+		err = h.c.GetPush().SendEmail(ctx, &push.Email{
 			To:      []string{user.Email},
 			Subject: VerifyEmailSubject,
 			Text:    fmt.Sprintf("Use code %s to verify your email", otp.Code),
@@ -789,7 +381,7 @@ func (h *authHandler) resetPassword(w http.ResponseWriter, r *http.Request) {
 		}
 	})
 
-	err = otpRepo.Create(otp, nil)
+	err = otpRepo.Create(ctx, otp, nil)
 	if err != nil {
 		resp.Message = err.Error()
 		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
@@ -797,7 +389,7 @@ func (h *authHandler) resetPassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp.Message = "otp send to your email"
-	env := h.c.Getenv("ENVIRONMENT")
+	env := h.c.GetSettings().Environment
 	if env == "development" || env == "test" {
 		resp.Data = map[string]string{
 			"code": code,
@@ -808,6 +400,7 @@ func (h *authHandler) resetPassword(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *authHandler) changePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 	body := new(changePasswordDto)
 
@@ -823,7 +416,7 @@ func (h *authHandler) changePassword(w http.ResponseWriter, r *http.Request) {
 	userRepo := h.c.GetUserRepository()
 	authRepo := h.c.GetAuthRepository()
 
-	user, err := userRepo.GetByEmail(body.Email, nil)
+	user, err := userRepo.GetByEmail(ctx, body.Email, nil)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -836,7 +429,7 @@ func (h *authHandler) changePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	auth, err := authRepo.GetByUserId(user.ID, nil)
+	auth, err := authRepo.GetByUserId(ctx, user.ID, nil)
 	if err != nil {
 		resp.Message = err.Error()
 		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
@@ -864,13 +457,21 @@ func (h *authHandler) changePassword(w http.ResponseWriter, r *http.Request) {
 		Timestamp: time.Now(),
 	})
 
-	err = authRepo.Update(auth, nil)
+	err = authRepo.Update(ctx, auth, nil)
 	if err != nil {
 		resp.Message = err.Error()
 		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
 		return
 	}
 
+	entry := audit.New(r, audit.ActionPasswordChanged, audit.EntityUser, user.ID)
+	entry.ActorID = &user.ID
+	entry.ActorType = models.AuditActorUser
+	err = h.c.GetAuditLogRepository().Create(ctx, entry, nil)
+	if err != nil {
+		h.c.GetLogger().Log(zerolog.ErrorLevel, audit.ErrRecordingMsg, nil, err)
+	}
+
 	resp.Message = "password changed successfully"
 	response.SendResponse(w, resp)
 }
@@ -3,10 +3,11 @@ package auth
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/services"
 )
 
 func AuthRouter(c config.IConfig) chi.Router {
-	h := authHandler{c}
+	h := authHandler{c, services.NewAuthService(c)}
 	r := chi.NewRouter()
 
 	r.Post("/sign-up", h.signUp)
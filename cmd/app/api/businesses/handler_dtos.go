@@ -0,0 +1,26 @@
+package businesses
+
+type createAPIKeyDto struct {
+	Name        string   `json:"name" validate:"required,min=3,max=100"`
+	Environment string   `json:"environment" validate:"required,oneof=test live"`
+	Scopes      []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=transactions:read transactions:write wallet:read"`
+}
+
+type createWebhookEndpointDto struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,unique,dive,oneof=* transaction.created transaction.paid transaction.completed transaction.canceled dispute.opened dispute.resolved milestone.funded milestone.delivered milestone.released wallet.funded withdrawal.settled withdrawal.failed"`
+	Description string   `json:"description" validate:"omitempty,max=255"`
+}
+
+type updateWebhookEndpointDto struct {
+	URL         *string  `json:"url" validate:"omitempty,url,max=2048"`
+	EventTypes  []string `json:"event_types" validate:"omitempty,min=1,unique,dive,oneof=* transaction.created transaction.paid transaction.completed transaction.canceled dispute.opened dispute.resolved milestone.funded milestone.delivered milestone.released wallet.funded withdrawal.settled withdrawal.failed"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	IsActive    *bool    `json:"is_active" validate:"omitempty"`
+}
+
+type getWebhookDeliveriesQueryDto struct {
+	Page     int    `json:"page" validate:"number,min=1"`
+	PageSize int    `json:"page_size" validate:"number,min=1,max=100"`
+	Status   string `json:"status" validate:"omitempty,oneof=pending succeeded failed"`
+}
@@ -1,10 +1,31 @@
 package businesses
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 	"github.com/princecee/escrow-api/cmd/app/pkg/response"
 	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/services"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/princecee/escrow-api/pkg/json"
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/princecee/escrow-api/pkg/validator"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+)
+
+var (
+	errAPIKeyNotFound  = services.NotFound(errors.New("api key not found"))
+	errAPIKeyRevoked   = services.Invalid(errors.New("api key has been revoked"))
+	errWebhookNotFound = services.NotFound(errors.New("webhook endpoint not found"))
 )
 
 type businessHandler struct {
@@ -15,3 +36,679 @@ func (h *businessHandler) notImplemented(w http.ResponseWriter, r *http.Request)
 	resp := response.ApiResponse{Message: "not implemented"}
 	response.SendErrorResponse(w, resp, http.StatusNotImplemented)
 }
+
+func getPageParams(r *http.Request) (int, int) {
+	query := r.URL.Query()
+
+	var page, pageSize int64
+	if query.Get("page") != "" {
+		page, _ = strconv.ParseInt(query.Get("page"), 10, 64)
+	}
+	if query.Get("page_size") != "" {
+		pageSize, _ = strconv.ParseInt(query.Get("page_size"), 10, 64)
+	}
+
+	return utils.GetPage(int(page)), utils.GetPageSize(int(pageSize))
+}
+
+// getBusinessUser returns the authenticated user when they belong to a
+// business. Api keys and webhooks are business integrations, so nobody else
+// can manage them.
+func getBusinessUser(r *http.Request) (*models.User, bool) {
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+	if user.AccountType != models.BusinessAccountType || user.BusinessID == nil {
+		return nil, false
+	}
+
+	return user, true
+}
+
+// newAPIKey generates a secret for the given environment and returns it with
+// the key to store. The secret is only ever returned once, on creation.
+func newAPIKey(user *models.User, name, environment string, scopes []string) (*models.APIKey, string, error) {
+	prefix := models.APIKeyPrefixes[environment]
+	key, err := utils.GenerateAPIKey(prefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey := &models.APIKey{
+		BusinessID:  *user.BusinessID,
+		CreatedBy:   user.ID,
+		Name:        name,
+		Prefix:      key[:len(prefix)+8],
+		KeyHash:     utils.HashAPIKey(key),
+		Environment: environment,
+		Scopes:      scopes,
+	}
+
+	return apiKey, key, nil
+}
+
+// getAPIKey loads the api key in the url, which has to belong to the business
+// of user and still be usable.
+func getAPIKey(r *http.Request, c config.IConfig, user *models.User, tx pgx.Tx) (*models.APIKey, error) {
+	apiKey, err := c.GetAPIKeyRepository().GetById(r.Context(), chi.URLParam(r, "api_key_id"), tx)
+	if (err == nil && apiKey.BusinessID != *user.BusinessID) || errors.Is(err, pgx.ErrNoRows) {
+		return nil, errAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey.IsRevoked() {
+		return nil, errAPIKeyRevoked
+	}
+
+	return apiKey, nil
+}
+
+func (h *businessHandler) getAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+	apiKeyRepo := h.c.GetAPIKeyRepository()
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage api keys"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	page, pageSize := getPageParams(r)
+	pagination := utils.GetPagination(page, pageSize)
+	f := filter.New(filter.Eq("business_id", *user.BusinessID)).Paginate(pagination.Offset, pagination.Limit)
+
+	total, err := apiKeyRepo.Count(ctx, f, nil)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	apiKeys, err := apiKeyRepo.GetMany(ctx, f, nil)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Message = "api keys fetched successfully"
+	resp.Data = map[string]any{
+		"api_keys": apiKeys,
+	}
+	resp.Meta.Page = page
+	resp.Meta.PageSize = pageSize
+	resp.Meta.Total = total
+	resp.Meta.TotalPages = utils.GetTotalPages(total, pageSize)
+
+	response.SendResponse(w, resp)
+}
+
+func (h *businessHandler) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+	body := new(createAPIKeyDto)
+
+	err := json.ReadJSON(r.Body, body)
+	defer r.Body.Close()
+
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage api keys"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	apiKey, key, err := newAPIKey(user, body.Name, body.Environment, body.Scopes)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	err = uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		err := u.GetAPIKeyRepository().Create(ctx, apiKey, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entry := audit.New(r, audit.ActionAPIKeyCreated, audit.EntityAPIKey, apiKey.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, nil, apiKey), u.Tx)
+	})
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "api key created successfully, copy it now as it will not be shown again"
+	resp.Data = map[string]any{
+		"api_key": apiKey,
+		"key":     key,
+	}
+	response.SendResponse(w, resp)
+}
+
+// rotateAPIKey issues a new secret with the same name, environment and scopes
+// and revokes the old one in the same transaction.
+func (h *businessHandler) rotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage api keys"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	var apiKey *models.APIKey
+	var key string
+	err := uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		apiKeyRepo := u.GetAPIKeyRepository()
+
+		oldKey, err := getAPIKey(r, u, user, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		before := *oldKey
+		err = apiKeyRepo.Revoke(ctx, oldKey, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		apiKey, key, err = newAPIKey(user, oldKey.Name, oldKey.Environment, oldKey.Scopes)
+		if err != nil {
+			return err
+		}
+
+		err = apiKeyRepo.Create(ctx, apiKey, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		rotated := audit.WithChanges(audit.New(r, audit.ActionAPIKeyRotated, audit.EntityAPIKey, oldKey.ID), before, oldKey)
+		rotated.Changes["replaced_by"] = models.AuditChange{To: apiKey.ID}
+		entries := []*models.AuditLog{
+			rotated,
+			audit.WithChanges(audit.New(r, audit.ActionAPIKeyCreated, audit.EntityAPIKey, apiKey.ID), nil, apiKey),
+		}
+		for _, entry := range entries {
+			err = u.GetAuditLogRepository().Create(ctx, entry, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "api key rotated successfully, copy it now as it will not be shown again"
+	resp.Data = map[string]any{
+		"api_key": apiKey,
+		"key":     key,
+	}
+	response.SendResponse(w, resp)
+}
+
+func (h *businessHandler) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage api keys"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	var apiKey *models.APIKey
+	err := uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		var err error
+		apiKey, err = getAPIKey(r, u, user, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		before := *apiKey
+		err = u.GetAPIKeyRepository().Revoke(ctx, apiKey, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entry := audit.New(r, audit.ActionAPIKeyRevoked, audit.EntityAPIKey, apiKey.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, before, apiKey), u.Tx)
+	})
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "api key revoked successfully"
+	resp.Data = map[string]any{
+		"api_key": apiKey,
+	}
+	response.SendResponse(w, resp)
+}
+
+// getWebhookEndpoint loads the webhook endpoint in the url, which has to belong
+// to the business of user.
+func getWebhookEndpoint(r *http.Request, c config.IConfig, user *models.User, tx pgx.Tx) (*models.WebhookEndpoint, error) {
+	endpoint, err := c.GetWebhookEndpointRepository().GetById(r.Context(), chi.URLParam(r, "webhook_id"), tx)
+	if (err == nil && endpoint.BusinessID != *user.BusinessID) || errors.Is(err, pgx.ErrNoRows) {
+		return nil, errWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// validateWebhookURL only allows plain http endpoints outside of production
+// so merchants can point them at local receivers while integrating. Urls
+// reaching private addresses are refused unless the settings allow them.
+func (h *businessHandler) validateWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if h.c.GetSettings().Environment == "production" && u.Scheme != "https" {
+		return errors.New("webhook url must use https")
+	}
+
+	if h.c.GetSettings().AllowPrivateWebhooks {
+		return nil
+	}
+
+	return webhooks.CheckURL(ctx, rawURL)
+}
+
+func (h *businessHandler) getWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage webhooks"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	page, pageSize := getPageParams(r)
+	pagination := utils.GetPagination(page, pageSize)
+	f := filter.New(filter.Eq("business_id", *user.BusinessID)).Paginate(pagination.Offset, pagination.Limit)
+
+	total, err := h.c.GetWebhookEndpointRepository().Count(ctx, f, nil)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	endpoints, err := h.c.GetWebhookEndpointRepository().GetMany(ctx, f, nil)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Message = "webhook endpoints fetched successfully"
+	resp.Data = map[string]any{
+		"webhook_endpoints": endpoints,
+	}
+	resp.Meta.Page = page
+	resp.Meta.PageSize = pageSize
+	resp.Meta.Total = total
+	resp.Meta.TotalPages = utils.GetTotalPages(total, pageSize)
+
+	response.SendResponse(w, resp)
+}
+
+func (h *businessHandler) createWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+	body := new(createWebhookEndpointDto)
+
+	err := json.ReadJSON(r.Body, body)
+	defer r.Body.Close()
+
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage webhooks"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	if err := h.validateWebhookURL(ctx, body.URL); err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		BusinessID:  *user.BusinessID,
+		URL:         body.URL,
+		Secret:      secret,
+		EventTypes:  body.EventTypes,
+		Description: body.Description,
+		IsActive:    true,
+	}
+
+	err = uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		err := u.GetWebhookEndpointRepository().Create(ctx, endpoint, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entry := audit.New(r, audit.ActionWebhookCreated, audit.EntityWebhook, endpoint.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, nil, endpoint), u.Tx)
+	})
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "webhook endpoint created successfully, copy the secret now as it will not be shown again"
+	resp.Data = map[string]any{
+		"webhook_endpoint": endpoint,
+		"secret":           secret,
+	}
+	response.SendResponse(w, resp)
+}
+
+func (h *businessHandler) updateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+	body := new(updateWebhookEndpointDto)
+
+	err := json.ReadJSON(r.Body, body)
+	defer r.Body.Close()
+
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage webhooks"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	if body.URL != nil {
+		if err := h.validateWebhookURL(ctx, *body.URL); err != nil {
+			resp.Message = err.Error()
+			response.SendErrorResponse(w, resp, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var endpoint *models.WebhookEndpoint
+	err = uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		var err error
+		endpoint, err = getWebhookEndpoint(r, u, user, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		before := *endpoint
+		if body.URL != nil {
+			endpoint.URL = *body.URL
+		}
+		if body.EventTypes != nil {
+			endpoint.EventTypes = body.EventTypes
+		}
+		if body.Description != nil {
+			endpoint.Description = *body.Description
+		}
+		if body.IsActive != nil {
+			endpoint.IsActive = *body.IsActive
+		}
+
+		err = u.GetWebhookEndpointRepository().Update(ctx, endpoint, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entry := audit.New(r, audit.ActionWebhookUpdated, audit.EntityWebhook, endpoint.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, before, endpoint), u.Tx)
+	})
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "webhook endpoint updated successfully"
+	resp.Data = map[string]any{
+		"webhook_endpoint": endpoint,
+	}
+	response.SendResponse(w, resp)
+}
+
+func (h *businessHandler) deleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage webhooks"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	err := uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		endpoint, err := getWebhookEndpoint(r, u, user, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		err = u.GetWebhookEndpointRepository().Delete(ctx, endpoint.ID, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entry := audit.New(r, audit.ActionWebhookDeleted, audit.EntityWebhook, endpoint.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, endpoint, nil), u.Tx)
+	})
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "webhook endpoint deleted successfully"
+	response.SendResponse(w, resp)
+}
+
+// testWebhookEndpoint queues a ping event so merchants can check that their
+// receiver is reachable and verifies signatures.
+func (h *businessHandler) testWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage webhooks"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	err := uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		endpoint, err := getWebhookEndpoint(r, u, user, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		return webhooks.EnqueueTo(ctx, u, u.Tx, endpoint, webhooks.EventPing, map[string]any{
+			"webhook_endpoint_id": endpoint.ID,
+		})
+	})
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "test event queued successfully"
+	response.SendResponse(w, resp)
+}
+
+func (h *businessHandler) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage webhooks"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	endpoint, err := getWebhookEndpoint(r, h.c, user, nil)
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	page, pageSize := getPageParams(r)
+	body := &getWebhookDeliveriesQueryDto{
+		Page:     page,
+		PageSize: pageSize,
+		Status:   r.URL.Query().Get("status"),
+	}
+
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	pagination := utils.GetPagination(body.Page, body.PageSize)
+	f := filter.New(
+		filter.Eq("endpoint_id", endpoint.ID),
+		filter.Eq("status", body.Status),
+	).Paginate(pagination.Offset, pagination.Limit)
+
+	total, err := h.c.GetWebhookDeliveryRepository().Count(ctx, f, nil)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	deliveries, err := h.c.GetWebhookDeliveryRepository().GetMany(ctx, f, nil)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Message = "webhook deliveries fetched successfully"
+	resp.Data = map[string]any{
+		"webhook_deliveries": deliveries,
+	}
+	resp.Meta.Page = body.Page
+	resp.Meta.PageSize = body.PageSize
+	resp.Meta.Total = total
+	resp.Meta.TotalPages = utils.GetTotalPages(total, body.PageSize)
+
+	response.SendResponse(w, resp)
+}
+
+// redeliverWebhook sends a delivery again right away, whatever its status, and
+// returns the outcome of that attempt.
+func (h *businessHandler) redeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	user, ok := getBusinessUser(r)
+	if !ok {
+		resp.Message = "only business accounts can manage webhooks"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	endpoint, err := getWebhookEndpoint(r, h.c, user, nil)
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	delivery, err := h.c.GetWebhookDeliveryRepository().GetById(ctx, chi.URLParam(r, "delivery_id"), nil)
+	if err != nil || delivery.EndpointID != endpoint.ID {
+		switch {
+		case err == nil, errors.Is(err, pgx.ErrNoRows):
+			resp.Message = "webhook delivery not found"
+			response.SendErrorResponse(w, resp, http.StatusNotFound)
+		default:
+			resp.Message = err.Error()
+			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = webhooks.NewWorker(h.c).Deliver(ctx, delivery)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Message = "webhook redelivered"
+	resp.Data = map[string]any{
+		"webhook_delivery": delivery,
+	}
+	response.SendResponse(w, resp)
+}
@@ -2,6 +2,7 @@ package businesses
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/princecee/escrow-api/cmd/app/middlewares"
 	"github.com/princecee/escrow-api/config"
 )
 
@@ -11,5 +12,22 @@ func BusinessRouter(c config.IConfig) chi.Router {
 
 	r.Get("/not-implemented", h.notImplemented)
 
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware(c))
+
+		r.Get("/api-keys", h.getAPIKeys)
+		r.Post("/api-keys", h.createAPIKey)
+		r.Post("/api-keys/{api_key_id}/rotate", h.rotateAPIKey)
+		r.Delete("/api-keys/{api_key_id}", h.revokeAPIKey)
+
+		r.Get("/webhooks", h.getWebhookEndpoints)
+		r.Post("/webhooks", h.createWebhookEndpoint)
+		r.Put("/webhooks/{webhook_id}", h.updateWebhookEndpoint)
+		r.Delete("/webhooks/{webhook_id}", h.deleteWebhookEndpoint)
+		r.Post("/webhooks/{webhook_id}/test", h.testWebhookEndpoint)
+		r.Get("/webhooks/{webhook_id}/deliveries", h.getWebhookDeliveries)
+		r.Post("/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver", h.redeliverWebhook)
+	})
+
 	return r
 }
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/princecee/escrow-api/cmd/app/pkg/response"
+	"github.com/princecee/escrow-api/config"
+)
+
+const checkTimeout = 2 * time.Second
+
+type healthHandler struct {
+	c config.IConfig
+}
+
+type check struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// liveness reports the process is up and serving. It checks nothing else, so
+// an outage of a dependency doesn't get the app restarted.
+func (h *healthHandler) liveness(w http.ResponseWriter, r *http.Request) {
+	response.SendResponse(w, response.ApiResponse{Message: "ok"})
+}
+
+// readiness reports whether the app can serve requests, checking the db,
+// redis and, when configured, the smtp server and paystack. It responds with
+// 503 and the failed checks otherwise.
+func (h *healthHandler) readiness(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	results := map[string]string{}
+	ready := true
+	for _, c := range h.checks() {
+		ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := c.fn(ctx)
+		cancel()
+
+		if err != nil {
+			ready = false
+			results[c.name] = err.Error()
+			continue
+		}
+		results[c.name] = "ok"
+	}
+
+	resp.Data = results
+	if !ready {
+		resp.Message = "not ready"
+		response.SendErrorResponse(w, resp, http.StatusServiceUnavailable)
+		return
+	}
+
+	resp.Message = "ready"
+	response.SendResponse(w, resp)
+}
+
+func (h *healthHandler) checks() []check {
+	settings := h.c.GetSettings()
+	production := settings.Environment == "production"
+
+	checks := []check{
+		{"postgres", func(ctx context.Context) error {
+			return h.c.GetDB().Ping(ctx)
+		}},
+		{"redis", func(ctx context.Context) error {
+			return h.c.GetRedisClient().Ping(ctx)
+		}},
+	}
+
+	if email := settings.Email; email.Host != "" || production {
+		checks = append(checks, check{"smtp", func(ctx context.Context) error {
+			if email.Host == "" || email.Port == "" {
+				return errors.New("not configured")
+			}
+
+			d := net.Dialer{}
+			conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(email.Host, email.Port))
+			if err != nil {
+				return err
+			}
+
+			return conn.Close()
+		}})
+	}
+
+	// paystack is only checked for a secret key, calling it on every probe
+	// would count against the rate limits of the account
+	if paystack := settings.Paystack; paystack.SecretKey != "" || production {
+		checks = append(checks, check{"paystack", func(ctx context.Context) error {
+			if paystack.SecretKey == "" || paystack.BaseURL == "" {
+				return errors.New("not configured")
+			}
+
+			return nil
+		}})
+	}
+
+	return checks
+}
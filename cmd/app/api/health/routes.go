@@ -0,0 +1,15 @@
+package health
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/princecee/escrow-api/config"
+)
+
+// HealthRoutes adds the probes of the app to r. They sit at the root, next to
+// /ping, rather than under /api/v1.
+func HealthRoutes(r chi.Router, c config.IConfig) {
+	h := healthHandler{c}
+
+	r.Get("/healthz", h.liveness)
+	r.Get("/readyz", h.readiness)
+}
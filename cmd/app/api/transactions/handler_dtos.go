@@ -1,45 +1,30 @@
 package transactions
 
-type createTransactionDto struct {
-	Type                string `json:"type" validate:"required,alpha,oneof=Product Service Crypto"`
-	CreatedBy           string `json:"created_by" validate:"required,alpha,oneof=Seller Buyer"`
-	BuyerID             string `json:"buyer_id,omitempty" validate:"omitempty,uuid"`
-	SellerID            string `json:"seller_id,omitempty" validate:"omitempty,uuid"`
-	DeliveryDuration    int    `json:"delivery_duration" validate:"required,int"`
-	Currency            string `json:"currency" validate:"required,oneof=NGN"`
-	ChargeConfiguration struct {
-		BuyerCharges  int `json:"buyer_charges" validate:"int,required"`
-		SellerCharges int `json:"seller_charges" validate:"int,required"`
-	} `json:"charge_configuration" validate:"required"`
-	ProductDetails []struct {
-		Name        string `json:"name" validate:"required,alphanum"`
-		Quantity    int    `json:"quantity" validate:"omitempty,int"`
-		Description string `json:"description" validate:"required,alphanum"`
-		Price       int    `json:"price" validate:"omitempty,int"`
-	} `json:"product_details" validate:"dive"`
-}
+import "github.com/princecee/escrow-api/internal/services"
+
+type createTransactionDto = services.CreateTransactionInput
 
-type updateTransactionDto struct {
-	DeliveryDuration    *int    `json:"delivery_duration" validate:"omitempty,int"`
-	Currency            *string `json:"currency" validate:"omitempty,oneof=NGN"`
-	ChargeConfiguration *struct {
-		BuyerCharges  int `json:"buyer_charges" validate:"int,required"`
-		SellerCharges int `json:"seller_charges" validate:"int,required"`
-	} `json:"charge_configuration" validate:"omitempty"`
-	ProductDetails []struct {
-		Name        string `json:"name" validate:"required,alphanum"`
-		Quantity    int    `json:"quantity" validate:"omitempty,int"`
-		Description string `json:"description" validate:"required,alphanum"`
-		Price       int    `json:"price" validate:"omitempty,int"`
-	} `json:"product_details" validate:"omitempty,dive"`
-	Status *string `json:"status,omitempty" validate:"omitempty"`
+type acceptInviteDto struct {
+	Token string `json:"token" validate:"required"`
 }
 
-type makePaymentDto struct {
-	TransactionID string `json:"transaction_id" validate:"required,uuid"`
-	IsUseWallet   bool   `json:"is_use_wallet" validate:"required,bool"`
+type updateTransactionDto = services.UpdateTransactionInput
+
+type makePaymentDto = services.MakePaymentInput
+
+type settleTransactionDto = services.SettleInput
+
+type getFeeQuoteQueryDto struct {
+	Type          string `json:"type" validate:"required,oneof=Product Service Crypto"`
+	Currency      string `json:"currency" validate:"required,currency"`
+	Amount        int    `json:"amount" validate:"min=0"`
+	SellerID      string `json:"seller_id" validate:"omitempty,uuid"`
+	BuyerCharges  int    `json:"buyer_charges" validate:"min=0,max=100"`
+	SellerCharges int    `json:"seller_charges" validate:"min=0,max=100"`
 }
 
+type openDisputeDto = services.OpenDisputeInput
+
 type getTransactionsQueryDto struct {
 	Page      int    `json:"page" validate:"number,min=1"`
 	PageSize  int    `json:"page_size" validate:"number,min=1,max=100"`
@@ -1,42 +1,34 @@
 package transactions
 
 import (
-	"context"
 	"errors"
-	"fmt"
-	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
 	"github.com/princecee/escrow-api/cmd/app/pkg/response"
 	"github.com/princecee/escrow-api/config"
 	"github.com/princecee/escrow-api/internal/models"
-	"github.com/princecee/escrow-api/pkg/apis/paystack"
+	"github.com/princecee/escrow-api/internal/services"
+	"github.com/princecee/escrow-api/pkg/fees"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/princecee/escrow-api/pkg/json"
-	"github.com/princecee/escrow-api/pkg/push"
 	"github.com/princecee/escrow-api/pkg/utils"
 	"github.com/princecee/escrow-api/pkg/validator"
-	"github.com/rs/zerolog"
 )
 
 type transactionHandler struct {
-	c config.IConfig
+	c            config.IConfig
+	transactions *services.TransactionService
 }
 
 func (t *transactionHandler) createTransaction(w http.ResponseWriter, r *http.Request) {
 	resp := response.ApiResponse{}
 	body := new(createTransactionDto)
 
-	userRepo := t.c.GetUserRepository()
-	businessRepo := t.c.GetBusinessRepository()
-	transactionRepo := t.c.GetTransactionRepository()
-	transactionTimelineRepo := t.c.GetTransactionTimelineRepository()
-
-	tx, _ := t.c.GetDB().Begin(context.Background())
-	defer tx.Rollback(context.Background())
-
 	err := json.ReadJSON(r.Body, body)
 	if err != nil {
 		resp.Message = err.Error()
@@ -52,150 +44,33 @@ func (t *transactionHandler) createTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	var seller *models.Business
-	var buyer *models.User
-
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
-	if body.CreatedBy == models.TransactionCreatedByBuyer {
-		buyer = user
-		seller, err = businessRepo.GetById(body.SellerID, tx)
-		if err != nil {
-			var status int
-			switch {
-			case errors.Is(err, pgx.ErrNoRows):
-				resp.Message = response.ErrNotFound.Error()
-				status = http.StatusNotFound
-			default:
-				resp.Message = err.Error()
-				status = http.StatusBadRequest
-			}
-
-			response.SendErrorResponse(w, resp, status)
-		}
-	} else {
-		seller = user.Business
-		buyer, err = userRepo.GetById(body.BuyerID, tx)
-		if err != nil {
-			var status int
-			switch {
-			case errors.Is(err, pgx.ErrNoRows):
-				resp.Message = response.ErrNotFound.Error()
-				status = http.StatusNotFound
-			default:
-				resp.Message = err.Error()
-				status = http.StatusBadRequest
-			}
 
-			response.SendErrorResponse(w, resp, status)
-		}
-	}
-
-	if seller == nil || buyer == nil {
-		resp.Message = "seller or buyer not found"
-		response.SendErrorResponse(w, resp, http.StatusNotFound)
-		return
-	}
-
-	var totalAmount, totalCost, charges, receivableAmount int
-
-	productDetails := []models.ProductDetail{}
-	for _, v := range body.ProductDetails {
-		detail := v
-
-		if body.Type == models.TransactionTypeProduct {
-			totalCost += detail.Price * detail.Quantity
-		} else {
-			totalCost += detail.Price
-		}
-
-		product := models.ProductDetail{
-			Name:        detail.Name,
-			Quantity:    detail.Quantity,
-			Description: detail.Description,
-			Price:       detail.Price,
-		}
-
-		productDetails = append(productDetails, product)
-	}
-
-	charges = int(math.Ceil(0.03 * float64(totalCost)))
-	totalAmount = charges + totalCost
-	receivableAmount = totalCost - int(math.Floor(float64(charges)*float64(body.ChargeConfiguration.SellerCharges/100)))
-
-	transaction := &models.Transaction{
-		Status:              models.TransactionStatusAwaiting,
-		Type:                body.Type,
-		CreatedBy:           body.CreatedBy,
-		BuyerID:             body.BuyerID,
-		SellerID:            body.SellerID,
-		DeliveryDuration:    body.DeliveryDuration,
-		Currency:            body.Currency,
-		ChargeConfiguration: models.ChargeConfiguration(body.ChargeConfiguration),
-		ProductDetails:      productDetails,
-		TotalAmount:         totalAmount,
-		TotalCost:           totalCost,
-		Charges:             charges,
-		ReceivableAmount:    receivableAmount,
-	}
-
-	err = transactionRepo.Create(transaction, tx)
+	transaction, invite, err := t.transactions.Create(r.Context(), user, body)
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
-	timeline := &models.TransactionTimeline{
-		Name:          models.TimelineCreated,
-		TransactionID: transaction.ID,
-	}
-	err = transactionTimelineRepo.Create(timeline, tx)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
-
-	transaction.Timeline = []*models.TransactionTimeline{timeline}
-
-	utils.Background(func() {
-		var email string
-		if transaction.CreatedBy == models.TransactionCreatedByBuyer {
-			email = seller.Email
-		} else {
-			email = buyer.Email
-		}
-
-		err = t.c.GetPush().SendEmail(&push.Email{
-			To:      []string{email},
-			Subject: "You have been invited to a new transaction",
-			Text:    fmt.Sprintf("You have been invited to a new transaction: %s", transaction.ID),
-			Html:    fmt.Sprintf("<p>You have been invited to a new transaction: %s</p>", transaction.ID),
-		})
-
-		if err != nil {
-			t.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
-		}
-	})
-
-	if err != nil {
-		t.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
-	}
-
 	resp.Message = "transaction created successfully"
 	resp.Data = map[string]any{
 		"transaction": transaction,
 	}
+	if invite != nil {
+		resp.Data = map[string]any{
+			"transaction": transaction,
+			"invite":      invite,
+		}
+	}
 
 	response.SendResponse(w, resp)
 }
 
-func (t *transactionHandler) updateTransaction(w http.ResponseWriter, r *http.Request) {
+// quoteTransaction prices a create payload without saving anything, so the
+// buyer can see what they will pay before the transaction exists.
+func (t *transactionHandler) quoteTransaction(w http.ResponseWriter, r *http.Request) {
 	resp := response.ApiResponse{}
-	body := new(updateTransactionDto)
-
-	transactionRepo := t.c.GetTransactionRepository()
-	transactionTimelineRepo := t.c.GetTransactionTimelineRepository()
+	body := new(createTransactionDto)
 
 	err := json.ReadJSON(r.Body, body)
 	if err != nil {
@@ -212,154 +87,46 @@ func (t *transactionHandler) updateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	transactionId := chi.URLParam(r, "transaction_id")
-
-	tx, _ := t.c.GetDB().Begin(context.Background())
-	defer tx.Rollback(context.Background())
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
 
-	transaction, err := transactionRepo.GetById(transactionId, tx)
+	quote, err := t.transactions.Quote(r.Context(), user, body)
 	if err != nil {
-		var status int
-		switch {
-		case errors.Is(err, pgx.ErrNoRows):
-			resp.Message = response.ErrNotFound.Error()
-			status = http.StatusBadRequest
-		default:
-			resp.Message = err.Error()
-			status = http.StatusInternalServerError
-		}
-
-		response.SendErrorResponse(w, resp, status)
-		return
-	}
-
-	user := r.Context().Value(utils.ContextKey{}).(*models.User)
-	if transaction.SellerID != *user.BusinessID && transaction.BuyerID != user.ID {
-		resp.Message = response.ErrForbidden.Error()
-		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
-	if body.DeliveryDuration != nil {
-		transaction.DeliveryDuration = *body.DeliveryDuration
-	}
-	if body.Currency != nil {
-		transaction.Currency = *body.Currency
-	}
-	if body.ChargeConfiguration != nil {
-		transaction.ChargeConfiguration = models.ChargeConfiguration(*body.ChargeConfiguration)
-	}
-	if body.ProductDetails != nil {
-		var totalAmount, totalCost, charges, receivableAmount int
-
-		productDetails := []models.ProductDetail{}
-		for _, v := range body.ProductDetails {
-			detail := v
-
-			if transaction.Type == models.TransactionTypeProduct {
-				totalCost += detail.Price * detail.Quantity
-			} else {
-				totalCost += detail.Price
-			}
-
-			product := models.ProductDetail{
-				Name:        detail.Name,
-				Quantity:    detail.Quantity,
-				Description: detail.Description,
-				Price:       detail.Price,
-			}
-
-			productDetails = append(productDetails, product)
-		}
-
-		charges = int(math.Ceil(0.03 * float64(totalCost)))
-		totalAmount = charges + totalCost
-		receivableAmount = totalCost - int(math.Floor(float64(charges)*float64(body.ChargeConfiguration.SellerCharges/100)))
-
-		transaction.Charges = charges
-		transaction.TotalAmount = totalAmount
-		transaction.TotalCost = totalCost
-		transaction.ReceivableAmount = receivableAmount
-		transaction.ProductDetails = productDetails
-	}
-
-	// pay from the wallet
-	// check appropriate status and timeline
-
-	timeline := &models.TransactionTimeline{
-		TransactionID: transactionId,
+	resp.Message = "transaction quoted successfully"
+	resp.Data = map[string]any{
+		"quote": quote,
 	}
-	if body.Status != nil {
-		var text, html string
-
-		transaction.Status = *body.Status
-
-		switch *body.Status {
-		case models.TransactionStatusPendingPayment:
-			text = "Transaction accepted"
-			timeline.Name = models.TimelineApproved
-		case models.TransactionStatusCanceled:
-			text = "Transaction canceled"
-			timeline.Name = models.TImelineCanceled
-		case models.TransactionStatusCompleted:
-			text = "Transaction completed"
-			timeline.Name = models.TimelineCompleted
-		}
-
-		html = fmt.Sprintf("<p>%s</p>", text)
-
-		utils.Background(func() {
-			err = t.c.GetPush().SendEmail(&push.Email{
-				To:      []string{transaction.Seller.Email, transaction.Buyer.Email},
-				Subject: "Transaction updated",
-				Text:    text,
-				Html:    html,
-			})
+	response.SendResponse(w, resp)
+}
 
-			if err != nil {
-				t.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
-			}
-		})
-	}
+func (t *transactionHandler) updateTransaction(w http.ResponseWriter, r *http.Request) {
+	resp := response.ApiResponse{}
+	body := new(updateTransactionDto)
 
-	err = transactionTimelineRepo.Create(timeline, tx)
+	err := json.ReadJSON(r.Body, body)
+	defer r.Body.Close()
 	if err != nil {
 		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
 		return
 	}
 
-	err = transactionRepo.Update(transaction, tx)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
 		return
 	}
 
-	where, args := utils.GenerateANDWhereFromArgs([]utils.WhereArgs{{
-		Name:  "transaction_id",
-		Value: transactionId,
-	}})
-	timelines, err := transactionTimelineRepo.GetMany(args, where, nil)
-	if err != nil {
-		var status int
-		switch {
-		case errors.Is(err, pgx.ErrNoRows):
-			transaction.Timeline = []*models.TransactionTimeline{}
-		default:
-			resp.Message = err.Error()
-			status = http.StatusInternalServerError
-			response.SendErrorResponse(w, resp, status)
-			return
-		}
-	} else {
-		transaction.Timeline = timelines
-	}
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
 
-	err = tx.Commit(context.Background())
+	transaction, err := t.transactions.Update(r.Context(), user, chi.URLParam(r, "transaction_id"), body)
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
@@ -392,74 +159,13 @@ func (t *transactionHandler) makePayment(w http.ResponseWriter, r *http.Request)
 
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
 
-	tx, _ := t.c.GetDB().Begin(context.Background())
-	defer tx.Rollback(context.Background())
-
-	walletRepo := t.c.GetWalletRepository()
-	transactionRepo := t.c.GetTransactionRepository()
-	transactionTimelineRepo := t.c.GetTransactionTimelineRepository()
-	paystackAPI := t.c.GetAPIs().GetPaystack()
-
-	transaction, err := transactionRepo.GetById(body.TransactionID, tx)
+	transaction, paymentData, err := t.transactions.MakePayment(r.Context(), user, body)
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
-	amount := transaction.TotalCost + int(math.Floor(float64(transaction.Charges)*float64(transaction.ChargeConfiguration.BuyerCharges/100)))
-	if body.IsUseWallet {
-		wallet, err := walletRepo.GetByIdentifier(user.ID, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
-		receivable := wallet.Receivable
-		diff := receivable - amount
-		if diff < 0 {
-			resp.Message = "insufficient wallet balance"
-			response.SendErrorResponse(w, resp, http.StatusBadRequest)
-			return
-		}
-
-		wallet.Receivable = diff
-		wallet.Balance -= amount
-
-		err = walletRepo.Update(wallet, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
-		timeline := &models.TransactionTimeline{
-			TransactionID: body.TransactionID,
-			Name:          models.TimelinePaymentSubmitted,
-		}
-		err = transactionTimelineRepo.Create(timeline, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
-		transaction.Status = models.TransactionStatusPendingDelivery
-		err = transactionRepo.Update(transaction, tx)
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
-		err = tx.Commit(context.Background())
-		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
-		}
-
+	if paymentData == nil {
 		resp.Message = "payment made successfully"
 		resp.Data = map[string]any{
 			"transaction": transaction,
@@ -468,35 +174,16 @@ func (t *transactionHandler) makePayment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	paystackResponse, err := paystackAPI.InitiateTransaction(paystack.InitiateTransactionDto{
-		Email:     user.Email,
-		Amount:    strconv.FormatInt(int64(amount), 10),
-		MetaData:  map[string]any{"is_for_transaction": true},
-		Reference: body.TransactionID,
-	})
-
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
-
-	err = tx.Commit(context.Background())
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
-
 	resp.Message = "wallet funded successfully"
 	resp.Data = map[string]any{
 		"transaction":  transaction,
-		"payment_data": paystackResponse,
+		"payment_data": paymentData,
 	}
 	response.SendResponse(w, resp)
 }
 
 func (t *transactionHandler) getTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 
 	transactionId := chi.URLParam(r, "transaction_id")
@@ -504,7 +191,7 @@ func (t *transactionHandler) getTransaction(w http.ResponseWriter, r *http.Reque
 	transactionRepo := t.c.GetTransactionRepository()
 	transactionTimelineRepo := t.c.GetTransactionTimelineRepository()
 
-	transaction, err := transactionRepo.GetById(transactionId, nil)
+	transaction, err := transactionRepo.GetById(ctx, transactionId, nil)
 	if err != nil {
 		var status int
 		switch {
@@ -527,11 +214,8 @@ func (t *transactionHandler) getTransaction(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	where, args := utils.GenerateANDWhereFromArgs([]utils.WhereArgs{{
-		Name:  "transaction_id",
-		Value: transactionId,
-	}})
-	timelines, err := transactionTimelineRepo.GetMany(args, where, nil)
+	f := filter.New(filter.Eq("transaction_id", transactionId))
+	timelines, err := transactionTimelineRepo.GetMany(ctx, f, nil)
 	if err != nil {
 		var status int
 		switch {
@@ -547,6 +231,16 @@ func (t *transactionHandler) getTransaction(w http.ResponseWriter, r *http.Reque
 		transaction.Timeline = timelines
 	}
 
+	if transaction.Type == models.TransactionTypeService {
+		milestones, err := t.c.GetMilestoneRepository().GetByTransactionId(ctx, transaction.ID, nil)
+		if err != nil {
+			resp.Message = err.Error()
+			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+			return
+		}
+		transaction.Milestones = milestones
+	}
+
 	resp.Message = "transaction fetched successfully"
 	resp.Data = map[string]any{
 		"transaction": transaction,
@@ -555,12 +249,14 @@ func (t *transactionHandler) getTransaction(w http.ResponseWriter, r *http.Reque
 }
 
 func (t *transactionHandler) getTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 
 	query := r.URL.Query()
 
 	transactionRepo := t.c.GetTransactionRepository()
 	transactionTimelineRepo := t.c.GetTransactionTimelineRepository()
+	milestoneRepo := t.c.GetMilestoneRepository()
 
 	var page, pageSize int64
 	if query.Get("page") != "" {
@@ -588,50 +284,60 @@ func (t *transactionHandler) getTransactions(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	pagination := utils.GetPagination(body.Page, body.PageSize)
-	where, args := utils.GenerateANDWhereFromArgs([]utils.WhereArgs{
-		{
-			Name:  "t.status",
-			Value: body.Status,
-		},
-		{
-			Name:  "t.type",
-			Value: body.Type,
-		},
-		{
-			Name:  "t.created_by",
-			Value: body.CreatedBy,
-		},
-		{
-			Name:  "t.seller_id",
-			Value: body.SellerId,
-		},
-		{
-			Name:  "t.buyer_id",
-			Value: body.BuyerId,
-		},
-	})
+	ranges, err := filter.ParseRanges(query)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
 
+	f := filter.New(
+		filter.Eq("status", body.Status),
+		filter.Eq("type", body.Type),
+		filter.Eq("created_by", body.CreatedBy),
+		filter.Eq("seller_id", body.SellerId),
+		filter.Eq("buyer_id", body.BuyerId),
+		filter.Range("created_at", ranges.CreatedFrom, ranges.CreatedTo),
+		filter.Range("total_amount", ranges.MinAmount, ranges.MaxAmount),
+	).Sort(strings.Split(query.Get("sort"), ",")...)
+
+	cursorKey := []byte(t.c.GetSettings().CursorKey)
+	cursor, err := filter.DecodeCursor(cursorKey, query.Get("cursor"))
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	// a cursor parameter, empty for the first page, switches to keyset
+	// pages, which stay fast on long histories
+	if query.Has("cursor") {
+		f.Seek(cursor, body.PageSize)
+	} else {
+		pagination := utils.GetPagination(body.Page, body.PageSize)
+		f.Paginate(pagination.Offset, pagination.Limit)
+	}
+
+	// count=false skips counting the rows, which costs as much as fetching
+	// them on large tables
 	var total int
-	_ = t.c.GetDB().
-		QueryRow(
-			context.Background(),
-			fmt.Sprintf(
-				`SELECT COUNT(*) FROM transactions t
-				INNER JOIN businesses b ON b.id = t.seller_id
-				INNER JOIN users u ON u.id= t.buyer_id
-				%s`,
-				where,
-			),
-			args...,
-		).
-		Scan(&total)
-
-	args = append(args, pagination.Offset, pagination.Limit)
-	transactions, err := transactionRepo.GetMany(args, where, nil)
+	if query.Get("count") != "false" {
+		total, err = transactionRepo.Count(ctx, f, nil)
+		if err != nil {
+			resp.Message = err.Error()
+			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	transactions, err := transactionRepo.GetMany(ctx, f, nil)
 
 	if err != nil {
 		switch {
+		case errors.Is(err, filter.ErrUnknownColumn), errors.Is(err, filter.ErrSortedCursor):
+			resp.Message = err.Error()
+			response.SendErrorResponse(w, resp, http.StatusBadRequest)
+			return
 		case errors.Is(err, pgx.ErrNoRows):
 			resp.Message = "transactions fetched successfully"
 			resp.Data = map[string]any{
@@ -646,14 +352,15 @@ func (t *transactionHandler) getTransactions(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	transactions, cursors := filter.SeekPage(f, transactions, func(v *models.Transaction) (time.Time, string) {
+		return v.CreatedAt, v.ID
+	})
+
 	for _, v := range transactions {
 		t := v
 
-		where, args := utils.GenerateANDWhereFromArgs([]utils.WhereArgs{{
-			Name:  "transaction_id",
-			Value: t.ID,
-		}})
-		timelines, err := transactionTimelineRepo.GetMany(args, where, nil)
+		f := filter.New(filter.Eq("transaction_id", t.ID))
+		timelines, err := transactionTimelineRepo.GetMany(ctx, f, nil)
 		if err != nil {
 			var status int
 			switch {
@@ -668,16 +375,259 @@ func (t *transactionHandler) getTransactions(w http.ResponseWriter, r *http.Requ
 		} else {
 			t.Timeline = timelines
 		}
+
+		if t.Type == models.TransactionTypeService {
+			milestones, err := milestoneRepo.GetByTransactionId(ctx, t.ID, nil)
+			if err != nil {
+				resp.Message = err.Error()
+				response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+				return
+			}
+			t.Milestones = milestones
+		}
 	}
 
 	resp.Message = "transactions fetched successfully"
 	resp.Data = map[string]any{
 		"transactions": transactions,
 	}
-	resp.Meta.Page = body.Page
 	resp.Meta.PageSize = body.PageSize
 	resp.Meta.Total = total
-	resp.Meta.TotalPages = utils.GetTotalPages(total, body.PageSize)
+	resp.Meta.NextCursor = cursors.Next.Encode(cursorKey)
+	resp.Meta.PrevCursor = cursors.Prev.Encode(cursorKey)
+	if !query.Has("cursor") {
+		resp.Meta.Page = body.Page
+		resp.Meta.TotalPages = utils.GetTotalPages(total, body.PageSize)
+	}
+
+	response.SendResponse(w, resp)
+}
+
+// getFeeQuote prices an amount with the fee schedule that applies to it. The
+// tier of the seller's business is used when a seller is given.
+func (t *transactionHandler) getFeeQuote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	query := r.URL.Query()
+
+	var amount, buyerCharges, sellerCharges int64
+	if query.Get("amount") != "" {
+		amount, _ = strconv.ParseInt(query.Get("amount"), 10, 64)
+	}
+	if query.Get("buyer_charges") != "" {
+		buyerCharges, _ = strconv.ParseInt(query.Get("buyer_charges"), 10, 64)
+	}
+	if query.Get("seller_charges") != "" {
+		sellerCharges, _ = strconv.ParseInt(query.Get("seller_charges"), 10, 64)
+	}
+
+	body := &getFeeQuoteQueryDto{
+		Type:          query.Get("type"),
+		Currency:      query.Get("currency"),
+		Amount:        int(amount),
+		SellerID:      query.Get("seller_id"),
+		BuyerCharges:  int(buyerCharges),
+		SellerCharges: int(sellerCharges),
+	}
+
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	tier := models.BusinessTierStandard
+	if body.SellerID != "" {
+		seller, err := t.c.GetBusinessRepository().GetById(ctx, body.SellerID, nil)
+		if err != nil {
+			var status int
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				resp.Message = response.ErrNotFound.Error()
+				status = http.StatusNotFound
+			default:
+				resp.Message = err.Error()
+				status = http.StatusInternalServerError
+			}
+
+			response.SendErrorResponse(w, resp, status)
+			return
+		}
+
+		tier = seller.Tier
+	}
+
+	quote, err := t.c.GetFeeEngine().Quote(fees.Params{
+		TransactionType: body.Type,
+		Currency:        body.Currency,
+		Tier:            tier,
+		Amount:          body.Amount,
+		ChargeConfiguration: models.ChargeConfiguration{
+			BuyerCharges:  body.BuyerCharges,
+			SellerCharges: body.SellerCharges,
+		},
+	})
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	resp.Message = "fee quote retrieved successfully"
+	resp.Data = map[string]any{
+		"quote": quote,
+	}
+	response.SendResponse(w, resp)
+}
+
+func (t *transactionHandler) openDispute(w http.ResponseWriter, r *http.Request) {
+	resp := response.ApiResponse{}
+	body := new(openDisputeDto)
+
+	err := json.ReadJSON(r.Body, body)
+	defer r.Body.Close()
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
 
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+
+	dispute, transaction, err := t.transactions.OpenDispute(r.Context(), user, chi.URLParam(r, "transaction_id"), body)
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "dispute opened successfully"
+	resp.Data = map[string]any{
+		"dispute":     dispute,
+		"transaction": transaction,
+	}
+	response.SendResponse(w, resp)
+}
+
+func (t *transactionHandler) getMilestones(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	transaction, err := t.c.GetTransactionRepository().GetById(ctx, chi.URLParam(r, "transaction_id"), nil)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			resp.Message = response.ErrNotFound.Error()
+			status = http.StatusNotFound
+		default:
+			resp.Message = err.Error()
+			status = http.StatusInternalServerError
+		}
+
+		response.SendErrorResponse(w, resp, status)
+		return
+	}
+
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+	isSeller := user.BusinessID != nil && transaction.SellerID == *user.BusinessID
+	if !isSeller && transaction.BuyerID != user.ID {
+		resp.Message = response.ErrForbidden.Error()
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	milestones, err := t.c.GetMilestoneRepository().GetByTransactionId(ctx, transaction.ID, nil)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Message = "milestones fetched successfully"
+	resp.Data = map[string]any{
+		"milestones": milestones,
+	}
+	response.SendResponse(w, resp)
+}
+
+func (t *transactionHandler) deliverMilestone(w http.ResponseWriter, r *http.Request) {
+	resp := response.ApiResponse{}
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+
+	milestone, err := t.transactions.DeliverMilestone(r.Context(), user, chi.URLParam(r, "transaction_id"), chi.URLParam(r, "milestone_id"))
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "milestone delivered successfully"
+	resp.Data = map[string]any{
+		"milestone": milestone,
+	}
+	response.SendResponse(w, resp)
+}
+
+func (t *transactionHandler) releaseMilestone(w http.ResponseWriter, r *http.Request) {
+	resp := response.ApiResponse{}
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+
+	milestone, transaction, err := t.transactions.ReleaseMilestone(r.Context(), user, chi.URLParam(r, "transaction_id"), chi.URLParam(r, "milestone_id"))
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "milestone released successfully"
+	resp.Data = map[string]any{
+		"milestone":   milestone,
+		"transaction": transaction,
+	}
+	response.SendResponse(w, resp)
+}
+
+func (t *transactionHandler) settleTransaction(w http.ResponseWriter, r *http.Request) {
+	resp := response.ApiResponse{}
+	body := new(settleTransactionDto)
+
+	err := json.ReadJSON(r.Body, body)
+	defer r.Body.Close()
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+
+	transaction, refund, err := t.transactions.Settle(r.Context(), user, chi.URLParam(r, "transaction_id"), body)
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "transaction settled successfully"
+	resp.Data = map[string]any{
+		"transaction": transaction,
+		"refund":      refund,
+	}
 	response.SendResponse(w, resp)
 }
@@ -0,0 +1,48 @@
+package transactions
+
+import (
+	"net/http"
+
+	"github.com/princecee/escrow-api/cmd/app/pkg/response"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/json"
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/princecee/escrow-api/pkg/validator"
+)
+
+// acceptInvite attaches the transaction of an invite link to the signed in
+// user. Invites sent to the email or phone number a user signs up with are
+// accepted on sign up; this is for invitees who signed up with another one.
+func (t *transactionHandler) acceptInvite(w http.ResponseWriter, r *http.Request) {
+	resp := response.ApiResponse{}
+	body := new(acceptInviteDto)
+
+	err := json.ReadJSON(r.Body, body)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+
+	transaction, err := t.transactions.AcceptInvite(r.Context(), user, body.Token)
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
+	}
+
+	resp.Message = "invite accepted successfully"
+	resp.Data = map[string]any{
+		"transaction": transaction,
+	}
+	response.SendResponse(w, resp)
+}
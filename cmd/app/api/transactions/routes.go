@@ -4,20 +4,40 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/princecee/escrow-api/cmd/app/middlewares"
 	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/services"
 )
 
 func TransactionsRouter(c config.IConfig) chi.Router {
-	t := transactionHandler{c}
+	t := transactionHandler{c, services.NewTransactionService(c)}
 	r := chi.NewRouter()
 
 	r.Group(func(r chi.Router) {
-		r.Use(middlewares.AuthMiddleware(c))
+		r.Use(middlewares.AuthMiddleware(c, models.ScopeTransactionsWrite))
+
+		r.With(middlewares.IdempotencyMiddleware(c)).Post("/create", t.createTransaction)
+		r.Put("/{transaction_id}", t.updateTransaction)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware(c, models.ScopeTransactionsRead))
 
-		r.Post("/create", t.createTransaction)
-		r.Put("/{transction_id}", t.updateTransaction)
+		r.Get("/fees", t.getFeeQuote)
+		r.Post("/quote", t.quoteTransaction)
 		r.Get("/{transaction_id}", t.getTransaction)
 		r.Get("/", t.getTransactions)
-		r.Post("/pay", t.makePayment)
+		r.Get("/{transaction_id}/milestones", t.getMilestones)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware(c))
+
+		r.Post("/invites/accept", t.acceptInvite)
+		r.Post("/{transaction_id}/disputes", t.openDispute)
+		r.With(middlewares.IdempotencyMiddleware(c)).Post("/pay", t.makePayment)
+		r.With(middlewares.IdempotencyMiddleware(c)).Post("/{transaction_id}/settle", t.settleTransaction)
+		r.Post("/{transaction_id}/milestones/{milestone_id}/deliver", t.deliverMilestone)
+		r.With(middlewares.IdempotencyMiddleware(c)).Post("/{transaction_id}/milestones/{milestone_id}/release", t.releaseMilestone)
 	})
 
 	return r
@@ -12,3 +12,9 @@ type updateAccountDto struct {
 	PhoneNumber  *string `json:"phone_number" validate:"omitempty,min=8"`
 	ImageUrl     *string `json:"image_url" validate:"omitempty,url"`
 }
+
+type getSecurityLogQueryDto struct {
+	Page     int    `json:"page" validate:"number,min=1"`
+	PageSize int    `json:"page_size" validate:"number,min=1,max=100"`
+	Outcome  string `json:"outcome" validate:"omitempty,oneof=success failed locked"`
+}
@@ -1,10 +1,10 @@
 package users
 
 import (
-	"context"
 	"database/sql"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -12,9 +12,13 @@ import (
 	"github.com/princecee/escrow-api/cmd/app/pkg/response"
 	"github.com/princecee/escrow-api/config"
 	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/princecee/escrow-api/pkg/json"
 	"github.com/princecee/escrow-api/pkg/utils"
 	"github.com/princecee/escrow-api/pkg/validator"
+	"github.com/rs/zerolog"
 )
 
 type userHandler struct {
@@ -32,12 +36,13 @@ func (h *userHandler) getMe(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *userHandler) getUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 
 	userId := chi.URLParam(r, "user_id")
 	userRepo := h.c.GetUserRepository()
 
-	user, err := userRepo.GetById(userId, nil)
+	user, err := userRepo.GetById(ctx, userId, nil)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -58,6 +63,7 @@ func (h *userHandler) getUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *userHandler) updateAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 
 	body := new(updateAccountDto)
@@ -78,14 +84,8 @@ func (h *userHandler) updateAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, _ := h.c.GetDB().Begin(context.Background())
-	defer tx.Rollback(context.Background())
-
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
 
-	userRepo := h.c.GetUserRepository()
-	businessRepo := h.c.GetBusinessRepository()
-
 	if body.FirstName != nil {
 		user.FirstName = models.NullString{NullString: sql.NullString{String: *body.FirstName, Valid: true}}
 	}
@@ -102,19 +102,21 @@ func (h *userHandler) updateAccount(w http.ResponseWriter, r *http.Request) {
 		user.ImageUrl = *body.ImageUrl
 	}
 
-	err = userRepo.Update(user, tx)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
+	err = uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		businessRepo := u.GetBusinessRepository()
 
-	if user.AccountType == models.BusinessAccountType {
-		business, err := businessRepo.GetById(*user.BusinessID, tx)
+		err := u.GetUserRepository().Update(ctx, user, u.Tx)
 		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
+			return err
+		}
+
+		if user.AccountType != models.BusinessAccountType {
+			return nil
+		}
+
+		business, err := businessRepo.GetById(ctx, *user.BusinessID, u.Tx)
+		if err != nil {
+			return err
 		}
 
 		if body.Email != nil {
@@ -127,20 +129,16 @@ func (h *userHandler) updateAccount(w http.ResponseWriter, r *http.Request) {
 			business.ImageUrl = *body.ImageUrl
 		}
 
-		err = businessRepo.Update(business, tx)
+		err = businessRepo.Update(ctx, business, u.Tx)
 		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
+			return err
 		}
 
 		user.Business = business
-	}
-
-	err = tx.Commit(context.Background())
+		return nil
+	})
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
@@ -150,6 +148,7 @@ func (h *userHandler) updateAccount(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *userHandler) changePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 
 	body := new(changePasswordDto)
@@ -174,7 +173,7 @@ func (h *userHandler) changePassword(w http.ResponseWriter, r *http.Request) {
 	authRepo := h.c.GetAuthRepository()
 
 	password, _ := utils.GeneratePasswordHash(body.Password)
-	auth, err := authRepo.GetByUserId(user.ID, nil)
+	auth, err := authRepo.GetByUserId(ctx, user.ID, nil)
 	if err != nil {
 		resp.Message = err.Error()
 		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
@@ -188,13 +187,80 @@ func (h *userHandler) changePassword(w http.ResponseWriter, r *http.Request) {
 	})
 	auth.Password = string(password)
 
-	err = authRepo.Update(auth, nil)
+	err = authRepo.Update(ctx, auth, nil)
 	if err != nil {
 		resp.Message = err.Error()
 		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
 		return
 	}
 
+	err = h.c.GetAuditLogRepository().Create(ctx, audit.New(r, audit.ActionPasswordChanged, audit.EntityUser, user.ID), nil)
+	if err != nil {
+		h.c.GetLogger().Log(zerolog.ErrorLevel, audit.ErrRecordingMsg, nil, err)
+	}
+
 	resp.Message = "password changed successfully"
 	response.SendResponse(w, resp)
 }
+
+func (h *userHandler) getSecurityLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+	signInAttemptRepo := h.c.GetSignInAttemptRepository()
+
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+	query := r.URL.Query()
+
+	var page, pageSize int64
+	if query.Get("page") != "" {
+		page, _ = strconv.ParseInt(query.Get("page"), 10, 64)
+	}
+	if query.Get("page_size") != "" {
+		pageSize, _ = strconv.ParseInt(query.Get("page_size"), 10, 64)
+	}
+
+	body := &getSecurityLogQueryDto{
+		Page:     utils.GetPage(int(page)),
+		PageSize: utils.GetPageSize(int(pageSize)),
+		Outcome:  query.Get("outcome"),
+	}
+
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	pagination := utils.GetPagination(body.Page, body.PageSize)
+	f := filter.New(
+		filter.Eq("user_id", user.ID),
+		filter.Eq("outcome", body.Outcome),
+	).Paginate(pagination.Offset, pagination.Limit)
+
+	total, err := signInAttemptRepo.Count(ctx, f, nil)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	attempts, err := signInAttemptRepo.GetMany(ctx, f, nil)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Message = "security log fetched successfully"
+	resp.Data = map[string]any{
+		"sign_in_attempts": attempts,
+	}
+	resp.Meta.Page = body.Page
+	resp.Meta.PageSize = body.PageSize
+	resp.Meta.Total = total
+	resp.Meta.TotalPages = utils.GetTotalPages(total, body.PageSize)
+
+	response.SendResponse(w, resp)
+}
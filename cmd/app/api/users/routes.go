@@ -14,6 +14,7 @@ func UsersRouter(c config.IConfig) chi.Router {
 		r.Use(middlewares.AuthMiddleware(c))
 
 		r.Get("/me", h.getMe)
+		r.Get("/me/security-log", h.getSecurityLog)
 		r.Get("/{user_id}", h.getUser)
 		r.Put("/update-account", h.updateAccount)
 		r.Put("/change-password", h.changePassword)
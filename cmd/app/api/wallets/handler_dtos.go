@@ -16,14 +16,22 @@ type getBankAccountsQueryDto struct {
 }
 
 type addFundsDto struct {
-	Amount int `json:"amount" validate:"required,min=5000"`
+	Amount   int    `json:"amount" validate:"required,min=5000"`
+	Currency string `json:"currency" validate:"omitempty,currency"`
 }
 
 type withrawFundsDto struct {
 	Amount        int    `json:"amount" validate:"required,min=5000"`
+	Currency      string `json:"currency" validate:"omitempty,currency"`
 	BankAccountId string `json:"bank_account_id" validate:"required,uuid"`
 }
 
+type createFXQuoteDto struct {
+	Amount       int    `json:"amount" validate:"required,min=1"`
+	FromCurrency string `json:"from_currency" validate:"required,currency"`
+	ToCurrency   string `json:"to_currency" validate:"required,currency,nefield=FromCurrency"`
+}
+
 type getWalletHistoriesQueryDto struct {
 	WalletID string `json:"wallet_id" validate:"uuid"`
 	Page     int    `json:"page" validate:"number,min=1"`
@@ -87,6 +95,15 @@ type TransactionData struct {
 	Plan any `json:"plan"`
 }
 
+type TransferData struct {
+	Amount       int    `json:"amount"`
+	Currency     string `json:"currency"`
+	Reference    string `json:"reference"`
+	Status       string `json:"status"`
+	TransferCode string `json:"transfer_code"`
+	Reason       string `json:"reason"`
+}
+
 type WebhookDto[T any] struct {
 	Event string `json:"event"`
 	Data  T      `json:"data"`
@@ -1,35 +1,41 @@
 package wallets
 
 import (
-	"context"
+	"bytes"
+	"crypto/hmac"
 	"errors"
-	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
 	"github.com/princecee/escrow-api/cmd/app/pkg/response"
 	"github.com/princecee/escrow-api/config"
 	"github.com/princecee/escrow-api/internal/models"
-	"github.com/princecee/escrow-api/pkg/apis/paystack"
+	"github.com/princecee/escrow-api/internal/services"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/princecee/escrow-api/pkg/json"
+	"github.com/princecee/escrow-api/pkg/money"
 	"github.com/princecee/escrow-api/pkg/utils"
 	"github.com/princecee/escrow-api/pkg/validator"
 	"github.com/rs/zerolog"
 )
 
 type walletHandler struct {
-	c config.IConfig
+	c       config.IConfig
+	wallets *services.WalletService
 }
 
 func (h *walletHandler) addBankAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 	body := new(addNewAccountDto)
 
-	walletRepo := h.c.GetWalletRepository()
-	bankAccountRepo := h.c.GetBankAccountRepository()
-
 	err := json.ReadJSON(r.Body, body)
 	defer r.Body.Close()
 
@@ -47,47 +53,35 @@ func (h *walletHandler) addBankAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, _ := h.c.GetDB().Begin(context.Background())
-	defer tx.Rollback(context.Background())
-
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
 
-	wallet := new(models.Wallet)
-	if user.AccountType == models.PersonalAccountType {
-		wallet, err = walletRepo.GetByIdentifier(user.ID, tx)
+	var bankAccount *models.BankAccount
+	err = uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		// payouts go to naira bank accounts
+		identifier, accountType := services.WalletOwner(user)
+		wallet, err := u.GetWalletRepository().GetOrCreate(ctx, identifier, accountType, money.NGN, u.Tx)
 		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
+			return err
 		}
-	} else {
-		wallet, err = walletRepo.GetByIdentifier(*user.BusinessID, tx)
+
+		bankAccount = &models.BankAccount{
+			BankName:      body.BankName,
+			AccountName:   body.AccountName,
+			AccountNumber: body.AccountNumber,
+			BVN:           body.BVN,
+			WalletID:      wallet.ID,
+			Wallet:        wallet,
+		}
+		err = u.GetBankAccountRepository().Create(ctx, bankAccount, u.Tx)
 		if err != nil {
-			resp.Message = err.Error()
-			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-			return
+			return err
 		}
-	}
 
-	bankAccount := &models.BankAccount{
-		BankName:      body.BankName,
-		AccountName:   body.AccountName,
-		AccountNumber: body.AccountNumber,
-		BVN:           body.BVN,
-		WalletID:      wallet.ID,
-		Wallet:        wallet,
-	}
-	err = bankAccountRepo.Create(bankAccount, tx)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
-	}
-
-	err = tx.Commit(context.Background())
+		entry := audit.New(r, audit.ActionBankAccountAdded, audit.EntityBankAccount, bankAccount.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, nil, bankAccount), u.Tx)
+	})
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
@@ -99,14 +93,14 @@ func (h *walletHandler) addBankAccount(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *walletHandler) deleteBankAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 	bankAccountRepo := h.c.GetBankAccountRepository()
-	walletRepo := h.c.GetWalletRepository()
 
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
 	bankAccountId := chi.URLParam(r, "bank_account_id")
 
-	bankAccount, err := bankAccountRepo.GetById(bankAccountId, nil)
+	bankAccount, err := bankAccountRepo.GetById(ctx, bankAccountId, nil)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -119,23 +113,23 @@ func (h *walletHandler) deleteBankAccount(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	wallet := new(models.Wallet)
-	if user.AccountType == models.PersonalAccountType {
-		wallet, _ = walletRepo.GetByIdentifier(user.ID, nil)
-	} else {
-		wallet, _ = walletRepo.GetByIdentifier(*user.BusinessID, nil)
-	}
-
-	if bankAccount.WalletID != wallet.ID {
+	if _, err := h.wallets.OwnWallet(ctx, user, bankAccount.WalletID, nil); err != nil {
 		resp.Message = "forbidden"
 		response.SendErrorResponse(w, resp, http.StatusForbidden)
 		return
 	}
 
-	err = bankAccountRepo.Delete(bankAccountId, nil)
+	err = uow.WithTx(ctx, h.c, func(u *uow.UnitOfWork) error {
+		err := u.GetBankAccountRepository().Delete(ctx, bankAccountId, u.Tx)
+		if err != nil {
+			return services.Invalid(err)
+		}
+
+		entry := audit.New(r, audit.ActionBankAccountDeleted, audit.EntityBankAccount, bankAccount.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, bankAccount, nil), u.Tx)
+	})
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
@@ -144,8 +138,8 @@ func (h *walletHandler) deleteBankAccount(w http.ResponseWriter, r *http.Request
 }
 
 func (h *walletHandler) getBankAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
-	walletRepo := h.c.GetWalletRepository()
 	bankAccountRepo := h.c.GetBankAccountRepository()
 
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
@@ -173,14 +167,7 @@ func (h *walletHandler) getBankAccounts(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	wallet := new(models.Wallet)
-	if user.AccountType == models.PersonalAccountType {
-		wallet, _ = walletRepo.GetByIdentifier(user.ID, nil)
-	} else {
-		wallet, _ = walletRepo.GetByIdentifier(*user.BusinessID, nil)
-	}
-
-	if wallet.ID != body.WalletID {
+	if _, err := h.wallets.OwnWallet(ctx, user, body.WalletID, nil); err != nil {
 		resp.Message = "forbidden"
 		response.SendErrorResponse(w, resp, http.StatusForbidden)
 		return
@@ -188,7 +175,7 @@ func (h *walletHandler) getBankAccounts(w http.ResponseWriter, r *http.Request)
 
 	pagination := utils.GetPagination(body.Page, body.PageSize)
 
-	bankAccounts, err := bankAccountRepo.GetByWalletId(body.WalletID, pagination, nil)
+	bankAccounts, err := bankAccountRepo.GetByWalletId(ctx, body.WalletID, pagination, nil)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -209,7 +196,7 @@ func (h *walletHandler) getBankAccounts(w http.ResponseWriter, r *http.Request)
 	var total int
 	_ = h.c.GetDB().
 		QueryRow(
-			context.Background(),
+			ctx,
 			`SELECT COUNT(*) AS total FROM bank_accounts b
 			INNER JOIN wallets w ON w.id = b.wallet_id
 			WHERE b.wallet_id = $1`,
@@ -232,20 +219,24 @@ func (h *walletHandler) getBankAccounts(w http.ResponseWriter, r *http.Request)
 
 func (h *walletHandler) getWallet(w http.ResponseWriter, r *http.Request) {
 	resp := response.ApiResponse{}
-	walletRepo := h.c.GetWalletRepository()
 
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
 
-	wallet := new(models.Wallet)
-	if user.AccountType == models.PersonalAccountType {
-		wallet, _ = walletRepo.GetByIdentifier(user.ID, nil)
-	} else {
-		wallet, _ = walletRepo.GetByIdentifier(*user.BusinessID, nil)
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		currency = money.NGN
+	}
+
+	wallet, wallets, err := h.wallets.GetWallets(r.Context(), user, currency)
+	if err != nil {
+		response.SendServiceError(w, resp, err)
+		return
 	}
 
 	resp.Message = "wallet fetched successfully"
-	resp.Data = map[string]models.Wallet{
-		"wallet": *wallet,
+	resp.Data = map[string]any{
+		"wallet":  wallet,
+		"wallets": wallets,
 	}
 	response.SendResponse(w, resp)
 }
@@ -253,7 +244,6 @@ func (h *walletHandler) getWallet(w http.ResponseWriter, r *http.Request) {
 func (h *walletHandler) addFunds(w http.ResponseWriter, r *http.Request) {
 	resp := response.ApiResponse{}
 	body := new(addFundsDto)
-	paystackAPI := h.c.GetAPIs().GetPaystack()
 
 	err := json.ReadJSON(r.Body, body)
 	defer r.Body.Close()
@@ -272,69 +262,69 @@ func (h *walletHandler) addFunds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, _ := h.c.GetDB().Begin(context.Background())
-
-	walletRepo := h.c.GetWalletRepository()
-	walletHistoryRepo := h.c.GetWalletHistoryRepository()
-
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
 
-	wallet := new(models.Wallet)
-	if user.AccountType == models.PersonalAccountType {
-		wallet, _ = walletRepo.GetByIdentifier(user.ID, tx)
-	} else {
-		wallet, _ = walletRepo.GetByIdentifier(*user.BusinessID, tx)
+	if body.Currency == "" {
+		body.Currency = money.NGN
 	}
 
+	walletHistory, paymentData, err := h.wallets.AddFunds(r.Context(), user, body.Amount, body.Currency)
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
-	walletHistory := &models.WalletHistory{
-		WalletID: wallet.ID,
-		Type:     models.WalletHistoryDepositType,
-		Amount:   body.Amount,
-		Status:   models.WalletHistoryPending,
-		Wallet:   *wallet,
+	resp.Message = "wallet funded successfully"
+	resp.Data = map[string]any{
+		"wallet_history": walletHistory,
+		"payment_data":   paymentData,
 	}
-	err = walletHistoryRepo.Create(walletHistory, tx)
+	response.SendResponse(w, resp)
+}
+
+func (h *walletHandler) withrawFunds(w http.ResponseWriter, r *http.Request) {
+	resp := response.ApiResponse{}
+	body := new(withrawFundsDto)
+
+	err := json.ReadJSON(r.Body, body)
+	defer r.Body.Close()
+
 	if err != nil {
 		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
 		return
 	}
 
-	paystackResponse, err := paystackAPI.InitiateTransaction(paystack.InitiateTransactionDto{
-		Email:     user.Email,
-		Amount:    strconv.FormatInt(int64(body.Amount), 10),
-		Reference: walletHistory.ID,
-	})
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+	validationErrors := validator.ValidateData(body)
+	if validationErrors != nil {
+		resp.Message = response.ErrBadRequest.Error()
+		resp.Data = validationErrors
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
 		return
 	}
 
-	err = tx.Commit(context.Background())
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+
+	if body.Currency == "" {
+		body.Currency = money.NGN
+	}
+
+	walletHistory, err := h.wallets.Withdraw(r.Context(), user, body.Amount, body.Currency)
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
-	resp.Message = "wallet funded successfully"
+	resp.Message = "funds successfully withdrawn"
 	resp.Data = map[string]any{
 		"wallet_history": walletHistory,
-		"payment_data":   paystackResponse,
 	}
 	response.SendResponse(w, resp)
 }
 
-func (h *walletHandler) withrawFunds(w http.ResponseWriter, r *http.Request) {
+func (h *walletHandler) createFXQuote(w http.ResponseWriter, r *http.Request) {
 	resp := response.ApiResponse{}
-	body := new(withrawFundsDto)
+	body := new(createFXQuoteDto)
 
 	err := json.ReadJSON(r.Body, body)
 	defer r.Body.Close()
@@ -355,64 +345,45 @@ func (h *walletHandler) withrawFunds(w http.ResponseWriter, r *http.Request) {
 
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
 
-	walletRepo := h.c.GetWalletRepository()
-	walletHistoryRepo := h.c.GetWalletHistoryRepository()
-
-	tx, _ := h.c.GetDB().Begin(context.Background())
-	defer tx.Rollback(context.Background())
-
-	wallet := new(models.Wallet)
-	if user.AccountType == models.PersonalAccountType {
-		wallet, _ = walletRepo.GetByIdentifier(user.ID, tx)
-	} else {
-		wallet, _ = walletRepo.GetByIdentifier(*user.BusinessID, tx)
-	}
-
-	if wallet.Receivable-body.Amount < 0 {
-		resp.Message = "insufficient balance"
-		response.SendErrorResponse(w, resp, http.StatusBadRequest)
-		return
-	}
-
-	wallet.Balance -= body.Amount
-	wallet.Receivable -= body.Amount
-	err = walletRepo.Update(wallet, tx)
+	quote, err := h.wallets.CreateFXQuote(r.Context(), user, body.Amount, body.FromCurrency, body.ToCurrency)
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
-	walletHistory := &models.WalletHistory{
-		WalletID: wallet.ID,
-		Type:     models.WalletHistoryWithdrawalType,
-		Amount:   body.Amount,
-		Status:   models.WalletHistoryPending,
-		Wallet:   *wallet,
-	}
-	err = walletHistoryRepo.Create(walletHistory, tx)
-	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
-		return
+	resp.Message = "quote created successfully"
+	resp.Data = map[string]any{
+		"quote": quote,
 	}
+	response.SendResponse(w, resp)
+}
+
+// executeFXQuote converts between two wallets of the user at the rate locked
+// by the quote.
+func (h *walletHandler) executeFXQuote(w http.ResponseWriter, r *http.Request) {
+	resp := response.ApiResponse{}
+	quoteId := chi.URLParam(r, "quote_id")
 
+	user := r.Context().Value(utils.ContextKey{}).(*models.User)
+
+	quote, histories, err := h.wallets.ExecuteFXQuote(r.Context(), user, quoteId)
 	if err != nil {
-		resp.Message = err.Error()
-		response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
-	// TODO - make transfer through paystack
-
-	resp.Message = "funds successfully withdrawn"
+	resp.Message = "quote executed successfully"
+	resp.Data = map[string]any{
+		"quote":            quote,
+		"wallet_histories": histories,
+	}
 	response.SendResponse(w, resp)
 }
 
 func (h *walletHandler) getWalletHistories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 
-	walletRepo := h.c.GetWalletRepository()
 	walletHistoryRepo := h.c.GetWalletHistoryRepository()
 
 	user := r.Context().Value(utils.ContextKey{}).(*models.User)
@@ -451,55 +422,65 @@ func (h *walletHandler) getWalletHistories(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	wallet := new(models.Wallet)
-	if user.AccountType == models.PersonalAccountType {
-		wallet, _ = walletRepo.GetByIdentifier(user.ID, nil)
-	} else {
-		wallet, _ = walletRepo.GetByIdentifier(*user.BusinessID, nil)
-	}
-
-	if body.WalletID != wallet.ID {
+	wallet, err := h.wallets.OwnWallet(ctx, user, body.WalletID, nil)
+	if err != nil {
 		resp.Message = "forbidden"
 		response.SendErrorResponse(w, resp, http.StatusForbidden)
 		return
 	}
 
-	pagination := utils.GetPagination(body.Page, body.PageSize)
-	where, args := utils.GenerateANDWhereFromArgs([]utils.WhereArgs{
-		{
-			Name:  "h.wallet_id",
-			Value: wallet.ID,
-		},
-		{
-			Name:  "h.status",
-			Value: body.Status,
-		},
-		{
-			Name:  "h.type",
-			Value: body.Type,
-		},
-	})
+	ranges, err := filter.ParseRanges(query)
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
 
+	f := filter.New(
+		filter.Eq("wallet_id", wallet.ID),
+		filter.Eq("status", body.Status),
+		filter.Eq("type", body.Type),
+		filter.Range("created_at", ranges.CreatedFrom, ranges.CreatedTo),
+		filter.Range("amount", ranges.MinAmount, ranges.MaxAmount),
+	).Sort(strings.Split(query.Get("sort"), ",")...)
+
+	cursorKey := []byte(h.c.GetSettings().CursorKey)
+	cursor, err := filter.DecodeCursor(cursorKey, query.Get("cursor"))
+	if err != nil {
+		resp.Message = err.Error()
+		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+
+	// a cursor parameter, empty for the first page, switches to keyset
+	// pages, which stay fast on long histories
+	if query.Has("cursor") {
+		f.Seek(cursor, body.PageSize)
+	} else {
+		pagination := utils.GetPagination(body.Page, body.PageSize)
+		f.Paginate(pagination.Offset, pagination.Limit)
+	}
+
+	// count=false skips counting the rows, which costs as much as fetching
+	// them on large tables
 	var total int
-	_ = h.c.GetDB().
-		QueryRow(
-			context.Background(),
-			fmt.Sprintf(
-				`SELECT COUNT(*) FROM wallet_histories h
-				INNER JOIN wallets w ON w.id = h.wallet_id
-				%s
-				`,
-				where,
-			),
-			args...,
-		).
-		Scan(&total)
+	if query.Get("count") != "false" {
+		total, err = walletHistoryRepo.Count(ctx, f, nil)
+		if err != nil {
+			resp.Message = err.Error()
+			response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+			return
+		}
+	}
 
-	args = append(args, pagination.Offset, pagination.Limit)
-	walletHistories, err := walletHistoryRepo.GetMany(args, where, nil)
+	walletHistories, err := walletHistoryRepo.GetMany(ctx, f, nil)
 
 	if err != nil {
 		switch {
+		case errors.Is(err, filter.ErrUnknownColumn), errors.Is(err, filter.ErrSortedCursor):
+			resp.Message = err.Error()
+			response.SendErrorResponse(w, resp, http.StatusBadRequest)
+			return
 		case errors.Is(err, pgx.ErrNoRows):
 			resp.Message = "wallet histories fetched successfully"
 			resp.Data = map[string]any{
@@ -514,22 +495,31 @@ func (h *walletHandler) getWalletHistories(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	walletHistories, cursors := filter.SeekPage(f, walletHistories, func(v *models.WalletHistory) (time.Time, string) {
+		return v.CreatedAt, v.ID
+	})
+
 	resp.Message = "wallet histories fetched successfully"
 	resp.Data = map[string]any{
 		"wallet_histories": walletHistories,
 	}
-	resp.Meta.Page = body.Page
 	resp.Meta.PageSize = body.PageSize
 	resp.Meta.Total = total
-	resp.Meta.TotalPages = utils.GetTotalPages(total, body.PageSize)
+	resp.Meta.NextCursor = cursors.Next.Encode(cursorKey)
+	resp.Meta.PrevCursor = cursors.Prev.Encode(cursorKey)
+	if !query.Has("cursor") {
+		resp.Meta.Page = body.Page
+		resp.Meta.TotalPages = utils.GetTotalPages(total, body.PageSize)
+	}
 
 	response.SendResponse(w, resp)
 }
 
 func (h *walletHandler) handlePaystackWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	resp := response.ApiResponse{}
 
-	env := h.c.Getenv("ENVIRONMENT")
+	env := h.c.GetSettings().Environment
 
 	if env != "test" {
 		paystackSig := r.Header.Get("x-paystack-signature")
@@ -538,23 +528,23 @@ func (h *walletHandler) handlePaystackWebhook(w http.ResponseWriter, r *http.Req
 			return
 		}
 
-		hash, err := utils.ComputeHMAC(r.Body)
+		// paystack signs the raw body, which the decoder below still needs
+		raw, err := io.ReadAll(r.Body)
 		if err != nil {
 			h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
 			response.SendErrorResponse(w, resp, http.StatusBadRequest)
 			return
 		}
+		r.Body = io.NopCloser(bytes.NewReader(raw))
 
-		if hash != paystackSig {
-			h.c.GetLogger().Log(zerolog.InfoLevel, "forbidden", nil, err)
+		hash := utils.ComputeHMAC([]byte(h.c.GetSettings().Paystack.SecretKey), raw)
+		if !hmac.Equal([]byte(hash), []byte(paystackSig)) {
+			h.c.GetLogger().Log(zerolog.InfoLevel, "forbidden", nil, nil)
 			response.SendErrorResponse(w, resp, http.StatusForbidden)
 			return
 		}
 	}
 
-	tx, _ := h.c.GetDB().Begin(context.Background())
-	defer tx.Rollback(context.Background())
-
 	// read the untyped response body so as to know the event
 	tmp := make(map[string]any)
 	err := json.ReadJSON(r.Body, &tmp)
@@ -566,98 +556,43 @@ func (h *walletHandler) handlePaystackWebhook(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	tmpJsonByte, _ := json.Marshal(tmp)
+
 	switch tmp["event"] {
 	case "charge.success":
 		body := new(WebhookDto[TransactionData])
-
-		tmpJsonByte, _ := json.Marshal(tmp)
 		err = json.Unmarshal(tmpJsonByte, body)
-
 		if err != nil {
 			h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
 			response.SendErrorResponse(w, resp, http.StatusBadRequest)
 			return
 		}
 
-		walletHistoryRepo := h.c.GetWalletHistoryRepository()
-		walletRepo := h.c.GetWalletRepository()
-		transactionRepo := h.c.GetTransactionRepository()
-		transactionTimelineRepo := h.c.GetTransactionTimelineRepository()
-
-		isForTransaction, ok := body.Data.Metadata.(map[string]any)["is_for_transaction"]
-		if !ok {
-			walletHistory, err := walletHistoryRepo.GetById(body.Data.Reference, tx)
-			if err != nil {
-				h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
-				response.SendErrorResponse(w, resp, http.StatusBadRequest)
-				return
-			}
-
-			wallet, err := walletRepo.GetById(walletHistory.WalletID, tx)
-			if err != nil {
-				h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
-				response.SendErrorResponse(w, resp, http.StatusBadRequest)
-				return
-			}
-
-			walletHistory.Status = models.WalletHistorySuccessful
-			wallet.Balance += walletHistory.Amount
-			wallet.Receivable += walletHistory.Amount
-
-			err = walletHistoryRepo.Update(walletHistory, tx)
-			if err != nil {
-				h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
-				response.SendErrorResponse(w, resp, http.StatusBadRequest)
-				return
-			}
-
-			err = walletRepo.Update(wallet, tx)
-			if err != nil {
-				h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
-				response.SendErrorResponse(w, resp, http.StatusBadRequest)
-				return
-			}
-		} else {
-			if !isForTransaction.(bool) {
-				if err != nil {
-					h.c.GetLogger().Log(zerolog.InfoLevel, "is_for_transaction wrongly placed", nil, err)
-					response.SendErrorResponse(w, resp, http.StatusBadRequest)
-					return
-				}
-			}
-
-			transaction, err := transactionRepo.GetById(body.Data.Reference, tx)
-			if err != nil {
-				h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
-				response.SendErrorResponse(w, resp, http.StatusBadRequest)
-				return
-			}
-
-			transaction.Status = models.TransactionStatusPendingDelivery
-			err = transactionRepo.Update(transaction, tx)
-			if err != nil {
-				h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
-				response.SendErrorResponse(w, resp, http.StatusBadRequest)
-				return
-			}
-
-			timeline := &models.TransactionTimeline{
-				TransactionID: transaction.ID,
-				Name:          models.TimelinePaymentSubmitted,
-			}
-			err = transactionTimelineRepo.Create(timeline, tx)
-			if err != nil {
-				h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
-				response.SendErrorResponse(w, resp, http.StatusBadRequest)
-				return
-			}
+		metadata, _ := body.Data.Metadata.(map[string]any)
+		amount, _ := strconv.ParseInt(body.Data.Amount, 10, 64)
+		err = h.wallets.ConfirmCharge(ctx, &services.Charge{
+			Reference: body.Data.Reference,
+			Amount:    amount,
+			Currency:  body.Data.Currency,
+			Metadata:  metadata,
+		})
+
+	// withdrawals are paid out as transfers referenced by their wallet history
+	case "transfer.success", "transfer.failed", "transfer.reversed":
+		body := new(WebhookDto[TransferData])
+		err = json.Unmarshal(tmpJsonByte, body)
+		if err != nil {
+			h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
+			response.SendErrorResponse(w, resp, http.StatusBadRequest)
+			return
 		}
+
+		err = h.wallets.SettleWithdrawal(ctx, body.Data.Reference, body.Event == "transfer.success")
 	}
 
-	err = tx.Commit(context.Background())
 	if err != nil {
 		h.c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
-		response.SendErrorResponse(w, resp, http.StatusBadRequest)
+		response.SendServiceError(w, resp, err)
 		return
 	}
 
@@ -4,21 +4,31 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/princecee/escrow-api/cmd/app/middlewares"
 	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/services"
+	"github.com/princecee/escrow-api/pkg/metrics"
 )
 
 func WalletsRouter(c config.IConfig) chi.Router {
-	h := walletHandler{c}
+	h := walletHandler{c, services.NewWalletService(c)}
 	r := chi.NewRouter()
 
-	r.Post("/paystack-webhook", h.handlePaystackWebhook)
+	r.With(metrics.PaystackWebhooks).Post("/paystack-webhook", h.handlePaystackWebhook)
 
 	r.Group(func(r chi.Router) {
-		r.Use(middlewares.AuthMiddleware(c))
+		r.Use(middlewares.AuthMiddleware(c, models.ScopeWalletRead))
 
-		r.Post("/add-funds", h.addFunds)
-		r.Post("/withdraw-funds", h.withrawFunds)
 		r.Get("/", h.getWallet)
 		r.Get("/{wallet_id}/history", h.getWalletHistories)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware(c))
+
+		r.With(middlewares.IdempotencyMiddleware(c)).Post("/add-funds", h.addFunds)
+		r.With(middlewares.IdempotencyMiddleware(c)).Post("/withdraw-funds", h.withrawFunds)
+		r.Post("/fx/quotes", h.createFXQuote)
+		r.With(middlewares.IdempotencyMiddleware(c)).Post("/fx/quotes/{quote_id}/execute", h.executeFXQuote)
 		r.Post("/bank-accounts", h.addBankAccount)
 		r.Delete("/bank-accounts/{bank_account_id}", h.deleteBankAccount)
 		r.Get("/bank-accounts", h.getBankAccounts)
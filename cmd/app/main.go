@@ -1,28 +1,86 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/princecee/escrow-api/cmd/app/pkg/routes"
 	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/pkg/metrics"
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/rs/zerolog"
 )
 
 func main() {
 	c := config.NewConfig()
 	defer c.DB.Close()
+	defer c.RedisClient.DB.Close()
 
 	r := routes.GetRouter(c)
 
-	port := c.Getenv("PORT")
+	port := c.Settings.Port
 	srv := http.Server{
 		Addr:    ":" + port,
 		Handler: r,
 	}
 
-	fmt.Printf("server running on port %s\n", port)
-	if err := srv.ListenAndServe(); err != nil {
-		log.Panic(err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// metrics are kept off the public listener
+	metricsSrv := http.Server{
+		Addr:    ":" + c.Settings.MetricsPort,
+		Handler: metrics.Handler(),
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		fmt.Printf("server running on port %s\n", port)
+		errCh <- srv.ListenAndServe()
+	}()
+	go func() {
+		errCh <- metricsSrv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		c.Logger.Log(zerolog.FatalLevel, "server stopped", nil, err)
+	case <-ctx.Done():
+	}
+
+	// a second signal kills the app without waiting
+	stop()
+
+	timeout := time.Duration(c.Settings.ShutdownTimeout) * time.Second
+	c.Logger.Log(zerolog.InfoLevel, "shutting down", map[string]any{"timeout": timeout.String()}, nil)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// stop taking requests and wait for the ones in flight, then for the
+	// emails and webhooks they started in the background
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		c.Logger.Log(zerolog.ErrorLevel, "error draining requests", nil, err)
+	}
+
+	if err := utils.WaitBackground(shutdownCtx); err != nil {
+		c.Logger.Log(zerolog.ErrorLevel, "background work left unfinished", nil, err)
+	}
+
+	if err := metricsSrv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		c.Logger.Log(zerolog.ErrorLevel, "error stopping metrics server", nil, err)
+	}
+
+	// flush the spans of the requests and work above
+	if err := c.TracerProvider.Shutdown(shutdownCtx); err != nil {
+		c.Logger.Log(zerolog.ErrorLevel, "error flushing traces", nil, err)
 	}
+
+	c.Logger.Log(zerolog.InfoLevel, "server stopped", nil, nil)
 }
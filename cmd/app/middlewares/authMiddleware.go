@@ -3,17 +3,25 @@ package middlewares
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/princecee/escrow-api/cmd/app/pkg/response"
 	"github.com/princecee/escrow-api/config"
-	"github.com/princecee/escrow-api/pkg/jwt"
+	"github.com/princecee/escrow-api/internal/models"
 	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/rs/zerolog"
 )
 
-func AuthMiddleware(c config.IConfig) func(http.Handler) http.Handler {
+const apiKeyPrefix = "esk_"
+
+// AuthMiddleware authenticates the request with either a user token or a
+// business api key. Api keys are refused unless scopes are given, and must
+// then carry every one of them, so routes have to opt in to server to server
+// access explicitly.
+func AuthMiddleware(c config.IConfig, scopes ...models.Scope) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			resp := response.ApiResponse{}
@@ -25,15 +33,27 @@ func AuthMiddleware(c config.IConfig) func(http.Handler) http.Handler {
 				return
 			}
 
-			token := strings.Split(auth, " ")[1]
-			claims, err := jwt.VerifyToken(token)
+			parts := strings.Split(auth, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				resp.Message = "invalid authorization header"
+				response.SendErrorResponse(w, resp, http.StatusUnauthorized)
+				return
+			}
+
+			token := parts[1]
+			if strings.HasPrefix(token, apiKeyPrefix) {
+				authenticateAPIKey(c, w, r, next, token, scopes)
+				return
+			}
+
+			claims, err := c.GetJWT().VerifyToken(token)
 			if err != nil {
 				resp.Message = err.Error()
 				response.SendErrorResponse(w, resp, http.StatusUnauthorized)
 				return
 			}
 
-			user, err := c.GetUserRepository().GetById(claims.UserID, nil)
+			user, err := c.GetUserRepository().GetById(r.Context(), claims.UserID, nil)
 			if err != nil {
 				switch {
 				case errors.Is(err, pgx.ErrNoRows):
@@ -51,3 +71,77 @@ func AuthMiddleware(c config.IConfig) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// authenticateAPIKey resolves key to the business user that created it. That
+// user goes into the context like with a token so handlers keep acting on
+// behalf of the business, and the key itself is stored alongside it.
+func authenticateAPIKey(c config.IConfig, w http.ResponseWriter, r *http.Request, next http.Handler, key string, scopes []models.Scope) {
+	ctx := r.Context()
+	resp := response.ApiResponse{}
+
+	if len(scopes) == 0 {
+		resp.Message = "api keys cannot be used on this endpoint"
+		response.SendErrorResponse(w, resp, http.StatusForbidden)
+		return
+	}
+
+	apiKey, err := c.GetAPIKeyRepository().GetByHash(ctx, utils.HashAPIKey(key), nil)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			resp.Message = "invalid api key"
+		default:
+			resp.Message = err.Error()
+		}
+		response.SendErrorResponse(w, resp, http.StatusUnauthorized)
+		return
+	}
+
+	if apiKey.IsRevoked() {
+		resp.Message = "api key has been revoked"
+		response.SendErrorResponse(w, resp, http.StatusUnauthorized)
+		return
+	}
+
+	if apiKey.Environment != apiKeyEnvironment(c) {
+		resp.Message = fmt.Sprintf("%s api keys cannot be used in this environment", apiKey.Environment)
+		response.SendErrorResponse(w, resp, http.StatusUnauthorized)
+		return
+	}
+
+	for _, s := range scopes {
+		if !apiKey.HasScope(s) {
+			resp.Message = fmt.Sprintf("api key is missing the %s scope", s)
+			response.SendErrorResponse(w, resp, http.StatusForbidden)
+			return
+		}
+	}
+
+	user, err := c.GetUserRepository().GetById(ctx, apiKey.CreatedBy, nil)
+	if err != nil || user.BusinessID == nil || *user.BusinessID != apiKey.BusinessID {
+		resp.Message = "invalid api key"
+		response.SendErrorResponse(w, resp, http.StatusUnauthorized)
+		return
+	}
+
+	utils.Background(ctx, func(ctx context.Context) {
+		if err := c.GetAPIKeyRepository().TouchLastUsed(ctx, apiKey.ID, nil); err != nil {
+			c.GetLogger().Log(zerolog.InfoLevel, err.Error(), nil, err)
+		}
+	})
+
+	ctx = context.WithValue(ctx, utils.ContextKey{}, user)
+	ctx = context.WithValue(ctx, utils.APIKeyContextKey{}, apiKey)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// apiKeyEnvironment returns which keys the server accepts: live keys in
+// production and test keys everywhere else.
+func apiKeyEnvironment(c config.IConfig) string {
+	if c.GetSettings().Environment == "production" {
+		return models.APIKeyEnvironmentLive
+	}
+
+	return models.APIKeyEnvironmentTest
+}
@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/pkg/utils"
+)
+
+// RealIPMiddleware sets RemoteAddr to the client ip forwarded by a proxy. The
+// X-Forwarded-For and X-Real-IP headers are set by clients as they please, so
+// they are only read when the peer is one of the trusted proxies of the
+// settings, and X-Forwarded-For is read from the right, skipping the trusted
+// proxies on the way, up to the first address they did not add themselves.
+func RealIPMiddleware(c config.IConfig) func(http.Handler) http.Handler {
+	// the networks are checked when the settings are loaded
+	trusted, _ := utils.ParseNetworks(c.GetSettings().TrustedProxies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(trusted) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			peer := net.ParseIP(utils.GetIPAddress(r))
+			if peer == nil || !utils.ContainsIP(trusted, peer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if ip := forwardedIP(r.Header, trusted); ip != "" {
+				r.RemoteAddr = net.JoinHostPort(ip, "0")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func forwardedIP(h http.Header, trusted []*net.IPNet) string {
+	hops := []string{}
+	for _, v := range h.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// whatever is left of a malformed hop can not be trusted
+			return ""
+		}
+
+		if i == 0 || !utils.ContainsIP(trusted, ip) {
+			return ip.String()
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(h.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	return ""
+}
+
+// ClientIPMiddleware puts the ip address of the client in the request context
+// for code that does not get the request itself. It must run after
+// RealIPMiddleware.
+func ClientIPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), utils.IPAddressContextKey{}, utils.GetIPAddress(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,139 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/princecee/escrow-api/cmd/app/pkg/response"
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/idempotency"
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/rs/zerolog"
+)
+
+// IdempotencyMiddleware makes retries of a request carrying an Idempotency-Key
+// header safe. The first response for a key is stored and returned again for
+// every retry with the same body, and a different body gets a 422. Server
+// errors are not stored so the request can be retried with the same key. It
+// must run after AuthMiddleware since keys are scoped to the user.
+func IdempotencyMiddleware(c config.IConfig) func(http.Handler) http.Handler {
+	store := idempotency.NewStore(c.GetRedisClient().DB)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resp := response.ApiResponse{}
+
+			key := r.Header.Get(idempotency.Header)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > idempotency.MaxKeyLength {
+				resp.Message = fmt.Sprintf("%s must be at most %d characters", idempotency.Header, idempotency.MaxKeyLength)
+				response.SendErrorResponse(w, resp, http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				resp.Message = err.Error()
+				response.SendErrorResponse(w, resp, http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			user := r.Context().Value(utils.ContextKey{}).(*models.User)
+			hash := idempotency.HashRequest(r.Method, r.URL.Path, body)
+
+			ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+			defer cancel()
+
+			record, err := store.Begin(ctx, user.ID, key, hash)
+			if err != nil {
+				switch {
+				case errors.Is(err, idempotency.ErrKeyReused):
+					resp.Message = err.Error()
+					response.SendErrorResponse(w, resp, http.StatusUnprocessableEntity)
+				case errors.Is(err, idempotency.ErrInProgress):
+					resp.Message = err.Error()
+					response.SendErrorResponse(w, resp, http.StatusConflict)
+				default:
+					// without the store a retry could move money twice, so the
+					// request is refused rather than handled unprotected
+					c.GetLogger().Log(zerolog.InfoLevel, "error checking idempotency key", nil, err)
+					resp.Message = response.ErrInternalServer.Error()
+					response.SendErrorResponse(w, resp, http.StatusInternalServerError)
+				}
+				return
+			}
+
+			if record != nil {
+				w.Header().Set("Content-Type", record.ContentType)
+				w.Header().Set(idempotency.ReplayedHeader, "true")
+				w.WriteHeader(record.StatusCode)
+				w.Write(record.Body)
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			// a panicking handler leaves the key to expire with its lock
+			release := store.Hold(user.ID, key, hash)
+			defer release()
+
+			next.ServeHTTP(rec, r)
+			release()
+
+			// the outcome is recorded even if the client has gone away, so a
+			// retry is not handled twice
+			ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			if rec.statusCode >= http.StatusInternalServerError {
+				err = store.Release(ctx, user.ID, key)
+			} else {
+				err = store.Complete(ctx, user.ID, key, &idempotency.Record{
+					RequestHash: hash,
+					StatusCode:  rec.statusCode,
+					ContentType: rec.Header().Get("Content-Type"),
+					Body:        rec.body.Bytes(),
+				})
+			}
+
+			if err != nil {
+				c.GetLogger().Log(zerolog.InfoLevel, "error saving idempotency key", map[string]any{"key": key}, err)
+			}
+		})
+	}
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if !rec.wroteHeader {
+		rec.statusCode = statusCode
+		rec.wroteHeader = true
+	}
+
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+
+	return rec.ResponseWriter.Write(b)
+}
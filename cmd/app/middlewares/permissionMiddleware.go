@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/princecee/escrow-api/cmd/app/pkg/response"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/utils"
+)
+
+// RequirePermission only lets the request through when the role of the
+// authenticated user grants p. It must run after AuthMiddleware. Staff
+// permissions are never extended to api keys.
+func RequirePermission(p models.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resp := response.ApiResponse{}
+
+			_, isAPIKey := r.Context().Value(utils.APIKeyContextKey{}).(*models.APIKey)
+			user, ok := r.Context().Value(utils.ContextKey{}).(*models.User)
+			if !ok || isAPIKey || !models.HasPermission(user.Role, p) {
+				resp.Message = response.ErrForbidden.Error()
+				response.SendErrorResponse(w, resp, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
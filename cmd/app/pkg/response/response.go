@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/princecee/escrow-api/internal/services"
 	"github.com/princecee/escrow-api/pkg/json"
 )
 
@@ -23,6 +24,8 @@ type ApiResponseMeta struct {
 	PageSize     int    `json:"page_size,omitempty"`
 	Total        int    `json:"total,omitempty"`
 	TotalPages   int    `json:"total_pages,omitempty"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+	PrevCursor   string `json:"prev_cursor,omitempty"`
 	AccessToken  string `json:"access_token,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 }
@@ -69,3 +72,26 @@ func SendErrorResponse(w http.ResponseWriter, a ApiResponse, statusCode int) {
 	a.StatusCode = &statusCode
 	SendResponse(w, a)
 }
+
+// SendServiceError sends err returned by a service with the status code of
+// its kind.
+func SendServiceError(w http.ResponseWriter, a ApiResponse, err error) {
+	a.Message = err.Error()
+	SendErrorResponse(w, a, StatusOf(err))
+}
+
+// StatusOf returns the status code of the kind of err.
+func StatusOf(err error) int {
+	switch services.KindOf(err) {
+	case services.KindInvalid:
+		return http.StatusBadRequest
+	case services.KindNotFound:
+		return http.StatusNotFound
+	case services.KindConflict:
+		return http.StatusConflict
+	case services.KindForbidden:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -9,17 +9,22 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httprate"
+	"github.com/princecee/escrow-api/cmd/app/api/admin"
 	"github.com/princecee/escrow-api/cmd/app/api/auth"
 	"github.com/princecee/escrow-api/cmd/app/api/businesses"
 	"github.com/princecee/escrow-api/cmd/app/api/customers"
+	"github.com/princecee/escrow-api/cmd/app/api/health"
 	"github.com/princecee/escrow-api/cmd/app/api/notifications"
 	"github.com/princecee/escrow-api/cmd/app/api/reports"
 	"github.com/princecee/escrow-api/cmd/app/api/reviews"
 	"github.com/princecee/escrow-api/cmd/app/api/transactions"
 	"github.com/princecee/escrow-api/cmd/app/api/users"
 	"github.com/princecee/escrow-api/cmd/app/api/wallets"
+	"github.com/princecee/escrow-api/cmd/app/middlewares"
 	"github.com/princecee/escrow-api/cmd/app/pkg/response"
 	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/pkg/metrics"
+	"github.com/princecee/escrow-api/pkg/tracing"
 )
 
 type routeFunc func(c config.IConfig) chi.Router
@@ -40,6 +45,7 @@ func initRoutes(c config.IConfig) chi.Router {
 		{"/customers", customers.CustomerRouter},
 		{"/reviews", reviews.ReviewsRouter},
 		{"/reports", reports.ReportRouter},
+		{"/admin", admin.AdminRouter},
 	}
 
 	r := chi.NewRouter()
@@ -54,10 +60,15 @@ func GetRouter(c config.IConfig) chi.Router {
 	apiRouter := initRoutes(c)
 	r := chi.NewRouter()
 
+	r.Use(tracing.Middleware)
+	r.Use(middleware.RequestID)
+	r.Use(metrics.Middleware)
+	r.Use(middlewares.RealIPMiddleware(c))
+	r.Use(middlewares.ClientIPMiddleware)
 	r.Use(httprate.LimitByIP(100, 1*time.Minute))
 	r.Use(middleware.CleanPath)
 
-	if c.Getenv("ENVIRONMENT") != "test" {
+	if c.GetSettings().Environment != "test" {
 		r.Use(middleware.Logger)
 	}
 
@@ -65,6 +76,7 @@ func GetRouter(c config.IConfig) chi.Router {
 	r.Use(middleware.Recoverer)
 	r.Use(cors.AllowAll().Handler)
 	r.Mount("/api/v1", apiRouter)
+	health.HealthRoutes(r, c)
 
 	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
 		resp := response.ApiResponse{Message: fmt.Sprintf("%s %s not found", r.Method, r.URL.Path)}
@@ -1,3 +1,57 @@
 package main
 
-func main() {}
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/pkg/metrics"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+	"github.com/rs/zerolog"
+)
+
+const pollInterval = 5 * time.Second
+
+func main() {
+	c := config.NewConfig()
+	defer c.DB.Close()
+	defer c.TracerProvider.Shutdown(context.Background())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := http.Server{
+		Addr:    ":" + c.Settings.MetricsPort,
+		Handler: metrics.Handler(),
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.GetLogger().Log(zerolog.ErrorLevel, "error serving metrics", nil, err)
+		}
+	}()
+	defer srv.Close()
+
+	worker := webhooks.NewWorker(c)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	c.GetLogger().Log(zerolog.InfoLevel, "webhook worker started", nil, nil)
+
+	for {
+		if _, err := worker.ProcessDue(ctx); err != nil {
+			c.GetLogger().Log(zerolog.ErrorLevel, "error processing webhooks", nil, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			c.GetLogger().Log(zerolog.InfoLevel, "webhook worker stopped", nil, nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
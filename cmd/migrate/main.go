@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/migrations"
+)
+
+const usage = `usage: migrate [-env development] [-config file] <command>
+
+commands:
+  up               apply all the migrations not applied yet
+  down [n]         revert the last n migrations, all of them when n is left out
+  to <version>     migrate up or down to version, 0 for an empty db
+  force <version>  record version without running anything, after fixing a dirty db
+  status           print the version of the db and the applied migrations
+`
+
+func main() {
+	var environment, configFile string
+
+	flag.StringVar(&environment, "env", "development", "The environment of the app(development/production)")
+	flag.StringVar(&configFile, "config", os.Getenv("CONFIG_FILE"), "Path of a YAML config file")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if environment == "development" {
+		if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			exit(fmt.Errorf("load .env: %w", err))
+		}
+	}
+
+	// the migrations run against the db the app is configured with
+	settings, err := config.LoadSettings(configFile)
+	if err != nil {
+		exit(err)
+	}
+
+	ctx := context.Background()
+
+	db, err := pgxpool.New(ctx, string(settings.DSN))
+	if err != nil {
+		exit(err)
+	}
+	defer db.Close()
+
+	m, err := migrations.New(db)
+	if err != nil {
+		exit(err)
+	}
+
+	if err := run(ctx, m, flag.Arg(0), flag.Args()[1:]); err != nil {
+		exit(err)
+	}
+}
+
+func run(ctx context.Context, m *migrations.Migrator, command string, args []string) error {
+	switch command {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		steps := 0
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations %q", args[0])
+			}
+			steps = n
+		}
+
+		return m.Down(ctx, steps)
+	case "to", "force":
+		if len(args) == 0 {
+			return fmt.Errorf("%s needs a version", command)
+		}
+
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+
+		if command == "force" {
+			return m.Force(ctx, uint(version))
+		}
+		return m.To(ctx, uint(version))
+	case "status":
+		s, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("version: %d (latest %d)\n", s.Version, m.Latest())
+		if s.Dirty {
+			fmt.Println("dirty: true")
+		}
+
+		for _, mig := range s.Migrations {
+			state := "pending"
+			if mig.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%08d_%s\t%s\n", mig.Version, mig.Name, state)
+		}
+
+		return nil
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "migrate:", err)
+	os.Exit(1)
+}
@@ -1,17 +1,29 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"flag"
+	"fmt"
+	"io/fs"
 	"os"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	"github.com/princecee/escrow-api/internal/models"
 	"github.com/princecee/escrow-api/internal/repositories"
 	"github.com/princecee/escrow-api/pkg/apis"
+	"github.com/princecee/escrow-api/pkg/apis/paystack"
+	"github.com/princecee/escrow-api/pkg/fees"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/princecee/escrow-api/pkg/fx"
+	"github.com/princecee/escrow-api/pkg/jwt"
+	"github.com/princecee/escrow-api/pkg/metrics"
 	"github.com/princecee/escrow-api/pkg/push"
+	"github.com/princecee/escrow-api/pkg/tracing"
 	"github.com/rs/zerolog"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 type Logger struct {
@@ -23,33 +35,6 @@ func NewLogger(l zerolog.Logger, level zerolog.Level) *Logger {
 	return &Logger{l, level}
 }
 
-func getLoggerLevel(loglevel string) zerolog.Level {
-	switch loglevel {
-	case zerolog.LevelTraceValue:
-		return zerolog.TraceLevel
-
-	case zerolog.LevelDebugValue:
-		return zerolog.DebugLevel
-
-	case zerolog.LevelInfoValue:
-		return zerolog.InfoLevel
-
-	case zerolog.LevelWarnValue:
-		return zerolog.WarnLevel
-
-	case zerolog.LevelErrorValue:
-		return zerolog.ErrorLevel
-
-	case zerolog.LevelFatalValue:
-		return zerolog.FatalLevel
-
-	case zerolog.LevelPanicValue:
-		return zerolog.PanicLevel
-	default:
-		panic(errors.New("invalid loglevel value"))
-	}
-}
-
 func (logger *Logger) Log(level zerolog.Level, msg string, data map[string]any, err error) {
 	var lEvent *zerolog.Event
 
@@ -88,7 +73,8 @@ func (logger *Logger) Log(level zerolog.Level, msg string, data map[string]any,
 }
 
 type IConfig interface {
-	Getenv(key string) string
+	GetSettings() *Settings
+	GetJWT() *jwt.JWT
 	GetAuthRepository() repositories.IAuthRepository
 	GetBusinessRepository() repositories.IBusinessRepository
 	GetEventRepository() repositories.IEventRepository
@@ -100,14 +86,28 @@ type IConfig interface {
 	GetBankAccountRepository() repositories.IBankAccountRepository
 	GetTransactionRepository() repositories.ITransactionRepository
 	GetTransactionTimelineRepository() repositories.ITransactionTimelineRepository
+	GetSignInAttemptRepository() repositories.ISignInAttemptRepository
+	GetDisputeRepository() repositories.IDisputeRepository
+	GetAuditLogRepository() repositories.IAuditLogRepository
+	GetAPIKeyRepository() repositories.IAPIKeyRepository
+	GetWebhookEndpointRepository() repositories.IWebhookEndpointRepository
+	GetWebhookDeliveryRepository() repositories.IWebhookDeliveryRepository
+	GetMilestoneRepository() repositories.IMilestoneRepository
+	GetFXQuoteRepository() repositories.IFXQuoteRepository
+	GetInviteRepository() repositories.IInviteRepository
 	GetDB() *pgxpool.Pool
 	GetRedisClient() *RedisClient
 	GetLogger() *Logger
 	GetPush() push.IPush
 	GetAPIs() apis.IAPIs
+	GetFeeEngine() *fees.Engine
+	GetFXEngine() *fx.Engine
 }
 
 type Config struct {
+	Settings                      *Settings
+	TracerProvider                *sdktrace.TracerProvider
+	JWT                           *jwt.JWT
 	AuthRepository                repositories.IAuthRepository
 	BusinessRepository            repositories.IBusinessRepository
 	EventRepository               repositories.IEventRepository
@@ -119,44 +119,95 @@ type Config struct {
 	BankAccountRepository         repositories.IBankAccountRepository
 	TransactionRepository         repositories.ITransactionRepository
 	TransactionTimelineRepository repositories.ITransactionTimelineRepository
+	SignInAttemptRepository       repositories.ISignInAttemptRepository
+	DisputeRepository             repositories.IDisputeRepository
+	AuditLogRepository            repositories.IAuditLogRepository
+	APIKeyRepository              repositories.IAPIKeyRepository
+	WebhookEndpointRepository     repositories.IWebhookEndpointRepository
+	WebhookDeliveryRepository     repositories.IWebhookDeliveryRepository
+	MilestoneRepository           repositories.IMilestoneRepository
+	FXQuoteRepository             repositories.IFXQuoteRepository
+	InviteRepository              repositories.IInviteRepository
 	DB                            *pgxpool.Pool
 	RedisClient                   *RedisClient
 	Logger                        *Logger
 	Push                          push.IPush
 	Apis                          apis.IAPIs
+	FeeEngine                     *fees.Engine
+	FXEngine                      *fx.Engine
 }
 
+// NewConfig loads the settings and connects everything the app runs on. It
+// exits with the list of invalid settings when they don't validate.
 func NewConfig() *Config {
-	var environment, loglevel string
+	var environment, loglevel, configFile string
 
 	flag.StringVar(&environment, "env", "development", "The environment of the app(development/production)")
-	flag.StringVar(&loglevel, "loglevel", "debug", "The logger log level")
+	flag.StringVar(&loglevel, "loglevel", "", "The logger log level, overriding LOG_LEVEL")
+	flag.StringVar(&configFile, "config", os.Getenv("CONFIG_FILE"), "Path of a YAML config file")
 	flag.Parse()
 
 	if environment == "development" {
-		if err := godotenv.Load(); err != nil {
-			panic(err)
+		if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			exit(fmt.Errorf("load .env: %w", err))
 		}
 	}
 
-	level := getLoggerLevel(loglevel)
+	if loglevel != "" {
+		os.Setenv("LOG_LEVEL", loglevel)
+	}
+
+	settings, err := LoadSettings(configFile)
+	if err != nil {
+		exit(err)
+	}
+
+	level, _ := ParseLogLevel(settings.LogLevel)
 	logger := NewLogger(
 		zerolog.New(os.Stderr).Level(level).With().Timestamp().Logger(),
 		level,
 	)
+	logger.Log(zerolog.InfoLevel, "config loaded", map[string]any{"config": settings}, nil)
+
+	exporter, err := tracing.NewExporter(context.Background(), settings.Tracing.Exporter, settings.Tracing.Endpoint, os.Stdout)
+	if err != nil {
+		logger.Log(zerolog.PanicLevel, "error configuring the trace exporter", nil, err)
+	}
+	tracerProvider := tracing.Setup(exporter, settings.Tracing.ServiceName, settings.Tracing.SampleRatio)
 
-	dbpool, err := configureDB(os.Getenv("DSN"))
+	dbpool, err := configureDB(string(settings.DSN))
 	if err != nil {
 		logger.Log(zerolog.PanicLevel, "error connecting to the db", nil, err)
 	}
 
-	rclient, err := NewRedisClient(os.Getenv("REDIS_URL"))
+	rclient, err := NewRedisClient(string(settings.RedisURL))
 	if err != nil {
 		logger.Log(zerolog.PanicLevel, "error instantiating redis client", nil, err)
 	}
 
+	schedules := fees.DefaultSchedules
+	if path := settings.FeeSchedulesPath; path != "" {
+		schedules, err = fees.LoadSchedules(path)
+		if err != nil {
+			logger.Log(zerolog.PanicLevel, "error loading the fee schedules", nil, err)
+		}
+	}
+
+	feeEngine, err := fees.NewEngine(schedules)
+	if err != nil {
+		logger.Log(zerolog.PanicLevel, "error configuring the fee engine", nil, err)
+	}
+
+	fxEngine, err := configureFX(settings.FX)
+	if err != nil {
+		logger.Log(zerolog.PanicLevel, "error configuring the fx engine", nil, err)
+	}
+
 	timeout := 10 * time.Second
-	return &Config{
+	c := &Config{
+		Settings:                      settings,
+		TracerProvider:                tracerProvider,
+		JWT:                           jwt.New(string(settings.JWTKey)),
 		DB:                            dbpool,
 		Logger:                        logger,
 		RedisClient:                   rclient,
@@ -171,12 +222,76 @@ func NewConfig() *Config {
 		BankAccountRepository:         repositories.NewBankAccountRepository(dbpool, timeout),
 		TransactionRepository:         repositories.NewTransactionRepository(dbpool, timeout),
 		TransactionTimelineRepository: repositories.NewTransactionTimelineRepository(dbpool, timeout),
-		Push:                          &push.Push{},
+		SignInAttemptRepository:       repositories.NewSignInAttemptRepository(dbpool, timeout),
+		DisputeRepository:             repositories.NewDisputeRepository(dbpool, timeout),
+		AuditLogRepository:            repositories.NewAuditLogRepository(dbpool, timeout),
+		APIKeyRepository:              repositories.NewAPIKeyRepository(dbpool, timeout),
+		WebhookEndpointRepository:     repositories.NewWebhookEndpointRepository(dbpool, timeout),
+		WebhookDeliveryRepository:     repositories.NewWebhookDeliveryRepository(dbpool, timeout),
+		MilestoneRepository:           repositories.NewMilestoneRepository(dbpool, timeout),
+		FXQuoteRepository:             repositories.NewFXQuoteRepository(dbpool, timeout),
+		InviteRepository:              repositories.NewInviteRepository(dbpool, timeout),
+		Push: push.NewPush(push.SMTP{
+			Host:     settings.Email.Host,
+			Port:     settings.Email.Port,
+			Username: settings.Email.Username,
+			Password: string(settings.Email.Password),
+			From:     settings.Email.From,
+		}),
+		Apis:      apis.NewAPIs(paystack.NewPaystackAPI(settings.Paystack.BaseURL, string(settings.Paystack.SecretKey))),
+		FeeEngine: feeEngine,
+		FXEngine:  fxEngine,
+	}
+
+	if err := registerMetrics(c); err != nil {
+		logger.Log(zerolog.PanicLevel, "error registering the metrics", nil, err)
 	}
+
+	return c
 }
 
-func (c *Config) Getenv(key string) string {
-	return os.Getenv(key)
+// registerMetrics reports the stats of the db pool and the depth of the
+// webhook outbox along with the other metrics.
+func registerMetrics(c *Config) error {
+	if err := metrics.RegisterPool(c.DB); err != nil {
+		return err
+	}
+
+	return metrics.RegisterOutbox(func(ctx context.Context) (int, error) {
+		f := filter.New(filter.Eq("status", models.WebhookDeliveryPending))
+		return c.WebhookDeliveryRepository.Count(ctx, f, nil)
+	})
+}
+
+// configureFX builds the fx engine from the rates file, spread and quote ttl
+// of s.
+func configureFX(s FXSettings) (*fx.Engine, error) {
+	var provider *fx.StaticProvider
+	var err error
+	if s.RatesPath != "" {
+		provider, err = fx.LoadStaticProvider(s.RatesPath)
+	} else {
+		provider, err = fx.NewStaticProvider(fx.DefaultRates)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fx.NewEngine(provider, s.Spread, time.Duration(s.QuoteTTL)*time.Second)
+}
+
+// exit stops the app on an error found before the logger exists.
+func exit(err error) {
+	fmt.Fprintf(os.Stderr, "invalid configuration:\n%s\n", err)
+	os.Exit(1)
+}
+
+func (c *Config) GetSettings() *Settings {
+	return c.Settings
+}
+
+func (c *Config) GetJWT() *jwt.JWT {
+	return c.JWT
 }
 
 func (c *Config) GetAuthRepository() repositories.IAuthRepository {
@@ -223,6 +338,42 @@ func (c *Config) GetTransactionTimelineRepository() repositories.ITransactionTim
 	return c.TransactionTimelineRepository
 }
 
+func (c *Config) GetSignInAttemptRepository() repositories.ISignInAttemptRepository {
+	return c.SignInAttemptRepository
+}
+
+func (c *Config) GetDisputeRepository() repositories.IDisputeRepository {
+	return c.DisputeRepository
+}
+
+func (c *Config) GetAuditLogRepository() repositories.IAuditLogRepository {
+	return c.AuditLogRepository
+}
+
+func (c *Config) GetAPIKeyRepository() repositories.IAPIKeyRepository {
+	return c.APIKeyRepository
+}
+
+func (c *Config) GetWebhookEndpointRepository() repositories.IWebhookEndpointRepository {
+	return c.WebhookEndpointRepository
+}
+
+func (c *Config) GetWebhookDeliveryRepository() repositories.IWebhookDeliveryRepository {
+	return c.WebhookDeliveryRepository
+}
+
+func (c *Config) GetMilestoneRepository() repositories.IMilestoneRepository {
+	return c.MilestoneRepository
+}
+
+func (c *Config) GetFXQuoteRepository() repositories.IFXQuoteRepository {
+	return c.FXQuoteRepository
+}
+
+func (c *Config) GetInviteRepository() repositories.IInviteRepository {
+	return c.InviteRepository
+}
+
 func (c *Config) GetDB() *pgxpool.Pool {
 	return c.DB
 }
@@ -236,9 +387,17 @@ func (c *Config) GetLogger() *Logger {
 }
 
 func (c *Config) GetPush() push.IPush {
-	return &push.Push{}
+	return c.Push
 }
 
 func (c *Config) GetAPIs() apis.IAPIs {
-	return apis.NewAPIs()
+	return c.Apis
+}
+
+func (c *Config) GetFeeEngine() *fees.Engine {
+	return c.FeeEngine
+}
+
+func (c *Config) GetFXEngine() *fx.Engine {
+	return c.FXEngine
 }
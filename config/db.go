@@ -7,6 +7,7 @@ import (
 	pgxuuid "github.com/jackc/pgx-gofrs-uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/repositories"
 )
 
 func configureDB(dsn string) (*pgxpool.Pool, error) {
@@ -33,4 +34,6 @@ func setupHooks(parseConfig *pgxpool.Config) {
 		pgxuuid.Register(c.TypeMap())
 		return nil
 	}
+
+	parseConfig.ConnConfig.Tracer = repositories.NewQueryTracer()
 }
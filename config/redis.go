@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/princecee/escrow-api/pkg/tracing"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -17,7 +18,10 @@ func NewRedisClient(redisUrl string) (*RedisClient, error) {
 		return nil, err
 	}
 
-	return &RedisClient{DB: redis.NewClient(opts)}, nil
+	client := redis.NewClient(opts)
+	client.AddHook(tracing.RedisHook{})
+
+	return &RedisClient{DB: client}, nil
 }
 
 func (rclient *RedisClient) Set(key string, value any, exp time.Duration) error {
@@ -33,3 +37,7 @@ func (rclient *RedisClient) Get(key string) error {
 
 	return rclient.DB.Get(ctx, key).Err()
 }
+
+func (rclient *RedisClient) Ping(ctx context.Context) error {
+	return rclient.DB.Ping(ctx).Err()
+}
@@ -0,0 +1,320 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/princecee/escrow-api/pkg/tracing"
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+// Secret is a setting that must not end up in logs. It prints and marshals
+// redacted; convert it to a string for its value.
+type Secret string
+
+const redacted = "[REDACTED]"
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(s.String())), nil
+}
+
+type PaystackSettings struct {
+	BaseURL   string `yaml:"base_url" json:"base_url"`
+	SecretKey Secret `yaml:"secret_key" json:"secret_key"`
+}
+
+type EmailSettings struct {
+	Host     string `yaml:"host" json:"host"`
+	Port     string `yaml:"port" json:"port"`
+	Username string `yaml:"username" json:"username"`
+	Password Secret `yaml:"password" json:"password"`
+	From     string `yaml:"from" json:"from"`
+}
+
+type FXSettings struct {
+	RatesPath string `yaml:"rates_path" json:"rates_path"`
+	Spread    int    `yaml:"spread" json:"spread"`       // basis points
+	QuoteTTL  int    `yaml:"quote_ttl" json:"quote_ttl"` // seconds
+}
+
+type TracingSettings struct {
+	Exporter    string  `yaml:"exporter" json:"exporter"` // none, stdout or otlp
+	Endpoint    string  `yaml:"endpoint" json:"endpoint"` // otlp collector url
+	ServiceName string  `yaml:"service_name" json:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio" json:"sample_ratio"` // of the traces started by the app
+}
+
+// Settings is the configuration of the app. It is read once at startup, from
+// an optional YAML file and then the environment, whose variables win over
+// the file.
+type Settings struct {
+	Environment          string           `yaml:"environment" json:"environment"`
+	Port                 string           `yaml:"port" json:"port"`
+	MetricsPort          string           `yaml:"metrics_port" json:"metrics_port"` // internal listener of /metrics
+	LogLevel             string           `yaml:"log_level" json:"log_level"`
+	DSN                  Secret           `yaml:"dsn" json:"dsn"`
+	RedisURL             Secret           `yaml:"redis_url" json:"redis_url"`
+	JWTKey               Secret           `yaml:"jwt_key" json:"jwt_key"`
+	CursorKey            Secret           `yaml:"cursor_key" json:"cursor_key"` // signs page cursors, apart from tokens
+	InviteKey            Secret           `yaml:"invite_key" json:"invite_key"` // signs invite links, apart from tokens
+	InviteURL            string           `yaml:"invite_url" json:"invite_url"`
+	FeeSchedulesPath     string           `yaml:"fee_schedules_path" json:"fee_schedules_path"`
+	ShutdownTimeout      int              `yaml:"shutdown_timeout" json:"shutdown_timeout"`             // seconds
+	TrustedProxies       []string         `yaml:"trusted_proxies" json:"trusted_proxies"`               // ips or cidrs allowed to forward the client ip
+	AllowPrivateWebhooks bool             `yaml:"allow_private_webhooks" json:"allow_private_webhooks"` // for receivers next to the app while integrating
+	FX                   FXSettings       `yaml:"fx" json:"fx"`
+	Paystack             PaystackSettings `yaml:"paystack" json:"paystack"`
+	Email                EmailSettings    `yaml:"email" json:"email"`
+	Tracing              TracingSettings  `yaml:"tracing" json:"tracing"`
+}
+
+// DefaultSettings are the settings left unset by the file and environment.
+var DefaultSettings = Settings{
+	Environment:     "development",
+	Port:            "8080",
+	MetricsPort:     "9090",
+	LogLevel:        zerolog.LevelDebugValue,
+	InviteURL:       "https://escrow.app/invites",
+	ShutdownTimeout: 30,
+	FX: FXSettings{
+		Spread:   100,
+		QuoteTTL: 60,
+	},
+	Paystack: PaystackSettings{
+		BaseURL: "https://api.paystack.co",
+	},
+	Tracing: TracingSettings{
+		Exporter:    tracing.ExporterNone,
+		ServiceName: "escrow-api",
+		SampleRatio: 1,
+	},
+}
+
+// LoadSettings reads the settings from the YAML file at path, when given, and
+// the environment, and validates them. The error lists every invalid setting
+// at once.
+func LoadSettings(path string) (*Settings, error) {
+	s := DefaultSettings
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+
+		if err := yaml.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := s.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (s *Settings) loadEnv() error {
+	values := map[string]*string{
+		"ENVIRONMENT":          &s.Environment,
+		"PORT":                 &s.Port,
+		"METRICS_PORT":         &s.MetricsPort,
+		"LOG_LEVEL":            &s.LogLevel,
+		"INVITE_URL":           &s.InviteURL,
+		"FEE_SCHEDULES_PATH":   &s.FeeSchedulesPath,
+		"FX_RATES_PATH":        &s.FX.RatesPath,
+		"PAYSTACK_BASE_URL":    &s.Paystack.BaseURL,
+		"EMAIL_HOST":           &s.Email.Host,
+		"EMAIL_PORT":           &s.Email.Port,
+		"EMAIL_USERNAME":       &s.Email.Username,
+		"EMAIL_FROM":           &s.Email.From,
+		"TRACING_EXPORTER":     &s.Tracing.Exporter,
+		"TRACING_ENDPOINT":     &s.Tracing.Endpoint,
+		"TRACING_SERVICE_NAME": &s.Tracing.ServiceName,
+	}
+	for key, v := range values {
+		if value, ok := os.LookupEnv(key); ok {
+			*v = value
+		}
+	}
+
+	secrets := map[string]*Secret{
+		"DSN":                 &s.DSN,
+		"REDIS_URL":           &s.RedisURL,
+		"JWT_KEY":             &s.JWTKey,
+		"CURSOR_KEY":          &s.CursorKey,
+		"INVITE_KEY":          &s.InviteKey,
+		"PAYSTACK_SECRET_KEY": &s.Paystack.SecretKey,
+		"EMAIL_PASSWORD":      &s.Email.Password,
+	}
+	for key, v := range secrets {
+		if value, ok := os.LookupEnv(key); ok {
+			*v = Secret(value)
+		}
+	}
+
+	errs := []error{}
+	if value, ok := os.LookupEnv("TRUSTED_PROXIES"); ok {
+		s.TrustedProxies = strings.Split(value, ",")
+	}
+
+	ints := map[string]*int{
+		"FX_SPREAD":        &s.FX.Spread,
+		"FX_QUOTE_TTL":     &s.FX.QuoteTTL,
+		"SHUTDOWN_TIMEOUT": &s.ShutdownTimeout,
+	}
+	for key, v := range ints {
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a number, got %q", key, value))
+			continue
+		}
+		*v = n
+	}
+
+	bools := map[string]*bool{
+		"ALLOW_PRIVATE_WEBHOOKS": &s.AllowPrivateWebhooks,
+	}
+	for key, v := range bools {
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+			continue
+		}
+		*v = b
+	}
+
+	floats := map[string]*float64{
+		"TRACING_SAMPLE_RATIO": &s.Tracing.SampleRatio,
+	}
+	for key, v := range floats {
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a number, got %q", key, value))
+			continue
+		}
+		*v = f
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the settings are complete and well formed.
+func (s *Settings) Validate() error {
+	errs := []error{}
+	required := func(name string, v string) {
+		if v == "" {
+			errs = append(errs, fmt.Errorf("%s is required", name))
+		}
+	}
+
+	switch s.Environment {
+	case "development", "production", "test":
+	default:
+		errs = append(errs, fmt.Errorf("ENVIRONMENT must be one of development, production or test, got %q", s.Environment))
+	}
+
+	if _, err := ParseLogLevel(s.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+
+	required("PORT", s.Port)
+	required("METRICS_PORT", s.MetricsPort)
+	if s.MetricsPort == s.Port {
+		errs = append(errs, errors.New("METRICS_PORT must differ from PORT, metrics are not served to the public"))
+	}
+	required("DSN", string(s.DSN))
+	required("REDIS_URL", string(s.RedisURL))
+	required("JWT_KEY", string(s.JWTKey))
+	required("CURSOR_KEY", string(s.CursorKey))
+	required("INVITE_KEY", string(s.InviteKey))
+
+	if s.Environment == "production" {
+		required("PAYSTACK_SECRET_KEY", string(s.Paystack.SecretKey))
+		required("EMAIL_HOST", s.Email.Host)
+		required("EMAIL_PORT", s.Email.Port)
+
+		if s.AllowPrivateWebhooks {
+			errs = append(errs, errors.New("ALLOW_PRIVATE_WEBHOOKS can not be set in production"))
+		}
+	}
+
+	if s.FX.Spread < 0 {
+		errs = append(errs, errors.New("FX_SPREAD can not be negative"))
+	}
+	if s.FX.QuoteTTL <= 0 {
+		errs = append(errs, errors.New("FX_QUOTE_TTL must be positive"))
+	}
+	if s.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT must be positive"))
+	}
+
+	if _, err := utils.ParseNetworks(s.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %w", err))
+	}
+
+	switch s.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be one of none, stdout or otlp, got %q", s.Tracing.Exporter))
+	}
+	if s.Tracing.SampleRatio < 0 || s.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("TRACING_SAMPLE_RATIO must be between 0 and 1"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ParseLogLevel returns the zerolog level named loglevel.
+func ParseLogLevel(loglevel string) (zerolog.Level, error) {
+	switch loglevel {
+	case zerolog.LevelTraceValue:
+		return zerolog.TraceLevel, nil
+	case zerolog.LevelDebugValue:
+		return zerolog.DebugLevel, nil
+	case zerolog.LevelInfoValue:
+		return zerolog.InfoLevel, nil
+	case zerolog.LevelWarnValue:
+		return zerolog.WarnLevel, nil
+	case zerolog.LevelErrorValue:
+		return zerolog.ErrorLevel, nil
+	case zerolog.LevelFatalValue:
+		return zerolog.FatalLevel, nil
+	case zerolog.LevelPanicValue:
+		return zerolog.PanicLevel, nil
+	default:
+		return zerolog.NoLevel, fmt.Errorf("LOG_LEVEL must be one of trace, debug, info, warn, error, fatal or panic, got %q", loglevel)
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+const (
+	APIKeyEnvironmentTest = "test"
+	APIKeyEnvironmentLive = "live"
+)
+
+// APIKeyPrefixes are prepended to the secret so test and live keys can be told
+// apart at a glance and by secret scanners.
+var APIKeyPrefixes = map[string]string{
+	APIKeyEnvironmentTest: "esk_test_",
+	APIKeyEnvironmentLive: "esk_live_",
+}
+
+type Scope string
+
+const (
+	ScopeTransactionsRead  Scope = "transactions:read"
+	ScopeTransactionsWrite Scope = "transactions:write"
+	ScopeWalletRead        Scope = "wallet:read"
+)
+
+// APIKey lets a business authenticate server to server. Only the sha256 of the
+// secret is stored; Prefix keeps enough of it to recognise the key in lists.
+type APIKey struct {
+	BusinessID  string   `json:"business_id" db:"business_id"`
+	CreatedBy   string   `json:"created_by" db:"created_by"`
+	Name        string   `json:"name" db:"name"`
+	Prefix      string   `json:"prefix" db:"prefix"`
+	KeyHash     string   `json:"-" db:"key_hash"`
+	Environment string   `json:"environment" db:"environment"`
+	Scopes      []string `json:"scopes" db:"scopes"`
+	LastUsedAt  NullTime `json:"last_used_at" db:"last_used_at"`
+	RevokedAt   NullTime `json:"revoked_at" db:"revoked_at"`
+	ModelMixin
+}
+
+func (k *APIKey) HasScope(s Scope) bool {
+	for _, v := range k.Scopes {
+		if v == string(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt.Valid
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	AuditActorUser      = "user"
+	AuditActorAnonymous = "anonymous"
+	AuditActorSystem    = "system"
+	AuditActorAPIKey    = "api_key"
+)
+
+// GenesisHash is the prev_hash of the first entry in the audit log chain.
+var GenesisHash = strings.Repeat("0", 64)
+
+type AuditChange struct {
+	From any `json:"from,omitempty"`
+	To   any `json:"to,omitempty"`
+}
+
+// AuditLog is an append-only record of a sensitive action. Every entry stores
+// the hash of the entry before it, so editing or removing a row breaks the
+// chain from that point on.
+type AuditLog struct {
+	ID         string                 `json:"id"`
+	Seq        int64                  `json:"seq"`
+	ActorID    *string                `json:"actor_id"`
+	ActorType  string                 `json:"actor_type"`
+	Action     string                 `json:"action"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   string                 `json:"entity_id"`
+	Changes    map[string]AuditChange `json:"changes"`
+	IPAddress  string                 `json:"ip_address"`
+	RequestID  string                 `json:"request_id"`
+	PrevHash   string                 `json:"prev_hash"`
+	Hash       string                 `json:"hash"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// ComputeHash returns the sha256 of the entry's content chained to PrevHash.
+// The sequence number is left out since it is only known after the insert.
+func (l *AuditLog) ComputeHash() string {
+	var actorId string
+	if l.ActorID != nil {
+		actorId = *l.ActorID
+	}
+
+	changes, _ := json.Marshal(l.Changes)
+	content, _ := json.Marshal([]string{
+		l.PrevHash,
+		actorId,
+		l.ActorType,
+		l.Action,
+		l.EntityType,
+		l.EntityID,
+		string(changes),
+		l.IPAddress,
+		l.RequestID,
+		l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,8 +1,15 @@
 package models
 
+const (
+	BusinessTierStandard   = "standard"
+	BusinessTierGrowth     = "growth"
+	BusinessTierEnterprise = "enterprise"
+)
+
 type Business struct {
 	Name     string `json:"name" db:"name"`
 	Email    string `json:"email" db:"email"`
 	ImageUrl string `json:"image_url,omitempty" db:"image_url"`
+	Tier     string `json:"tier" db:"tier"` // picks the fee schedule of the business's sales
 	ModelMixin
 }
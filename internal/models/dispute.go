@@ -0,0 +1,23 @@
+package models
+
+const (
+	DisputeStatusOpen     = "Open"
+	DisputeStatusResolved = "Resolved"
+)
+
+const (
+	DisputeResolutionRefundBuyer   = "refund_buyer"
+	DisputeResolutionReleaseSeller = "release_seller"
+)
+
+type Dispute struct {
+	TransactionID  string     `json:"transaction_id" db:"transaction_id"`
+	RaisedBy       string     `json:"raised_by" db:"raised_by"`
+	Reason         string     `json:"reason" db:"reason"`
+	Status         string     `json:"status" db:"status"`
+	Resolution     NullString `json:"resolution" db:"resolution"`
+	ResolutionNote string     `json:"resolution_note" db:"resolution_note"`
+	ResolvedBy     *string    `json:"resolved_by" db:"resolved_by"`
+	ResolvedAt     NullTime   `json:"resolved_at" db:"resolved_at"`
+	ModelMixin
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/princecee/escrow-api/pkg/fx"
+)
+
+const (
+	FXQuotePending  = "Pending"
+	FXQuoteExecuted = "Executed"
+)
+
+// FXQuote locks the rate of a conversion between two wallets of the same
+// owner until it expires.
+type FXQuote struct {
+	Identifier      string    `json:"identifier" db:"identifier"`
+	AccountType     string    `json:"account_type" db:"account_type"`
+	FromCurrency    string    `json:"from_currency" db:"from_currency"`
+	ToCurrency      string    `json:"to_currency" db:"to_currency"`
+	Amount          int       `json:"amount" db:"amount"`
+	ConvertedAmount int       `json:"converted_amount" db:"converted_amount"`
+	MidRate         fx.Rate   `json:"mid_rate" db:"mid_rate"`
+	Rate            fx.Rate   `json:"rate" db:"rate"`
+	Spread          int       `json:"spread" db:"spread"`
+	Status          string    `json:"status" db:"status"`
+	ExpiresAt       time.Time `json:"expires_at" db:"expires_at"`
+	ExecutedAt      NullTime  `json:"executed_at" db:"executed_at"`
+	ModelMixin
+}
+
+func (q *FXQuote) IsExpired() bool {
+	return time.Now().UTC().After(q.ExpiresAt)
+}
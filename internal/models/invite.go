@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	InvitePending  = "Pending"
+	InviteAccepted = "Accepted"
+)
+
+// InviteExpiresIn is how long an invite link stays valid.
+const InviteExpiresIn = 14 * 24 * time.Hour
+
+// Invite brings a counterparty who has no account yet into a transaction.
+// The transaction points at a placeholder user, or business for an invited
+// seller, until the invitee signs up and the invite is accepted.
+type Invite struct {
+	TransactionID string     `json:"transaction_id" db:"transaction_id"`
+	InviterID     string     `json:"inviter_id" db:"inviter_id"`
+	Role          string     `json:"role" db:"role"` // the side the invitee joins as, Buyer or Seller
+	Email         NullString `json:"email" db:"email"`
+	PhoneNumber   NullString `json:"phone_number" db:"phone_number"`
+	PlaceholderID string     `json:"placeholder_id" db:"placeholder_id"`
+	Status        string     `json:"status" db:"status"`
+	AcceptedBy    NullString `json:"accepted_by" db:"accepted_by"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt    NullTime   `json:"accepted_at" db:"accepted_at"`
+	ModelMixin
+}
+
+func (i *Invite) IsExpired() bool {
+	return time.Now().UTC().After(i.ExpiresAt)
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	MilestoneStatusPendingPayment = "Pending-Payment"
+	MilestoneStatusFunded         = "Funded"
+	MilestoneStatusDelivered      = "Delivered"
+	MilestoneStatusReleased       = "Released"
+)
+
+// Milestone is one ordered, separately paid part of a service transaction.
+// Its charges follow the charge configuration of the transaction.
+type Milestone struct {
+	TransactionID    string    `json:"transaction_id" db:"transaction_id"`
+	Position         int       `json:"position" db:"position"`
+	Title            string    `json:"title" db:"title"`
+	Description      string    `json:"description" db:"description"`
+	Amount           int       `json:"amount" db:"amount"`
+	Charges          int       `json:"charges" db:"charges"`
+	ReceivableAmount int       `json:"receivable_amount" db:"receivable_amount"`
+	DueDate          time.Time `json:"due_date" db:"due_date"`
+	Status           string    `json:"status" db:"status"`
+	FundedAt         NullTime  `json:"funded_at" db:"funded_at"`
+	DeliveredAt      NullTime  `json:"delivered_at" db:"delivered_at"`
+	ReleasedAt       NullTime  `json:"released_at" db:"released_at"`
+	ModelMixin
+}
+
+// BuyerPayable is what the buyer pays into escrow to fund the milestone.
+func (m *Milestone) BuyerPayable(c ChargeConfiguration) int {
+	buyerFee, _ := c.Split(m.Charges)
+	return m.Amount + buyerFee
+}
+
+// IsFunded reports whether the milestone's money is held in escrow or was
+// already released.
+func (m *Milestone) IsFunded() bool {
+	return m.Status != MilestoneStatusPendingPayment
+}
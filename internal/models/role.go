@@ -0,0 +1,52 @@
+package models
+
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+type Permission string
+
+const (
+	PermissionReadUsers        Permission = "users:read"
+	PermissionManageRoles      Permission = "roles:manage"
+	PermissionReadTransactions Permission = "transactions:read"
+	PermissionFreezeWallets    Permission = "wallets:freeze"
+	PermissionAdjustWallets    Permission = "wallets:adjust"
+	PermissionResolveDisputes  Permission = "disputes:resolve"
+	PermissionReadAuditLogs    Permission = "audit:read"
+	PermissionManageBusinesses Permission = "businesses:manage"
+)
+
+// RolePermissions lists what each role is allowed to do. Plain users get
+// nothing here; their access is decided by ownership checks in the handlers.
+var RolePermissions = map[string][]Permission{
+	RoleUser: {},
+	RoleSupport: {
+		PermissionReadUsers,
+		PermissionReadTransactions,
+		PermissionFreezeWallets,
+		PermissionResolveDisputes,
+	},
+	RoleAdmin: {
+		PermissionReadUsers,
+		PermissionManageRoles,
+		PermissionReadTransactions,
+		PermissionFreezeWallets,
+		PermissionAdjustWallets,
+		PermissionResolveDisputes,
+		PermissionReadAuditLogs,
+		PermissionManageBusinesses,
+	},
+}
+
+func HasPermission(role string, p Permission) bool {
+	for _, v := range RolePermissions[role] {
+		if v == p {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,25 @@
+package models
+
+const (
+	SignInOutcomeSuccess = "success"
+	SignInOutcomeFailed  = "failed"
+	SignInOutcomeLocked  = "locked"
+)
+
+const (
+	SignInReasonAccountNotFound     = "account_not_found"
+	SignInReasonInvalidCredentials  = "invalid_credentials"
+	SignInReasonEmailNotVerified    = "email_not_verified"
+	SignInReasonPhoneNotVerified    = "phone_number_not_verified"
+	SignInReasonTooManyFailedLogins = "too_many_failed_attempts"
+)
+
+type SignInAttempt struct {
+	UserID    *string `json:"user_id,omitempty" db:"user_id"`
+	Email     string  `json:"email" db:"email"`
+	IPAddress string  `json:"ip_address" db:"ip_address"`
+	UserAgent string  `json:"user_agent" db:"user_agent"`
+	Outcome   string  `json:"outcome" db:"outcome"`
+	Reason    string  `json:"reason,omitempty" db:"reason"`
+	ModelMixin
+}
@@ -1,5 +1,7 @@
 package models
 
+import "github.com/princecee/escrow-api/pkg/money"
+
 const (
 	TransactionTypeProduct = "Product"
 	TransactionTypeService = "Service"
@@ -15,6 +17,7 @@ const (
 	TransactionStatusAwaiting        = "Sent-Awaiting"
 	TransactionStatusPendingPayment  = "Pending-Payment"
 	TransactionStatusPendingDelivery = "Pending-Delivery"
+	TransactionStatusDisputed        = "Disputed"
 	TransactionStatusCanceled        = "Canceled"
 	TransactionStatusCompleted       = "Completed"
 )
@@ -24,11 +27,29 @@ type ChargeConfiguration struct {
 	SellerCharges int
 }
 
+// Split divides charges between the buyer and the seller by their shares.
+// The two shares always add up to charges, and a configuration without
+// shares leaves all of it to the seller.
+func (c ChargeConfiguration) Split(charges int) (buyer, seller int) {
+	parts, err := money.Allocate(int64(charges), int64(c.BuyerCharges), int64(c.SellerCharges))
+	if err != nil {
+		return 0, charges
+	}
+
+	return int(parts[0]), int(parts[1])
+}
+
+const (
+	ProductDetailAccepted = "Accepted"
+	ProductDetailRejected = "Rejected"
+)
+
 type ProductDetail struct {
 	Name        string
 	Quantity    int
 	Description string
 	Price       int
+	Status      string // set once the buyer accepts or rejects the line
 }
 
 type Transaction struct {
@@ -45,8 +66,59 @@ type Transaction struct {
 	TotalCost           int                 `json:"total_cost" db:"total_cost"`
 	Charges             int                 `json:"charges" db:"charges"`
 	ReceivableAmount    int                 `json:"receivable_amount" db:"receivable_amount"`
+	PaidAmount          int                 `json:"paid_amount" db:"paid_amount"` // what the buyer paid into escrow, milestones are paid on their own
 	Seller              *Business           `json:"seller" db:"-"`
 	Buyer               *User               `json:"buyer" db:"-"`
 	Timeline            []*TransactionTimeline
+	Milestones          []*Milestone `json:"milestones,omitempty" db:"-"`
 	ModelMixin
 }
+
+// ItemCost is what one product detail line costs: the price times the
+// quantity for products and the price alone otherwise.
+func (t *Transaction) ItemCost(d ProductDetail) (money.Money, error) {
+	price := money.New(int64(d.Price), t.Currency)
+	if t.Type == TransactionTypeProduct {
+		return price.Mul(int64(d.Quantity))
+	}
+
+	return price, nil
+}
+
+// BuyerPayable is what the buyer pays into escrow: the cost of the items plus
+// the buyer's share of the charges.
+func (t *Transaction) BuyerPayable() int {
+	buyerFee, _ := t.ChargeConfiguration.Split(t.Charges)
+	return t.TotalCost + buyerFee
+}
+
+// Held is what is still held in escrow for the transaction: what refunding the
+// buyer credits back and what releasing to the seller pays out. It follows the
+// payments recorded, not the prices: with milestones only the ones funded and
+// not yet released count, otherwise what the buyer paid, and product lines the
+// buyer already settled were paid out when they were settled.
+func (t *Transaction) Held(milestones []*Milestone) (refund, release int) {
+	if len(milestones) > 0 {
+		for _, m := range milestones {
+			if m.Status == MilestoneStatusFunded || m.Status == MilestoneStatusDelivered {
+				refund += m.BuyerPayable(t.ChargeConfiguration)
+				release += m.ReceivableAmount
+			}
+		}
+
+		return refund, release
+	}
+
+	if t.PaidAmount == 0 {
+		return 0, 0
+	}
+
+	// settlement decides every line at once and pays all of it out
+	for _, d := range t.ProductDetails {
+		if d.Status != "" {
+			return 0, 0
+		}
+	}
+
+	return t.PaidAmount, t.ReceivableAmount
+}
@@ -1,17 +1,25 @@
 package models
 
 const (
-	TimelineCreated          = "Transaction Created"
-	TimelineApproved         = "Transaction Approved"
-	TimelinePaymentSubmitted = "Payment Submitted"
-	TimelineDeliveryDone     = "Delivery Done"
-	TimelineCompleted        = "Marked As Completed"
-	TImelineCanceled         = "Transaction Canceled"
+	TimelineCreated            = "Transaction Created"
+	TimelineApproved           = "Transaction Approved"
+	TimelinePaymentSubmitted   = "Payment Submitted"
+	TimelineDeliveryDone       = "Delivery Done"
+	TimelineCompleted          = "Marked As Completed"
+	TImelineCanceled           = "Transaction Canceled"
+	TimelineDisputeOpened      = "Dispute Opened"
+	TimelineDisputeResolved    = "Dispute Resolved"
+	TimelineMilestoneFunded    = "Milestone Funded"
+	TimelineMilestoneDelivered = "Milestone Delivered"
+	TimelineMilestoneReleased  = "Milestone Released"
+	TimelineItemsAccepted      = "Items Accepted"
+	TimelineItemsRejected      = "Items Rejected"
 )
 
 type TransactionTimeline struct {
 	Name          string       `json:"name" db:"name"`
 	TransactionID string       `json:"transaction_id" db:"transaction_id"`
+	MilestoneID   *string      `json:"milestone_id,omitempty" db:"milestone_id"`
 	Transaction   *Transaction `json:"transaction" db:"-"`
 	ModelMixin
 }
@@ -17,5 +17,6 @@ type User struct {
 	BusinessID            *string    `json:"business_id,omitempty" db:"business_id,omitempty"`
 	Business              *Business  `json:"business,omitempty" db:"-"`
 	ImageUrl              string     `json:"image_url,omitempty" db:"image_url,omitempty"`
+	Role                  string     `json:"role,omitempty" db:"role"`
 	ModelMixin
 }
@@ -1,11 +1,19 @@
 package models
 
+import "errors"
+
+var ErrInsufficientFunds = errors.New("insufficient balance")
+
+// Wallet holds the balance of a user or business in one currency. An owner
+// has a wallet per currency they have held.
 type Wallet struct {
 	Balance     int       `json:"balance" db:"balance"`
 	Receivable  int       `json:"receivable_balance" db:"receivable_balance"`
 	Payable     int       `json:"payable_balance" db:"payable_balance"`
+	Currency    string    `json:"currency" db:"currency"`
 	AccountType string    `json:"account_type" db:"account_type"`
 	Identifier  string    `json:"identifier" db:"identifier"`
+	IsFrozen    bool      `json:"is_frozen" db:"is_frozen"`
 	User        *User     `json:"user,omitempty" db:"-"`
 	Business    *Business `json:"business,omitempty" db:"-"`
 	ModelMixin
@@ -3,6 +3,8 @@ package models
 const (
 	WalletHistoryWithdrawalType = "Withdrawal"
 	WalletHistoryDepositType    = "Deposit"
+	WalletHistoryAdjustmentType = "Adjustment"
+	WalletHistoryConversionType = "Conversion"
 )
 
 const (
@@ -16,6 +18,7 @@ type WalletHistory struct {
 	Type     string `json:"type" db:"type"`
 	Amount   int    `json:"amount" db:"amount"`
 	Status   string `json:"status" db:"status"`
+	Note     string `json:"note,omitempty" db:"note"`
 	Wallet   Wallet `json:"wallet,omitempty" db:"-"`
 	ModelMixin
 }
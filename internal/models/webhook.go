@@ -0,0 +1,52 @@
+package models
+
+import "encoding/json"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEndpoint is a merchant url subscribed to a set of event types. The
+// secret signs every payload sent to it and is only shown on creation.
+type WebhookEndpoint struct {
+	BusinessID  string   `json:"business_id" db:"business_id"`
+	URL         string   `json:"url" db:"url"`
+	Secret      string   `json:"-" db:"secret"`
+	EventTypes  []string `json:"event_types" db:"event_types"`
+	Description string   `json:"description" db:"description"`
+	IsActive    bool     `json:"is_active" db:"is_active"`
+	ModelMixin
+}
+
+func (e *WebhookEndpoint) IsSubscribed(eventType string) bool {
+	for _, v := range e.EventTypes {
+		if v == eventType || v == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookDelivery is one event sent to one endpoint, with the outcome of its
+// latest attempt. Payload is kept byte for byte so redeliveries are identical.
+// ResponseBody is kept for support and never handed back to merchants.
+// TraceContext is the W3C trace context of the request that raised the
+// event, which its attempts carry on.
+type WebhookDelivery struct {
+	EndpointID     string            `json:"endpoint_id" db:"endpoint_id"`
+	EventID        string            `json:"event_id" db:"event_id"`
+	EventType      string            `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage   `json:"payload" db:"payload"`
+	Status         string            `json:"status" db:"status"`
+	Attempts       int               `json:"attempts" db:"attempts"`
+	NextAttemptAt  NullTime          `json:"next_attempt_at" db:"next_attempt_at"`
+	LastAttemptAt  NullTime          `json:"last_attempt_at" db:"last_attempt_at"`
+	ResponseStatus int               `json:"response_status" db:"response_status"`
+	ResponseBody   string            `json:"-" db:"response_body"`
+	Error          string            `json:"error" db:"error"`
+	TraceContext   map[string]string `json:"-" db:"trace_context"`
+	ModelMixin
+}
@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
+)
+
+type IAPIKeyRepository interface {
+	Create(ctx context.Context, k *models.APIKey, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.APIKey, error)
+	GetByHash(ctx context.Context, hash string, tx pgx.Tx) (*models.APIKey, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.APIKey, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	Revoke(ctx context.Context, k *models.APIKey, tx pgx.Tx) error
+	TouchLastUsed(ctx context.Context, id string, tx pgx.Tx) error
+}
+
+type APIKeyRepository struct {
+	DB      *pgxpool.Pool
+	Timeout time.Duration
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool, timeout time.Duration) *APIKeyRepository {
+	return &APIKeyRepository{DB: db, Timeout: timeout}
+}
+
+const apiKeySelectQuery = `
+	SELECT
+		id,
+		business_id,
+		created_by,
+		name,
+		prefix,
+		key_hash,
+		environment,
+		scopes,
+		last_used_at,
+		revoked_at,
+		created_at,
+		updated_at,
+		deleted_at,
+		version
+	FROM
+		api_keys
+`
+
+func (repo *APIKeyRepository) Create(ctx context.Context, k *models.APIKey, tx pgx.Tx) error {
+	now := time.Now().UTC()
+	k.CreatedAt = now
+	k.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO api_keys (business_id, created_by, name, prefix, key_hash, environment, scopes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, version
+	`
+
+	args := []any{
+		k.BusinessID,
+		k.CreatedBy,
+		k.Name,
+		k.Prefix,
+		k.KeyHash,
+		k.Environment,
+		k.Scopes,
+		k.CreatedAt,
+		k.UpdatedAt,
+	}
+
+	var id uuid.UUID
+	if tx != nil {
+		err := tx.QueryRow(ctx, query, args...).Scan(&id, &k.Version)
+		if err != nil {
+			return err
+		}
+
+		k.ID = id.String()
+		return nil
+	}
+
+	err := repo.DB.QueryRow(ctx, query, args...).Scan(&id, &k.Version)
+	if err != nil {
+		return err
+	}
+
+	k.ID = id.String()
+	return nil
+}
+
+func (repo *APIKeyRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := apiKeySelectQuery + `WHERE id = $1`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, id)
+	}
+
+	return scanAPIKey(row)
+}
+
+func (repo *APIKeyRepository) GetByHash(ctx context.Context, hash string, tx pgx.Tx) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := apiKeySelectQuery + `WHERE key_hash = $1`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, hash)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, hash)
+	}
+
+	return scanAPIKey(row)
+}
+
+// apiKeyFilterColumns are the columns api keys can be filtered and sorted on.
+var apiKeyFilterColumns = filter.Columns{
+	"id":          "id",
+	"business_id": "business_id",
+	"name":        "name",
+	"created_at":  "created_at",
+}
+
+func (repo *APIKeyRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	q, err := f.Build(apiKeyFilterColumns, "-created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query := apiKeySelectQuery + fmt.Sprintf(`
+		%s
+		%s
+		%s
+	`, q.Where, q.OrderBy, q.Page)
+
+	var rows pgx.Rows
+	if tx != nil {
+		_rows, err := tx.Query(ctx, query, q.Args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	} else {
+		_rows, err := repo.DB.Query(ctx, query, q.Args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (repo *APIKeyRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `api_keys`, apiKeyFilterColumns, f)
+}
+
+// Revoke marks k as revoked. Keys are never deleted so audit entries and
+// wallet movements made with them can still be traced back.
+func (repo *APIKeyRepository) Revoke(ctx context.Context, k *models.APIKey, tx pgx.Tx) error {
+	now := time.Now().UTC()
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		UPDATE api_keys
+		SET revoked_at = $1, updated_at = $1, version = version + 1
+		WHERE id = $2 AND version = $3
+		RETURNING revoked_at, updated_at, version
+	`
+
+	args := []any{now, k.ID, k.Version}
+
+	if tx != nil {
+		return tx.QueryRow(ctx, query, args...).Scan(&k.RevokedAt, &k.UpdatedAt, &k.Version)
+	}
+
+	return repo.DB.QueryRow(ctx, query, args...).Scan(&k.RevokedAt, &k.UpdatedAt, &k.Version)
+}
+
+// TouchLastUsed records that the key was just used. It skips the version so it
+// never conflicts with a concurrent revoke.
+func (repo *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`
+
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, time.Now().UTC(), id)
+	} else {
+		_, err = repo.DB.Exec(ctx, query, time.Now().UTC(), id)
+	}
+
+	return
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	k := new(models.APIKey)
+
+	var id, businessId, createdBy uuid.UUID
+	err := row.Scan(
+		&id,
+		&businessId,
+		&createdBy,
+		&k.Name,
+		&k.Prefix,
+		&k.KeyHash,
+		&k.Environment,
+		&k.Scopes,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+		&k.CreatedAt,
+		&k.UpdatedAt,
+		&k.DeletedAt,
+		&k.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	k.ID = id.String()
+	k.BusinessID = businessId.String()
+	k.CreatedBy = createdBy.String()
+
+	return k, nil
+}
@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
+)
+
+type IAuditLogRepository interface {
+	Create(ctx context.Context, l *models.AuditLog, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.AuditLog, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.AuditLog, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	GetAfterSeq(ctx context.Context, seq int64, limit int, tx pgx.Tx) ([]*models.AuditLog, error)
+}
+
+type AuditLogRepository struct {
+	DB      *pgxpool.Pool
+	Timeout time.Duration
+}
+
+func NewAuditLogRepository(db *pgxpool.Pool, timeout time.Duration) *AuditLogRepository {
+	return &AuditLogRepository{DB: db, Timeout: timeout}
+}
+
+// Create appends l to the chain. The hash of the previous entry is read from
+// the chain head, locked until tx ends, so concurrent appends cannot fork the
+// chain: read committed ones wait for the head to move and serializable ones
+// fail to serialize and are retried. When tx is nil the entry is written in
+// its own transaction.
+func (repo *AuditLogRepository) Create(ctx context.Context, l *models.AuditLog, tx pgx.Tx) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	if tx == nil {
+		_tx, err := repo.DB.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer _tx.Rollback(ctx)
+
+		if err := repo.create(ctx, l, _tx); err != nil {
+			return err
+		}
+
+		return _tx.Commit(ctx)
+	}
+
+	return repo.create(ctx, l, tx)
+}
+
+func (repo *AuditLogRepository) create(ctx context.Context, l *models.AuditLog, tx pgx.Tx) error {
+	// postgres keeps microseconds, so truncate before hashing or the hash
+	// could never be reproduced from the stored row
+	l.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	if l.Changes == nil {
+		l.Changes = map[string]models.AuditChange{}
+	}
+
+	err := tx.QueryRow(ctx, `SELECT hash FROM audit_log_head FOR UPDATE`).Scan(&l.PrevHash)
+	if err != nil {
+		return err
+	}
+
+	l.Hash = l.ComputeHash()
+
+	query := `
+		INSERT INTO audit_logs (actor_id, actor_type, action, entity_type, entity_id, changes, ip_address, request_id, prev_hash, hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, seq
+	`
+
+	args := []any{
+		l.ActorID,
+		l.ActorType,
+		l.Action,
+		l.EntityType,
+		l.EntityID,
+		l.Changes,
+		l.IPAddress,
+		l.RequestID,
+		l.PrevHash,
+		l.Hash,
+		l.CreatedAt,
+	}
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, query, args...).Scan(&id, &l.Seq)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE audit_log_head SET hash = $1`, l.Hash)
+	if err != nil {
+		return err
+	}
+
+	l.ID = id.String()
+	return nil
+}
+
+func (repo *AuditLogRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.AuditLog, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		SELECT
+			id,
+			seq,
+			actor_id,
+			actor_type,
+			action,
+			entity_type,
+			entity_id,
+			changes,
+			ip_address,
+			request_id,
+			prev_hash,
+			hash,
+			created_at
+		FROM
+			audit_logs
+		WHERE id = $1
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, id)
+	}
+
+	return scanAuditLog(row)
+}
+
+// auditLogFilterColumns are the columns audit logs can be filtered and sorted on.
+var auditLogFilterColumns = filter.Columns{
+	"seq":         "seq",
+	"actor_id":    "actor_id",
+	"actor_type":  "actor_type",
+	"action":      "action",
+	"entity_type": "entity_type",
+	"entity_id":   "entity_id",
+	"created_at":  "created_at",
+}
+
+func (repo *AuditLogRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.AuditLog, error) {
+	q, err := f.Build(auditLogFilterColumns, "-seq")
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			seq,
+			actor_id,
+			actor_type,
+			action,
+			entity_type,
+			entity_id,
+			changes,
+			ip_address,
+			request_id,
+			prev_hash,
+			hash,
+			created_at
+		FROM
+			audit_logs
+		%s
+		%s
+		%s
+	`, q.Where, q.OrderBy, q.Page)
+
+	return repo.query(ctx, query, q.Args, tx)
+}
+
+func (repo *AuditLogRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `audit_logs`, auditLogFilterColumns, f)
+}
+
+// GetAfterSeq returns up to limit entries following seq in chain order. It is
+// used to walk the chain when verifying it.
+func (repo *AuditLogRepository) GetAfterSeq(ctx context.Context, seq int64, limit int, tx pgx.Tx) ([]*models.AuditLog, error) {
+	query := `
+		SELECT
+			id,
+			seq,
+			actor_id,
+			actor_type,
+			action,
+			entity_type,
+			entity_id,
+			changes,
+			ip_address,
+			request_id,
+			prev_hash,
+			hash,
+			created_at
+		FROM
+			audit_logs
+		WHERE seq > $1
+		ORDER BY seq
+		LIMIT $2
+	`
+
+	return repo.query(ctx, query, []any{seq, limit}, tx)
+}
+
+func (repo *AuditLogRepository) query(ctx context.Context, query string, args []any, tx pgx.Tx) ([]*models.AuditLog, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	var rows pgx.Rows
+	if tx != nil {
+		_rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	} else {
+		_rows, err := repo.DB.Query(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	}
+	defer rows.Close()
+
+	logs := []*models.AuditLog{}
+	for rows.Next() {
+		l, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		logs = append(logs, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func scanAuditLog(row pgx.Row) (*models.AuditLog, error) {
+	l := new(models.AuditLog)
+
+	var id uuid.UUID
+	var actorId *uuid.UUID
+	err := row.Scan(
+		&id,
+		&l.Seq,
+		&actorId,
+		&l.ActorType,
+		&l.Action,
+		&l.EntityType,
+		&l.EntityID,
+		&l.Changes,
+		&l.IPAddress,
+		&l.RequestID,
+		&l.PrevHash,
+		&l.Hash,
+		&l.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	l.ID = id.String()
+	if actorId != nil {
+		aid := actorId.String()
+		l.ActorID = &aid
+	}
+
+	return l, nil
+}
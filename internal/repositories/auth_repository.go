@@ -14,12 +14,12 @@ import (
 )
 
 type IAuthRepository interface {
-	Create(a *models.Auth, tx pgx.Tx) error
-	Update(a *models.Auth, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.Auth, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
-	GetByUserId(id string, tx pgx.Tx) (*models.Auth, error)
+	Create(ctx context.Context, a *models.Auth, tx pgx.Tx) error
+	Update(ctx context.Context, a *models.Auth, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Auth, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
+	GetByUserId(ctx context.Context, id string, tx pgx.Tx) (*models.Auth, error)
 }
 
 type AuthRepository struct {
@@ -31,12 +31,12 @@ func NewAuthRepository(db *pgxpool.Pool, timeout time.Duration) *AuthRepository
 	return &AuthRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *AuthRepository) Create(a *models.Auth, tx pgx.Tx) error {
+func (repo *AuthRepository) Create(ctx context.Context, a *models.Auth, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	a.CreatedAt = now
 	a.UpdatedAt = now
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
@@ -67,10 +67,10 @@ func (repo *AuthRepository) Create(a *models.Auth, tx pgx.Tx) error {
 	return nil
 }
 
-func (repo *AuthRepository) Update(a *models.Auth, tx pgx.Tx) error {
+func (repo *AuthRepository) Update(ctx context.Context, a *models.Auth, tx pgx.Tx) error {
 	a.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(a, "auths")
@@ -85,8 +85,8 @@ func (repo *AuthRepository) Update(a *models.Auth, tx pgx.Tx) error {
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&a.Version)
 }
 
-func (repo *AuthRepository) getByKey(key string, value any, tx pgx.Tx) (*models.Auth, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *AuthRepository) getByKey(ctx context.Context, key string, value any, tx pgx.Tx) (*models.Auth, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	var id, userId uuid.UUID
@@ -134,16 +134,16 @@ func (repo *AuthRepository) getByKey(key string, value any, tx pgx.Tx) (*models.
 	return a, nil
 }
 
-func (repo *AuthRepository) GetById(id string, tx pgx.Tx) (*models.Auth, error) {
-	return repo.getByKey("id", id, tx)
+func (repo *AuthRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Auth, error) {
+	return repo.getByKey(ctx, "id", id, tx)
 }
 
-func (repo *AuthRepository) GetByUserId(id string, tx pgx.Tx) (*models.Auth, error) {
-	return repo.getByKey("user_id", id, tx)
+func (repo *AuthRepository) GetByUserId(ctx context.Context, id string, tx pgx.Tx) (*models.Auth, error) {
+	return repo.getByKey(ctx, "user_id", id, tx)
 }
 
-func (repo *AuthRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *AuthRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM auths WHERE id = $1`
@@ -157,8 +157,8 @@ func (repo *AuthRepository) Delete(id string, tx pgx.Tx) (err error) {
 	return
 }
 
-func (repo *AuthRepository) SoftDelete(id string, tx pgx.Tx) error {
-	a, err := repo.GetById(id, tx)
+func (repo *AuthRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	a, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return err
 	}
@@ -167,5 +167,5 @@ func (repo *AuthRepository) SoftDelete(id string, tx pgx.Tx) error {
 	a.UpdatedAt = now
 	a.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 
-	return repo.Update(a, tx)
+	return repo.Update(ctx, a, tx)
 }
@@ -14,12 +14,12 @@ import (
 )
 
 type IBankAccountRepository interface {
-	Create(b *models.BankAccount, tx pgx.Tx) error
-	Update(b *models.BankAccount, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.BankAccount, error)
-	GetByWalletId(id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.BankAccount, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
+	Create(ctx context.Context, b *models.BankAccount, tx pgx.Tx) error
+	Update(ctx context.Context, b *models.BankAccount, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.BankAccount, error)
+	GetByWalletId(ctx context.Context, id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.BankAccount, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
 }
 
 type BankAccountRepository struct {
@@ -31,12 +31,12 @@ func NewBankAccountRepository(db *pgxpool.Pool, timeout time.Duration) *BankAcco
 	return &BankAccountRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *BankAccountRepository) Create(b *models.BankAccount, tx pgx.Tx) error {
+func (repo *BankAccountRepository) Create(ctx context.Context, b *models.BankAccount, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	b.CreatedAt = now
 	b.UpdatedAt = now
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
@@ -68,10 +68,10 @@ func (repo *BankAccountRepository) Create(b *models.BankAccount, tx pgx.Tx) erro
 	return nil
 }
 
-func (repo *BankAccountRepository) Update(b *models.BankAccount, tx pgx.Tx) error {
+func (repo *BankAccountRepository) Update(ctx context.Context, b *models.BankAccount, tx pgx.Tx) error {
 	b.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(b, "bank_accounts")
@@ -86,8 +86,8 @@ func (repo *BankAccountRepository) Update(b *models.BankAccount, tx pgx.Tx) erro
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&b.Version)
 }
 
-func (repo *BankAccountRepository) getByKey(key string, value any, tx pgx.Tx) (*models.BankAccount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *BankAccountRepository) getByKey(ctx context.Context, key string, value any, tx pgx.Tx) (*models.BankAccount, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	b := new(models.BankAccount)
@@ -159,12 +159,12 @@ func (repo *BankAccountRepository) getByKey(key string, value any, tx pgx.Tx) (*
 	return b, nil
 }
 
-func (repo *BankAccountRepository) GetById(id string, tx pgx.Tx) (*models.BankAccount, error) {
-	return repo.getByKey("b.id", id, tx)
+func (repo *BankAccountRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.BankAccount, error) {
+	return repo.getByKey(ctx, "b.id", id, tx)
 }
 
-func (repo *BankAccountRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *BankAccountRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM bank_accounts WHERE id = $1`
@@ -178,8 +178,8 @@ func (repo *BankAccountRepository) Delete(id string, tx pgx.Tx) (err error) {
 	return
 }
 
-func (repo *BankAccountRepository) SoftDelete(id string, tx pgx.Tx) error {
-	b, err := repo.GetById(id, tx)
+func (repo *BankAccountRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	b, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return err
 	}
@@ -188,11 +188,11 @@ func (repo *BankAccountRepository) SoftDelete(id string, tx pgx.Tx) error {
 	b.UpdatedAt = now
 	b.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 
-	return repo.Update(b, tx)
+	return repo.Update(ctx, b, tx)
 }
 
-func (repo *BankAccountRepository) GetByWalletId(id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.BankAccount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *BankAccountRepository) GetByWalletId(ctx context.Context, id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.BankAccount, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
@@ -13,11 +13,11 @@ import (
 )
 
 type IBusinessRepository interface {
-	Create(b *models.Business, tx pgx.Tx) error
-	Update(b *models.Business, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.Business, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
+	Create(ctx context.Context, b *models.Business, tx pgx.Tx) error
+	Update(ctx context.Context, b *models.Business, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Business, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
 }
 
 type BusinessRepository struct {
@@ -29,21 +29,24 @@ func NewBusinessRepository(db *pgxpool.Pool, timeout time.Duration) *BusinessRep
 	return &BusinessRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *BusinessRepository) Create(b *models.Business, tx pgx.Tx) error {
+func (repo *BusinessRepository) Create(ctx context.Context, b *models.Business, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	b.CreatedAt = now
 	b.UpdatedAt = now
+	if b.Tier == "" {
+		b.Tier = models.BusinessTierStandard
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
-		INSERT INTO businesses (name, email, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO businesses (name, email, tier, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, version
 	`
 
-	args := []any{b.Name, b.Email, b.CreatedAt, b.UpdatedAt}
+	args := []any{b.Name, b.Email, b.Tier, b.CreatedAt, b.UpdatedAt}
 
 	var id uuid.UUID
 	if tx != nil {
@@ -65,10 +68,10 @@ func (repo *BusinessRepository) Create(b *models.Business, tx pgx.Tx) error {
 	return nil
 }
 
-func (repo *BusinessRepository) Update(b *models.Business, tx pgx.Tx) error {
+func (repo *BusinessRepository) Update(ctx context.Context, b *models.Business, tx pgx.Tx) error {
 	b.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(b, "businesses")
@@ -83,8 +86,8 @@ func (repo *BusinessRepository) Update(b *models.Business, tx pgx.Tx) error {
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&b.Version)
 }
 
-func (repo *BusinessRepository) getByKey(key string, value any, tx pgx.Tx) (*models.Business, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *BusinessRepository) getByKey(ctx context.Context, key string, value any, tx pgx.Tx) (*models.Business, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	b := new(models.Business)
@@ -93,6 +96,7 @@ func (repo *BusinessRepository) getByKey(key string, value any, tx pgx.Tx) (*mod
 			id,
 			name,
 			email,
+			tier,
 			created_at,
 			updated_at,
 			deleted_at,
@@ -114,6 +118,7 @@ func (repo *BusinessRepository) getByKey(key string, value any, tx pgx.Tx) (*mod
 		&id,
 		&b.Name,
 		&b.Email,
+		&b.Tier,
 		&b.CreatedAt,
 		&b.UpdatedAt,
 		&b.DeletedAt,
@@ -127,12 +132,12 @@ func (repo *BusinessRepository) getByKey(key string, value any, tx pgx.Tx) (*mod
 	return b, nil
 }
 
-func (repo *BusinessRepository) GetById(id string, tx pgx.Tx) (*models.Business, error) {
-	return repo.getByKey("id", id, tx)
+func (repo *BusinessRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Business, error) {
+	return repo.getByKey(ctx, "id", id, tx)
 }
 
-func (repo *BusinessRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *BusinessRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM businesses WHERE id = $1`
@@ -146,8 +151,8 @@ func (repo *BusinessRepository) Delete(id string, tx pgx.Tx) (err error) {
 	return
 }
 
-func (repo *BusinessRepository) SoftDelete(id string, tx pgx.Tx) error {
-	b, err := repo.GetById(id, tx)
+func (repo *BusinessRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	b, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return err
 	}
@@ -156,5 +161,5 @@ func (repo *BusinessRepository) SoftDelete(id string, tx pgx.Tx) error {
 	b.UpdatedAt = now
 	b.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 
-	return repo.Update(b, tx)
+	return repo.Update(ctx, b, tx)
 }
@@ -0,0 +1,251 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/princecee/escrow-api/pkg/utils"
+)
+
+type IDisputeRepository interface {
+	Create(ctx context.Context, d *models.Dispute, tx pgx.Tx) error
+	Update(ctx context.Context, d *models.Dispute, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Dispute, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.Dispute, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+}
+
+type DisputeRepository struct {
+	DB      *pgxpool.Pool
+	Timeout time.Duration
+}
+
+func NewDisputeRepository(db *pgxpool.Pool, timeout time.Duration) *DisputeRepository {
+	return &DisputeRepository{DB: db, Timeout: timeout}
+}
+
+func (repo *DisputeRepository) Create(ctx context.Context, d *models.Dispute, tx pgx.Tx) error {
+	now := time.Now().UTC()
+	d.CreatedAt = now
+	d.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO disputes (transaction_id, raised_by, reason, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, version
+	`
+
+	args := []any{d.TransactionID, d.RaisedBy, d.Reason, d.Status, d.CreatedAt, d.UpdatedAt}
+
+	var id uuid.UUID
+	if tx != nil {
+		err := tx.QueryRow(ctx, query, args...).Scan(&id, &d.Version)
+		if err != nil {
+			return err
+		}
+
+		d.ID = id.String()
+		return nil
+	}
+
+	err := repo.DB.QueryRow(ctx, query, args...).Scan(&id, &d.Version)
+	if err != nil {
+		return err
+	}
+
+	d.ID = id.String()
+	return nil
+}
+
+func (repo *DisputeRepository) Update(ctx context.Context, d *models.Dispute, tx pgx.Tx) error {
+	d.UpdatedAt = time.Now().UTC()
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	qs, err := utils.GetUpdateQueryFromStruct(d, "disputes")
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		return tx.QueryRow(ctx, qs.Query, qs.Args...).Scan(&d.Version)
+	}
+
+	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&d.Version)
+}
+
+func (repo *DisputeRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Dispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		SELECT
+			id,
+			transaction_id,
+			raised_by,
+			reason,
+			status,
+			resolution,
+			resolution_note,
+			resolved_by,
+			resolved_at,
+			created_at,
+			updated_at,
+			deleted_at,
+			version
+		FROM
+			disputes
+		WHERE id = $1
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, id)
+	}
+
+	return scanDispute(row)
+}
+
+// disputeFilterColumns are the columns disputes can be filtered and sorted on.
+var disputeFilterColumns = filter.Columns{
+	"id":             "id",
+	"transaction_id": "transaction_id",
+	"status":         "status",
+	"created_at":     "created_at",
+}
+
+func (repo *DisputeRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.Dispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	q, err := f.Build(disputeFilterColumns, "-created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			transaction_id,
+			raised_by,
+			reason,
+			status,
+			resolution,
+			resolution_note,
+			resolved_by,
+			resolved_at,
+			created_at,
+			updated_at,
+			deleted_at,
+			version
+		FROM
+			disputes
+		%s
+		%s
+		%s
+	`, q.Where, q.OrderBy, q.Page)
+
+	var rows pgx.Rows
+	if tx != nil {
+		_rows, err := tx.Query(ctx, query, q.Args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	} else {
+		_rows, err := repo.DB.Query(ctx, query, q.Args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	}
+	defer rows.Close()
+
+	disputes := []*models.Dispute{}
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		disputes = append(disputes, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return disputes, nil
+}
+
+func (repo *DisputeRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `disputes`, disputeFilterColumns, f)
+}
+
+func (repo *DisputeRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `DELETE FROM disputes WHERE id = $1`
+
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, id)
+	} else {
+		_, err = repo.DB.Exec(ctx, query, id)
+	}
+
+	return
+}
+
+func scanDispute(row pgx.Row) (*models.Dispute, error) {
+	d := new(models.Dispute)
+
+	var id, transactionId, raisedBy uuid.UUID
+	var resolvedBy *uuid.UUID
+	err := row.Scan(
+		&id,
+		&transactionId,
+		&raisedBy,
+		&d.Reason,
+		&d.Status,
+		&d.Resolution,
+		&d.ResolutionNote,
+		&resolvedBy,
+		&d.ResolvedAt,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+		&d.DeletedAt,
+		&d.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	d.ID = id.String()
+	d.TransactionID = transactionId.String()
+	d.RaisedBy = raisedBy.String()
+	if resolvedBy != nil {
+		rid := resolvedBy.String()
+		d.ResolvedBy = &rid
+	}
+
+	return d, nil
+}
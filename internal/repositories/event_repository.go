@@ -13,11 +13,11 @@ import (
 )
 
 type IEventRepository interface {
-	Create(b *models.Event, tx pgx.Tx) error
-	Update(b *models.Event, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.Event, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
+	Create(ctx context.Context, b *models.Event, tx pgx.Tx) error
+	Update(ctx context.Context, b *models.Event, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Event, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
 }
 
 type EventRepository struct {
@@ -29,12 +29,12 @@ func NewEventRepository(db *pgxpool.Pool, timeout time.Duration) *EventRepositor
 	return &EventRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *EventRepository) Create(e *models.Event, tx pgx.Tx) error {
+func (repo *EventRepository) Create(ctx context.Context, e *models.Event, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	e.CreatedAt = now
 	e.UpdatedAt = now
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
@@ -72,10 +72,10 @@ func (repo *EventRepository) Create(e *models.Event, tx pgx.Tx) error {
 	return nil
 }
 
-func (repo *EventRepository) Update(e *models.Event, tx pgx.Tx) error {
+func (repo *EventRepository) Update(ctx context.Context, e *models.Event, tx pgx.Tx) error {
 	e.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(e, "events")
@@ -90,8 +90,8 @@ func (repo *EventRepository) Update(e *models.Event, tx pgx.Tx) error {
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&e.Version)
 }
 
-func (repo *EventRepository) GetById(id string, tx pgx.Tx) (*models.Event, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *EventRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	e := new(models.Event)
@@ -138,8 +138,8 @@ func (repo *EventRepository) GetById(id string, tx pgx.Tx) (*models.Event, error
 	return e, nil
 }
 
-func (repo *EventRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *EventRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM events WHERE id = $1`
@@ -152,8 +152,8 @@ func (repo *EventRepository) Delete(id string, tx pgx.Tx) (err error) {
 	return err
 }
 
-func (repo *EventRepository) SoftDelete(id string, tx pgx.Tx) error {
-	e, err := repo.GetById(id, tx)
+func (repo *EventRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	e, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return err
 	}
@@ -162,5 +162,5 @@ func (repo *EventRepository) SoftDelete(id string, tx pgx.Tx) error {
 	e.UpdatedAt = now
 	e.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 
-	return repo.Update(e, tx)
+	return repo.Update(ctx, e, tx)
 }
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/fx"
+)
+
+type IFXQuoteRepository interface {
+	Create(ctx context.Context, q *models.FXQuote, tx pgx.Tx) error
+	Update(ctx context.Context, q *models.FXQuote, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.FXQuote, error)
+}
+
+type FXQuoteRepository struct {
+	DB      *pgxpool.Pool
+	Timeout time.Duration
+}
+
+func NewFXQuoteRepository(db *pgxpool.Pool, timeout time.Duration) *FXQuoteRepository {
+	return &FXQuoteRepository{DB: db, Timeout: timeout}
+}
+
+const fxQuoteColumns = `
+	id,
+	identifier,
+	account_type,
+	from_currency,
+	to_currency,
+	amount,
+	converted_amount,
+	mid_rate,
+	rate,
+	spread,
+	status,
+	expires_at,
+	executed_at,
+	created_at,
+	updated_at,
+	deleted_at,
+	version
+`
+
+func (repo *FXQuoteRepository) Create(ctx context.Context, q *models.FXQuote, tx pgx.Tx) error {
+	now := time.Now().UTC()
+	q.CreatedAt = now
+	q.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO fx_quotes (identifier, account_type, from_currency, to_currency, amount, converted_amount, mid_rate, rate, spread, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, version
+	`
+
+	args := []any{
+		q.Identifier,
+		q.AccountType,
+		q.FromCurrency,
+		q.ToCurrency,
+		q.Amount,
+		q.ConvertedAmount,
+		int64(q.MidRate),
+		int64(q.Rate),
+		q.Spread,
+		q.Status,
+		q.ExpiresAt,
+		q.CreatedAt,
+		q.UpdatedAt,
+	}
+
+	var id uuid.UUID
+	if tx != nil {
+		err := tx.QueryRow(ctx, query, args...).Scan(&id, &q.Version)
+		if err != nil {
+			return err
+		}
+
+		q.ID = id.String()
+		return nil
+	}
+
+	err := repo.DB.QueryRow(ctx, query, args...).Scan(&id, &q.Version)
+	if err != nil {
+		return err
+	}
+
+	q.ID = id.String()
+	return nil
+}
+
+// Update saves the status of q. The rest of a quote is fixed once created.
+func (repo *FXQuoteRepository) Update(ctx context.Context, q *models.FXQuote, tx pgx.Tx) error {
+	q.UpdatedAt = time.Now().UTC()
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		UPDATE fx_quotes
+		SET status = $1, executed_at = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND version = $5
+		RETURNING version
+	`
+
+	args := []any{q.Status, q.ExecutedAt, q.UpdatedAt, q.ID, q.Version}
+	if tx != nil {
+		return tx.QueryRow(ctx, query, args...).Scan(&q.Version)
+	}
+
+	return repo.DB.QueryRow(ctx, query, args...).Scan(&q.Version)
+}
+
+func (repo *FXQuoteRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.FXQuote, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT %s FROM fx_quotes WHERE id = $1`, fxQuoteColumns)
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, id)
+	}
+
+	return scanFXQuote(row)
+}
+
+func scanFXQuote(row pgx.Row) (*models.FXQuote, error) {
+	q := new(models.FXQuote)
+
+	var id, identifier uuid.UUID
+	var midRate, rate int64
+	err := row.Scan(
+		&id,
+		&identifier,
+		&q.AccountType,
+		&q.FromCurrency,
+		&q.ToCurrency,
+		&q.Amount,
+		&q.ConvertedAmount,
+		&midRate,
+		&rate,
+		&q.Spread,
+		&q.Status,
+		&q.ExpiresAt,
+		&q.ExecutedAt,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+		&q.DeletedAt,
+		&q.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	q.ID = id.String()
+	q.Identifier = identifier.String()
+	q.MidRate = fx.Rate(midRate)
+	q.Rate = fx.Rate(rate)
+
+	return q, nil
+}
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+)
+
+type IInviteRepository interface {
+	Create(ctx context.Context, i *models.Invite, tx pgx.Tx) error
+	Update(ctx context.Context, i *models.Invite, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Invite, error)
+	GetPendingByContact(ctx context.Context, email, phone string, tx pgx.Tx) ([]*models.Invite, error)
+}
+
+type InviteRepository struct {
+	DB      *pgxpool.Pool
+	Timeout time.Duration
+}
+
+func NewInviteRepository(db *pgxpool.Pool, timeout time.Duration) *InviteRepository {
+	return &InviteRepository{DB: db, Timeout: timeout}
+}
+
+const inviteColumns = `
+	id,
+	transaction_id,
+	inviter_id,
+	role,
+	email,
+	phone_number,
+	placeholder_id,
+	status,
+	accepted_by,
+	expires_at,
+	accepted_at,
+	created_at,
+	updated_at,
+	deleted_at,
+	version
+`
+
+func (repo *InviteRepository) Create(ctx context.Context, i *models.Invite, tx pgx.Tx) error {
+	now := time.Now().UTC()
+	i.CreatedAt = now
+	i.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO transaction_invites (transaction_id, inviter_id, role, email, phone_number, placeholder_id, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, version
+	`
+
+	args := []any{
+		i.TransactionID,
+		i.InviterID,
+		i.Role,
+		i.Email,
+		i.PhoneNumber,
+		i.PlaceholderID,
+		i.Status,
+		i.ExpiresAt,
+		i.CreatedAt,
+		i.UpdatedAt,
+	}
+
+	var id uuid.UUID
+	if tx != nil {
+		err := tx.QueryRow(ctx, query, args...).Scan(&id, &i.Version)
+		if err != nil {
+			return err
+		}
+
+		i.ID = id.String()
+		return nil
+	}
+
+	err := repo.DB.QueryRow(ctx, query, args...).Scan(&id, &i.Version)
+	if err != nil {
+		return err
+	}
+
+	i.ID = id.String()
+	return nil
+}
+
+// Update saves the status of i. Who was invited to what is fixed once
+// created.
+func (repo *InviteRepository) Update(ctx context.Context, i *models.Invite, tx pgx.Tx) error {
+	i.UpdatedAt = time.Now().UTC()
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		UPDATE transaction_invites
+		SET status = $1, accepted_by = $2, accepted_at = $3, updated_at = $4, version = version + 1
+		WHERE id = $5 AND version = $6
+		RETURNING version
+	`
+
+	args := []any{i.Status, i.AcceptedBy, i.AcceptedAt, i.UpdatedAt, i.ID, i.Version}
+	if tx != nil {
+		return tx.QueryRow(ctx, query, args...).Scan(&i.Version)
+	}
+
+	return repo.DB.QueryRow(ctx, query, args...).Scan(&i.Version)
+}
+
+func (repo *InviteRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Invite, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT %s FROM transaction_invites WHERE id = $1 AND deleted_at IS NULL`, inviteColumns)
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, id)
+	}
+
+	return scanInvite(row)
+}
+
+// GetPendingByContact returns the pending, unexpired invites sent to email
+// or phone, oldest first. An empty phone matches nothing.
+func (repo *InviteRepository) GetPendingByContact(ctx context.Context, email, phone string, tx pgx.Tx) ([]*models.Invite, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM transaction_invites
+		WHERE
+			(LOWER(email) = LOWER($1) OR (phone_number = $2 AND $2 <> ''))
+			AND status = $3
+			AND expires_at > $4
+			AND deleted_at IS NULL
+		ORDER BY created_at ASC
+	`, inviteColumns)
+
+	args := []any{email, phone, models.InvitePending, time.Now().UTC()}
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, args...)
+	} else {
+		rows, err = repo.DB.Query(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []*models.Invite{}
+	for rows.Next() {
+		i, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		invites = append(invites, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+func scanInvite(row pgx.Row) (*models.Invite, error) {
+	i := new(models.Invite)
+
+	var id, transactionId, inviterId, placeholderId uuid.UUID
+	var acceptedBy *uuid.UUID
+	err := row.Scan(
+		&id,
+		&transactionId,
+		&inviterId,
+		&i.Role,
+		&i.Email,
+		&i.PhoneNumber,
+		&placeholderId,
+		&i.Status,
+		&acceptedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	i.ID = id.String()
+	i.TransactionID = transactionId.String()
+	i.InviterID = inviterId.String()
+	i.PlaceholderID = placeholderId.String()
+	if acceptedBy != nil {
+		i.AcceptedBy.String = acceptedBy.String()
+		i.AcceptedBy.Valid = true
+	}
+
+	return i, nil
+}
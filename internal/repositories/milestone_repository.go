@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/utils"
+)
+
+type IMilestoneRepository interface {
+	Create(ctx context.Context, m *models.Milestone, tx pgx.Tx) error
+	Update(ctx context.Context, m *models.Milestone, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Milestone, error)
+	GetByTransactionId(ctx context.Context, transactionId string, tx pgx.Tx) ([]*models.Milestone, error)
+}
+
+type MilestoneRepository struct {
+	DB      *pgxpool.Pool
+	Timeout time.Duration
+}
+
+func NewMilestoneRepository(db *pgxpool.Pool, timeout time.Duration) *MilestoneRepository {
+	return &MilestoneRepository{DB: db, Timeout: timeout}
+}
+
+const milestoneColumns = `
+	id,
+	transaction_id,
+	position,
+	title,
+	description,
+	amount,
+	charges,
+	receivable_amount,
+	due_date,
+	status,
+	funded_at,
+	delivered_at,
+	released_at,
+	created_at,
+	updated_at,
+	deleted_at,
+	version
+`
+
+func (repo *MilestoneRepository) Create(ctx context.Context, m *models.Milestone, tx pgx.Tx) error {
+	now := time.Now().UTC()
+	m.CreatedAt = now
+	m.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO milestones (transaction_id, position, title, description, amount, charges, receivable_amount, due_date, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, version
+	`
+
+	args := []any{
+		m.TransactionID,
+		m.Position,
+		m.Title,
+		m.Description,
+		m.Amount,
+		m.Charges,
+		m.ReceivableAmount,
+		m.DueDate,
+		m.Status,
+		m.CreatedAt,
+		m.UpdatedAt,
+	}
+
+	var id uuid.UUID
+	if tx != nil {
+		err := tx.QueryRow(ctx, query, args...).Scan(&id, &m.Version)
+		if err != nil {
+			return err
+		}
+
+		m.ID = id.String()
+		return nil
+	}
+
+	err := repo.DB.QueryRow(ctx, query, args...).Scan(&id, &m.Version)
+	if err != nil {
+		return err
+	}
+
+	m.ID = id.String()
+	return nil
+}
+
+func (repo *MilestoneRepository) Update(ctx context.Context, m *models.Milestone, tx pgx.Tx) error {
+	m.UpdatedAt = time.Now().UTC()
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	qs, err := utils.GetUpdateQueryFromStruct(m, "milestones")
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		return tx.QueryRow(ctx, qs.Query, qs.Args...).Scan(&m.Version)
+	}
+
+	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&m.Version)
+}
+
+func (repo *MilestoneRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Milestone, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT %s FROM milestones WHERE id = $1`, milestoneColumns)
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, id)
+	}
+
+	return scanMilestone(row)
+}
+
+// GetByTransactionId returns the milestones of a transaction in order.
+func (repo *MilestoneRepository) GetByTransactionId(ctx context.Context, transactionId string, tx pgx.Tx) ([]*models.Milestone, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM milestones
+		WHERE transaction_id = $1
+		ORDER BY position
+	`, milestoneColumns)
+
+	var rows pgx.Rows
+	if tx != nil {
+		_rows, err := tx.Query(ctx, query, transactionId)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	} else {
+		_rows, err := repo.DB.Query(ctx, query, transactionId)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	}
+	defer rows.Close()
+
+	milestones := []*models.Milestone{}
+	for rows.Next() {
+		m, err := scanMilestone(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		milestones = append(milestones, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return milestones, nil
+}
+
+func scanMilestone(row pgx.Row) (*models.Milestone, error) {
+	m := new(models.Milestone)
+
+	var id, transactionId uuid.UUID
+	err := row.Scan(
+		&id,
+		&transactionId,
+		&m.Position,
+		&m.Title,
+		&m.Description,
+		&m.Amount,
+		&m.Charges,
+		&m.ReceivableAmount,
+		&m.DueDate,
+		&m.Status,
+		&m.FundedAt,
+		&m.DeliveredAt,
+		&m.ReleasedAt,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+		&m.DeletedAt,
+		&m.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ID = id.String()
+	m.TransactionID = transactionId.String()
+
+	return m, nil
+}
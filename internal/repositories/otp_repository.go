@@ -14,12 +14,12 @@ import (
 )
 
 type IOtpRepository interface {
-	Create(otp *models.Otp, tx pgx.Tx) error
-	Update(otp *models.Otp, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.Otp, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
-	GetOneByWhere(where string, args []any, tx pgx.Tx) (*models.Otp, error)
+	Create(ctx context.Context, otp *models.Otp, tx pgx.Tx) error
+	Update(ctx context.Context, otp *models.Otp, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Otp, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
+	GetOneByWhere(ctx context.Context, where string, args []any, tx pgx.Tx) (*models.Otp, error)
 }
 
 type OtpRepository struct {
@@ -31,12 +31,12 @@ func NewOtpRepository(db *pgxpool.Pool, timeout time.Duration) *OtpRepository {
 	return &OtpRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *OtpRepository) Create(otp *models.Otp, tx pgx.Tx) error {
+func (repo *OtpRepository) Create(ctx context.Context, otp *models.Otp, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	otp.CreatedAt = now
 	otp.UpdatedAt = now
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
@@ -75,10 +75,10 @@ func (repo *OtpRepository) Create(otp *models.Otp, tx pgx.Tx) error {
 	return nil
 }
 
-func (repo *OtpRepository) Update(otp *models.Otp, tx pgx.Tx) error {
+func (repo *OtpRepository) Update(ctx context.Context, otp *models.Otp, tx pgx.Tx) error {
 	otp.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(otp, "otps")
@@ -93,8 +93,8 @@ func (repo *OtpRepository) Update(otp *models.Otp, tx pgx.Tx) error {
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&otp.Version)
 }
 
-func (repo *OtpRepository) getByKey(key string, value any, tx pgx.Tx) (*models.Otp, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *OtpRepository) getByKey(ctx context.Context, key string, value any, tx pgx.Tx) (*models.Otp, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	var id, userId uuid.UUID
@@ -145,14 +145,14 @@ func (repo *OtpRepository) getByKey(key string, value any, tx pgx.Tx) (*models.O
 	return otp, nil
 }
 
-func (repo *OtpRepository) GetById(id string, tx pgx.Tx) (*models.Otp, error) {
-	return repo.getByKey("id", id, tx)
+func (repo *OtpRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Otp, error) {
+	return repo.getByKey(ctx, "id", id, tx)
 }
 
-func (repo *OtpRepository) GetOneByWhere(where string, args []any, tx pgx.Tx) (*models.Otp, error) {
+func (repo *OtpRepository) GetOneByWhere(ctx context.Context, where string, args []any, tx pgx.Tx) (*models.Otp, error) {
 	otp := new(models.Otp)
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := fmt.Sprintf(`
@@ -203,8 +203,8 @@ func (repo *OtpRepository) GetOneByWhere(where string, args []any, tx pgx.Tx) (*
 	return otp, nil
 }
 
-func (repo *OtpRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *OtpRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM otps WHERE id = $1`
@@ -218,8 +218,8 @@ func (repo *OtpRepository) Delete(id string, tx pgx.Tx) (err error) {
 	return
 }
 
-func (repo *OtpRepository) SoftDelete(id string, tx pgx.Tx) error {
-	otp, err := repo.GetById(id, tx)
+func (repo *OtpRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	otp, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return err
 	}
@@ -228,5 +228,5 @@ func (repo *OtpRepository) SoftDelete(id string, tx pgx.Tx) error {
 	otp.UpdatedAt = now
 	otp.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 
-	return repo.Update(otp, tx)
+	return repo.Update(ctx, otp, tx)
 }
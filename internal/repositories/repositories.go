@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/pkg/filter"
+)
+
+// count returns the number of rows of from that f matches. The sort and page
+// of f are ignored.
+func count(ctx context.Context, db *pgxpool.Pool, tx pgx.Tx, from string, columns filter.Columns, f *filter.Filter) (int, error) {
+	q, err := f.Unpaged().Build(columns)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s %s`, from, q.Where)
+
+	var total int
+	if tx != nil {
+		err = tx.QueryRow(ctx, query, q.Args...).Scan(&total)
+	} else {
+		err = db.QueryRow(ctx, query, q.Args...).Scan(&total)
+	}
+
+	return total, err
+}
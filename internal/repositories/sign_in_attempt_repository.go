@@ -0,0 +1,251 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
+)
+
+type ISignInAttemptRepository interface {
+	Create(ctx context.Context, a *models.SignInAttempt, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.SignInAttempt, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.SignInAttempt, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	Exists(ctx context.Context, where string, args []any, tx pgx.Tx) (bool, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+}
+
+type SignInAttemptRepository struct {
+	DB      *pgxpool.Pool
+	Timeout time.Duration
+}
+
+func NewSignInAttemptRepository(db *pgxpool.Pool, timeout time.Duration) *SignInAttemptRepository {
+	return &SignInAttemptRepository{DB: db, Timeout: timeout}
+}
+
+func (repo *SignInAttemptRepository) Create(ctx context.Context, a *models.SignInAttempt, tx pgx.Tx) error {
+	now := time.Now().UTC()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO sign_in_attempts (user_id, email, ip_address, user_agent, outcome, reason, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version
+	`
+
+	args := []any{
+		a.UserID,
+		a.Email,
+		a.IPAddress,
+		a.UserAgent,
+		a.Outcome,
+		a.Reason,
+		a.CreatedAt,
+		a.UpdatedAt,
+	}
+
+	var id uuid.UUID
+	if tx != nil {
+		err := tx.QueryRow(ctx, query, args...).Scan(&id, &a.Version)
+		if err != nil {
+			return err
+		}
+
+		a.ID = id.String()
+		return nil
+	}
+
+	err := repo.DB.QueryRow(ctx, query, args...).Scan(&id, &a.Version)
+	if err != nil {
+		return err
+	}
+
+	a.ID = id.String()
+	return nil
+}
+
+func (repo *SignInAttemptRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.SignInAttempt, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		SELECT
+			id,
+			user_id,
+			email,
+			ip_address,
+			user_agent,
+			outcome,
+			reason,
+			created_at,
+			updated_at,
+			deleted_at,
+			version
+		FROM
+			sign_in_attempts
+		WHERE id = $1
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, id)
+	}
+
+	return scanSignInAttempt(row)
+}
+
+// signInAttemptFilterColumns are the columns sign in attempts can be filtered and sorted on.
+var signInAttemptFilterColumns = filter.Columns{
+	"user_id":    "user_id",
+	"outcome":    "outcome",
+	"ip_address": "ip_address",
+	"created_at": "created_at",
+}
+
+func (repo *SignInAttemptRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.SignInAttempt, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	q, err := f.Build(signInAttemptFilterColumns, "-created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			user_id,
+			email,
+			ip_address,
+			user_agent,
+			outcome,
+			reason,
+			created_at,
+			updated_at,
+			deleted_at,
+			version
+		FROM
+			sign_in_attempts
+		%s
+		%s
+		%s
+	`, q.Where, q.OrderBy, q.Page)
+
+	var rows pgx.Rows
+	if tx != nil {
+		_rows, err := tx.Query(ctx, query, q.Args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	} else {
+		_rows, err := repo.DB.Query(ctx, query, q.Args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	}
+	defer rows.Close()
+
+	attempts := []*models.SignInAttempt{}
+	for rows.Next() {
+		a, err := scanSignInAttempt(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		attempts = append(attempts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+func (repo *SignInAttemptRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `sign_in_attempts`, signInAttemptFilterColumns, f)
+}
+
+func (repo *SignInAttemptRepository) Exists(ctx context.Context, where string, args []any, tx pgx.Tx) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM sign_in_attempts %s)`, where)
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, args...)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, args...)
+	}
+
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+func (repo *SignInAttemptRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `DELETE FROM sign_in_attempts WHERE id = $1`
+
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, id)
+	} else {
+		_, err = repo.DB.Exec(ctx, query, id)
+	}
+
+	return
+}
+
+func scanSignInAttempt(row pgx.Row) (*models.SignInAttempt, error) {
+	a := new(models.SignInAttempt)
+
+	var id uuid.UUID
+	var userId *uuid.UUID
+	err := row.Scan(
+		&id,
+		&userId,
+		&a.Email,
+		&a.IPAddress,
+		&a.UserAgent,
+		&a.Outcome,
+		&a.Reason,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+		&a.DeletedAt,
+		&a.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	a.ID = id.String()
+	if userId != nil {
+		uid := userId.String()
+		a.UserID = &uid
+	}
+
+	return a, nil
+}
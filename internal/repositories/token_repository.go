@@ -13,11 +13,11 @@ import (
 )
 
 type ITokenRepository interface {
-	Create(t *models.Token, tx pgx.Tx) error
-	Update(t *models.Token, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.Token, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
+	Create(ctx context.Context, t *models.Token, tx pgx.Tx) error
+	Update(ctx context.Context, t *models.Token, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Token, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
 }
 
 type TokenRepository struct {
@@ -29,12 +29,12 @@ func NewTokenRepository(db *pgxpool.Pool, timeout time.Duration) *TokenRepositor
 	return &TokenRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *TokenRepository) Create(t *models.Token, tx pgx.Tx) error {
+func (repo *TokenRepository) Create(ctx context.Context, t *models.Token, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	t.CreatedAt = now
 	t.UpdatedAt = now
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
@@ -72,10 +72,10 @@ func (repo *TokenRepository) Create(t *models.Token, tx pgx.Tx) error {
 	return nil
 }
 
-func (repo *TokenRepository) Update(t *models.Token, tx pgx.Tx) error {
+func (repo *TokenRepository) Update(ctx context.Context, t *models.Token, tx pgx.Tx) error {
 	t.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(t, "tokens")
@@ -90,8 +90,8 @@ func (repo *TokenRepository) Update(t *models.Token, tx pgx.Tx) error {
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&t.Version)
 }
 
-func (repo *TokenRepository) getByKey(key string, value any, tx pgx.Tx) (*models.Token, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *TokenRepository) getByKey(ctx context.Context, key string, value any, tx pgx.Tx) (*models.Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	var id, userId uuid.UUID
@@ -139,12 +139,12 @@ func (repo *TokenRepository) getByKey(key string, value any, tx pgx.Tx) (*models
 	return t, nil
 }
 
-func (repo *TokenRepository) GetById(id string, tx pgx.Tx) (*models.Token, error) {
-	return repo.getByKey("id", id, tx)
+func (repo *TokenRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Token, error) {
+	return repo.getByKey(ctx, "id", id, tx)
 }
 
-func (repo *TokenRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *TokenRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM tokens WHERE id = $1`
@@ -158,8 +158,8 @@ func (repo *TokenRepository) Delete(id string, tx pgx.Tx) (err error) {
 	return
 }
 
-func (repo *TokenRepository) SoftDelete(id string, tx pgx.Tx) error {
-	t, err := repo.GetById(id, tx)
+func (repo *TokenRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	t, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return err
 	}
@@ -168,5 +168,5 @@ func (repo *TokenRepository) SoftDelete(id string, tx pgx.Tx) error {
 	t.UpdatedAt = now
 	t.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 
-	return repo.Update(t, tx)
+	return repo.Update(ctx, t, tx)
 }
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/pkg/metrics"
+	"github.com/princecee/escrow-api/pkg/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pkgPath prefixes the names of the functions of this package in stack
+// frames.
+var pkgPath = reflect.TypeOf(QueryTracer{}).PkgPath() + "."
+
+// QueryTracer is the pgx tracer of the pool. It times and traces every query
+// against the repository method running it, found on the stack, so
+// repositories don't have to instrument their own queries.
+type QueryTracer struct{}
+
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{}
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	at         time.Time
+	repository string
+	method     string
+	span       trace.Span
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	repository, method := caller()
+
+	name := repository + "." + method
+	if repository == "other" {
+		name = "postgres"
+	}
+
+	// arguments are left out of spans, they hold personal data
+	ctx, span := tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBStatement(data.SQL)),
+	)
+
+	return context.WithValue(ctx, queryStartKey{}, queryStart{time.Now(), repository, method, span})
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	// no rows is an answer rather than a failure of the query
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+
+	metrics.ObserveQuery(start.repository, start.method, time.Since(start.at), err)
+	tracing.End(start.span, err)
+}
+
+// caller returns the repository and exported method running the query being
+// traced, skipping the helpers of the package like count. Queries run
+// outside repositories, like the begin and commit of transactions, are
+// returned as "other".
+func caller() (string, string) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+
+		// "github.com/.../repositories.(*WalletRepository).GetById"
+		if name, ok := strings.CutPrefix(frame.Function, pkgPath); ok {
+			receiver, method, ok := strings.Cut(name, ").")
+			// closures of a method are "Method.func1"
+			method, _, _ = strings.Cut(method, ".")
+			if ok && isExported(method) {
+				return strings.TrimPrefix(receiver, "(*"), method
+			}
+		}
+
+		if !more {
+			return "other", "other"
+		}
+	}
+}
+
+func isExported(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+
+	return false
+}
@@ -10,16 +10,19 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/princecee/escrow-api/pkg/utils"
 )
 
 type ITransactionRepository interface {
-	Create(t *models.Transaction, tx pgx.Tx) error
-	Update(t *models.Transaction, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.Transaction, error)
-	GetMany(args []any, where string, tx pgx.Tx) ([]*models.Transaction, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
+	Create(ctx context.Context, t *models.Transaction, tx pgx.Tx) error
+	Update(ctx context.Context, t *models.Transaction, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Transaction, error)
+	GetByIdForUpdate(ctx context.Context, id string, tx pgx.Tx) (*models.Transaction, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.Transaction, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
 }
 
 type TransactionRepository struct {
@@ -31,7 +34,7 @@ func NewTransactionRepository(db *pgxpool.Pool, timeout time.Duration) *Transact
 	return &TransactionRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *TransactionRepository) Create(t *models.Transaction, tx pgx.Tx) error {
+func (repo *TransactionRepository) Create(ctx context.Context, t *models.Transaction, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	t.CreatedAt = now
 	t.UpdatedAt = now
@@ -50,15 +53,16 @@ func (repo *TransactionRepository) Create(t *models.Transaction, tx pgx.Tx) erro
 		t.TotalCost,
 		t.Charges,
 		t.ReceivableAmount,
+		t.PaidAmount,
 		t.CreatedAt,
 		t.UpdatedAt,
 	}
 
-	query := `INSERT INTO transactions (status, type, seller_id, buyer_id, created_by, delivery_duration, currency, charge_configuration, product_details, total_amount, total_cost, charges, receivable_amount, created_at, updated_at
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	query := `INSERT INTO transactions (status, type, seller_id, buyer_id, created_by, delivery_duration, currency, charge_configuration, product_details, total_amount, total_cost, charges, receivable_amount, paid_amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING id, version`
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	var id uuid.UUID
@@ -81,13 +85,29 @@ func (repo *TransactionRepository) Create(t *models.Transaction, tx pgx.Tx) erro
 	return nil
 }
 
-func (repo *TransactionRepository) Update(t *models.Transaction, tx pgx.Tx) error {
+func (repo *TransactionRepository) Update(ctx context.Context, t *models.Transaction, tx pgx.Tx) error {
+	buyer := t.Buyer
+	seller := t.Seller
+	timeline := t.Timeline
+	milestones := t.Milestones
+
+	t.Buyer = nil
+	t.Seller = nil
+	t.Timeline = nil
+	t.Milestones = nil
+
 	t.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(t, "transactions")
+
+	t.Buyer = buyer
+	t.Seller = seller
+	t.Timeline = timeline
+	t.Milestones = milestones
+
 	if err != nil {
 		return err
 	}
@@ -99,15 +119,17 @@ func (repo *TransactionRepository) Update(t *models.Transaction, tx pgx.Tx) erro
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&t.Version)
 }
 
-func (repo *TransactionRepository) getByKey(key string, value any, tx pgx.Tx) (*models.Transaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+// getByKey returns the transaction whose key is value. lock is appended to
+// the query to lock the row, empty for a plain read.
+func (repo *TransactionRepository) getByKey(ctx context.Context, key string, value any, lock string, tx pgx.Tx) (*models.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	t := new(models.Transaction)
 	var id, buyerId, sellerId uuid.UUID
 	var sellerImgUrl, buyerImgUrl *string
-	var buyer *models.User
-	var seller *models.Business
+	buyer := new(models.User)
+	seller := new(models.Business)
 
 	query := fmt.Sprintf(`
 		SELECT
@@ -125,9 +147,11 @@ func (repo *TransactionRepository) getByKey(key string, value any, tx pgx.Tx) (*
 			t.total_cost,
 			t.charges,
 			t.receivable_amount,
+			t.paid_amount,
 			t.created_at,
 			t.updated_at,
 			t.deleted_at,
+			t.version,
 			u.email,
 			u.phone_number,
 			u.first_name,
@@ -143,6 +167,7 @@ func (repo *TransactionRepository) getByKey(key string, value any, tx pgx.Tx) (*
 			b.name,
 			b.email,
 			b.image_url,
+			b.tier,
 			b.created_at,
 			b.updated_at,
 			b.deleted_at
@@ -150,8 +175,9 @@ func (repo *TransactionRepository) getByKey(key string, value any, tx pgx.Tx) (*
 		FROM transactions t
 		INNER JOIN businesses b ON b.id = t.seller_id
 		INNER JOIN users u ON u.id= t.buyer_id
-		WHERE %s = $1`,
+		WHERE %s = $1 %s`,
 		key,
+		lock,
 	)
 
 	var row pgx.Row
@@ -176,9 +202,11 @@ func (repo *TransactionRepository) getByKey(key string, value any, tx pgx.Tx) (*
 		&t.TotalCost,
 		&t.Charges,
 		&t.ReceivableAmount,
+		&t.PaidAmount,
 		&t.CreatedAt,
 		&t.UpdatedAt,
 		&t.DeletedAt,
+		&t.Version,
 		&buyer.Email,
 		&buyer.PhoneNumber,
 		&buyer.FirstName,
@@ -194,6 +222,7 @@ func (repo *TransactionRepository) getByKey(key string, value any, tx pgx.Tx) (*
 		&seller.Name,
 		&seller.Email,
 		&sellerImgUrl,
+		&seller.Tier,
 		&seller.CreatedAt,
 		&seller.UpdatedAt,
 		&seller.DeletedAt,
@@ -221,15 +250,42 @@ func (repo *TransactionRepository) getByKey(key string, value any, tx pgx.Tx) (*
 	return t, nil
 }
 
-func (repo *TransactionRepository) GetById(id string, tx pgx.Tx) (*models.Transaction, error) {
-	return repo.getByKey("t.id", id, tx)
+func (repo *TransactionRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Transaction, error) {
+	return repo.getByKey(ctx, "t.id", id, "", tx)
 }
 
-func (repo *TransactionRepository) GetMany(args []any, where string, tx pgx.Tx) ([]*models.Transaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+// GetByIdForUpdate returns the transaction with id and locks it until tx
+// ends, so concurrent changes to it are applied one after the other.
+func (repo *TransactionRepository) GetByIdForUpdate(ctx context.Context, id string, tx pgx.Tx) (*models.Transaction, error) {
+	return repo.getByKey(ctx, "t.id", id, "FOR UPDATE OF t", tx)
+}
+
+// transactionFilterColumns are the columns transactions can be filtered and sorted on.
+var transactionFilterColumns = filter.Columns{
+	"id":           "t.id",
+	"status":       "t.status",
+	"type":         "t.type",
+	"created_by":   "t.created_by",
+	"buyer_id":     "t.buyer_id",
+	"seller_id":    "t.seller_id",
+	"currency":     "t.currency",
+	"total_amount": "t.total_amount",
+	"total_cost":   "t.total_cost",
+	"created_at":   "t.created_at",
+	"updated_at":   "t.updated_at",
+	"buyer_email":  "u.email",
+	"seller_name":  "b.name",
+}
+
+func (repo *TransactionRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
-	argLen := len(args)
+	q, err := f.Build(transactionFilterColumns, "-created_at")
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT
 			t.id,
@@ -246,9 +302,11 @@ func (repo *TransactionRepository) GetMany(args []any, where string, tx pgx.Tx)
 			t.total_cost,
 			t.charges,
 			t.receivable_amount,
+			t.paid_amount,
 			t.created_at,
 			t.updated_at,
 			t.deleted_at,
+			t.version,
 			u.email,
 			u.phone_number,
 			u.first_name,
@@ -264,6 +322,7 @@ func (repo *TransactionRepository) GetMany(args []any, where string, tx pgx.Tx)
 			b.name,
 			b.email,
 			b.image_url,
+			b.tier,
 			b.created_at,
 			b.updated_at,
 			b.deleted_at
@@ -272,35 +331,36 @@ func (repo *TransactionRepository) GetMany(args []any, where string, tx pgx.Tx)
 		INNER JOIN businesses b ON b.id = t.seller_id
 		INNER JOIN users u ON u.id= t.buyer_id
 		%s
-		OFFSET $%d
-		LIMIT $%d
-	`, where, argLen-1, argLen)
+		%s
+		%s
+	`, q.Where, q.OrderBy, q.Page)
 
 	var rows pgx.Rows
 	if tx != nil {
-		_rows, err := tx.Query(ctx, query, args...)
+		_rows, err := tx.Query(ctx, query, q.Args...)
 		if err != nil {
 			return nil, err
 		}
 
 		rows = _rows
 	} else {
-		_rows, err := repo.DB.Query(ctx, query, args...)
+		_rows, err := repo.DB.Query(ctx, query, q.Args...)
 		if err != nil {
 			return nil, err
 		}
 
 		rows = _rows
 	}
+	defer rows.Close()
 
 	transactions := []*models.Transaction{}
 
 	for rows.Next() {
-		var t *models.Transaction
+		t := new(models.Transaction)
 		var id, buyerId, sellerId uuid.UUID
 		var sellerImgUrl, buyerImgUrl *string
-		var buyer *models.User
-		var seller *models.Business
+		buyer := new(models.User)
+		seller := new(models.Business)
 
 		err := rows.Scan(
 			&id,
@@ -317,9 +377,11 @@ func (repo *TransactionRepository) GetMany(args []any, where string, tx pgx.Tx)
 			&t.TotalCost,
 			&t.Charges,
 			&t.ReceivableAmount,
+			&t.PaidAmount,
 			&t.CreatedAt,
 			&t.UpdatedAt,
 			&t.DeletedAt,
+			&t.Version,
 			&buyer.Email,
 			&buyer.PhoneNumber,
 			&buyer.FirstName,
@@ -335,6 +397,7 @@ func (repo *TransactionRepository) GetMany(args []any, where string, tx pgx.Tx)
 			&seller.Name,
 			&seller.Email,
 			&sellerImgUrl,
+			&seller.Tier,
 			&seller.CreatedAt,
 			&seller.UpdatedAt,
 			&seller.DeletedAt,
@@ -370,8 +433,17 @@ func (repo *TransactionRepository) GetMany(args []any, where string, tx pgx.Tx)
 	return transactions, nil
 }
 
-func (repo *TransactionRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *TransactionRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `transactions t
+		INNER JOIN businesses b ON b.id = t.seller_id
+		INNER JOIN users u ON u.id = t.buyer_id`, transactionFilterColumns, f)
+}
+
+func (repo *TransactionRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM transactions WHERE id = $1`
@@ -385,8 +457,8 @@ func (repo *TransactionRepository) Delete(id string, tx pgx.Tx) (err error) {
 	return
 }
 
-func (repo *TransactionRepository) SoftDelete(id string, tx pgx.Tx) error {
-	u, err := repo.GetById(id, tx)
+func (repo *TransactionRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	u, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return nil
 	}
@@ -394,5 +466,5 @@ func (repo *TransactionRepository) SoftDelete(id string, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	u.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 	u.UpdatedAt = now
-	return repo.Update(u, tx)
+	return repo.Update(ctx, u, tx)
 }
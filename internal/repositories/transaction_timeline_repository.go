@@ -10,16 +10,18 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/princecee/escrow-api/pkg/utils"
 )
 
 type ITransactionTimelineRepository interface {
-	Create(tt *models.TransactionTimeline, tx pgx.Tx) error
-	Update(tt *models.TransactionTimeline, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.TransactionTimeline, error)
-	GetMany(args []any, where string, tx pgx.Tx) ([]*models.TransactionTimeline, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
+	Create(ctx context.Context, tt *models.TransactionTimeline, tx pgx.Tx) error
+	Update(ctx context.Context, tt *models.TransactionTimeline, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.TransactionTimeline, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.TransactionTimeline, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
 }
 
 type TransactionTimelineRepository struct {
@@ -31,7 +33,7 @@ func NewTransactionTimelineRepository(db *pgxpool.Pool, timeout time.Duration) *
 	return &TransactionTimelineRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *TransactionTimelineRepository) Create(tt *models.TransactionTimeline, tx pgx.Tx) error {
+func (repo *TransactionTimelineRepository) Create(ctx context.Context, tt *models.TransactionTimeline, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	tt.CreatedAt = now
 	tt.UpdatedAt = now
@@ -39,15 +41,16 @@ func (repo *TransactionTimelineRepository) Create(tt *models.TransactionTimeline
 	args := []any{
 		tt.Name,
 		tt.TransactionID,
+		tt.MilestoneID,
 		tt.CreatedAt,
 		tt.UpdatedAt,
 	}
 
-	query := `INSERT INTO transaction_timelines (name, transaction_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
+	query := `INSERT INTO transaction_timelines (name, transaction_id, milestone_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, version`
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	var id uuid.UUID
@@ -70,10 +73,10 @@ func (repo *TransactionTimelineRepository) Create(tt *models.TransactionTimeline
 	return nil
 }
 
-func (repo *TransactionTimelineRepository) Update(tt *models.TransactionTimeline, tx pgx.Tx) error {
+func (repo *TransactionTimelineRepository) Update(ctx context.Context, tt *models.TransactionTimeline, tx pgx.Tx) error {
 	tt.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(tt, "transaction_timelines")
@@ -88,18 +91,20 @@ func (repo *TransactionTimelineRepository) Update(tt *models.TransactionTimeline
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&tt.Version)
 }
 
-func (repo *TransactionTimelineRepository) getByKey(key string, value any, tx pgx.Tx) (*models.TransactionTimeline, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *TransactionTimelineRepository) getByKey(ctx context.Context, key string, value any, tx pgx.Tx) (*models.TransactionTimeline, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	tt := new(models.TransactionTimeline)
 	var id, transactionId uuid.UUID
+	var milestoneId *uuid.UUID
 
 	query := fmt.Sprintf(`
 		SELECT
 			id,
 			name,
 			transaction_id,
+			milestone_id,
 			created_at,
 			updated_at,
 			deleted_at
@@ -120,6 +125,7 @@ func (repo *TransactionTimelineRepository) getByKey(key string, value any, tx pg
 		&id,
 		&tt.Name,
 		&transactionId,
+		&milestoneId,
 		&tt.CreatedAt,
 		&tt.UpdatedAt,
 		&tt.DeletedAt,
@@ -130,57 +136,81 @@ func (repo *TransactionTimelineRepository) getByKey(key string, value any, tx pg
 
 	tt.ID = id.String()
 	tt.TransactionID = transactionId.String()
+	if milestoneId != nil {
+		mid := milestoneId.String()
+		tt.MilestoneID = &mid
+	}
 
 	return tt, nil
 }
 
-func (repo *TransactionTimelineRepository) GetById(id string, tx pgx.Tx) (*models.TransactionTimeline, error) {
-	return repo.getByKey("id", id, tx)
+func (repo *TransactionTimelineRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.TransactionTimeline, error) {
+	return repo.getByKey(ctx, "id", id, tx)
+}
+
+// transactionTimelineFilterColumns are the columns transaction timelines can be filtered and sorted on.
+var transactionTimelineFilterColumns = filter.Columns{
+	"id":             "id",
+	"name":           "name",
+	"transaction_id": "transaction_id",
+	"milestone_id":   "milestone_id",
+	"created_at":     "created_at",
 }
 
-func (repo *TransactionTimelineRepository) GetMany(args []any, where string, tx pgx.Tx) ([]*models.TransactionTimeline, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *TransactionTimelineRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.TransactionTimeline, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
+	q, err := f.Build(transactionTimelineFilterColumns, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT
 			id,
 			name,
 			transaction_id,
+			milestone_id,
 			created_at,
 			updated_at,
 			deleted_at
+		FROM transaction_timelines
 		%s
-		ORDER BY created_at
-	`, where)
+		%s
+		%s
+	`, q.Where, q.OrderBy, q.Page)
 
 	var rows pgx.Rows
 	if tx != nil {
-		_rows, err := tx.Query(ctx, query, args...)
+		_rows, err := tx.Query(ctx, query, q.Args...)
 		if err != nil {
 			return nil, err
 		}
 
 		rows = _rows
 	} else {
-		_rows, err := repo.DB.Query(ctx, query, args...)
+		_rows, err := repo.DB.Query(ctx, query, q.Args...)
 		if err != nil {
 			return nil, err
 		}
 
 		rows = _rows
 	}
+	defer rows.Close()
 
 	timelines := []*models.TransactionTimeline{}
 
 	for rows.Next() {
-		var tt *models.TransactionTimeline
+		tt := new(models.TransactionTimeline)
 		var id, transactionId uuid.UUID
+		var milestoneId *uuid.UUID
 
 		err := rows.Scan(
 			&id,
 			&tt.Name,
 			&transactionId,
+			&milestoneId,
 			&tt.CreatedAt,
 			&tt.UpdatedAt,
 			&tt.DeletedAt,
@@ -192,6 +222,10 @@ func (repo *TransactionTimelineRepository) GetMany(args []any, where string, tx
 
 		tt.ID = id.String()
 		tt.TransactionID = transactionId.String()
+		if milestoneId != nil {
+			mid := milestoneId.String()
+			tt.MilestoneID = &mid
+		}
 
 		timelines = append(timelines, tt)
 	}
@@ -203,8 +237,15 @@ func (repo *TransactionTimelineRepository) GetMany(args []any, where string, tx
 	return timelines, nil
 }
 
-func (repo *TransactionTimelineRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *TransactionTimelineRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `transaction_timelines`, transactionTimelineFilterColumns, f)
+}
+
+func (repo *TransactionTimelineRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM transaction_timelines WHERE id = $1`
@@ -218,8 +259,8 @@ func (repo *TransactionTimelineRepository) Delete(id string, tx pgx.Tx) (err err
 	return
 }
 
-func (repo *TransactionTimelineRepository) SoftDelete(id string, tx pgx.Tx) error {
-	tt, err := repo.GetById(id, tx)
+func (repo *TransactionTimelineRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	tt, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return nil
 	}
@@ -227,5 +268,5 @@ func (repo *TransactionTimelineRepository) SoftDelete(id string, tx pgx.Tx) erro
 	now := time.Now().UTC()
 	tt.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 	tt.UpdatedAt = now
-	return repo.Update(tt, tx)
+	return repo.Update(ctx, tt, tx)
 }
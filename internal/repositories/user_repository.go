@@ -10,18 +10,21 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/princecee/escrow-api/pkg/utils"
 )
 
 type IUserRepository interface {
-	Create(u *models.User, tx pgx.Tx) error
-	Update(u *models.User, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.User, error)
-	GetByEmail(email string, tx pgx.Tx) (*models.User, error)
-	GetByPhoneNumber(phone string, tx pgx.Tx) (*models.User, error)
-	GetByBusinessId(id string, tx pgx.Tx) (*models.User, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
+	Create(ctx context.Context, u *models.User, tx pgx.Tx) error
+	Update(ctx context.Context, u *models.User, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.User, error)
+	GetByEmail(ctx context.Context, email string, tx pgx.Tx) (*models.User, error)
+	GetByPhoneNumber(ctx context.Context, phone string, tx pgx.Tx) (*models.User, error)
+	GetByBusinessId(ctx context.Context, id string, tx pgx.Tx) (*models.User, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.User, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
 }
 
 type UserRepository struct {
@@ -33,7 +36,7 @@ func NewUserRepository(db *pgxpool.Pool, timeout time.Duration) *UserRepository
 	return &UserRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *UserRepository) Create(u *models.User, tx pgx.Tx) error {
+func (repo *UserRepository) Create(ctx context.Context, u *models.User, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	u.CreatedAt = now
 	u.UpdatedAt = now
@@ -59,7 +62,7 @@ func (repo *UserRepository) Create(u *models.User, tx pgx.Tx) error {
 		args = []any{u.Email, u.AccountType, u.RegStage, u.BusinessID, u.CreatedAt, u.UpdatedAt}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	var id uuid.UUID
@@ -82,10 +85,10 @@ func (repo *UserRepository) Create(u *models.User, tx pgx.Tx) error {
 	return nil
 }
 
-func (repo *UserRepository) Update(u *models.User, tx pgx.Tx) error {
+func (repo *UserRepository) Update(ctx context.Context, u *models.User, tx pgx.Tx) error {
 	u.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(u, "users")
@@ -100,46 +103,46 @@ func (repo *UserRepository) Update(u *models.User, tx pgx.Tx) error {
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&u.Version)
 }
 
-func (repo *UserRepository) getByKey(key string, value any, tx pgx.Tx) (*models.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+const userSelectQuery = `
+	SELECT
+		u.id,
+		u.email,
+		u.phone_number,
+		u.first_name,
+		u.last_name,
+		u.is_phone_number_verified,
+		u.is_email_verified,
+		u.reg_stage,
+		u.account_type,
+		u.business_id,
+		u.image_url,
+		u.role,
+		u.created_at,
+		u.updated_at,
+		u.deleted_at,
+		u.version,
+		COALESCE(b.name, '') AS b_name,
+		COALESCE(b.email, '') AS b_email,
+		COALESCE(b.image_url, '') AS b_image_url,
+		COALESCE(b.tier, 'standard') AS b_tier,
+		COALESCE(b.created_at, '1970-01-01 00:00:00') AS b_created_at,
+		COALESCE(b.updated_at, '1970-01-01 00:00:00') AS b_updated_at,
+		COALESCE(b.deleted_at, '1970-01-01 00:00:00') AS b_deleted_at,
+		COALESCE(b.version, 1) AS b_version
+	FROM
+		users u
+	LEFT JOIN businesses b ON b.id = u.business_id
+`
+
+func (repo *UserRepository) getByKey(ctx context.Context, key string, value any, tx pgx.Tx) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
-	u := new(models.User)
-
-	var id, businessId *uuid.UUID
-	var imageUrl *string
-	var business models.Business
-
-	query := fmt.Sprintf(`
-		SELECT
-			u.id,
-			u.email,
-			u.phone_number,
-			u.first_name,
-			u.last_name,
-			u.is_phone_number_verified,
-			u.is_email_verified,
-			u.reg_stage,
-			u.account_type,
-			u.business_id,
-			u.image_url,
-			u.created_at,
-			u.updated_at,
-			u.deleted_at,
-			u.version,
-			COALESCE(b.name, '') AS b_name,
-			COALESCE(b.email, '') AS b_email,
-			COALESCE(b.image_url, '') AS b_image_url,
-			COALESCE(b.created_at, '1970-01-01 00:00:00') AS b_created_at,
-			COALESCE(b.updated_at, '1970-01-01 00:00:00') AS b_updated_at,
-			COALESCE(b.deleted_at, '1970-01-01 00:00:00') AS b_deleted_at,
-			COALESCE(b.version, 1) AS b_version
-		FROM
-			users u
-		LEFT JOIN businesses b ON b.id = u.business_id
+	query := fmt.Sprintf(`%s
 		WHERE
 			%s = $1
 			AND u.deleted_at IS NULL`,
+		userSelectQuery,
 		key,
 	)
 
@@ -150,6 +153,85 @@ func (repo *UserRepository) getByKey(key string, value any, tx pgx.Tx) (*models.
 		row = repo.DB.QueryRow(ctx, query, value)
 	}
 
+	return scanUser(row)
+}
+
+// userFilterColumns are the columns users can be filtered and sorted on.
+var userFilterColumns = filter.Columns{
+	"id":           "u.id",
+	"role":         "u.role",
+	"account_type": "u.account_type",
+	"email":        "u.email",
+	"first_name":   "u.first_name",
+	"last_name":    "u.last_name",
+	"phone_number": "u.phone_number",
+	"created_at":   "u.created_at",
+}
+
+func (repo *UserRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	q, err := f.Build(userFilterColumns, "-created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`%s
+		%s
+		%s
+		%s
+	`, userSelectQuery, q.Where, q.OrderBy, q.Page)
+
+	var rows pgx.Rows
+	if tx != nil {
+		_rows, err := tx.Query(ctx, query, q.Args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	} else {
+		_rows, err := repo.DB.Query(ctx, query, q.Args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (repo *UserRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `users u`, userFilterColumns, f)
+}
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	u := new(models.User)
+
+	var id, businessId *uuid.UUID
+	var imageUrl *string
+	var business models.Business
+
 	err := row.Scan(
 		&id,
 		&u.Email,
@@ -162,6 +244,7 @@ func (repo *UserRepository) getByKey(key string, value any, tx pgx.Tx) (*models.
 		&u.AccountType,
 		&businessId,
 		&imageUrl,
+		&u.Role,
 		&u.CreatedAt,
 		&u.UpdatedAt,
 		&u.DeletedAt,
@@ -169,6 +252,7 @@ func (repo *UserRepository) getByKey(key string, value any, tx pgx.Tx) (*models.
 		&business.Name,
 		&business.Email,
 		&business.ImageUrl,
+		&business.Tier,
 		&business.CreatedAt,
 		&business.UpdatedAt,
 		&business.DeletedAt,
@@ -185,32 +269,34 @@ func (repo *UserRepository) getByKey(key string, value any, tx pgx.Tx) (*models.
 		business.ImageUrl = *imageUrl
 	}
 
-	if u.AccountType == models.BusinessAccountType {
-		*u.BusinessID = businessId.String()
+	if u.AccountType == models.BusinessAccountType && businessId != nil {
+		bid := businessId.String()
+		business.ID = bid
+		u.BusinessID = &bid
 		u.Business = &business
 	}
 
 	return u, nil
 }
 
-func (repo *UserRepository) GetById(id string, tx pgx.Tx) (*models.User, error) {
-	return repo.getByKey("u.id", id, tx)
+func (repo *UserRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.User, error) {
+	return repo.getByKey(ctx, "u.id", id, tx)
 }
 
-func (repo *UserRepository) GetByEmail(email string, tx pgx.Tx) (*models.User, error) {
-	return repo.getByKey("u.email", email, tx)
+func (repo *UserRepository) GetByEmail(ctx context.Context, email string, tx pgx.Tx) (*models.User, error) {
+	return repo.getByKey(ctx, "u.email", email, tx)
 }
 
-func (repo *UserRepository) GetByPhoneNumber(phone string, tx pgx.Tx) (*models.User, error) {
-	return repo.getByKey("u.phone_number", phone, tx)
+func (repo *UserRepository) GetByPhoneNumber(ctx context.Context, phone string, tx pgx.Tx) (*models.User, error) {
+	return repo.getByKey(ctx, "u.phone_number", phone, tx)
 }
 
-func (repo *UserRepository) GetByBusinessId(id string, tx pgx.Tx) (*models.User, error) {
-	return repo.getByKey("u.business_id", id, tx)
+func (repo *UserRepository) GetByBusinessId(ctx context.Context, id string, tx pgx.Tx) (*models.User, error) {
+	return repo.getByKey(ctx, "u.business_id", id, tx)
 }
 
-func (repo *UserRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *UserRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM users WHERE id = $1`
@@ -224,8 +310,8 @@ func (repo *UserRepository) Delete(id string, tx pgx.Tx) (err error) {
 	return
 }
 
-func (repo *UserRepository) SoftDelete(id string, tx pgx.Tx) error {
-	u, err := repo.GetById(id, tx)
+func (repo *UserRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	u, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return nil
 	}
@@ -233,5 +319,5 @@ func (repo *UserRepository) SoftDelete(id string, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	u.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 	u.UpdatedAt = now
-	return repo.Update(u, tx)
+	return repo.Update(ctx, u, tx)
 }
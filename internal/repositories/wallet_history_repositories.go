@@ -10,17 +10,19 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/princecee/escrow-api/pkg/utils"
 )
 
 type IWalletHistoryRepository interface {
-	Create(h *models.WalletHistory, tx pgx.Tx) error
-	Update(h *models.WalletHistory, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.WalletHistory, error)
-	GetByWalletId(id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.WalletHistory, error)
-	GetMany(args []any, where string, tx pgx.Tx) ([]*models.WalletHistory, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
+	Create(ctx context.Context, h *models.WalletHistory, tx pgx.Tx) error
+	Update(ctx context.Context, h *models.WalletHistory, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.WalletHistory, error)
+	GetByWalletId(ctx context.Context, id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.WalletHistory, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.WalletHistory, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
 }
 
 type WalletHistoryRepository struct {
@@ -32,21 +34,21 @@ func NewWalletHistoryRepository(db *pgxpool.Pool, timeout time.Duration) *Wallet
 	return &WalletHistoryRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *WalletHistoryRepository) Create(h *models.WalletHistory, tx pgx.Tx) error {
+func (repo *WalletHistoryRepository) Create(ctx context.Context, h *models.WalletHistory, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	h.CreatedAt = now
 	h.UpdatedAt = now
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
-		INSERT INTO wallet_histories (wallet_id, type, amount, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO wallet_histories (wallet_id, type, amount, status, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, version
 	`
 
-	args := []any{h.WalletID, h.Type, h.Amount, h.Status, h.CreatedAt, h.UpdatedAt}
+	args := []any{h.WalletID, h.Type, h.Amount, h.Status, h.Note, h.CreatedAt, h.UpdatedAt}
 
 	if tx != nil {
 		return tx.QueryRow(ctx, query, args...).Scan(&h.ID, &h.Version)
@@ -55,10 +57,10 @@ func (repo *WalletHistoryRepository) Create(h *models.WalletHistory, tx pgx.Tx)
 	return repo.DB.QueryRow(ctx, query, args...).Scan(&h.ID, &h.Version)
 }
 
-func (repo *WalletHistoryRepository) Update(h *models.WalletHistory, tx pgx.Tx) error {
+func (repo *WalletHistoryRepository) Update(ctx context.Context, h *models.WalletHistory, tx pgx.Tx) error {
 	h.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(h, "wallet_histories")
@@ -73,8 +75,8 @@ func (repo *WalletHistoryRepository) Update(h *models.WalletHistory, tx pgx.Tx)
 	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&h.Version)
 }
 
-func (repo *WalletHistoryRepository) getByKey(key string, value any, tx pgx.Tx) (*models.WalletHistory, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *WalletHistoryRepository) getByKey(ctx context.Context, key string, value any, tx pgx.Tx) (*models.WalletHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	h := new(models.WalletHistory)
@@ -85,6 +87,7 @@ func (repo *WalletHistoryRepository) getByKey(key string, value any, tx pgx.Tx)
 			h.type,
 			h.amount,
 			h.status,
+			h.note,
 			h.created_at,
 			h.updated_at,
 			h.deleted_at,
@@ -119,6 +122,7 @@ func (repo *WalletHistoryRepository) getByKey(key string, value any, tx pgx.Tx)
 		&h.Type,
 		&h.Amount,
 		&h.Status,
+		&h.Note,
 		&h.CreatedAt,
 		&h.UpdatedAt,
 		&h.DeletedAt,
@@ -143,12 +147,12 @@ func (repo *WalletHistoryRepository) getByKey(key string, value any, tx pgx.Tx)
 	return h, nil
 }
 
-func (repo *WalletHistoryRepository) GetById(id string, tx pgx.Tx) (*models.WalletHistory, error) {
-	return repo.getByKey("h.id", id, tx)
+func (repo *WalletHistoryRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.WalletHistory, error) {
+	return repo.getByKey(ctx, "h.id", id, tx)
 }
 
-func (repo *WalletHistoryRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *WalletHistoryRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `DELETE FROM wallet_histories WHERE id = $1`
@@ -162,8 +166,8 @@ func (repo *WalletHistoryRepository) Delete(id string, tx pgx.Tx) (err error) {
 	return
 }
 
-func (repo *WalletHistoryRepository) SoftDelete(id string, tx pgx.Tx) error {
-	h, err := repo.GetById(id, tx)
+func (repo *WalletHistoryRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	h, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return err
 	}
@@ -172,11 +176,11 @@ func (repo *WalletHistoryRepository) SoftDelete(id string, tx pgx.Tx) error {
 	h.UpdatedAt = now
 	h.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 
-	return repo.Update(h, tx)
+	return repo.Update(ctx, h, tx)
 }
 
-func (repo *WalletHistoryRepository) GetByWalletId(id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.WalletHistory, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+func (repo *WalletHistoryRepository) GetByWalletId(ctx context.Context, id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.WalletHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
@@ -186,10 +190,11 @@ func (repo *WalletHistoryRepository) GetByWalletId(id string, pagination utils.P
 			h.type,
 			h.amount,
 			h.status,
+			h.note,
 			h.created_at,
 			h.updated_at,
 			h.deleted_at,
-			h.version
+			h.version,
 			w.identifier,
 			w.balance,
 			w.receivable_balance,
@@ -228,11 +233,25 @@ func (repo *WalletHistoryRepository) GetByWalletId(id string, pagination utils.P
 	return returnFromRows(rows)
 }
 
-func (repo *WalletHistoryRepository) GetMany(args []any, where string, tx pgx.Tx) ([]*models.WalletHistory, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+// walletHistoryFilterColumns are the columns wallet histories can be filtered and sorted on.
+var walletHistoryFilterColumns = filter.Columns{
+	"id":         "h.id",
+	"wallet_id":  "h.wallet_id",
+	"type":       "h.type",
+	"amount":     "h.amount",
+	"status":     "h.status",
+	"created_at": "h.created_at",
+}
+
+func (repo *WalletHistoryRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.WalletHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
-	argLen := len(args)
+	q, err := f.Build(walletHistoryFilterColumns, "-created_at")
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT
 			h.id,
@@ -240,6 +259,7 @@ func (repo *WalletHistoryRepository) GetMany(args []any, where string, tx pgx.Tx
 			h.type,
 			h.amount,
 			h.status,
+			h.note,
 			h.created_at,
 			h.updated_at,
 			h.deleted_at,
@@ -257,20 +277,20 @@ func (repo *WalletHistoryRepository) GetMany(args []any, where string, tx pgx.Tx
 			wallet_histories h
 		INNER JOIN wallets w ON w.id = h.wallet_id
 		%s
-		OFFSET $%d
-		LIMIT $%d
-	`, where, argLen-1, argLen)
+		%s
+		%s
+	`, q.Where, q.OrderBy, q.Page)
 
 	var rows pgx.Rows
 	if tx != nil {
-		_rows, err := tx.Query(ctx, query, args...)
+		_rows, err := tx.Query(ctx, query, q.Args...)
 		if err != nil {
 			return nil, err
 		}
 
 		rows = _rows
 	} else {
-		_rows, err := repo.DB.Query(ctx, query, args...)
+		_rows, err := repo.DB.Query(ctx, query, q.Args...)
 		if err != nil {
 			return nil, err
 		}
@@ -281,6 +301,14 @@ func (repo *WalletHistoryRepository) GetMany(args []any, where string, tx pgx.Tx
 	return returnFromRows(rows)
 }
 
+func (repo *WalletHistoryRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `wallet_histories h
+		INNER JOIN wallets w ON w.id = h.wallet_id`, walletHistoryFilterColumns, f)
+}
+
 func returnFromRows(rows pgx.Rows) ([]*models.WalletHistory, error) {
 	walletHistories := []*models.WalletHistory{}
 
@@ -295,6 +323,7 @@ func returnFromRows(rows pgx.Rows) ([]*models.WalletHistory, error) {
 			&h.Type,
 			&h.Amount,
 			&h.Status,
+			&h.Note,
 			&h.CreatedAt,
 			&h.UpdatedAt,
 			&h.DeletedAt,
@@ -3,23 +3,38 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/money"
 	"github.com/princecee/escrow-api/pkg/utils"
 )
 
+// ErrConflict is returned by updates made from a stale copy of a record.
+var ErrConflict = errors.New("record was changed by another request, try again")
+
+// checkViolation is the postgres error code of a failed CHECK constraint.
+const checkViolation = "23514"
+
 type IWalletRepository interface {
-	Create(w *models.Wallet, tx pgx.Tx) error
-	Update(w *models.Wallet, tx pgx.Tx) error
-	GetById(id string, tx pgx.Tx) (*models.Wallet, error)
-	GetByIdentifier(id string, tx pgx.Tx) (*models.Wallet, error)
-	Delete(id string, tx pgx.Tx) error
-	SoftDelete(id string, tx pgx.Tx) error
+	Create(ctx context.Context, w *models.Wallet, tx pgx.Tx) error
+	Update(ctx context.Context, w *models.Wallet, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Wallet, error)
+	GetByIdentifier(ctx context.Context, id, currency string, tx pgx.Tx) (*models.Wallet, error)
+	GetManyByIdentifier(ctx context.Context, id string, tx pgx.Tx) ([]*models.Wallet, error)
+	GetOrCreate(ctx context.Context, identifier, accountType, currency string, tx pgx.Tx) (*models.Wallet, error)
+	GetByIdForUpdate(ctx context.Context, id string, tx pgx.Tx) (*models.Wallet, error)
+	GetByIdentifierForUpdate(ctx context.Context, id, currency string, tx pgx.Tx) (*models.Wallet, error)
+	Credit(ctx context.Context, id string, m money.Money, tx pgx.Tx) (*models.Wallet, error)
+	Debit(ctx context.Context, id string, m money.Money, tx pgx.Tx) (*models.Wallet, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+	SoftDelete(ctx context.Context, id string, tx pgx.Tx) error
 }
 
 type WalletRepository struct {
@@ -31,21 +46,24 @@ func NewWalletRepository(db *pgxpool.Pool, timeout time.Duration) *WalletReposit
 	return &WalletRepository{DB: db, Timeout: timeout}
 }
 
-func (repo *WalletRepository) Create(w *models.Wallet, tx pgx.Tx) error {
+func (repo *WalletRepository) Create(ctx context.Context, w *models.Wallet, tx pgx.Tx) error {
 	now := time.Now().UTC()
 	w.CreatedAt = now
 	w.UpdatedAt = now
+	if w.Currency == "" {
+		w.Currency = money.NGN
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	query := `
-		INSERT INTO wallets (identifier, balance, receivable_balance, payable_balance, account_type, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO wallets (identifier, balance, receivable_balance, payable_balance, currency, account_type, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, version
 	`
 
-	args := []any{w.Identifier, w.Balance, w.Receivable, w.Payable, w.AccountType, w.CreatedAt, w.UpdatedAt}
+	args := []any{w.Identifier, w.Balance, w.Receivable, w.Payable, w.Currency, w.AccountType, w.CreatedAt, w.UpdatedAt}
 
 	var id uuid.UUID
 	if tx != nil {
@@ -67,7 +85,7 @@ func (repo *WalletRepository) Create(w *models.Wallet, tx pgx.Tx) error {
 	return nil
 }
 
-func (repo *WalletRepository) Update(w *models.Wallet, tx pgx.Tx) error {
+func (repo *WalletRepository) Update(ctx context.Context, w *models.Wallet, tx pgx.Tx) error {
 	user := w.User
 	business := w.Business
 
@@ -76,7 +94,7 @@ func (repo *WalletRepository) Update(w *models.Wallet, tx pgx.Tx) error {
 
 	w.UpdatedAt = time.Now().UTC()
 
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
 	qs, err := utils.GetUpdateQueryFromStruct(w, "wallets")
@@ -88,73 +106,272 @@ func (repo *WalletRepository) Update(w *models.Wallet, tx pgx.Tx) error {
 	w.Business = business
 
 	if tx != nil {
-		return tx.QueryRow(ctx, qs.Query, qs.Args...).Scan(&w.Version)
+		err = tx.QueryRow(ctx, qs.Query, qs.Args...).Scan(&w.Version)
+	} else {
+		err = repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&w.Version)
 	}
 
-	return repo.DB.QueryRow(ctx, qs.Query, qs.Args...).Scan(&w.Version)
+	// the version in the WHERE clause no longer matches
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrConflict
+	}
+
+	return err
 }
 
-func (repo *WalletRepository) getByKey(key string, value any, tx pgx.Tx) (*models.Wallet, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
+const walletSelectQuery = `
+	SELECT
+		w.id,
+		w.identifier,
+		w.balance,
+		w.receivable_balance,
+		w.payable_balance,
+		w.currency,
+		w.account_type,
+		w.is_frozen,
+		w.created_at,
+		w.updated_at,
+		w.deleted_at,
+		w.version,
+		COALESCE(u.email, ''),
+		COALESCE(u.phone_number, ''),
+		COALESCE(u.first_name, ''),
+		COALESCE(u.last_name, ''),
+		COALESCE(u.is_phone_number_verified, false),
+		COALESCE(u.is_email_verified, false),
+		COALESCE(u.reg_stage, 1),
+		COALESCE(u.account_type, 'personal'),
+		COALESCE(u.business_id, NULL),
+		COALESCE(u.created_at, now()),
+		COALESCE(u.updated_at, now()),
+		COALESCE(u.deleted_at, NULL),
+		COALESCE(u.version, 1),
+		COALESCE(b.name, ''),
+		COALESCE(b.email, ''),
+		COALESCE(b.created_at, now()),
+		COALESCE(b.updated_at, now()),
+		COALESCE(b.deleted_at, NULL),
+		COALESCE(b.version, 1)
+
+	FROM wallets w
+	LEFT JOIN users u ON u.id = w.identifier AND w.account_type = 'personal'
+	LEFT JOIN businesses b ON b.id = w.identifier AND w.account_type = 'business'
+`
+
+func (repo *WalletRepository) getByKeys(ctx context.Context, where string, tx pgx.Tx, args ...any) (*models.Wallet, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
 	defer cancel()
 
-	w := new(models.Wallet)
-	query := fmt.Sprintf(`
-		SELECT
-			w.id,
-			w.identifier,
-			w.balance,
-			w.receivable_balance,
-			w.payable_balance,
-			w.account_type,
-			w.created_at,
-			w.updated_at,
-			w.deleted_at,
-			w.version,
-			COALESCE(u.email, ''),
-			COALESCE(u.phone_number, ''),
-			COALESCE(u.first_name, ''),
-			COALESCE(u.last_name, ''),
-			COALESCE(u.is_phone_number_verified, false),
-			COALESCE(u.is_email_verified, false),
-			COALESCE(u.reg_stage, 1),
-			COALESCE(u.account_type, 'personal'),
-			COALESCE(u.business_id, NULL),
-			COALESCE(u.created_at, now()),
-			COALESCE(u.updated_at, now()),
-			COALESCE(u.deleted_at, NULL),
-			COALESCE(u.version, 1),
-			COALESCE(b.name, ''),
-			COALESCE(b.email, ''),
-			COALESCE(b.created_at, now()),
-			COALESCE(b.updated_at, now()),
-			COALESCE(b.deleted_at, NULL),
-			COALESCE(b.version, 1)
-
-		FROM wallets w
-		LEFT JOIN users u ON u.id = w.identifier AND w.account_type = 'personal'
-		LEFT JOIN businesses b ON b.id = w.identifier AND w.account_type = 'business'
-		WHERE %s = $1
-	`, key)
-
-	var id, identifier uuid.UUID
-	user := new(models.User)
-	business := new(models.Business)
+	query := fmt.Sprintf(`%s
+		WHERE %s
+	`, walletSelectQuery, where)
 
 	var row pgx.Row
 	if tx != nil {
-		row = tx.QueryRow(ctx, query, value)
+		row = tx.QueryRow(ctx, query, args...)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, args...)
+	}
+
+	return scanWallet(row)
+}
+
+func (repo *WalletRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Wallet, error) {
+	return repo.getByKeys(ctx, "w.id = $1", tx, id)
+}
+
+// GetByIdentifier returns the wallet an owner holds in currency.
+func (repo *WalletRepository) GetByIdentifier(ctx context.Context, id, currency string, tx pgx.Tx) (*models.Wallet, error) {
+	return repo.getByKeys(ctx, "w.identifier = $1 AND w.currency = $2", tx, id, currency)
+}
+
+// GetManyByIdentifier returns every wallet of an owner, one per currency.
+func (repo *WalletRepository) GetManyByIdentifier(ctx context.Context, id string, tx pgx.Tx) ([]*models.Wallet, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`%s
+		WHERE w.identifier = $1
+		ORDER BY w.created_at
+	`, walletSelectQuery)
+
+	var rows pgx.Rows
+	if tx != nil {
+		_rows, err := tx.Query(ctx, query, id)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	} else {
+		_rows, err := repo.DB.Query(ctx, query, id)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	}
+	defer rows.Close()
+
+	wallets := []*models.Wallet{}
+	for rows.Next() {
+		w, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		wallets = append(wallets, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+// GetOrCreate returns the wallet an owner holds in currency, opening it the
+// first time they receive money in that currency.
+func (repo *WalletRepository) GetOrCreate(ctx context.Context, identifier, accountType, currency string, tx pgx.Tx) (*models.Wallet, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	query := `
+		INSERT INTO wallets (identifier, currency, account_type, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (identifier, currency) DO NOTHING
+	`
+
+	args := []any{identifier, currency, accountType, now, now}
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, args...)
+	} else {
+		_, err = repo.DB.Exec(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByIdentifier(ctx, identifier, currency, tx)
+}
+
+// GetByIdForUpdate returns the wallet with id and locks it until tx ends.
+func (repo *WalletRepository) GetByIdForUpdate(ctx context.Context, id string, tx pgx.Tx) (*models.Wallet, error) {
+	return repo.getByKeys(ctx, "w.id = $1 FOR UPDATE OF w", tx, id)
+}
+
+// GetByIdentifierForUpdate returns the wallet an owner holds in currency and
+// locks it until tx ends.
+func (repo *WalletRepository) GetByIdentifierForUpdate(ctx context.Context, id, currency string, tx pgx.Tx) (*models.Wallet, error) {
+	return repo.getByKeys(ctx, "w.identifier = $1 AND w.currency = $2 FOR UPDATE OF w", tx, id, currency)
+}
+
+// Credit adds m to the balance of the wallet with id, all of it available
+// for withdrawal, and returns the updated wallet.
+func (repo *WalletRepository) Credit(ctx context.Context, id string, m money.Money, tx pgx.Tx) (*models.Wallet, error) {
+	if m.IsNegative() {
+		return nil, errors.New("cannot credit a negative amount")
+	}
+
+	query := `
+		UPDATE wallets
+		SET balance = balance + $1, receivable_balance = receivable_balance + $1, updated_at = $2, version = version + 1
+		WHERE id = $3 AND currency = $4
+	`
+
+	return repo.move(ctx, id, m, query, tx)
+}
+
+// Debit takes m out of the part of the balance of the wallet with id that is
+// available for withdrawal, and returns the updated wallet. The balance is
+// checked by the same statement that moves it, so concurrent debits cannot
+// both spend it.
+func (repo *WalletRepository) Debit(ctx context.Context, id string, m money.Money, tx pgx.Tx) (*models.Wallet, error) {
+	if m.IsNegative() {
+		return nil, errors.New("cannot debit a negative amount")
+	}
+
+	query := `
+		UPDATE wallets
+		SET balance = balance - $1, receivable_balance = receivable_balance - $1, updated_at = $2, version = version + 1
+		WHERE id = $3 AND currency = $4 AND receivable_balance >= $1
+	`
+
+	return repo.move(ctx, id, m, query, tx)
+}
+
+// move runs a credit or debit query and tells why it matched no wallet.
+func (repo *WalletRepository) move(ctx context.Context, id string, m money.Money, query string, tx pgx.Tx) (*models.Wallet, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	args := []any{m.Amount, time.Now().UTC(), id, m.Currency}
+
+	var tag pgconn.CommandTag
+	var err error
+	if tx != nil {
+		tag, err = tx.Exec(ctx, query, args...)
+	} else {
+		tag, err = repo.DB.Exec(ctx, query, args...)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == checkViolation {
+		return nil, models.ErrInsufficientFunds
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := repo.GetById(ctx, id, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		if w.Currency != m.Currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+
+		return nil, models.ErrInsufficientFunds
+	}
+
+	return w, nil
+}
+
+func (repo *WalletRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `DELETE FROM wallets WHERE id = $1`
+
+	if tx != nil {
+		_, err = tx.Exec(ctx, query)
 	} else {
-		row = repo.DB.QueryRow(ctx, query, value)
+		_, err = repo.DB.Exec(ctx, query)
 	}
 
+	return
+}
+
+func scanWallet(row pgx.Row) (*models.Wallet, error) {
+	w := new(models.Wallet)
+	user := new(models.User)
+	business := new(models.Business)
+
+	var id, identifier uuid.UUID
 	err := row.Scan(
 		&id,
 		&identifier,
 		&w.Balance,
 		&w.Receivable,
 		&w.Payable,
+		&w.Currency,
 		&w.AccountType,
+		&w.IsFrozen,
 		&w.CreatedAt,
 		&w.UpdatedAt,
 		&w.DeletedAt,
@@ -184,44 +401,20 @@ func (repo *WalletRepository) getByKey(key string, value any, tx pgx.Tx) (*model
 	}
 
 	w.ID = id.String()
+	w.Identifier = identifier.String()
 	if w.AccountType == models.PersonalAccountType {
 		user.ID = identifier.String()
-		w.Identifier = identifier.String()
 		w.User = user
 	} else {
 		business.ID = identifier.String()
-		w.Identifier = identifier.String()
 		w.Business = business
 	}
 
 	return w, nil
 }
 
-func (repo *WalletRepository) GetById(id string, tx pgx.Tx) (*models.Wallet, error) {
-	return repo.getByKey("w.id", id, tx)
-}
-
-func (repo *WalletRepository) GetByIdentifier(id string, tx pgx.Tx) (*models.Wallet, error) {
-	return repo.getByKey("w.identifier", id, tx)
-}
-
-func (repo *WalletRepository) Delete(id string, tx pgx.Tx) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.Timeout)
-	defer cancel()
-
-	query := `DELETE FROM wallets WHERE id = $1`
-
-	if tx != nil {
-		_, err = tx.Exec(ctx, query)
-	} else {
-		_, err = repo.DB.Exec(ctx, query)
-	}
-
-	return
-}
-
-func (repo *WalletRepository) SoftDelete(id string, tx pgx.Tx) error {
-	w, err := repo.GetById(id, tx)
+func (repo *WalletRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	w, err := repo.GetById(ctx, id, tx)
 	if err != nil {
 		return err
 	}
@@ -230,5 +423,5 @@ func (repo *WalletRepository) SoftDelete(id string, tx pgx.Tx) error {
 	w.UpdatedAt = now
 	w.DeletedAt = models.NullTime{NullTime: sql.NullTime{Time: now}}
 
-	return repo.Update(w, tx)
+	return repo.Update(ctx, w, tx)
 }
@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
+)
+
+type IWebhookDeliveryRepository interface {
+	Create(ctx context.Context, d *models.WebhookDelivery, tx pgx.Tx) error
+	Update(ctx context.Context, d *models.WebhookDelivery, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.WebhookDelivery, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.WebhookDelivery, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration, tx pgx.Tx) ([]*models.WebhookDelivery, error)
+}
+
+type WebhookDeliveryRepository struct {
+	DB      *pgxpool.Pool
+	Timeout time.Duration
+}
+
+func NewWebhookDeliveryRepository(db *pgxpool.Pool, timeout time.Duration) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{DB: db, Timeout: timeout}
+}
+
+const webhookDeliveryColumns = `
+	id,
+	endpoint_id,
+	event_id,
+	event_type,
+	payload,
+	status,
+	attempts,
+	next_attempt_at,
+	last_attempt_at,
+	response_status,
+	response_body,
+	error,
+	trace_context,
+	created_at,
+	updated_at,
+	deleted_at,
+	version
+`
+
+func (repo *WebhookDeliveryRepository) Create(ctx context.Context, d *models.WebhookDelivery, tx pgx.Tx) error {
+	now := time.Now().UTC()
+	d.CreatedAt = now
+	d.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	traceContext := d.TraceContext
+	if traceContext == nil {
+		traceContext = map[string]string{}
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, status, next_attempt_at, trace_context, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, version
+	`
+
+	args := []any{
+		d.EndpointID,
+		d.EventID,
+		d.EventType,
+		string(d.Payload),
+		d.Status,
+		d.NextAttemptAt,
+		traceContext,
+		d.CreatedAt,
+		d.UpdatedAt,
+	}
+
+	var id uuid.UUID
+	if tx != nil {
+		err := tx.QueryRow(ctx, query, args...).Scan(&id, &d.Version)
+		if err != nil {
+			return err
+		}
+
+		d.ID = id.String()
+		return nil
+	}
+
+	err := repo.DB.QueryRow(ctx, query, args...).Scan(&id, &d.Version)
+	if err != nil {
+		return err
+	}
+
+	d.ID = id.String()
+	return nil
+}
+
+// Update records the outcome of an attempt. The version is not checked since
+// a claimed delivery is only ever written by the worker holding the claim.
+func (repo *WebhookDeliveryRepository) Update(ctx context.Context, d *models.WebhookDelivery, tx pgx.Tx) error {
+	d.UpdatedAt = time.Now().UTC()
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET
+			status = $1,
+			attempts = $2,
+			next_attempt_at = $3,
+			last_attempt_at = $4,
+			response_status = $5,
+			response_body = $6,
+			error = $7,
+			updated_at = $8,
+			version = version + 1
+		WHERE id = $9
+		RETURNING version
+	`
+
+	args := []any{
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastAttemptAt,
+		d.ResponseStatus,
+		d.ResponseBody,
+		d.Error,
+		d.UpdatedAt,
+		d.ID,
+	}
+
+	if tx != nil {
+		return tx.QueryRow(ctx, query, args...).Scan(&d.Version)
+	}
+
+	return repo.DB.QueryRow(ctx, query, args...).Scan(&d.Version)
+}
+
+func (repo *WebhookDeliveryRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT %s FROM webhook_deliveries WHERE id = $1`, webhookDeliveryColumns)
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, id)
+	}
+
+	return scanWebhookDelivery(row)
+}
+
+// webhookDeliveryFilterColumns are the columns webhook deliveries can be filtered and sorted on.
+var webhookDeliveryFilterColumns = filter.Columns{
+	"endpoint_id": "endpoint_id",
+	"event_type":  "event_type",
+	"status":      "status",
+	"created_at":  "created_at",
+}
+
+func (repo *WebhookDeliveryRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.WebhookDelivery, error) {
+	q, err := f.Build(webhookDeliveryFilterColumns, "-created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM webhook_deliveries
+		%s
+		%s
+		%s
+	`, webhookDeliveryColumns, q.Where, q.OrderBy, q.Page)
+
+	return repo.query(ctx, query, q.Args, tx)
+}
+
+func (repo *WebhookDeliveryRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `webhook_deliveries`, webhookDeliveryFilterColumns, f)
+}
+
+// ClaimDue picks up to limit pending deliveries whose next attempt is due and
+// pushes their next attempt back by lease. Rows locked by another worker are
+// skipped, and a worker that dies mid delivery only delays it by lease.
+func (repo *WebhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration, tx pgx.Tx) ([]*models.WebhookDelivery, error) {
+	query := fmt.Sprintf(`
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $2
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s
+	`, webhookDeliveryColumns)
+
+	now := time.Now().UTC()
+	return repo.query(ctx, query, []any{now.Add(lease), now, limit}, tx)
+}
+
+func (repo *WebhookDeliveryRepository) query(ctx context.Context, query string, args []any, tx pgx.Tx) ([]*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	var rows pgx.Rows
+	if tx != nil {
+		_rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	} else {
+		_rows, err := repo.DB.Query(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func scanWebhookDelivery(row pgx.Row) (*models.WebhookDelivery, error) {
+	d := new(models.WebhookDelivery)
+
+	var id, endpointId, eventId uuid.UUID
+	var payload []byte
+	err := row.Scan(
+		&id,
+		&endpointId,
+		&eventId,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastAttemptAt,
+		&d.ResponseStatus,
+		&d.ResponseBody,
+		&d.Error,
+		&d.TraceContext,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+		&d.DeletedAt,
+		&d.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	d.ID = id.String()
+	d.EndpointID = endpointId.String()
+	d.EventID = eventId.String()
+	d.Payload = payload
+
+	return d, nil
+}
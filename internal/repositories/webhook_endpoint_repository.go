@@ -0,0 +1,256 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
+)
+
+type IWebhookEndpointRepository interface {
+	Create(ctx context.Context, e *models.WebhookEndpoint, tx pgx.Tx) error
+	Update(ctx context.Context, e *models.WebhookEndpoint, tx pgx.Tx) error
+	GetById(ctx context.Context, id string, tx pgx.Tx) (*models.WebhookEndpoint, error)
+	GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.WebhookEndpoint, error)
+	Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error)
+	GetSubscribed(ctx context.Context, businessId, eventType string, tx pgx.Tx) ([]*models.WebhookEndpoint, error)
+	Delete(ctx context.Context, id string, tx pgx.Tx) error
+}
+
+type WebhookEndpointRepository struct {
+	DB      *pgxpool.Pool
+	Timeout time.Duration
+}
+
+func NewWebhookEndpointRepository(db *pgxpool.Pool, timeout time.Duration) *WebhookEndpointRepository {
+	return &WebhookEndpointRepository{DB: db, Timeout: timeout}
+}
+
+const webhookEndpointSelectQuery = `
+	SELECT
+		id,
+		business_id,
+		url,
+		secret,
+		event_types,
+		description,
+		is_active,
+		created_at,
+		updated_at,
+		deleted_at,
+		version
+	FROM
+		webhook_endpoints
+`
+
+func (repo *WebhookEndpointRepository) Create(ctx context.Context, e *models.WebhookEndpoint, tx pgx.Tx) error {
+	now := time.Now().UTC()
+	e.CreatedAt = now
+	e.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhook_endpoints (business_id, url, secret, event_types, description, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version
+	`
+
+	args := []any{
+		e.BusinessID,
+		e.URL,
+		e.Secret,
+		e.EventTypes,
+		e.Description,
+		e.IsActive,
+		e.CreatedAt,
+		e.UpdatedAt,
+	}
+
+	var id uuid.UUID
+	if tx != nil {
+		err := tx.QueryRow(ctx, query, args...).Scan(&id, &e.Version)
+		if err != nil {
+			return err
+		}
+
+		e.ID = id.String()
+		return nil
+	}
+
+	err := repo.DB.QueryRow(ctx, query, args...).Scan(&id, &e.Version)
+	if err != nil {
+		return err
+	}
+
+	e.ID = id.String()
+	return nil
+}
+
+// Update writes the editable fields of e. It does not go through
+// GetUpdateQueryFromStruct since the secret is hidden from json and the event
+// types are an array column.
+func (repo *WebhookEndpointRepository) Update(ctx context.Context, e *models.WebhookEndpoint, tx pgx.Tx) error {
+	e.UpdatedAt = time.Now().UTC()
+
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $1, event_types = $2, description = $3, is_active = $4, updated_at = $5, version = version + 1
+		WHERE id = $6 AND version = $7
+		RETURNING version
+	`
+
+	args := []any{e.URL, e.EventTypes, e.Description, e.IsActive, e.UpdatedAt, e.ID, e.Version}
+
+	if tx != nil {
+		return tx.QueryRow(ctx, query, args...).Scan(&e.Version)
+	}
+
+	return repo.DB.QueryRow(ctx, query, args...).Scan(&e.Version)
+}
+
+func (repo *WebhookEndpointRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.WebhookEndpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := webhookEndpointSelectQuery + `WHERE id = $1`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRow(ctx, query, id)
+	}
+
+	return scanWebhookEndpoint(row)
+}
+
+// webhookEndpointFilterColumns are the columns webhook endpoints can be filtered and sorted on.
+var webhookEndpointFilterColumns = filter.Columns{
+	"business_id": "business_id",
+	"created_at":  "created_at",
+}
+
+func (repo *WebhookEndpointRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.WebhookEndpoint, error) {
+	q, err := f.Build(webhookEndpointFilterColumns, "-created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query := webhookEndpointSelectQuery + fmt.Sprintf(`
+		%s
+		%s
+		%s
+	`, q.Where, q.OrderBy, q.Page)
+
+	return repo.query(ctx, query, q.Args, tx)
+}
+
+func (repo *WebhookEndpointRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	return count(ctx, repo.DB, tx, `webhook_endpoints`, webhookEndpointFilterColumns, f)
+}
+
+// GetSubscribed returns the active endpoints of a business that listen for
+// eventType, either by name or through the "*" wildcard.
+func (repo *WebhookEndpointRepository) GetSubscribed(ctx context.Context, businessId, eventType string, tx pgx.Tx) ([]*models.WebhookEndpoint, error) {
+	query := webhookEndpointSelectQuery + `
+		WHERE business_id = $1
+			AND is_active
+			AND ($2 = ANY(event_types) OR '*' = ANY(event_types))
+	`
+
+	return repo.query(ctx, query, []any{businessId, eventType}, tx)
+}
+
+func (repo *WebhookEndpointRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	query := `DELETE FROM webhook_endpoints WHERE id = $1`
+
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, id)
+	} else {
+		_, err = repo.DB.Exec(ctx, query, id)
+	}
+
+	return
+}
+
+func (repo *WebhookEndpointRepository) query(ctx context.Context, query string, args []any, tx pgx.Tx) ([]*models.WebhookEndpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.Timeout)
+	defer cancel()
+
+	var rows pgx.Rows
+	if tx != nil {
+		_rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	} else {
+		_rows, err := repo.DB.Query(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = _rows
+	}
+	defer rows.Close()
+
+	endpoints := []*models.WebhookEndpoint{}
+	for rows.Next() {
+		e, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func scanWebhookEndpoint(row pgx.Row) (*models.WebhookEndpoint, error) {
+	e := new(models.WebhookEndpoint)
+
+	var id, businessId uuid.UUID
+	err := row.Scan(
+		&id,
+		&businessId,
+		&e.URL,
+		&e.Secret,
+		&e.EventTypes,
+		&e.Description,
+		&e.IsActive,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+		&e.DeletedAt,
+		&e.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	e.ID = id.String()
+	e.BusinessID = businessId.String()
+
+	return e, nil
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/money"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+)
+
+var (
+	ErrWalletNotFound  = newError(KindNotFound, "wallet not found")
+	ErrDisputeNotFound = newError(KindNotFound, "dispute not found")
+	ErrDisputeResolved = newError(KindInvalid, "dispute has already been resolved")
+	ErrNotInDispute    = newError(KindInvalid, "transaction is not in dispute")
+	ErrNegativeBalance = newError(KindInvalid, "adjustment would leave the wallet with a negative balance")
+)
+
+// AdjustBalanceInput is what an admin credits, or debits when negative, to a
+// wallet and why.
+type AdjustBalanceInput struct {
+	Amount int    `json:"amount" validate:"required,ne=0"`
+	Reason string `json:"reason" validate:"required,min=10,max=500"`
+}
+
+// ResolveDisputeInput is how an admin settles a dispute.
+type ResolveDisputeInput struct {
+	Resolution string `json:"resolution" validate:"required,oneof=refund_buyer release_seller"`
+	Note       string `json:"note" validate:"required,min=10,max=500"`
+}
+
+// AdminService is what admins do to the accounts and transactions of others.
+// Who may do what is checked by the permissions of the routes.
+type AdminService struct {
+	c config.IConfig
+}
+
+func NewAdminService(c config.IConfig) *AdminService {
+	return &AdminService{c: c}
+}
+
+// SetWalletFrozen freezes or unfreezes the wallet with id.
+func (s *AdminService) SetWalletFrozen(ctx context.Context, id string, frozen bool) (*models.Wallet, error) {
+	var wallet *models.Wallet
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		walletRepo := u.GetWalletRepository()
+
+		var err error
+		wallet, err = walletRepo.GetByIdForUpdate(ctx, id, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWalletNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		before := *wallet
+		wallet.IsFrozen = frozen
+		err = walletRepo.Update(ctx, wallet, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		action := audit.ActionAdminWalletUnfrozen
+		if frozen {
+			action = audit.ActionAdminWalletFrozen
+		}
+
+		entry := audit.FromContext(ctx, action, audit.EntityWallet, wallet.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, before, wallet), u.Tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// AdjustBalance credits or debits the wallet with id by admin, recording the
+// reason in its history. A debit cannot take the balance below zero.
+func (s *AdminService) AdjustBalance(ctx context.Context, admin *models.User, id string, input *AdjustBalanceInput) (*models.Wallet, *models.WalletHistory, error) {
+	var wallet *models.Wallet
+	var walletHistory *models.WalletHistory
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		walletRepo := u.GetWalletRepository()
+
+		var err error
+		wallet, err = walletRepo.GetByIdForUpdate(ctx, id, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWalletNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		before := *wallet
+		if input.Amount < 0 {
+			wallet, err = walletRepo.Debit(ctx, wallet.ID, money.New(-int64(input.Amount), wallet.Currency), u.Tx)
+		} else {
+			wallet, err = walletRepo.Credit(ctx, wallet.ID, money.New(int64(input.Amount), wallet.Currency), u.Tx)
+		}
+		if errors.Is(err, models.ErrInsufficientFunds) {
+			return ErrNegativeBalance
+		}
+		if err != nil {
+			return err
+		}
+
+		walletHistory = &models.WalletHistory{
+			WalletID: wallet.ID,
+			Type:     models.WalletHistoryAdjustmentType,
+			Amount:   input.Amount,
+			Status:   models.WalletHistorySuccessful,
+			Note:     fmt.Sprintf("%s (by %s)", input.Reason, admin.ID),
+			Wallet:   *wallet,
+		}
+		err = u.GetWalletHistoryRepository().Create(ctx, walletHistory, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entry := audit.WithChanges(audit.FromContext(ctx, audit.ActionAdminWalletAdjusted, audit.EntityWallet, wallet.ID), before, wallet)
+		entry.Changes["reason"] = models.AuditChange{To: input.Reason}
+		return u.GetAuditLogRepository().Create(ctx, entry, u.Tx)
+	}, uow.Serializable)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return wallet, walletHistory, nil
+}
+
+// ResolveDispute settles the disputed transaction of the dispute with id.
+// Refunding the buyer credits back what is still held in escrow and cancels
+// the transaction; releasing to the seller pays out the receivable amount of
+// what is held, releases the funded milestones and completes it.
+func (s *AdminService) ResolveDispute(ctx context.Context, admin *models.User, id string, input *ResolveDisputeInput) (*models.Dispute, *models.Transaction, error) {
+	var dispute *models.Dispute
+	var transaction *models.Transaction
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		disputeRepo := u.GetDisputeRepository()
+		walletRepo := u.GetWalletRepository()
+		transactionRepo := u.GetTransactionRepository()
+		milestoneRepo := u.GetMilestoneRepository()
+
+		var err error
+		dispute, err = disputeRepo.GetById(ctx, id, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrDisputeNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if dispute.Status != models.DisputeStatusOpen {
+			return ErrDisputeResolved
+		}
+
+		transaction, err = transactionRepo.GetByIdForUpdate(ctx, dispute.TransactionID, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		if transaction.Status != models.TransactionStatusDisputed {
+			return ErrNotInDispute
+		}
+
+		disputeBefore := *dispute
+		transactionBefore := *transaction
+
+		milestones, err := milestoneRepo.GetByTransactionId(ctx, transaction.ID, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		// only the money still held in escrow is paid out, released milestones
+		// and settled lines were already paid
+		refund, release := transaction.Held(milestones)
+
+		var identifier, accountType string
+		var amount int
+		if input.Resolution == models.DisputeResolutionRefundBuyer {
+			identifier, accountType = transaction.BuyerID, models.PersonalAccountType
+			amount = refund
+			transaction.Status = models.TransactionStatusCanceled
+		} else {
+			identifier, accountType = transaction.SellerID, models.BusinessAccountType
+			amount = release
+			transaction.Status = models.TransactionStatusCompleted
+		}
+
+		entries := []*models.AuditLog{}
+
+		if amount > 0 {
+			// the money is paid out in the currency the transaction was settled in
+			wallet, err := walletRepo.GetOrCreate(ctx, identifier, accountType, transaction.Currency, u.Tx)
+			if err != nil {
+				return err
+			}
+
+			walletBefore := *wallet
+			wallet, err = walletRepo.Credit(ctx, wallet.ID, money.New(int64(amount), transaction.Currency), u.Tx)
+			if err != nil {
+				return err
+			}
+
+			walletHistory := &models.WalletHistory{
+				WalletID: wallet.ID,
+				Type:     models.WalletHistoryDepositType,
+				Amount:   amount,
+				Status:   models.WalletHistorySuccessful,
+				Note:     fmt.Sprintf("dispute %s resolved: %s", dispute.ID, input.Resolution),
+				Wallet:   *wallet,
+			}
+			err = u.GetWalletHistoryRepository().Create(ctx, walletHistory, u.Tx)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, audit.WithChanges(audit.FromContext(ctx, audit.ActionWalletCredited, audit.EntityWallet, wallet.ID), walletBefore, wallet))
+		}
+
+		// releasing pays the seller for every funded milestone, so they are
+		// released with it
+		if input.Resolution != models.DisputeResolutionRefundBuyer {
+			for _, milestone := range milestones {
+				if milestone.Status != models.MilestoneStatusFunded && milestone.Status != models.MilestoneStatusDelivered {
+					continue
+				}
+
+				milestoneBefore := *milestone
+				milestone.Status = models.MilestoneStatusReleased
+				milestone.ReleasedAt = models.NullTime{NullTime: sql.NullTime{Time: time.Now().UTC(), Valid: true}}
+				err = milestoneRepo.Update(ctx, milestone, u.Tx)
+				if err != nil {
+					return err
+				}
+
+				entries = append(entries, audit.WithChanges(audit.FromContext(ctx, audit.ActionMilestoneReleased, audit.EntityMilestone, milestone.ID), milestoneBefore, milestone))
+			}
+		}
+		transaction.Milestones = milestones
+
+		err = transactionRepo.Update(ctx, transaction, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		timeline := &models.TransactionTimeline{
+			TransactionID: transaction.ID,
+			Name:          models.TimelineDisputeResolved,
+		}
+		err = u.GetTransactionTimelineRepository().Create(ctx, timeline, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		dispute.Status = models.DisputeStatusResolved
+		dispute.Resolution = models.NullString{NullString: sql.NullString{String: input.Resolution, Valid: true}}
+		dispute.ResolutionNote = input.Note
+		dispute.ResolvedBy = &admin.ID
+		dispute.ResolvedAt = models.NullTime{NullTime: sql.NullTime{Time: time.Now().UTC(), Valid: true}}
+		err = disputeRepo.Update(ctx, dispute, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries,
+			audit.WithChanges(audit.FromContext(ctx, audit.ActionAdminDisputeResolved, audit.EntityDispute, dispute.ID), disputeBefore, dispute),
+			audit.WithChanges(audit.FromContext(ctx, audit.ActionTransactionStatusChanged, audit.EntityTransaction, transaction.ID), transactionBefore, transaction),
+		)
+		for _, entry := range entries {
+			err = u.GetAuditLogRepository().Create(ctx, entry, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		transactionEvent := webhooks.EventTransactionCompleted
+		if transaction.Status == models.TransactionStatusCanceled {
+			transactionEvent = webhooks.EventTransactionCanceled
+		}
+
+		err = webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventDisputeResolved, dispute)
+		if err != nil {
+			return err
+		}
+
+		return webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, transactionEvent, transaction)
+	}, uow.Serializable)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	text := fmt.Sprintf("The dispute on transaction %s has been resolved: %s", transaction.ID, input.Note)
+	sendEmail(ctx, s.c, "Dispute resolved", text, transaction.Seller.Email, transaction.Buyer.Email)
+
+	return dispute, transaction, nil
+}
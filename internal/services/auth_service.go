@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/invites"
+	"github.com/princecee/escrow-api/pkg/jwt"
+	"github.com/princecee/escrow-api/pkg/push"
+	"github.com/princecee/escrow-api/pkg/throttle"
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/rs/zerolog"
+)
+
+const VerifyEmailSubject = "Email verification"
+
+var ErrAccountExists = newError(KindInvalid, "account already exists")
+
+// SignUpInput is one stage of signing up: the email and account type, then
+// the phone number, then the name and password.
+type SignUpInput struct {
+	AccountType  *string         `json:"account_type" validate:"omitempty,oneof=personal business"`
+	Email        *string         `json:"email" validate:"required,email"`
+	PhoneNumber  *string         `json:"phone_number" validate:"omitempty,min=8"`
+	FirstName    *string         `json:"first_name" validate:"omitempty,alpha"`
+	LastName     *string         `json:"last_name" validate:"omitempty,alpha"`
+	Password     *string         `json:"password" validate:"omitempty,min=8"`
+	BusinessName *string         `json:"business_name" validate:"omitempty"`
+	RegStage     *utils.RegStage `json:"reg_stage" validate:"required,numeric,oneof=1 2 3"`
+}
+
+// SignUpResult is the outcome of a sign up stage. Code is the otp sent to
+// verify the email or phone number of the first two stages; the tokens are
+// only set once the last one is done.
+type SignUpResult struct {
+	User         *models.User
+	Stage        utils.RegStage
+	Code         string
+	AccessToken  string
+	RefreshToken string
+}
+
+type AuthService struct {
+	c        config.IConfig
+	throttle *throttle.LoginThrottle
+}
+
+func NewAuthService(c config.IConfig) *AuthService {
+	return &AuthService{c: c, throttle: throttle.NewLoginThrottle(c.GetRedisClient().DB)}
+}
+
+// SignUp runs the stage of input. A user who comes back before verifying the
+// email or phone number of their current stage is sent a new code instead.
+func (s *AuthService) SignUp(ctx context.Context, input *SignUpInput) (*SignUpResult, error) {
+	user, err := s.c.GetUserRepository().GetByEmail(ctx, *input.Email, nil)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, Invalid(err)
+	}
+
+	if user != nil {
+		switch user.RegStage {
+		case int(utils.RegStage1):
+			if !user.IsEmailVerified {
+				otp, err := s.sendOtp(ctx, user, models.EmailOtpType, nil)
+				if err != nil {
+					return nil, err
+				}
+
+				return &SignUpResult{User: user, Stage: utils.RegStage1, Code: otp.Code}, nil
+			}
+
+		case int(utils.RegStage2):
+			if !user.IsPhoneNumberVerified {
+				otp, err := s.sendOtp(ctx, user, models.SmsOtpType, nil)
+				if err != nil {
+					return nil, err
+				}
+
+				return &SignUpResult{User: user, Stage: utils.RegStage2, Code: otp.Code}, nil
+			}
+
+		case int(utils.RegStage3):
+			return nil, ErrAccountExists
+		}
+	}
+
+	if *input.RegStage != utils.RegStage1 && user == nil {
+		return nil, ErrNotFound
+	}
+
+	result := &SignUpResult{Stage: *input.RegStage}
+	err = uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		var err error
+		switch *input.RegStage {
+		case utils.RegStage1:
+			user, err = s.createUser(ctx, input, u)
+			if err != nil {
+				return err
+			}
+
+			otp, err := s.sendOtp(ctx, user, models.EmailOtpType, u.Tx)
+			if err != nil {
+				return err
+			}
+			result.Code = otp.Code
+
+		case utils.RegStage2:
+			user.PhoneNumber = models.NullString{NullString: sql.NullString{String: *input.PhoneNumber, Valid: true}}
+			user.RegStage = int(*input.RegStage)
+
+			err = u.GetUserRepository().Update(ctx, user, u.Tx)
+			if err != nil {
+				return Invalid(err)
+			}
+
+			otp, err := s.sendOtp(ctx, user, models.SmsOtpType, u.Tx)
+			if err != nil {
+				return err
+			}
+			result.Code = otp.Code
+
+		case utils.RegStage3:
+			err = s.completeSignUp(ctx, user, input, u)
+			if err != nil {
+				return err
+			}
+
+			result.AccessToken, result.RefreshToken, err = s.issueTokens(ctx, user, u)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.User = user
+	return result, nil
+}
+
+// createUser creates the user of the first stage of input, and their
+// business for business accounts.
+func (s *AuthService) createUser(ctx context.Context, input *SignUpInput, u *uow.UnitOfWork) (*models.User, error) {
+	var business *models.Business
+	if *input.AccountType == models.BusinessAccountType {
+		business = &models.Business{
+			Name:  *input.BusinessName,
+			Email: *input.Email,
+		}
+
+		err := u.GetBusinessRepository().Create(ctx, business, u.Tx)
+		if err != nil {
+			return nil, Invalid(err)
+		}
+	}
+
+	user := &models.User{
+		Email:       *input.Email,
+		AccountType: *input.AccountType,
+		RegStage:    int(*input.RegStage),
+	}
+
+	if business != nil {
+		user.BusinessID = &business.ID
+		user.Business = business
+	}
+
+	err := u.GetUserRepository().Create(ctx, user, u.Tx)
+	if err != nil {
+		return nil, Invalid(err)
+	}
+
+	return user, nil
+}
+
+// completeSignUp sets the name and password of user, opens their wallet and
+// attaches the transactions they were invited to before they had an account.
+func (s *AuthService) completeSignUp(ctx context.Context, user *models.User, input *SignUpInput, u *uow.UnitOfWork) error {
+	user.FirstName = models.NullString{NullString: sql.NullString{String: *input.FirstName, Valid: true}}
+	user.LastName = models.NullString{NullString: sql.NullString{String: *input.LastName, Valid: true}}
+	user.RegStage = int(*input.RegStage)
+
+	err := u.GetUserRepository().Update(ctx, user, u.Tx)
+	if err != nil {
+		return Invalid(err)
+	}
+
+	hashPwd, err := utils.GeneratePasswordHash(*input.Password)
+	if err != nil {
+		return err
+	}
+
+	auth := &models.Auth{
+		UserID:   &user.ID,
+		Password: string(hashPwd),
+	}
+
+	err = u.GetAuthRepository().Create(ctx, auth, u.Tx)
+	if err != nil {
+		return Invalid(err)
+	}
+
+	identifier, accountType := WalletOwner(user)
+	wallet := &models.Wallet{Identifier: identifier, AccountType: accountType}
+	err = u.GetWalletRepository().Create(ctx, wallet, u.Tx)
+	if err != nil {
+		return err
+	}
+
+	auditLogRepo := u.GetAuditLogRepository()
+
+	entry := audit.FromContext(ctx, audit.ActionSignUp, audit.EntityUser, user.ID)
+	entry.ActorID = &user.ID
+	entry.ActorType = models.AuditActorUser
+	err = auditLogRepo.Create(ctx, audit.WithChanges(entry, nil, user), u.Tx)
+	if err != nil {
+		return err
+	}
+
+	// transactions the user was invited to before they had an account are
+	// theirs now
+	transactions, err := invites.AcceptPending(ctx, u, u.Tx, user)
+	if err != nil {
+		return err
+	}
+
+	for _, transaction := range transactions {
+		entry := audit.FromContext(ctx, audit.ActionTransactionInviteAccepted, audit.EntityTransaction, transaction.ID)
+		entry.ActorID = &user.ID
+		entry.ActorType = models.AuditActorUser
+		err = auditLogRepo.Create(ctx, entry, u.Tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// issueTokens saves and returns a new access and refresh token of user.
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User, u *uow.UnitOfWork) (string, string, error) {
+	accessTokenStr, _ := s.c.GetJWT().GenerateToken(&jwt.TokenClaims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenType: string(models.AccessToken),
+	})
+
+	refreshTokenStr, _ := s.c.GetJWT().GenerateToken(&jwt.TokenClaims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenType: string(models.RefreshToken),
+	})
+
+	tokens := []*models.Token{
+		{
+			Hash:      accessTokenStr,
+			UserID:    user.ID,
+			InUse:     true,
+			TokenType: models.AccessToken,
+		},
+		{
+			Hash:      refreshTokenStr,
+			UserID:    user.ID,
+			InUse:     true,
+			TokenType: models.RefreshToken,
+		},
+	}
+
+	for _, token := range tokens {
+		err := u.GetTokenRepository().Create(ctx, token, u.Tx)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return accessTokenStr, refreshTokenStr, nil
+}
+
+// sendOtp creates a code of otpType for user and sends it by email or sms.
+// Outside production sms codes are emailed too, since test numbers can not
+// receive them.
+func (s *AuthService) sendOtp(ctx context.Context, user *models.User, otpType string, tx pgx.Tx) (*models.Otp, error) {
+	otp := &models.Otp{
+		UserID:    user.ID,
+		Code:      utils.GenerateRandomNumber(),
+		IsUsed:    false,
+		OtpType:   otpType,
+		ExpiresIn: time.Now().Add(models.OtpExpiresIn * time.Minute),
+	}
+
+	err := s.c.GetOtpRepository().Create(ctx, otp, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	env := s.c.GetSettings().Environment
+	utils.Background(ctx, func(ctx context.Context) {
+		subject := "email"
+		if otpType == models.SmsOtpType {
+			subject = "phone number"
+
+			s.c.GetPush().SendSMS(ctx, &push.Sms{
+				Phone:   user.PhoneNumber.String,
+				Message: fmt.Sprintf("Use code %s to verify your phone number", otp.Code),
+			})
+
+			if env != "development" && env != "test" {
+				return
+			}
+		}
+
+		err := s.c.GetPush().SendEmail(ctx, &push.Email{
+			To:      []string{user.Email},
+			Subject: VerifyEmailSubject,
+			Text:    fmt.Sprintf("Use code %s to verify your %s", otp.Code, subject),
+			Html:    fmt.Sprintf("<p>Use code %s to verify your %s</p>", otp.Code, subject),
+		})
+
+		if err != nil {
+			s.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
+		}
+	})
+
+	return otp, nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+)
+
+var ErrNotDisputable = newError(KindInvalid, "only transactions pending delivery can be disputed")
+
+// OpenDisputeInput is why a party disputes a transaction.
+type OpenDisputeInput struct {
+	Reason string `json:"reason" validate:"required,min=10,max=1000"`
+}
+
+// OpenDispute lets either party of the transaction with id dispute it while
+// delivery is pending. The money stays held until an admin resolves it; this
+// is the only way into Disputed and resolving the only way out of it.
+func (s *TransactionService) OpenDispute(ctx context.Context, user *models.User, id string, input *OpenDisputeInput) (*models.Dispute, *models.Transaction, error) {
+	var dispute *models.Dispute
+	var transaction *models.Transaction
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		var err error
+		transaction, err = u.GetTransactionRepository().GetByIdForUpdate(ctx, id, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !isParty(user, transaction) {
+			return ErrNotParty
+		}
+
+		if transaction.Status != models.TransactionStatusPendingDelivery {
+			return ErrNotDisputable
+		}
+
+		before := *transaction
+		dispute = &models.Dispute{
+			TransactionID: transaction.ID,
+			RaisedBy:      user.ID,
+			Reason:        input.Reason,
+			Status:        models.DisputeStatusOpen,
+		}
+		err = u.GetDisputeRepository().Create(ctx, dispute, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		transaction.Status = models.TransactionStatusDisputed
+		err = u.GetTransactionRepository().Update(ctx, transaction, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		timeline := &models.TransactionTimeline{
+			TransactionID: transaction.ID,
+			Name:          models.TimelineDisputeOpened,
+		}
+		err = u.GetTransactionTimelineRepository().Create(ctx, timeline, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entries := []*models.AuditLog{
+			audit.WithChanges(audit.FromContext(ctx, audit.ActionDisputeOpened, audit.EntityDispute, dispute.ID), nil, dispute),
+			audit.WithChanges(audit.FromContext(ctx, audit.ActionTransactionStatusChanged, audit.EntityTransaction, transaction.ID), before, transaction),
+		}
+		for _, entry := range entries {
+			err = u.GetAuditLogRepository().Create(ctx, entry, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventDisputeOpened, dispute)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	text := fmt.Sprintf("A dispute has been opened on transaction %s", transaction.ID)
+	s.notify(ctx, "Dispute opened", text, transaction.Seller.Email, transaction.Buyer.Email)
+
+	return dispute, transaction, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+)
+
+// Kind is the class of a failure, which the http handlers turn into a status
+// code. Errors without a kind are internal.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindForbidden
+)
+
+// Error is a failure of a service the caller can act on. Err is what is
+// reported and can be matched with errors.Is.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func newError(kind Kind, format string, args ...any) *Error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// Invalid marks err as caused by the input of the caller.
+func Invalid(err error) error {
+	return &Error{Kind: KindInvalid, Err: err}
+}
+
+// NotFound marks err as caused by a missing record.
+func NotFound(err error) error {
+	return &Error{Kind: KindNotFound, Err: err}
+}
+
+// Conflict marks err as caused by the current state of a record.
+func Conflict(err error) error {
+	return &Error{Kind: KindConflict, Err: err}
+}
+
+// Forbidden marks err as caused by the caller not being allowed to act.
+func Forbidden(err error) error {
+	return &Error{Kind: KindForbidden, Err: err}
+}
+
+// KindOf returns the kind of err, KindInternal when it has none. A record
+// changed by a concurrent request is a conflict wherever it surfaces.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	if errors.Is(err, repositories.ErrConflict) {
+		return KindConflict
+	}
+
+	return KindInternal
+}
+
+var (
+	ErrNotFound          = newError(KindNotFound, "not found")
+	ErrWalletFrozen      = newError(KindForbidden, "wallet is frozen")
+	ErrInsufficientFunds = Invalid(models.ErrInsufficientFunds)
+	ErrQuoteNotFound     = newError(KindNotFound, "quote not found")
+	ErrQuoteExecuted     = newError(KindConflict, "quote has already been executed")
+)
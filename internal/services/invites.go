@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/invites"
+	"github.com/princecee/escrow-api/pkg/push"
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/rs/zerolog"
+)
+
+// findCounterparty returns the signed up user with the email or phone number
+// of the counterparty of input, or nil if they have no account yet.
+func (s *TransactionService) findCounterparty(ctx context.Context, input *CreateTransactionInput, tx pgx.Tx) (*models.User, error) {
+	userRepo := s.c.GetUserRepository()
+
+	var user *models.User
+	var err error
+	if input.Counterparty.Email != "" {
+		user, err = userRepo.GetByEmail(ctx, input.Counterparty.Email, tx)
+	}
+	if user == nil && input.Counterparty.PhoneNumber != "" && (err == nil || errors.Is(err, pgx.ErrNoRows)) {
+		user, err = userRepo.GetByPhoneNumber(ctx, input.Counterparty.PhoneNumber, tx)
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) || (user != nil && user.RegStage != int(utils.RegStage3)) {
+		return nil, nil
+	}
+
+	return user, err
+}
+
+// counterpartySeller returns the business of the counterparty of input, or a
+// placeholder business standing in for them when they have no account yet.
+func (s *TransactionService) counterpartySeller(ctx context.Context, input *CreateTransactionInput, tx pgx.Tx) (*models.Business, bool, error) {
+	user, err := s.findCounterparty(ctx, input, tx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if user != nil {
+		if user.Business == nil {
+			return nil, false, ErrCounterpartyNotBusiness
+		}
+
+		return user.Business, false, nil
+	}
+
+	id, err := invites.CreatePlaceholder(ctx, s.c, tx, models.TransactionCreatedBySeller, input.Counterparty.Name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	seller, err := s.c.GetBusinessRepository().GetById(ctx, id, tx)
+	return seller, true, err
+}
+
+// counterpartyBuyer returns the counterparty of input, or a placeholder user
+// standing in for them when they have no account yet.
+func (s *TransactionService) counterpartyBuyer(ctx context.Context, input *CreateTransactionInput, tx pgx.Tx) (*models.User, bool, error) {
+	user, err := s.findCounterparty(ctx, input, tx)
+	if err != nil || user != nil {
+		return user, false, err
+	}
+
+	id, err := invites.CreatePlaceholder(ctx, s.c, tx, models.TransactionCreatedByBuyer, input.Counterparty.Name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	buyer, err := s.c.GetUserRepository().GetById(ctx, id, tx)
+	return buyer, true, err
+}
+
+// sendInvite emails or texts the invite link of invite to the invitee.
+func (s *TransactionService) sendInvite(ctx context.Context, invite *models.Invite, inviter *models.User) {
+	link := invites.Link(s.c, invites.Sign([]byte(s.c.GetSettings().InviteKey), invite.ID, invite.ExpiresAt))
+
+	name := inviter.Email
+	if inviter.FirstName.Valid {
+		name = fmt.Sprintf("%s %s", inviter.FirstName.String, inviter.LastName.String)
+	}
+
+	if invite.Email.Valid {
+		err := s.c.GetPush().SendEmail(ctx, &push.Email{
+			To:      []string{invite.Email.String},
+			Subject: "You have been invited to a new transaction",
+			Text:    fmt.Sprintf("%s invited you to a transaction. Sign up to join it: %s", name, link),
+			Html:    fmt.Sprintf(`<p>%s invited you to a transaction. <a href="%s">Sign up</a> to join it.</p>`, name, link),
+		})
+
+		if err != nil {
+			s.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
+		}
+	}
+
+	if invite.PhoneNumber.Valid {
+		s.c.GetPush().SendSMS(ctx, &push.Sms{
+			Phone:   invite.PhoneNumber.String,
+			Message: fmt.Sprintf("%s invited you to a transaction. Sign up to join it: %s", name, link),
+		})
+	}
+}
+
+// newInvite returns the unsaved invite of the counterparty of input to
+// transaction, who is stood in for by placeholderId.
+func newInvite(input *CreateTransactionInput, transaction *models.Transaction, inviter *models.User, placeholderId string) *models.Invite {
+	role := models.TransactionCreatedBySeller
+	if input.CreatedBy == models.TransactionCreatedBySeller {
+		role = models.TransactionCreatedByBuyer
+	}
+
+	invite := &models.Invite{
+		TransactionID: transaction.ID,
+		InviterID:     inviter.ID,
+		Role:          role,
+		PlaceholderID: placeholderId,
+		Status:        models.InvitePending,
+		ExpiresAt:     time.Now().UTC().Add(models.InviteExpiresIn),
+	}
+
+	if input.Counterparty.Email != "" {
+		invite.Email = models.NullString{NullString: sql.NullString{String: input.Counterparty.Email, Valid: true}}
+	}
+	if input.Counterparty.PhoneNumber != "" {
+		invite.PhoneNumber = models.NullString{NullString: sql.NullString{String: input.Counterparty.PhoneNumber, Valid: true}}
+	}
+
+	return invite
+}
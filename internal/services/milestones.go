@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/money"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+)
+
+var (
+	ErrMilestoneNotFound       = newError(KindNotFound, "milestone not found")
+	ErrNotMilestoneSeller      = newError(KindForbidden, "only the seller can deliver a milestone")
+	ErrNotMilestoneBuyer       = newError(KindForbidden, "only the buyer can release a milestone")
+	ErrMilestoneDeliveryClosed = newError(KindInvalid, "only transactions pending delivery can have milestones delivered")
+	ErrMilestoneReleaseClosed  = newError(KindInvalid, "only transactions pending delivery can have milestones released")
+	ErrMilestoneNotFunded      = newError(KindInvalid, "only funded milestones can be delivered")
+	ErrMilestoneNotHeld        = newError(KindInvalid, "only funded milestones can be released")
+)
+
+// getMilestone loads the transaction with transactionId and its milestone with
+// milestoneId, checking that user is a party to the transaction.
+func (s *TransactionService) getMilestone(ctx context.Context, u *uow.UnitOfWork, user *models.User, transactionId, milestoneId string) (*models.Transaction, *models.Milestone, error) {
+	transaction, err := u.GetTransactionRepository().GetById(ctx, transactionId, u.Tx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !isParty(user, transaction) {
+		return nil, nil, ErrNotParty
+	}
+
+	milestone, err := u.GetMilestoneRepository().GetById(ctx, milestoneId, u.Tx)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && milestone.TransactionID != transaction.ID) {
+		return nil, nil, ErrMilestoneNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transaction, milestone, nil
+}
+
+// DeliverMilestone lets the seller mark a funded milestone as delivered so the
+// buyer can review it before releasing the funds.
+func (s *TransactionService) DeliverMilestone(ctx context.Context, user *models.User, transactionId, milestoneId string) (*models.Milestone, error) {
+	var transaction *models.Transaction
+	var milestone *models.Milestone
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		var err error
+		transaction, milestone, err = s.getMilestone(ctx, u, user, transactionId, milestoneId)
+		if err != nil {
+			return err
+		}
+
+		if user.BusinessID == nil || transaction.SellerID != *user.BusinessID {
+			return ErrNotMilestoneSeller
+		}
+
+		if transaction.Status != models.TransactionStatusPendingDelivery {
+			return ErrMilestoneDeliveryClosed
+		}
+
+		if milestone.Status != models.MilestoneStatusFunded {
+			return ErrMilestoneNotFunded
+		}
+
+		before := *milestone
+		milestone.Status = models.MilestoneStatusDelivered
+		milestone.DeliveredAt = models.NullTime{NullTime: sql.NullTime{Time: time.Now().UTC(), Valid: true}}
+		err = u.GetMilestoneRepository().Update(ctx, milestone, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		timeline := &models.TransactionTimeline{
+			TransactionID: transaction.ID,
+			MilestoneID:   &milestone.ID,
+			Name:          models.TimelineMilestoneDelivered,
+		}
+		err = u.GetTransactionTimelineRepository().Create(ctx, timeline, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entry := audit.FromContext(ctx, audit.ActionMilestoneDelivered, audit.EntityMilestone, milestone.ID)
+		err = u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, before, milestone), u.Tx)
+		if err != nil {
+			return err
+		}
+
+		return webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventMilestoneDelivered, milestone)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("Milestone %q of transaction %s has been delivered", milestone.Title, transaction.ID)
+	s.notify(ctx, "Milestone delivered", text, transaction.Buyer.Email)
+
+	return milestone, nil
+}
+
+// ReleaseMilestone lets the buyer pay a funded milestone out to the seller.
+// The transaction is completed with its last milestone.
+func (s *TransactionService) ReleaseMilestone(ctx context.Context, user *models.User, transactionId, milestoneId string) (*models.Milestone, *models.Transaction, error) {
+	var transaction *models.Transaction
+	var milestone *models.Milestone
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		walletRepo := u.GetWalletRepository()
+		milestoneRepo := u.GetMilestoneRepository()
+
+		var err error
+		transaction, milestone, err = s.getMilestone(ctx, u, user, transactionId, milestoneId)
+		if err != nil {
+			return err
+		}
+
+		if transaction.BuyerID != user.ID {
+			return ErrNotMilestoneBuyer
+		}
+
+		if transaction.Status != models.TransactionStatusPendingDelivery {
+			return ErrMilestoneReleaseClosed
+		}
+
+		if milestone.Status != models.MilestoneStatusFunded && milestone.Status != models.MilestoneStatusDelivered {
+			return ErrMilestoneNotHeld
+		}
+
+		wallet, err := walletRepo.GetOrCreate(ctx, transaction.SellerID, models.BusinessAccountType, transaction.Currency, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		walletBefore := *wallet
+		wallet, err = walletRepo.Credit(ctx, wallet.ID, money.New(int64(milestone.ReceivableAmount), transaction.Currency), u.Tx)
+		if err != nil {
+			return err
+		}
+
+		walletHistory := &models.WalletHistory{
+			WalletID: wallet.ID,
+			Type:     models.WalletHistoryDepositType,
+			Amount:   milestone.ReceivableAmount,
+			Status:   models.WalletHistorySuccessful,
+			Note:     fmt.Sprintf("milestone %d of transaction %s released", milestone.Position, transaction.ID),
+			Wallet:   *wallet,
+		}
+		err = u.GetWalletHistoryRepository().Create(ctx, walletHistory, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		milestoneBefore := *milestone
+		milestone.Status = models.MilestoneStatusReleased
+		milestone.ReleasedAt = models.NullTime{NullTime: sql.NullTime{Time: time.Now().UTC(), Valid: true}}
+		err = milestoneRepo.Update(ctx, milestone, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		timelines := []*models.TransactionTimeline{{
+			TransactionID: transaction.ID,
+			MilestoneID:   &milestone.ID,
+			Name:          models.TimelineMilestoneReleased,
+		}}
+
+		entries := []*models.AuditLog{
+			audit.WithChanges(audit.FromContext(ctx, audit.ActionMilestoneReleased, audit.EntityMilestone, milestone.ID), milestoneBefore, milestone),
+			audit.WithChanges(audit.FromContext(ctx, audit.ActionWalletCredited, audit.EntityWallet, wallet.ID), walletBefore, wallet),
+		}
+
+		milestones, err := milestoneRepo.GetByTransactionId(ctx, transaction.ID, u.Tx)
+		if err != nil {
+			return err
+		}
+		transaction.Milestones = milestones
+
+		isCompleted := true
+		for _, m := range milestones {
+			if m.Status != models.MilestoneStatusReleased {
+				isCompleted = false
+			}
+		}
+
+		if isCompleted {
+			transactionBefore := *transaction
+			transaction.Status = models.TransactionStatusCompleted
+			err = u.GetTransactionRepository().Update(ctx, transaction, u.Tx)
+			if err != nil {
+				return err
+			}
+
+			timelines = append(timelines, &models.TransactionTimeline{
+				TransactionID: transaction.ID,
+				Name:          models.TimelineCompleted,
+			})
+			entries = append(entries, audit.WithChanges(audit.FromContext(ctx, audit.ActionTransactionStatusChanged, audit.EntityTransaction, transaction.ID), transactionBefore, transaction))
+		}
+
+		for _, timeline := range timelines {
+			err = u.GetTransactionTimelineRepository().Create(ctx, timeline, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, entry := range entries {
+			err = u.GetAuditLogRepository().Create(ctx, entry, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventMilestoneReleased, milestone)
+		if err != nil {
+			return err
+		}
+
+		if isCompleted {
+			return webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventTransactionCompleted, transaction)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	text := fmt.Sprintf("Milestone %q of transaction %s has been released", milestone.Title, transaction.ID)
+	s.notify(ctx, "Milestone released", text, transaction.Seller.Email)
+
+	return milestone, transaction, nil
+}
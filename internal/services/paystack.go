@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/metrics"
+	"github.com/princecee/escrow-api/pkg/money"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+)
+
+var ErrUnknownReference = newError(KindInvalid, "no payment or withdrawal has this reference")
+
+// Charge is a card payment paystack confirmed. Its reference is the wallet
+// history it funds, or the transaction or milestone it pays for when its
+// metadata says so.
+type Charge struct {
+	Reference string
+	Amount    int64
+	Currency  string
+	Metadata  map[string]any
+}
+
+// ConfirmCharge credits the wallet a charge funds or marks the transaction and
+// milestones it pays for as paid. Paystack retries its webhooks, so a funding
+// already credited and a transaction or milestones already paid for are left
+// alone.
+func (s *WalletService) ConfirmCharge(ctx context.Context, charge *Charge) error {
+	isForTransaction, _ := charge.Metadata["is_for_transaction"].(bool)
+	var escrowed bool
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		if isForTransaction {
+			var err error
+			escrowed, err = s.payTransaction(ctx, u, charge)
+			return err
+		}
+
+		walletHistoryRepo := u.GetWalletHistoryRepository()
+		walletRepo := u.GetWalletRepository()
+
+		walletHistory, err := walletHistoryRepo.GetById(ctx, charge.Reference, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUnknownReference
+		}
+		if err != nil {
+			return err
+		}
+
+		if walletHistory.Status != models.WalletHistoryPending {
+			return nil
+		}
+
+		wallet, err := walletRepo.GetById(ctx, walletHistory.WalletID, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		// the charge must be in the currency of the wallet it funds
+		before := *wallet
+		walletHistory.Status = models.WalletHistorySuccessful
+		wallet, err = walletRepo.Credit(ctx, wallet.ID, money.New(int64(walletHistory.Amount), charge.Currency), u.Tx)
+		if errors.Is(err, money.ErrCurrencyMismatch) {
+			return Invalid(err)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = walletHistoryRepo.Update(ctx, walletHistory, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entry := audit.FromContext(ctx, audit.ActionWalletCredited, audit.EntityWallet, wallet.ID)
+		err = u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, before, wallet), u.Tx)
+		if err != nil {
+			return err
+		}
+
+		return s.EnqueueEvent(ctx, u.Tx, wallet, webhooks.EventWalletFunded, walletHistory)
+	})
+	if err != nil {
+		return err
+	}
+
+	if escrowed {
+		metrics.Escrowed(charge.Currency, charge.Amount)
+	}
+
+	return nil
+}
+
+// payTransaction marks the transaction of charge as paid, along with the
+// milestones it funds, and reports whether it did. Milestone payments carry
+// the transaction and the milestones they fund since the reference may be a
+// milestone id. A charge is only taken for a transaction awaiting payment or
+// for milestones not funded yet, so a retry finds nothing left to pay and is
+// ignored, and it must be for exactly what is owed.
+func (s *WalletService) payTransaction(ctx context.Context, u *uow.UnitOfWork, charge *Charge) (bool, error) {
+	transactionRepo := u.GetTransactionRepository()
+	transactionTimelineRepo := u.GetTransactionTimelineRepository()
+	milestoneRepo := u.GetMilestoneRepository()
+
+	transactionId := charge.Reference
+	if id, ok := charge.Metadata["transaction_id"].(string); ok {
+		transactionId = id
+	}
+	milestoneIds, isForMilestones := charge.Metadata["milestone_ids"].([]any)
+
+	// the row stays locked until the payment is recorded, so a retry delivered
+	// at the same time waits and then sees it paid
+	transaction, err := transactionRepo.GetByIdForUpdate(ctx, transactionId, u.Tx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrUnknownReference
+	}
+	if err != nil {
+		return false, err
+	}
+
+	funding := []*models.Milestone{}
+	owed := transaction.BuyerPayable()
+	if isForMilestones {
+		payable := transaction.Status == models.TransactionStatusPendingPayment || transaction.Status == models.TransactionStatusPendingDelivery
+		if !payable {
+			return false, nil
+		}
+
+		total := money.New(0, transaction.Currency)
+		for _, v := range milestoneIds {
+			id, _ := v.(string)
+			milestone, err := milestoneRepo.GetById(ctx, id, u.Tx)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return false, ErrUnknownReference
+			}
+			if err != nil {
+				return false, err
+			}
+
+			if milestone.TransactionID != transaction.ID {
+				return false, ErrUnknownReference
+			}
+			if milestone.IsFunded() {
+				continue
+			}
+
+			total, err = total.Add(money.New(int64(milestone.BuyerPayable(transaction.ChargeConfiguration)), transaction.Currency))
+			if err != nil {
+				return false, err
+			}
+			funding = append(funding, milestone)
+		}
+
+		if len(funding) == 0 {
+			return false, nil
+		}
+		owed = total.Int()
+	} else if transaction.Status != models.TransactionStatusPendingPayment {
+		return false, nil
+	}
+
+	if charge.Currency != transaction.Currency || charge.Amount != int64(owed) {
+		return false, newError(KindInvalid, "charge of %d %s does not pay the %d %s owed", charge.Amount, charge.Currency, owed, transaction.Currency)
+	}
+
+	before := *transaction
+	transaction.Status = models.TransactionStatusPendingDelivery
+	if !isForMilestones {
+		transaction.PaidAmount = owed
+	}
+	err = transactionRepo.Update(ctx, transaction, u.Tx)
+	if err != nil {
+		return false, err
+	}
+
+	entry := audit.FromContext(ctx, audit.ActionTransactionStatusChanged, audit.EntityTransaction, transaction.ID)
+	err = u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, before, transaction), u.Tx)
+	if err != nil {
+		return false, err
+	}
+
+	if before.Status != transaction.Status {
+		err = webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventTransactionPaid, transaction)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if !isForMilestones {
+		timeline := &models.TransactionTimeline{
+			TransactionID: transaction.ID,
+			Name:          models.TimelinePaymentSubmitted,
+		}
+		return true, transactionTimelineRepo.Create(ctx, timeline, u.Tx)
+	}
+
+	for _, milestone := range funding {
+		milestoneBefore := *milestone
+		milestone.Status = models.MilestoneStatusFunded
+		milestone.FundedAt = models.NullTime{NullTime: sql.NullTime{Time: time.Now().UTC(), Valid: true}}
+		err = milestoneRepo.Update(ctx, milestone, u.Tx)
+		if err != nil {
+			return false, err
+		}
+
+		timeline := &models.TransactionTimeline{
+			TransactionID: transaction.ID,
+			MilestoneID:   &milestone.ID,
+			Name:          models.TimelineMilestoneFunded,
+		}
+		err = transactionTimelineRepo.Create(ctx, timeline, u.Tx)
+		if err != nil {
+			return false, err
+		}
+
+		entry := audit.FromContext(ctx, audit.ActionMilestoneFunded, audit.EntityMilestone, milestone.ID)
+		err = u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, milestoneBefore, milestone), u.Tx)
+		if err != nil {
+			return false, err
+		}
+
+		err = webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventMilestoneFunded, milestone)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// SettleWithdrawal records the outcome of the transfer paying out the
+// withdrawal with reference. A failed transfer credits the debit back so the
+// funds can be withdrawn again. Paystack retries its webhooks, so a settled
+// withdrawal is left alone.
+func (s *WalletService) SettleWithdrawal(ctx context.Context, reference string, succeeded bool) error {
+	var wallet *models.Wallet
+	var walletHistory *models.WalletHistory
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		walletHistoryRepo := u.GetWalletHistoryRepository()
+		walletRepo := u.GetWalletRepository()
+
+		var err error
+		walletHistory, err = walletHistoryRepo.GetById(ctx, reference, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUnknownReference
+		}
+		if err != nil {
+			return err
+		}
+
+		if walletHistory.Type != models.WalletHistoryWithdrawalType || walletHistory.Status != models.WalletHistoryPending {
+			walletHistory = nil
+			return nil
+		}
+
+		wallet, err = walletRepo.GetById(ctx, walletHistory.WalletID, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		event := webhooks.EventWithdrawalSettled
+		if succeeded {
+			walletHistory.Status = models.WalletHistorySuccessful
+		} else {
+			event = webhooks.EventWithdrawalFailed
+			walletHistory.Status = models.WalletHistoryCanceled
+
+			before := *wallet
+			wallet, err = walletRepo.Credit(ctx, wallet.ID, money.New(int64(walletHistory.Amount), wallet.Currency), u.Tx)
+			if err != nil {
+				return err
+			}
+
+			entry := audit.FromContext(ctx, audit.ActionWalletCredited, audit.EntityWallet, wallet.ID)
+			err = u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, before, wallet), u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = walletHistoryRepo.Update(ctx, walletHistory, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		return s.EnqueueEvent(ctx, u.Tx, wallet, event, walletHistory)
+	})
+	if err != nil {
+		return err
+	}
+
+	if walletHistory != nil {
+		metrics.Withdrawal(wallet.Currency, walletHistory.Status)
+	}
+
+	return nil
+}
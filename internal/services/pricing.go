@@ -0,0 +1,252 @@
+package services
+
+import (
+	"time"
+
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/apis/paystack"
+	"github.com/princecee/escrow-api/pkg/fees"
+	"github.com/princecee/escrow-api/pkg/money"
+)
+
+var (
+	ErrMilestonesNotService = newError(KindInvalid, "only service transactions can have milestones")
+	ErrMilestoneDueDatePast = newError(KindInvalid, "milestone due dates must be in the future")
+	ErrMilestonesOutOfOrder = newError(KindInvalid, "milestones must be ordered by due date")
+)
+
+// CreateTransactionInput is what a transaction is created or quoted from.
+type CreateTransactionInput struct {
+	Type      string `json:"type" validate:"required,alpha,oneof=Product Service Crypto"`
+	CreatedBy string `json:"created_by" validate:"required,alpha,oneof=Seller Buyer"`
+	BuyerID   string `json:"buyer_id,omitempty" validate:"omitempty,uuid"`
+	SellerID  string `json:"seller_id,omitempty" validate:"omitempty,uuid"`
+	// Counterparty invites a buyer or seller without an account in place of
+	// buyer_id or seller_id
+	Counterparty *struct {
+		Email       string `json:"email" validate:"required_without=PhoneNumber,omitempty,email"`
+		PhoneNumber string `json:"phone_number" validate:"required_without=Email,omitempty,min=8,max=20"`
+		Name        string `json:"name" validate:"omitempty,max=255"`
+	} `json:"counterparty,omitempty" validate:"omitempty"`
+	DeliveryDuration    int    `json:"delivery_duration" validate:"required,min=1"`
+	Currency            string `json:"currency" validate:"required,currency"`
+	ChargeConfiguration struct {
+		BuyerCharges  int `json:"buyer_charges" validate:"min=0,max=100"`
+		SellerCharges int `json:"seller_charges" validate:"min=0,max=100"`
+	} `json:"charge_configuration" validate:"required"`
+	ProductDetails []struct {
+		Name        string `json:"name" validate:"required,alphanum"`
+		Quantity    int    `json:"quantity" validate:"omitempty,min=0"`
+		Description string `json:"description" validate:"required,alphanum"`
+		Price       int    `json:"price" validate:"omitempty,min=0"`
+	} `json:"product_details" validate:"dive"`
+	Milestones []struct {
+		Title       string    `json:"title" validate:"required,min=3,max=255"`
+		Description string    `json:"description" validate:"omitempty,max=1000"`
+		Amount      int       `json:"amount" validate:"required,min=1"`
+		DueDate     time.Time `json:"due_date" validate:"required"`
+	} `json:"milestones" validate:"omitempty,max=20,dive"`
+}
+
+// TransactionQuote is the itemized price of a transaction. Payable is what
+// the buyer pays into escrow and Receivable what the seller gets on release.
+// GatewayFee is what paystack keeps when the payable is paid by card, one
+// payment per milestone; it comes out of the charges.
+type TransactionQuote struct {
+	Subtotal   int                 `json:"subtotal"`
+	Charges    int                 `json:"charges"`
+	BuyerFee   int                 `json:"buyer_fee"`
+	SellerFee  int                 `json:"seller_fee"`
+	GatewayFee int                 `json:"gateway_fee"`
+	Payable    int                 `json:"payable"`
+	Receivable int                 `json:"receivable"`
+	Currency   string              `json:"currency"`
+	Milestones []*models.Milestone `json:"milestones,omitempty"`
+}
+
+// BuildTransaction turns a create payload into an unsaved, priced transaction
+// and its milestones. Creating a transaction and quoting one both go through
+// it so the two never disagree.
+func BuildTransaction(engine *fees.Engine, body *CreateTransactionInput, tier string) (*models.Transaction, []*models.Milestone, *TransactionQuote, error) {
+	productDetails := []models.ProductDetail{}
+	for _, v := range body.ProductDetails {
+		detail := v
+
+		product := models.ProductDetail{
+			Name:        detail.Name,
+			Quantity:    detail.Quantity,
+			Description: detail.Description,
+			Price:       detail.Price,
+		}
+
+		productDetails = append(productDetails, product)
+	}
+
+	// a transaction with milestones costs the sum of its milestones, each
+	// charged separately so they can be paid and released on their own
+	milestones := []*models.Milestone{}
+	if len(body.Milestones) > 0 {
+		if body.Type != models.TransactionTypeService {
+			return nil, nil, nil, ErrMilestonesNotService
+		}
+
+		for i, v := range body.Milestones {
+			if !v.DueDate.After(time.Now()) {
+				return nil, nil, nil, ErrMilestoneDueDatePast
+			}
+
+			if i > 0 && v.DueDate.Before(body.Milestones[i-1].DueDate) {
+				return nil, nil, nil, ErrMilestonesOutOfOrder
+			}
+
+			milestones = append(milestones, &models.Milestone{
+				Position:    i + 1,
+				Title:       v.Title,
+				Description: v.Description,
+				Amount:      v.Amount,
+				DueDate:     v.DueDate.UTC(),
+				Status:      models.MilestoneStatusPendingPayment,
+			})
+		}
+	}
+
+	transaction := &models.Transaction{
+		Status:              models.TransactionStatusAwaiting,
+		Type:                body.Type,
+		CreatedBy:           body.CreatedBy,
+		DeliveryDuration:    body.DeliveryDuration,
+		Currency:            body.Currency,
+		ChargeConfiguration: models.ChargeConfiguration(body.ChargeConfiguration),
+		ProductDetails:      productDetails,
+	}
+
+	total, err := PriceTransaction(engine, transaction, milestones, tier)
+	if err != nil {
+		return nil, nil, nil, Invalid(err)
+	}
+
+	quote := &TransactionQuote{
+		Subtotal:   total.Amount,
+		Charges:    total.Charges,
+		BuyerFee:   total.BuyerFee,
+		SellerFee:  total.SellerFee,
+		Payable:    total.BuyerPayable,
+		Receivable: total.SellerReceivable,
+		Currency:   transaction.Currency,
+	}
+
+	// every milestone is paid, and charged for by paystack, on its own
+	payments := []int{total.BuyerPayable}
+	if len(milestones) > 0 {
+		payments = payments[:0]
+		for _, milestone := range milestones {
+			payments = append(payments, milestone.BuyerPayable(transaction.ChargeConfiguration))
+		}
+		quote.Milestones = milestones
+	}
+
+	for _, payment := range payments {
+		fee, err := paystack.Fee(payment, transaction.Currency)
+		if err != nil {
+			return nil, nil, nil, Invalid(err)
+		}
+
+		quote.GatewayFee += fee
+	}
+
+	return transaction, milestones, quote, nil
+}
+
+// PriceTransaction sets the cost and charges of a transaction from its
+// product details, or from its milestones when it has some. Milestones are
+// priced one by one since each is paid and released on its own. The returned
+// quote holds the totals.
+func PriceTransaction(engine *fees.Engine, transaction *models.Transaction, milestones []*models.Milestone, tier string) (*fees.Quote, error) {
+	if tier == "" {
+		tier = models.BusinessTierStandard
+	}
+
+	params := fees.Params{
+		TransactionType:     transaction.Type,
+		Currency:            transaction.Currency,
+		Tier:                tier,
+		ChargeConfiguration: transaction.ChargeConfiguration,
+	}
+
+	total := new(fees.Quote)
+	if len(milestones) == 0 {
+		subtotal := money.New(0, transaction.Currency)
+		for _, d := range transaction.ProductDetails {
+			cost, err := transaction.ItemCost(d)
+			if err != nil {
+				return nil, err
+			}
+
+			if subtotal, err = subtotal.Add(cost); err != nil {
+				return nil, err
+			}
+		}
+		params.Amount = subtotal.Int()
+
+		quote, err := engine.Quote(params)
+		if err != nil {
+			return nil, err
+		}
+
+		total = quote
+	}
+
+	for _, milestone := range milestones {
+		params.Amount = milestone.Amount
+
+		quote, err := engine.Quote(params)
+		if err != nil {
+			return nil, err
+		}
+
+		milestone.Charges = quote.Charges
+		milestone.ReceivableAmount = quote.SellerReceivable
+
+		if err := addQuote(total, quote); err != nil {
+			return nil, err
+		}
+	}
+
+	totalAmount, err := money.Add(int64(total.Amount), int64(total.Charges))
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.TotalCost = total.Amount
+	transaction.Charges = total.Charges
+	transaction.TotalAmount = int(totalAmount)
+	transaction.ReceivableAmount = total.SellerReceivable
+
+	return total, nil
+}
+
+// addQuote adds every amount of q to total.
+func addQuote(total, q *fees.Quote) error {
+	sums := []struct {
+		total *int
+		v     int
+	}{
+		{&total.Amount, q.Amount},
+		{&total.Charges, q.Charges},
+		{&total.BuyerFee, q.BuyerFee},
+		{&total.SellerFee, q.SellerFee},
+		{&total.BuyerPayable, q.BuyerPayable},
+		{&total.SellerReceivable, q.SellerReceivable},
+	}
+
+	for _, sum := range sums {
+		v, err := money.Add(int64(*sum.total), int64(sum.v))
+		if err != nil {
+			return err
+		}
+
+		*sum.total = int(v)
+	}
+
+	return nil
+}
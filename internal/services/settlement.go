@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/money"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+)
+
+var (
+	ErrNotSettlingBuyer = newError(KindForbidden, "only the buyer can accept or reject items")
+	ErrNotProduct       = newError(KindInvalid, "only product transactions can be settled by item")
+	ErrNotSettleable    = newError(KindInvalid, "only transactions pending delivery can be settled")
+	ErrUndecidedItems   = newError(KindInvalid, "every item must be accepted or rejected")
+)
+
+// SettleInput is the product lines, by index, the buyer accepts and rejects.
+type SettleInput struct {
+	AcceptedItems []int `json:"accepted_items" validate:"unique,dive,min=0"`
+	RejectedItems []int `json:"rejected_items" validate:"unique,dive,min=0"`
+}
+
+// Settle lets the buyer accept some product lines and reject the others.
+// Charges are split in proportion to the accepted cost: the seller is paid for
+// the accepted lines and the buyer refunded the rest of what they paid. Every
+// line has to be decided, and the transaction is completed, or canceled when
+// nothing was accepted. Refunds come out of what the buyer was recorded paying. The refund is returned with the transaction.
+func (s *TransactionService) Settle(ctx context.Context, user *models.User, id string, input *SettleInput) (*models.Transaction, int, error) {
+	var transaction *models.Transaction
+	var refund int
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		walletRepo := u.GetWalletRepository()
+
+		var err error
+		transaction, err = u.GetTransactionRepository().GetById(ctx, id, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if transaction.BuyerID != user.ID {
+			return ErrNotSettlingBuyer
+		}
+
+		if transaction.Type != models.TransactionTypeProduct {
+			return ErrNotProduct
+		}
+
+		if transaction.Status != models.TransactionStatusPendingDelivery {
+			return ErrNotSettleable
+		}
+
+		decisions := map[int]string{}
+		for _, v := range input.AcceptedItems {
+			decisions[v] = models.ProductDetailAccepted
+		}
+		for _, v := range input.RejectedItems {
+			if _, ok := decisions[v]; ok {
+				return newError(KindInvalid, "item %d cannot be both accepted and rejected", v)
+			}
+			decisions[v] = models.ProductDetailRejected
+		}
+
+		for i := range decisions {
+			if i >= len(transaction.ProductDetails) {
+				return newError(KindInvalid, "item %d does not exist", i)
+			}
+		}
+
+		if len(decisions) != len(transaction.ProductDetails) {
+			return ErrUndecidedItems
+		}
+
+		before := *transaction
+		paid := transaction.PaidAmount
+
+		acceptedCost := money.New(0, transaction.Currency)
+		productDetails := []models.ProductDetail{}
+		for i, v := range transaction.ProductDetails {
+			detail := v
+			detail.Status = decisions[i]
+			if detail.Status == models.ProductDetailAccepted {
+				cost, err := transaction.ItemCost(detail)
+				if err == nil {
+					acceptedCost, err = acceptedCost.Add(cost)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			productDetails = append(productDetails, detail)
+		}
+
+		// the charges shrink with the cost of the accepted lines, rounded down
+		// in the buyer's favour
+		acceptedCharges := money.New(0, transaction.Currency)
+		if transaction.TotalCost > 0 {
+			acceptedCharges, err = money.New(int64(transaction.Charges), transaction.Currency).
+				MulDiv(acceptedCost.Amount, int64(transaction.TotalCost), money.RoundDown)
+			if err != nil {
+				return err
+			}
+		}
+
+		totalAmount, err := acceptedCost.Add(acceptedCharges)
+		if err != nil {
+			return err
+		}
+
+		// the buyer gets back whatever they paid beyond the accepted lines and
+		// their share of the charges on them
+		transaction.ProductDetails = productDetails
+		transaction.TotalCost = acceptedCost.Int()
+		transaction.Charges = acceptedCharges.Int()
+		transaction.TotalAmount = totalAmount.Int()
+		_, sellerFee := transaction.ChargeConfiguration.Split(acceptedCharges.Int())
+		transaction.ReceivableAmount = acceptedCost.Int() - sellerFee
+		refund = paid - transaction.BuyerPayable()
+		if refund < 0 {
+			return fmt.Errorf("transaction %s was paid %d, less than the %d accepted", transaction.ID, paid, transaction.BuyerPayable())
+		}
+
+		if acceptedCost.Amount > 0 {
+			transaction.Status = models.TransactionStatusCompleted
+		} else {
+			transaction.Status = models.TransactionStatusCanceled
+		}
+
+		err = u.GetTransactionRepository().Update(ctx, transaction, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entries := []*models.AuditLog{
+			audit.WithChanges(audit.FromContext(ctx, audit.ActionTransactionStatusChanged, audit.EntityTransaction, transaction.ID), before, transaction),
+		}
+
+		payouts := []struct {
+			identifier  string
+			accountType string
+			amount      int
+			note        string
+		}{
+			{transaction.SellerID, models.BusinessAccountType, transaction.ReceivableAmount, fmt.Sprintf("accepted items of transaction %s", transaction.ID)},
+			{transaction.BuyerID, models.PersonalAccountType, refund, fmt.Sprintf("refund for rejected items of transaction %s", transaction.ID)},
+		}
+		for _, payout := range payouts {
+			if payout.amount <= 0 {
+				continue
+			}
+
+			wallet, err := walletRepo.GetOrCreate(ctx, payout.identifier, payout.accountType, transaction.Currency, u.Tx)
+			if err != nil {
+				return err
+			}
+
+			walletBefore := *wallet
+			wallet, err = walletRepo.Credit(ctx, wallet.ID, money.New(int64(payout.amount), transaction.Currency), u.Tx)
+			if err != nil {
+				return err
+			}
+
+			walletHistory := &models.WalletHistory{
+				WalletID: wallet.ID,
+				Type:     models.WalletHistoryDepositType,
+				Amount:   payout.amount,
+				Status:   models.WalletHistorySuccessful,
+				Note:     payout.note,
+				Wallet:   *wallet,
+			}
+			err = u.GetWalletHistoryRepository().Create(ctx, walletHistory, u.Tx)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, audit.WithChanges(audit.FromContext(ctx, audit.ActionWalletCredited, audit.EntityWallet, wallet.ID), walletBefore, wallet))
+		}
+
+		timelineNames := []string{}
+		if len(input.AcceptedItems) > 0 {
+			timelineNames = append(timelineNames, models.TimelineItemsAccepted)
+		}
+		if len(input.RejectedItems) > 0 {
+			timelineNames = append(timelineNames, models.TimelineItemsRejected)
+		}
+		if transaction.Status == models.TransactionStatusCompleted {
+			timelineNames = append(timelineNames, models.TimelineCompleted)
+		} else {
+			timelineNames = append(timelineNames, models.TImelineCanceled)
+		}
+
+		for _, name := range timelineNames {
+			timeline := &models.TransactionTimeline{
+				TransactionID: transaction.ID,
+				Name:          name,
+			}
+			err = u.GetTransactionTimelineRepository().Create(ctx, timeline, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, entry := range entries {
+			err = u.GetAuditLogRepository().Create(ctx, entry, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		event := webhooks.EventTransactionCompleted
+		if transaction.Status == models.TransactionStatusCanceled {
+			event = webhooks.EventTransactionCanceled
+		}
+
+		return webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, event, transaction)
+	}, uow.Serializable)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	text := fmt.Sprintf(
+		"Transaction %s has been settled: %d item(s) accepted and %d item(s) rejected",
+		transaction.ID,
+		len(input.AcceptedItems),
+		len(input.RejectedItems),
+	)
+	s.notify(ctx, "Transaction settled", text, transaction.Seller.Email, transaction.Buyer.Email)
+
+	return transaction, refund, nil
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/push"
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const NewSignInSubject = "New sign in to your account"
+
+var (
+	ErrAccountNotFound    = newError(KindInvalid, "account doesn't exist")
+	ErrInvalidCredentials = newError(KindInvalid, "invalid sign in credentials")
+	ErrEmailNotVerified   = newError(KindForbidden, "email not verified")
+	ErrPhoneNotVerified   = newError(KindForbidden, "phone number not verified")
+)
+
+// SignInInput is the credentials of a sign in and the client it comes from.
+type SignInInput struct {
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,min=8"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// SignInResult is the user signed in and the tokens issued to them.
+type SignInResult struct {
+	User         *models.User
+	AccessToken  string
+	RefreshToken string
+}
+
+// LockedOutError is returned for sign ins from an account or ip address
+// locked out after repeated failures.
+type LockedOutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedOutError) Error() string {
+	return fmt.Sprintf("too many failed sign in attempts, try again in %d seconds", e.Seconds())
+}
+
+// Seconds is RetryAfter rounded up to the second, as the Retry-After header
+// wants it.
+func (e *LockedOutError) Seconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// SignIn checks the credentials of input and issues a new access and refresh
+// token. Every attempt is recorded and audited; failures count towards a
+// lockout of the account and the ip address, and a success from a device or
+// location not seen before is emailed to the user.
+func (s *AuthService) SignIn(ctx context.Context, input *SignInInput) (*SignInResult, error) {
+	attempt := &models.SignInAttempt{
+		Email:     input.Email,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	}
+
+	lockout, err := s.throttle.Check(ctx, input.Email, input.IPAddress)
+	if err != nil {
+		// a redis outage should not lock every user out of their account
+		s.c.GetLogger().Log(zerolog.ErrorLevel, "error checking sign in throttle", nil, err)
+	}
+
+	if lockout > 0 {
+		attempt.Outcome = models.SignInOutcomeLocked
+		attempt.Reason = models.SignInReasonTooManyFailedLogins
+		s.recordSignInAttempt(ctx, attempt)
+
+		return nil, &LockedOutError{RetryAfter: lockout}
+	}
+
+	user, err := s.c.GetUserRepository().GetByEmail(ctx, input.Email, nil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		attempt.Outcome = models.SignInOutcomeFailed
+		attempt.Reason = models.SignInReasonAccountNotFound
+		s.registerSignInFailure(ctx, attempt)
+
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attempt.UserID = &user.ID
+
+	if !user.IsEmailVerified || !user.IsPhoneNumberVerified {
+		err = ErrEmailNotVerified
+		attempt.Reason = models.SignInReasonEmailNotVerified
+		if user.IsEmailVerified {
+			err = ErrPhoneNotVerified
+			attempt.Reason = models.SignInReasonPhoneNotVerified
+		}
+
+		attempt.Outcome = models.SignInOutcomeFailed
+		s.recordSignInAttempt(ctx, attempt)
+
+		return nil, err
+	}
+
+	auth, err := s.c.GetAuthRepository().GetByUserId(ctx, user.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = utils.ComparePassword(input.Password, []byte(auth.Password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		attempt.Outcome = models.SignInOutcomeFailed
+		attempt.Reason = models.SignInReasonInvalidCredentials
+		s.registerSignInFailure(ctx, attempt)
+
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SignInResult{User: user}
+	err = uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		var err error
+		result.AccessToken, result.RefreshToken, err = s.issueTokens(ctx, user, u)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.throttle.Reset(ctx, input.Email)
+	if err != nil {
+		s.c.GetLogger().Log(zerolog.ErrorLevel, "error resetting sign in throttle", nil, err)
+	}
+
+	s.alertOnUnrecognizedSignIn(ctx, user, attempt)
+
+	attempt.Outcome = models.SignInOutcomeSuccess
+	s.recordSignInAttempt(ctx, attempt)
+
+	return result, nil
+}
+
+// recordSignInAttempt saves and audits attempt. Failing to is logged rather
+// than failing the sign in.
+func (s *AuthService) recordSignInAttempt(ctx context.Context, attempt *models.SignInAttempt) {
+	err := s.c.GetSignInAttemptRepository().Create(ctx, attempt, nil)
+	if err != nil {
+		s.c.GetLogger().Log(zerolog.ErrorLevel, "error recording sign in attempt", nil, err)
+	}
+
+	var action string
+	switch attempt.Outcome {
+	case models.SignInOutcomeSuccess:
+		action = audit.ActionSignIn
+	case models.SignInOutcomeLocked:
+		action = audit.ActionSignInLocked
+	default:
+		action = audit.ActionSignInFailed
+	}
+
+	entry := audit.FromContext(ctx, action, audit.EntityUser, "")
+	entry.ActorType = models.AuditActorAnonymous
+	entry.IPAddress = attempt.IPAddress
+	if attempt.UserID != nil {
+		entry.ActorID = attempt.UserID
+		entry.ActorType = models.AuditActorUser
+		entry.EntityID = *attempt.UserID
+	}
+
+	audit.WithChanges(entry, nil, map[string]any{
+		"email":      attempt.Email,
+		"user_agent": attempt.UserAgent,
+		"outcome":    attempt.Outcome,
+		"reason":     attempt.Reason,
+	})
+
+	err = s.c.GetAuditLogRepository().Create(ctx, entry, nil)
+	if err != nil {
+		s.c.GetLogger().Log(zerolog.ErrorLevel, audit.ErrRecordingMsg, nil, err)
+	}
+}
+
+// registerSignInFailure records attempt and counts it towards a lockout.
+func (s *AuthService) registerSignInFailure(ctx context.Context, attempt *models.SignInAttempt) {
+	s.recordSignInAttempt(ctx, attempt)
+
+	lockout, err := s.throttle.RegisterFailure(ctx, attempt.Email, attempt.IPAddress)
+	if err != nil {
+		s.c.GetLogger().Log(zerolog.ErrorLevel, "error registering failed sign in", nil, err)
+		return
+	}
+
+	if lockout > 0 {
+		s.c.GetLogger().Log(zerolog.WarnLevel, "sign in locked after repeated failures", map[string]any{
+			"email":      attempt.Email,
+			"ip_address": attempt.IPAddress,
+			"lockout":    lockout.String(),
+		}, nil)
+	}
+}
+
+// alertOnUnrecognizedSignIn emails the user when a successful sign in comes
+// from a user agent or ip address that has not signed in to the account before.
+// The very first sign in is not alerted on since there is nothing to compare it
+// against.
+func (s *AuthService) alertOnUnrecognizedSignIn(ctx context.Context, user *models.User, attempt *models.SignInAttempt) {
+	signInAttemptRepo := s.c.GetSignInAttemptRepository()
+	where := "WHERE user_id = $1 AND outcome = 'success'"
+
+	hasSignedIn, err := signInAttemptRepo.Exists(ctx, where, []any{user.ID}, nil)
+	if err != nil || !hasSignedIn {
+		return
+	}
+
+	knownDevice, err := signInAttemptRepo.Exists(ctx, where+" AND user_agent = $2", []any{user.ID, attempt.UserAgent}, nil)
+	if err != nil {
+		return
+	}
+
+	knownLocation, err := signInAttemptRepo.Exists(ctx, where+" AND ip_address = $2", []any{user.ID, attempt.IPAddress}, nil)
+	if err != nil {
+		return
+	}
+
+	if knownDevice && knownLocation {
+		return
+	}
+
+	signedInAt := time.Now().UTC().Format(time.RFC1123)
+	utils.Background(ctx, func(ctx context.Context) {
+		err := s.c.GetPush().SendEmail(ctx, &push.Email{
+			To:      []string{user.Email},
+			Subject: NewSignInSubject,
+			Text: fmt.Sprintf(
+				"Your account was signed in to from a new device or location.\nTime: %s\nIP address: %s\nDevice: %s\nIf this wasn't you, reset your password immediately.",
+				signedInAt, attempt.IPAddress, attempt.UserAgent,
+			),
+			Html: fmt.Sprintf(
+				"<p>Your account was signed in to from a new device or location.</p><p>Time: %s<br>IP address: %s<br>Device: %s</p><p>If this wasn't you, reset your password immediately.</p>",
+				signedInAt, attempt.IPAddress, html.EscapeString(attempt.UserAgent),
+			),
+		})
+
+		if err != nil {
+			s.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
+		}
+	})
+}
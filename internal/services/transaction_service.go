@@ -0,0 +1,682 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/apis/paystack"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/princecee/escrow-api/pkg/invites"
+	"github.com/princecee/escrow-api/pkg/metrics"
+	"github.com/princecee/escrow-api/pkg/money"
+	"github.com/princecee/escrow-api/pkg/push"
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrSellerNotFound          = newError(KindNotFound, "seller not found")
+	ErrSellerOrBuyerNotFound   = newError(KindNotFound, "seller or buyer not found")
+	ErrCounterpartyNotBusiness = newError(KindInvalid, "the counterparty's account is not a business, it can not sell")
+	ErrNoUnfundedMilestone     = newError(KindInvalid, "there is no unfunded milestone to pay for")
+	ErrNoMilestones            = newError(KindInvalid, "transaction has no milestones")
+	ErrNotBuyer                = newError(KindForbidden, "only the buyer can pay for a transaction")
+	ErrNotAwaitingPayment      = newError(KindConflict, "transaction is not awaiting payment")
+	ErrNotParty                = newError(KindForbidden, "only the buyer or the seller can act on a transaction")
+	ErrMilestonesSetCost       = newError(KindInvalid, "the cost of a transaction with milestones is set by its milestones")
+	ErrCurrencyFixed           = newError(KindInvalid, "the currency of a paid transaction or one with milestones cannot be changed")
+	ErrTermsFixed              = newError(KindInvalid, "the items and charges of a paid transaction cannot be changed")
+)
+
+// statusUpdates are the statuses a party can move a transaction to with
+// Update, by the status it is in. Paying, settling, releasing milestones and
+// opening and resolving disputes move it everywhere else, along with the money.
+var statusUpdates = map[string][]string{
+	models.TransactionStatusAwaiting:       {models.TransactionStatusPendingPayment, models.TransactionStatusCanceled},
+	models.TransactionStatusPendingPayment: {models.TransactionStatusCanceled},
+}
+
+// MakePaymentInput is what a transaction or its milestones are paid for with.
+type MakePaymentInput struct {
+	TransactionID string `json:"transaction_id" validate:"required,uuid"`
+	IsUseWallet   bool   `json:"is_use_wallet" validate:"required,bool"`
+	MilestoneID   string `json:"milestone_id" validate:"omitempty,uuid"`
+	Currency      string `json:"currency" validate:"omitempty,currency"` // defaults to the transaction's
+}
+
+// UpdateTransactionInput is what a party changes on a transaction. Fields left
+// nil are kept.
+type UpdateTransactionInput struct {
+	DeliveryDuration    *int    `json:"delivery_duration" validate:"omitempty,min=1"`
+	Currency            *string `json:"currency" validate:"omitempty,currency"`
+	ChargeConfiguration *struct {
+		BuyerCharges  int `json:"buyer_charges" validate:"min=0,max=100"`
+		SellerCharges int `json:"seller_charges" validate:"min=0,max=100"`
+	} `json:"charge_configuration" validate:"omitempty"`
+	ProductDetails []struct {
+		Name        string `json:"name" validate:"required,alphanum"`
+		Quantity    int    `json:"quantity" validate:"omitempty,min=0"`
+		Description string `json:"description" validate:"required,alphanum"`
+		Price       int    `json:"price" validate:"omitempty,min=0"`
+	} `json:"product_details" validate:"omitempty,dive"`
+	Status *string `json:"status,omitempty" validate:"omitempty"`
+}
+
+type TransactionService struct {
+	c config.IConfig
+}
+
+func NewTransactionService(c config.IConfig) *TransactionService {
+	return &TransactionService{c: c}
+}
+
+// Quote prices input without saving anything, so the buyer can see what they
+// will pay before the transaction exists.
+func (s *TransactionService) Quote(ctx context.Context, user *models.User, input *CreateTransactionInput) (*TransactionQuote, error) {
+	// the seller's tier decides the fee schedule; without a seller yet the
+	// standard one applies
+	tier := models.BusinessTierStandard
+	if input.CreatedBy == models.TransactionCreatedBySeller {
+		if user.Business == nil {
+			return nil, ErrSellerNotFound
+		}
+
+		tier = user.Business.Tier
+	} else if input.SellerID != "" {
+		seller, err := s.c.GetBusinessRepository().GetById(ctx, input.SellerID, nil)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tier = seller.Tier
+	}
+
+	_, _, quote, err := BuildTransaction(s.c.GetFeeEngine(), input, tier)
+	return quote, err
+}
+
+// Create creates the transaction of input between user and its counterparty.
+// A counterparty given by email or phone number who has no account yet is
+// stood in for by a placeholder and invited; the invite is returned too.
+func (s *TransactionService) Create(ctx context.Context, user *models.User, input *CreateTransactionInput) (*models.Transaction, *models.Invite, error) {
+	var transaction *models.Transaction
+	var invite *models.Invite
+	var seller *models.Business
+	var buyer *models.User
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		var isPlaceholder bool
+		var err error
+
+		if input.CreatedBy == models.TransactionCreatedByBuyer {
+			buyer = user
+			if input.SellerID == "" && input.Counterparty != nil {
+				seller, isPlaceholder, err = s.counterpartySeller(ctx, input, u.Tx)
+			} else {
+				seller, err = u.GetBusinessRepository().GetById(ctx, input.SellerID, u.Tx)
+			}
+		} else {
+			seller = user.Business
+			if input.BuyerID == "" && input.Counterparty != nil {
+				buyer, isPlaceholder, err = s.counterpartyBuyer(ctx, input, u.Tx)
+			} else {
+				buyer, err = u.GetUserRepository().GetById(ctx, input.BuyerID, u.Tx)
+			}
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return Invalid(err)
+		}
+
+		if seller == nil || buyer == nil {
+			return ErrSellerOrBuyerNotFound
+		}
+
+		var milestones []*models.Milestone
+		transaction, milestones, _, err = BuildTransaction(u.GetFeeEngine(), input, seller.Tier)
+		if err != nil {
+			return err
+		}
+		transaction.BuyerID = buyer.ID
+		transaction.SellerID = seller.ID
+
+		err = u.GetTransactionRepository().Create(ctx, transaction, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		timeline := &models.TransactionTimeline{
+			Name:          models.TimelineCreated,
+			TransactionID: transaction.ID,
+		}
+		err = u.GetTransactionTimelineRepository().Create(ctx, timeline, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		transaction.Timeline = []*models.TransactionTimeline{timeline}
+
+		for _, milestone := range milestones {
+			milestone.TransactionID = transaction.ID
+			err = u.GetMilestoneRepository().Create(ctx, milestone, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+		transaction.Milestones = milestones
+
+		if isPlaceholder {
+			placeholderId := transaction.SellerID
+			if input.CreatedBy == models.TransactionCreatedBySeller {
+				placeholderId = transaction.BuyerID
+			}
+
+			invite = newInvite(input, transaction, user, placeholderId)
+			err = u.GetInviteRepository().Create(ctx, invite, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventTransactionCreated, transaction)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metrics.TransactionCreated(transaction.Type, transaction.Currency)
+
+	utils.Background(ctx, func(ctx context.Context) {
+		if invite != nil {
+			s.sendInvite(ctx, invite, user)
+			return
+		}
+
+		var email string
+		if transaction.CreatedBy == models.TransactionCreatedByBuyer {
+			email = seller.Email
+		} else {
+			email = buyer.Email
+		}
+
+		err := s.c.GetPush().SendEmail(ctx, &push.Email{
+			To:      []string{email},
+			Subject: "You have been invited to a new transaction",
+			Text:    fmt.Sprintf("You have been invited to a new transaction: %s", transaction.ID),
+			Html:    fmt.Sprintf("<p>You have been invited to a new transaction: %s</p>", transaction.ID),
+		})
+
+		if err != nil {
+			s.c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
+		}
+	})
+
+	return transaction, invite, nil
+}
+
+// Update changes the terms or the status of the transaction with id. Either
+// party can update it; a change of terms prices it again. The terms are fixed
+// once it is paid and only the statuses in statusUpdates can be set here.
+func (s *TransactionService) Update(ctx context.Context, user *models.User, id string, input *UpdateTransactionInput) (*models.Transaction, error) {
+	var transaction *models.Transaction
+	var text string
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		transactionRepo := u.GetTransactionRepository()
+		transactionTimelineRepo := u.GetTransactionTimelineRepository()
+
+		var err error
+		transaction, err = transactionRepo.GetById(ctx, id, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !isParty(user, transaction) {
+			return ErrNotParty
+		}
+
+		milestones, err := u.GetMilestoneRepository().GetByTransactionId(ctx, transaction.ID, u.Tx)
+		if err != nil {
+			return err
+		}
+		transaction.Milestones = milestones
+
+		if len(milestones) > 0 && (input.ProductDetails != nil || input.ChargeConfiguration != nil) {
+			return ErrMilestonesSetCost
+		}
+
+		// the money held for a paid transaction is in its currency and was
+		// paid for its items and charges
+		isPaid := transaction.Status != models.TransactionStatusAwaiting && transaction.Status != models.TransactionStatusPendingPayment
+		if input.Currency != nil && *input.Currency != transaction.Currency && (isPaid || len(milestones) > 0) {
+			return ErrCurrencyFixed
+		}
+		if isPaid && (input.ProductDetails != nil || input.ChargeConfiguration != nil) {
+			return ErrTermsFixed
+		}
+
+		// setting the status it is in already changes nothing
+		if input.Status != nil && *input.Status == transaction.Status {
+			input.Status = nil
+		}
+		if input.Status != nil && !canUpdateStatus(transaction.Status, *input.Status) {
+			return newError(KindConflict, "a %s transaction cannot be moved to %s", transaction.Status, *input.Status)
+		}
+
+		before := *transaction
+
+		if input.DeliveryDuration != nil {
+			transaction.DeliveryDuration = *input.DeliveryDuration
+		}
+		if input.Currency != nil {
+			transaction.Currency = *input.Currency
+		}
+		if input.ChargeConfiguration != nil {
+			transaction.ChargeConfiguration = models.ChargeConfiguration(*input.ChargeConfiguration)
+		}
+		if input.ProductDetails != nil {
+			productDetails := []models.ProductDetail{}
+			for _, v := range input.ProductDetails {
+				productDetails = append(productDetails, models.ProductDetail{
+					Name:        v.Name,
+					Quantity:    v.Quantity,
+					Description: v.Description,
+					Price:       v.Price,
+				})
+			}
+
+			transaction.ProductDetails = productDetails
+		}
+		if len(milestones) == 0 && (input.ProductDetails != nil || input.ChargeConfiguration != nil || input.Currency != nil) {
+			_, err = PriceTransaction(u.GetFeeEngine(), transaction, nil, transaction.Seller.Tier)
+			if err != nil {
+				return Invalid(err)
+			}
+		}
+
+		timeline := &models.TransactionTimeline{
+			TransactionID: transaction.ID,
+		}
+		if input.Status != nil {
+			transaction.Status = *input.Status
+
+			switch *input.Status {
+			case models.TransactionStatusPendingPayment:
+				text = "Transaction accepted"
+				timeline.Name = models.TimelineApproved
+			case models.TransactionStatusCanceled:
+				text = "Transaction canceled"
+				timeline.Name = models.TImelineCanceled
+			}
+		}
+
+		// only a change of status makes the timeline
+		if timeline.Name != "" {
+			err = transactionTimelineRepo.Create(ctx, timeline, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = transactionRepo.Update(ctx, transaction, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		if input.Status != nil {
+			entry := audit.FromContext(ctx, audit.ActionTransactionStatusChanged, audit.EntityTransaction, transaction.ID)
+			err = u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, before, transaction), u.Tx)
+			if err != nil {
+				return err
+			}
+
+			if transaction.Status == models.TransactionStatusCanceled {
+				err = webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventTransactionCanceled, transaction)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		timelines, err := transactionTimelineRepo.GetMany(ctx, filter.New(filter.Eq("transaction_id", transaction.ID)), u.Tx)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			transaction.Timeline = []*models.TransactionTimeline{}
+		case err != nil:
+			return err
+		default:
+			transaction.Timeline = timelines
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Status != nil {
+		s.notify(ctx, "Transaction updated", text, transaction.Seller.Email, transaction.Buyer.Email)
+	}
+
+	return transaction, nil
+}
+
+// AcceptInvite attaches the transaction of the invite token was signed for to
+// user. Invites sent to the email or phone number a user signs up with are
+// accepted on sign up; this is for invitees who signed up with another one.
+// Seller invites are the exception, they must be accepted by the account
+// holding the email or phone number the invite was sent to.
+func (s *TransactionService) AcceptInvite(ctx context.Context, user *models.User, token string) (*models.Transaction, error) {
+	id, err := invites.Verify([]byte(s.c.GetSettings().InviteKey), token)
+	if err != nil {
+		return nil, Invalid(err)
+	}
+
+	var transaction *models.Transaction
+	err = uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		invite, err := u.GetInviteRepository().GetById(ctx, id, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		transaction, err = invites.Accept(ctx, u, u.Tx, invite, user)
+		switch {
+		case errors.Is(err, invites.ErrAccepted), errors.Is(err, pgx.ErrNoRows):
+			return Conflict(invites.ErrAccepted)
+		case errors.Is(err, invites.ErrOwnInvite), errors.Is(err, invites.ErrSellerNotBusiness), errors.Is(err, invites.ErrNotInvitee):
+			return Forbidden(err)
+		case errors.Is(err, invites.ErrExpired):
+			return Invalid(err)
+		case err != nil:
+			return err
+		}
+
+		entry := audit.FromContext(ctx, audit.ActionTransactionInviteAccepted, audit.EntityTransaction, transaction.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, nil, invite), u.Tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// MakePayment pays for a transaction, or for its unfunded milestones, from the
+// wallet of user or by card. Wallet payments are settled at once; card
+// payments return the paystack payment to complete and are settled by its
+// webhook.
+func (s *TransactionService) MakePayment(ctx context.Context, user *models.User, input *MakePaymentInput) (*models.Transaction, *paystack.InitiateTransactionResponse, error) {
+	var transaction *models.Transaction
+	var paymentData *paystack.InitiateTransactionResponse
+	var paidFromWallet int
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		// the row stays locked until the payment is recorded, so a retry or a
+		// second tab paying at the same time waits and then sees it paid
+		var err error
+		transaction, err = u.GetTransactionRepository().GetByIdForUpdate(ctx, input.TransactionID, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if transaction.BuyerID != user.ID {
+			return ErrNotBuyer
+		}
+
+		milestones, err := u.GetMilestoneRepository().GetByTransactionId(ctx, transaction.ID, u.Tx)
+		if err != nil {
+			return err
+		}
+		transaction.Milestones = milestones
+
+		// milestones are funded one by one, so the transaction is already
+		// pending delivery once the first one is
+		payable := transaction.Status == models.TransactionStatusPendingPayment ||
+			(len(milestones) > 0 && transaction.Status == models.TransactionStatusPendingDelivery)
+		if !payable {
+			return ErrNotAwaitingPayment
+		}
+
+		// transactions settle in their own currency, so paying from another one
+		// needs the balance converted first
+		if input.Currency != "" && input.Currency != transaction.Currency {
+			return newError(KindInvalid, "transaction is settled in %s, convert your %s balance first", transaction.Currency, input.Currency)
+		}
+
+		// milestones are funded one at a time when one is given, or all the
+		// remaining ones at once otherwise
+		funding := []*models.Milestone{}
+		for _, m := range milestones {
+			if !m.IsFunded() && (input.MilestoneID == "" || m.ID == input.MilestoneID) {
+				funding = append(funding, m)
+			}
+		}
+
+		if len(milestones) > 0 && len(funding) == 0 {
+			return ErrNoUnfundedMilestone
+		}
+
+		if len(milestones) == 0 && input.MilestoneID != "" {
+			return ErrNoMilestones
+		}
+
+		amount := transaction.BuyerPayable()
+		if len(funding) > 0 {
+			total := money.New(0, transaction.Currency)
+			for _, m := range funding {
+				total, err = total.Add(money.New(int64(m.BuyerPayable(transaction.ChargeConfiguration)), transaction.Currency))
+				if err != nil {
+					return err
+				}
+			}
+			amount = total.Int()
+		}
+
+		if input.IsUseWallet {
+			paidFromWallet = amount
+			return s.payFromWallet(ctx, u, user, transaction, funding, amount)
+		}
+
+		// paystack needs a new reference for every charge, so a single
+		// milestone is referenced by its own id
+		reference := input.TransactionID
+		metaData := map[string]any{"is_for_transaction": true}
+		if len(funding) > 0 {
+			milestoneIds := []string{}
+			for _, m := range funding {
+				milestoneIds = append(milestoneIds, m.ID)
+			}
+
+			if len(funding) == 1 {
+				reference = funding[0].ID
+			}
+			metaData["transaction_id"] = transaction.ID
+			metaData["milestone_ids"] = milestoneIds
+		}
+
+		paymentData, err = u.GetAPIs().GetPaystack().InitiateTransaction(ctx, paystack.InitiateTransactionDto{
+			Email:     user.Email,
+			Amount:    strconv.FormatInt(int64(amount), 10),
+			Currency:  transaction.Currency,
+			MetaData:  metaData,
+			Reference: reference,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// card payments are counted once paystack confirms them
+	if paidFromWallet > 0 {
+		metrics.Escrowed(transaction.Currency, int64(paidFromWallet))
+	}
+
+	return transaction, paymentData, nil
+}
+
+// payFromWallet debits amount from the wallet of user and marks transaction,
+// or the milestones being funded, as paid.
+func (s *TransactionService) payFromWallet(ctx context.Context, u *uow.UnitOfWork, user *models.User, transaction *models.Transaction, funding []*models.Milestone, amount int) error {
+	walletRepo := u.GetWalletRepository()
+	transactionTimelineRepo := u.GetTransactionTimelineRepository()
+
+	identifier, _ := WalletOwner(user)
+	wallet, err := walletRepo.GetByIdentifierForUpdate(ctx, identifier, transaction.Currency, u.Tx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInsufficientFunds
+	}
+	if err != nil {
+		return err
+	}
+
+	if wallet.IsFrozen {
+		return ErrWalletFrozen
+	}
+
+	walletBefore := *wallet
+	transactionBefore := *transaction
+
+	wallet, err = walletRepo.Debit(ctx, wallet.ID, money.New(int64(amount), transaction.Currency), u.Tx)
+	if errors.Is(err, models.ErrInsufficientFunds) {
+		return ErrInsufficientFunds
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(funding) == 0 {
+		transaction.PaidAmount = amount
+
+		timeline := &models.TransactionTimeline{
+			TransactionID: transaction.ID,
+			Name:          models.TimelinePaymentSubmitted,
+		}
+		err = transactionTimelineRepo.Create(ctx, timeline, u.Tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	transaction.Status = models.TransactionStatusPendingDelivery
+	err = u.GetTransactionRepository().Update(ctx, transaction, u.Tx)
+	if err != nil {
+		return err
+	}
+
+	entries := []*models.AuditLog{
+		audit.WithChanges(audit.FromContext(ctx, audit.ActionWalletDebited, audit.EntityWallet, wallet.ID), walletBefore, wallet),
+		audit.WithChanges(audit.FromContext(ctx, audit.ActionTransactionStatusChanged, audit.EntityTransaction, transaction.ID), transactionBefore, transaction),
+	}
+
+	now := time.Now().UTC()
+	for _, m := range funding {
+		milestoneBefore := *m
+		m.Status = models.MilestoneStatusFunded
+		m.FundedAt = models.NullTime{NullTime: sql.NullTime{Time: now, Valid: true}}
+		err = u.GetMilestoneRepository().Update(ctx, m, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		timeline := &models.TransactionTimeline{
+			TransactionID: transaction.ID,
+			MilestoneID:   &m.ID,
+			Name:          models.TimelineMilestoneFunded,
+		}
+		err = transactionTimelineRepo.Create(ctx, timeline, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, audit.WithChanges(audit.FromContext(ctx, audit.ActionMilestoneFunded, audit.EntityMilestone, m.ID), milestoneBefore, m))
+	}
+
+	for _, entry := range entries {
+		err = u.GetAuditLogRepository().Create(ctx, entry, u.Tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if transactionBefore.Status != transaction.Status {
+		err = webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventTransactionPaid, transaction)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, m := range funding {
+		err = webhooks.Enqueue(ctx, u, u.Tx, transaction.SellerID, webhooks.EventMilestoneFunded, m)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// canUpdateStatus reports whether Update can move a transaction from status
+// from to status to.
+func canUpdateStatus(from, to string) bool {
+	for _, status := range statusUpdates[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isParty reports whether user is the buyer or the seller of transaction.
+func isParty(user *models.User, transaction *models.Transaction) bool {
+	isSeller := user.BusinessID != nil && transaction.SellerID == *user.BusinessID
+	return isSeller || transaction.BuyerID == user.ID
+}
+
+// notify emails text to the parties of a transaction once the request is
+// done with.
+func (s *TransactionService) notify(ctx context.Context, subject, text string, to ...string) {
+	sendEmail(ctx, s.c, subject, text, to...)
+}
+
+// sendEmail emails text to the given addresses once the request is done with.
+// Failing to is logged.
+func sendEmail(ctx context.Context, c config.IConfig, subject, text string, to ...string) {
+	utils.Background(ctx, func(ctx context.Context) {
+		err := c.GetPush().SendEmail(ctx, &push.Email{
+			To:      to,
+			Subject: subject,
+			Text:    text,
+			Html:    fmt.Sprintf("<p>%s</p>", text),
+		})
+
+		if err != nil {
+			c.GetLogger().Log(zerolog.InfoLevel, push.ErrSendingEmailMsg, nil, err)
+		}
+	})
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/apis/paystack"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/fx"
+	"github.com/princecee/escrow-api/pkg/metrics"
+	"github.com/princecee/escrow-api/pkg/money"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+)
+
+type WalletService struct {
+	c config.IConfig
+}
+
+func NewWalletService(c config.IConfig) *WalletService {
+	return &WalletService{c: c}
+}
+
+// WalletOwner returns the identifier and account type the wallets of user are
+// held under: the user for personal accounts, their business otherwise.
+func WalletOwner(user *models.User) (string, string) {
+	if user.AccountType == models.PersonalAccountType {
+		return user.ID, models.PersonalAccountType
+	}
+
+	return *user.BusinessID, models.BusinessAccountType
+}
+
+// EnqueueEvent sends eventType to the webhooks of the business owning wallet.
+// Personal wallets have no webhooks.
+func (s *WalletService) EnqueueEvent(ctx context.Context, tx pgx.Tx, wallet *models.Wallet, eventType string, data any) error {
+	if wallet.AccountType != models.BusinessAccountType {
+		return nil
+	}
+
+	return webhooks.Enqueue(ctx, s.c, tx, wallet.Identifier, eventType, data)
+}
+
+// OwnWallet returns the wallet with walletId if it belongs to user.
+func (s *WalletService) OwnWallet(ctx context.Context, user *models.User, walletId string, tx pgx.Tx) (*models.Wallet, error) {
+	wallet, err := s.c.GetWalletRepository().GetById(ctx, walletId, tx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	identifier, _ := WalletOwner(user)
+	if wallet.Identifier != identifier {
+		return nil, ErrNotFound
+	}
+
+	return wallet, nil
+}
+
+// GetWallets returns the wallet of user in currency along with all their
+// wallets.
+func (s *WalletService) GetWallets(ctx context.Context, user *models.User, currency string) (*models.Wallet, []*models.Wallet, error) {
+	if !money.IsSupported(currency) {
+		return nil, nil, Invalid(money.ErrUnsupportedCurrency)
+	}
+
+	identifier, _ := WalletOwner(user)
+	wallets, err := s.c.GetWalletRepository().GetManyByIdentifier(ctx, identifier, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, wallet := range wallets {
+		if wallet.Currency == currency {
+			return wallet, wallets, nil
+		}
+	}
+
+	return nil, nil, newError(KindNotFound, "no wallet in %s", currency)
+}
+
+// AddFunds starts a card payment of amount into the wallet of user in
+// currency, creating the wallet if needed. The deposit stays pending until
+// paystack confirms the charge.
+func (s *WalletService) AddFunds(ctx context.Context, user *models.User, amount int, currency string) (*models.WalletHistory, *paystack.InitiateTransactionResponse, error) {
+	var walletHistory *models.WalletHistory
+	var paymentData *paystack.InitiateTransactionResponse
+
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		identifier, accountType := WalletOwner(user)
+		wallet, err := u.GetWalletRepository().GetOrCreate(ctx, identifier, accountType, currency, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		walletHistory = &models.WalletHistory{
+			WalletID: wallet.ID,
+			Type:     models.WalletHistoryDepositType,
+			Amount:   amount,
+			Status:   models.WalletHistoryPending,
+			Wallet:   *wallet,
+		}
+		err = u.GetWalletHistoryRepository().Create(ctx, walletHistory, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		paymentData, err = u.GetAPIs().GetPaystack().InitiateTransaction(ctx, paystack.InitiateTransactionDto{
+			Email:     user.Email,
+			Amount:    strconv.FormatInt(int64(amount), 10),
+			Currency:  wallet.Currency,
+			Reference: walletHistory.ID,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return walletHistory, paymentData, nil
+}
+
+// Withdraw debits amount from the wallet of user in currency and records a
+// pending withdrawal.
+func (s *WalletService) Withdraw(ctx context.Context, user *models.User, amount int, currency string) (*models.WalletHistory, error) {
+	walletRepo := s.c.GetWalletRepository()
+
+	var walletHistory *models.WalletHistory
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		identifier, _ := WalletOwner(user)
+		wallet, err := walletRepo.GetByIdentifierForUpdate(ctx, identifier, currency, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInsufficientFunds
+		}
+		if err != nil {
+			return err
+		}
+
+		if wallet.IsFrozen {
+			return ErrWalletFrozen
+		}
+
+		before := *wallet
+		wallet, err = walletRepo.Debit(ctx, wallet.ID, money.New(int64(amount), currency), u.Tx)
+		if errors.Is(err, models.ErrInsufficientFunds) {
+			return ErrInsufficientFunds
+		}
+		if err != nil {
+			return err
+		}
+
+		walletHistory = &models.WalletHistory{
+			WalletID: wallet.ID,
+			Type:     models.WalletHistoryWithdrawalType,
+			Amount:   amount,
+			Status:   models.WalletHistoryPending,
+			Wallet:   *wallet,
+		}
+		err = u.GetWalletHistoryRepository().Create(ctx, walletHistory, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		entry := audit.FromContext(ctx, audit.ActionWalletDebited, audit.EntityWallet, wallet.ID)
+		return u.GetAuditLogRepository().Create(ctx, audit.WithChanges(entry, before, wallet), u.Tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO - make transfer through paystack
+
+	metrics.Withdrawal(currency, walletHistory.Status)
+
+	return walletHistory, nil
+}
+
+// CreateFXQuote locks the rate of converting amount from one wallet currency
+// of user to another.
+func (s *WalletService) CreateFXQuote(ctx context.Context, user *models.User, amount int, from, to string) (*models.FXQuote, error) {
+	quote, err := s.c.GetFXEngine().Quote(money.New(int64(amount), from), to)
+	if err != nil {
+		return nil, Invalid(err)
+	}
+
+	identifier, accountType := WalletOwner(user)
+	fxQuote := &models.FXQuote{
+		Identifier:      identifier,
+		AccountType:     accountType,
+		FromCurrency:    quote.From.Currency,
+		ToCurrency:      quote.To.Currency,
+		Amount:          quote.From.Int(),
+		ConvertedAmount: quote.To.Int(),
+		MidRate:         quote.MidRate,
+		Rate:            quote.Rate,
+		Spread:          quote.Spread,
+		Status:          models.FXQuotePending,
+		ExpiresAt:       quote.ExpiresAt,
+	}
+	err = s.c.GetFXQuoteRepository().Create(ctx, fxQuote, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return fxQuote, nil
+}
+
+// ExecuteFXQuote converts between two wallets of user at the rate locked by
+// the quote. Both legs are posted in one db transaction.
+func (s *WalletService) ExecuteFXQuote(ctx context.Context, user *models.User, quoteId string) (*models.FXQuote, []*models.WalletHistory, error) {
+	walletRepo := s.c.GetWalletRepository()
+	fxQuoteRepo := s.c.GetFXQuoteRepository()
+
+	var quote *models.FXQuote
+	var histories []*models.WalletHistory
+	err := uow.WithTx(ctx, s.c, func(u *uow.UnitOfWork) error {
+		identifier, accountType := WalletOwner(user)
+
+		var err error
+		quote, err = fxQuoteRepo.GetById(ctx, quoteId, u.Tx)
+		if err != nil || quote.Identifier != identifier {
+			return ErrQuoteNotFound
+		}
+
+		if quote.Status != models.FXQuotePending {
+			return ErrQuoteExecuted
+		}
+
+		if quote.IsExpired() {
+			return Invalid(fx.ErrQuoteExpired)
+		}
+
+		source, err := walletRepo.GetByIdentifierForUpdate(ctx, identifier, quote.FromCurrency, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInsufficientFunds
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := walletRepo.GetOrCreate(ctx, identifier, accountType, quote.ToCurrency, u.Tx)
+		if err != nil {
+			return err
+		}
+
+		if source.IsFrozen || target.IsFrozen {
+			return ErrWalletFrozen
+		}
+
+		sourceBefore, targetBefore := *source, *target
+		source, err = walletRepo.Debit(ctx, source.ID, money.New(int64(quote.Amount), quote.FromCurrency), u.Tx)
+		if errors.Is(err, models.ErrInsufficientFunds) {
+			return ErrInsufficientFunds
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err = walletRepo.Credit(ctx, target.ID, money.New(int64(quote.ConvertedAmount), quote.ToCurrency), u.Tx)
+		if err != nil {
+			return err
+		}
+
+		note := fmt.Sprintf("%s to %s at %s", quote.FromCurrency, quote.ToCurrency, quote.Rate)
+		histories = []*models.WalletHistory{
+			{
+				WalletID: source.ID,
+				Type:     models.WalletHistoryConversionType,
+				Amount:   quote.Amount,
+				Status:   models.WalletHistorySuccessful,
+				Note:     note,
+				Wallet:   *source,
+			},
+			{
+				WalletID: target.ID,
+				Type:     models.WalletHistoryConversionType,
+				Amount:   quote.ConvertedAmount,
+				Status:   models.WalletHistorySuccessful,
+				Note:     note,
+				Wallet:   *target,
+			},
+		}
+		for _, walletHistory := range histories {
+			err = u.GetWalletHistoryRepository().Create(ctx, walletHistory, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		quote.Status = models.FXQuoteExecuted
+		quote.ExecutedAt = models.NullTime{NullTime: sql.NullTime{Time: time.Now().UTC(), Valid: true}}
+		err = fxQuoteRepo.Update(ctx, quote, u.Tx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrQuoteExecuted
+		}
+		if err != nil {
+			return err
+		}
+
+		entries := []*models.AuditLog{
+			audit.WithChanges(audit.FromContext(ctx, audit.ActionWalletDebited, audit.EntityWallet, source.ID), sourceBefore, source),
+			audit.WithChanges(audit.FromContext(ctx, audit.ActionWalletCredited, audit.EntityWallet, target.ID), targetBefore, target),
+		}
+		for _, entry := range entries {
+			err = u.GetAuditLogRepository().Create(ctx, entry, u.Tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.EnqueueEvent(ctx, u.Tx, source, webhooks.EventWalletConverted, quote)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return quote, histories, nil
+}
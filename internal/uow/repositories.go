@@ -0,0 +1,701 @@
+package uow
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/princecee/escrow-api/pkg/money"
+	"github.com/princecee/escrow-api/pkg/utils"
+)
+
+// The repositories of a unit of work are those of the config bound to its
+// transaction: every call runs in Tx whatever tx it is passed, so one cannot
+// leave the transaction by passing nil or another one by mistake.
+
+func (u *UnitOfWork) GetAPIKeyRepository() repositories.IAPIKeyRepository {
+	return apiKeyRepository{u.IConfig.GetAPIKeyRepository(), u.Tx}
+}
+
+type apiKeyRepository struct {
+	repo repositories.IAPIKeyRepository
+	tx   pgx.Tx
+}
+
+func (r apiKeyRepository) Create(ctx context.Context, k *models.APIKey, _ pgx.Tx) error {
+	return r.repo.Create(ctx, k, r.tx)
+}
+
+func (r apiKeyRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.APIKey, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r apiKeyRepository) GetByHash(ctx context.Context, hash string, _ pgx.Tx) (*models.APIKey, error) {
+	return r.repo.GetByHash(ctx, hash, r.tx)
+}
+
+func (r apiKeyRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.APIKey, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r apiKeyRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r apiKeyRepository) Revoke(ctx context.Context, k *models.APIKey, _ pgx.Tx) error {
+	return r.repo.Revoke(ctx, k, r.tx)
+}
+
+func (r apiKeyRepository) TouchLastUsed(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.TouchLastUsed(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetAuditLogRepository() repositories.IAuditLogRepository {
+	return auditLogRepository{u.IConfig.GetAuditLogRepository(), u.Tx}
+}
+
+type auditLogRepository struct {
+	repo repositories.IAuditLogRepository
+	tx   pgx.Tx
+}
+
+func (r auditLogRepository) Create(ctx context.Context, l *models.AuditLog, _ pgx.Tx) error {
+	return r.repo.Create(ctx, l, r.tx)
+}
+
+func (r auditLogRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.AuditLog, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r auditLogRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.AuditLog, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r auditLogRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r auditLogRepository) GetAfterSeq(ctx context.Context, seq int64, limit int, _ pgx.Tx) ([]*models.AuditLog, error) {
+	return r.repo.GetAfterSeq(ctx, seq, limit, r.tx)
+}
+
+func (u *UnitOfWork) GetAuthRepository() repositories.IAuthRepository {
+	return authRepository{u.IConfig.GetAuthRepository(), u.Tx}
+}
+
+type authRepository struct {
+	repo repositories.IAuthRepository
+	tx   pgx.Tx
+}
+
+func (r authRepository) Create(ctx context.Context, a *models.Auth, _ pgx.Tx) error {
+	return r.repo.Create(ctx, a, r.tx)
+}
+
+func (r authRepository) Update(ctx context.Context, a *models.Auth, _ pgx.Tx) error {
+	return r.repo.Update(ctx, a, r.tx)
+}
+
+func (r authRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Auth, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r authRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r authRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (r authRepository) GetByUserId(ctx context.Context, id string, _ pgx.Tx) (*models.Auth, error) {
+	return r.repo.GetByUserId(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetBankAccountRepository() repositories.IBankAccountRepository {
+	return bankAccountRepository{u.IConfig.GetBankAccountRepository(), u.Tx}
+}
+
+type bankAccountRepository struct {
+	repo repositories.IBankAccountRepository
+	tx   pgx.Tx
+}
+
+func (r bankAccountRepository) Create(ctx context.Context, b *models.BankAccount, _ pgx.Tx) error {
+	return r.repo.Create(ctx, b, r.tx)
+}
+
+func (r bankAccountRepository) Update(ctx context.Context, b *models.BankAccount, _ pgx.Tx) error {
+	return r.repo.Update(ctx, b, r.tx)
+}
+
+func (r bankAccountRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.BankAccount, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r bankAccountRepository) GetByWalletId(ctx context.Context, id string, pagination utils.Pagination, _ pgx.Tx) ([]*models.BankAccount, error) {
+	return r.repo.GetByWalletId(ctx, id, pagination, r.tx)
+}
+
+func (r bankAccountRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r bankAccountRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetBusinessRepository() repositories.IBusinessRepository {
+	return businessRepository{u.IConfig.GetBusinessRepository(), u.Tx}
+}
+
+type businessRepository struct {
+	repo repositories.IBusinessRepository
+	tx   pgx.Tx
+}
+
+func (r businessRepository) Create(ctx context.Context, b *models.Business, _ pgx.Tx) error {
+	return r.repo.Create(ctx, b, r.tx)
+}
+
+func (r businessRepository) Update(ctx context.Context, b *models.Business, _ pgx.Tx) error {
+	return r.repo.Update(ctx, b, r.tx)
+}
+
+func (r businessRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Business, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r businessRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r businessRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetDisputeRepository() repositories.IDisputeRepository {
+	return disputeRepository{u.IConfig.GetDisputeRepository(), u.Tx}
+}
+
+type disputeRepository struct {
+	repo repositories.IDisputeRepository
+	tx   pgx.Tx
+}
+
+func (r disputeRepository) Create(ctx context.Context, d *models.Dispute, _ pgx.Tx) error {
+	return r.repo.Create(ctx, d, r.tx)
+}
+
+func (r disputeRepository) Update(ctx context.Context, d *models.Dispute, _ pgx.Tx) error {
+	return r.repo.Update(ctx, d, r.tx)
+}
+
+func (r disputeRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Dispute, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r disputeRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.Dispute, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r disputeRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r disputeRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetEventRepository() repositories.IEventRepository {
+	return eventRepository{u.IConfig.GetEventRepository(), u.Tx}
+}
+
+type eventRepository struct {
+	repo repositories.IEventRepository
+	tx   pgx.Tx
+}
+
+func (r eventRepository) Create(ctx context.Context, b *models.Event, _ pgx.Tx) error {
+	return r.repo.Create(ctx, b, r.tx)
+}
+
+func (r eventRepository) Update(ctx context.Context, b *models.Event, _ pgx.Tx) error {
+	return r.repo.Update(ctx, b, r.tx)
+}
+
+func (r eventRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Event, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r eventRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r eventRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetFXQuoteRepository() repositories.IFXQuoteRepository {
+	return fxQuoteRepository{u.IConfig.GetFXQuoteRepository(), u.Tx}
+}
+
+type fxQuoteRepository struct {
+	repo repositories.IFXQuoteRepository
+	tx   pgx.Tx
+}
+
+func (r fxQuoteRepository) Create(ctx context.Context, q *models.FXQuote, _ pgx.Tx) error {
+	return r.repo.Create(ctx, q, r.tx)
+}
+
+func (r fxQuoteRepository) Update(ctx context.Context, q *models.FXQuote, _ pgx.Tx) error {
+	return r.repo.Update(ctx, q, r.tx)
+}
+
+func (r fxQuoteRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.FXQuote, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetInviteRepository() repositories.IInviteRepository {
+	return inviteRepository{u.IConfig.GetInviteRepository(), u.Tx}
+}
+
+type inviteRepository struct {
+	repo repositories.IInviteRepository
+	tx   pgx.Tx
+}
+
+func (r inviteRepository) Create(ctx context.Context, i *models.Invite, _ pgx.Tx) error {
+	return r.repo.Create(ctx, i, r.tx)
+}
+
+func (r inviteRepository) Update(ctx context.Context, i *models.Invite, _ pgx.Tx) error {
+	return r.repo.Update(ctx, i, r.tx)
+}
+
+func (r inviteRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Invite, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r inviteRepository) GetPendingByContact(ctx context.Context, email, phone string, _ pgx.Tx) ([]*models.Invite, error) {
+	return r.repo.GetPendingByContact(ctx, email, phone, r.tx)
+}
+
+func (u *UnitOfWork) GetMilestoneRepository() repositories.IMilestoneRepository {
+	return milestoneRepository{u.IConfig.GetMilestoneRepository(), u.Tx}
+}
+
+type milestoneRepository struct {
+	repo repositories.IMilestoneRepository
+	tx   pgx.Tx
+}
+
+func (r milestoneRepository) Create(ctx context.Context, m *models.Milestone, _ pgx.Tx) error {
+	return r.repo.Create(ctx, m, r.tx)
+}
+
+func (r milestoneRepository) Update(ctx context.Context, m *models.Milestone, _ pgx.Tx) error {
+	return r.repo.Update(ctx, m, r.tx)
+}
+
+func (r milestoneRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Milestone, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r milestoneRepository) GetByTransactionId(ctx context.Context, transactionId string, _ pgx.Tx) ([]*models.Milestone, error) {
+	return r.repo.GetByTransactionId(ctx, transactionId, r.tx)
+}
+
+func (u *UnitOfWork) GetOtpRepository() repositories.IOtpRepository {
+	return otpRepository{u.IConfig.GetOtpRepository(), u.Tx}
+}
+
+type otpRepository struct {
+	repo repositories.IOtpRepository
+	tx   pgx.Tx
+}
+
+func (r otpRepository) Create(ctx context.Context, otp *models.Otp, _ pgx.Tx) error {
+	return r.repo.Create(ctx, otp, r.tx)
+}
+
+func (r otpRepository) Update(ctx context.Context, otp *models.Otp, _ pgx.Tx) error {
+	return r.repo.Update(ctx, otp, r.tx)
+}
+
+func (r otpRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Otp, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r otpRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r otpRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (r otpRepository) GetOneByWhere(ctx context.Context, where string, args []any, _ pgx.Tx) (*models.Otp, error) {
+	return r.repo.GetOneByWhere(ctx, where, args, r.tx)
+}
+
+func (u *UnitOfWork) GetSignInAttemptRepository() repositories.ISignInAttemptRepository {
+	return signInAttemptRepository{u.IConfig.GetSignInAttemptRepository(), u.Tx}
+}
+
+type signInAttemptRepository struct {
+	repo repositories.ISignInAttemptRepository
+	tx   pgx.Tx
+}
+
+func (r signInAttemptRepository) Create(ctx context.Context, a *models.SignInAttempt, _ pgx.Tx) error {
+	return r.repo.Create(ctx, a, r.tx)
+}
+
+func (r signInAttemptRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.SignInAttempt, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r signInAttemptRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.SignInAttempt, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r signInAttemptRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r signInAttemptRepository) Exists(ctx context.Context, where string, args []any, _ pgx.Tx) (bool, error) {
+	return r.repo.Exists(ctx, where, args, r.tx)
+}
+
+func (r signInAttemptRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetTokenRepository() repositories.ITokenRepository {
+	return tokenRepository{u.IConfig.GetTokenRepository(), u.Tx}
+}
+
+type tokenRepository struct {
+	repo repositories.ITokenRepository
+	tx   pgx.Tx
+}
+
+func (r tokenRepository) Create(ctx context.Context, t *models.Token, _ pgx.Tx) error {
+	return r.repo.Create(ctx, t, r.tx)
+}
+
+func (r tokenRepository) Update(ctx context.Context, t *models.Token, _ pgx.Tx) error {
+	return r.repo.Update(ctx, t, r.tx)
+}
+
+func (r tokenRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Token, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r tokenRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r tokenRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetTransactionRepository() repositories.ITransactionRepository {
+	return transactionRepository{u.IConfig.GetTransactionRepository(), u.Tx}
+}
+
+type transactionRepository struct {
+	repo repositories.ITransactionRepository
+	tx   pgx.Tx
+}
+
+func (r transactionRepository) Create(ctx context.Context, t *models.Transaction, _ pgx.Tx) error {
+	return r.repo.Create(ctx, t, r.tx)
+}
+
+func (r transactionRepository) Update(ctx context.Context, t *models.Transaction, _ pgx.Tx) error {
+	return r.repo.Update(ctx, t, r.tx)
+}
+
+func (r transactionRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Transaction, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r transactionRepository) GetByIdForUpdate(ctx context.Context, id string, _ pgx.Tx) (*models.Transaction, error) {
+	return r.repo.GetByIdForUpdate(ctx, id, r.tx)
+}
+
+func (r transactionRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.Transaction, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r transactionRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r transactionRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r transactionRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetTransactionTimelineRepository() repositories.ITransactionTimelineRepository {
+	return transactionTimelineRepository{u.IConfig.GetTransactionTimelineRepository(), u.Tx}
+}
+
+type transactionTimelineRepository struct {
+	repo repositories.ITransactionTimelineRepository
+	tx   pgx.Tx
+}
+
+func (r transactionTimelineRepository) Create(ctx context.Context, tt *models.TransactionTimeline, _ pgx.Tx) error {
+	return r.repo.Create(ctx, tt, r.tx)
+}
+
+func (r transactionTimelineRepository) Update(ctx context.Context, tt *models.TransactionTimeline, _ pgx.Tx) error {
+	return r.repo.Update(ctx, tt, r.tx)
+}
+
+func (r transactionTimelineRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.TransactionTimeline, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r transactionTimelineRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.TransactionTimeline, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r transactionTimelineRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r transactionTimelineRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r transactionTimelineRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetUserRepository() repositories.IUserRepository {
+	return userRepository{u.IConfig.GetUserRepository(), u.Tx}
+}
+
+type userRepository struct {
+	repo repositories.IUserRepository
+	tx   pgx.Tx
+}
+
+func (r userRepository) Create(ctx context.Context, u *models.User, _ pgx.Tx) error {
+	return r.repo.Create(ctx, u, r.tx)
+}
+
+func (r userRepository) Update(ctx context.Context, u *models.User, _ pgx.Tx) error {
+	return r.repo.Update(ctx, u, r.tx)
+}
+
+func (r userRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.User, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r userRepository) GetByEmail(ctx context.Context, email string, _ pgx.Tx) (*models.User, error) {
+	return r.repo.GetByEmail(ctx, email, r.tx)
+}
+
+func (r userRepository) GetByPhoneNumber(ctx context.Context, phone string, _ pgx.Tx) (*models.User, error) {
+	return r.repo.GetByPhoneNumber(ctx, phone, r.tx)
+}
+
+func (r userRepository) GetByBusinessId(ctx context.Context, id string, _ pgx.Tx) (*models.User, error) {
+	return r.repo.GetByBusinessId(ctx, id, r.tx)
+}
+
+func (r userRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.User, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r userRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r userRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r userRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetWalletRepository() repositories.IWalletRepository {
+	return walletRepository{u.IConfig.GetWalletRepository(), u.Tx}
+}
+
+type walletRepository struct {
+	repo repositories.IWalletRepository
+	tx   pgx.Tx
+}
+
+func (r walletRepository) Create(ctx context.Context, w *models.Wallet, _ pgx.Tx) error {
+	return r.repo.Create(ctx, w, r.tx)
+}
+
+func (r walletRepository) Update(ctx context.Context, w *models.Wallet, _ pgx.Tx) error {
+	return r.repo.Update(ctx, w, r.tx)
+}
+
+func (r walletRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r walletRepository) GetByIdentifier(ctx context.Context, id, currency string, _ pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetByIdentifier(ctx, id, currency, r.tx)
+}
+
+func (r walletRepository) GetManyByIdentifier(ctx context.Context, id string, _ pgx.Tx) ([]*models.Wallet, error) {
+	return r.repo.GetManyByIdentifier(ctx, id, r.tx)
+}
+
+func (r walletRepository) GetOrCreate(ctx context.Context, identifier, accountType, currency string, _ pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetOrCreate(ctx, identifier, accountType, currency, r.tx)
+}
+
+func (r walletRepository) GetByIdForUpdate(ctx context.Context, id string, _ pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetByIdForUpdate(ctx, id, r.tx)
+}
+
+func (r walletRepository) GetByIdentifierForUpdate(ctx context.Context, id, currency string, _ pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetByIdentifierForUpdate(ctx, id, currency, r.tx)
+}
+
+func (r walletRepository) Credit(ctx context.Context, id string, m money.Money, _ pgx.Tx) (*models.Wallet, error) {
+	return r.repo.Credit(ctx, id, m, r.tx)
+}
+
+func (r walletRepository) Debit(ctx context.Context, id string, m money.Money, _ pgx.Tx) (*models.Wallet, error) {
+	return r.repo.Debit(ctx, id, m, r.tx)
+}
+
+func (r walletRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r walletRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetWalletHistoryRepository() repositories.IWalletHistoryRepository {
+	return walletHistoryRepository{u.IConfig.GetWalletHistoryRepository(), u.Tx}
+}
+
+type walletHistoryRepository struct {
+	repo repositories.IWalletHistoryRepository
+	tx   pgx.Tx
+}
+
+func (r walletHistoryRepository) Create(ctx context.Context, h *models.WalletHistory, _ pgx.Tx) error {
+	return r.repo.Create(ctx, h, r.tx)
+}
+
+func (r walletHistoryRepository) Update(ctx context.Context, h *models.WalletHistory, _ pgx.Tx) error {
+	return r.repo.Update(ctx, h, r.tx)
+}
+
+func (r walletHistoryRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.WalletHistory, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r walletHistoryRepository) GetByWalletId(ctx context.Context, id string, pagination utils.Pagination, _ pgx.Tx) ([]*models.WalletHistory, error) {
+	return r.repo.GetByWalletId(ctx, id, pagination, r.tx)
+}
+
+func (r walletHistoryRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.WalletHistory, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r walletHistoryRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r walletHistoryRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
+
+func (r walletHistoryRepository) SoftDelete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, r.tx)
+}
+
+func (u *UnitOfWork) GetWebhookDeliveryRepository() repositories.IWebhookDeliveryRepository {
+	return webhookDeliveryRepository{u.IConfig.GetWebhookDeliveryRepository(), u.Tx}
+}
+
+type webhookDeliveryRepository struct {
+	repo repositories.IWebhookDeliveryRepository
+	tx   pgx.Tx
+}
+
+func (r webhookDeliveryRepository) Create(ctx context.Context, d *models.WebhookDelivery, _ pgx.Tx) error {
+	return r.repo.Create(ctx, d, r.tx)
+}
+
+func (r webhookDeliveryRepository) Update(ctx context.Context, d *models.WebhookDelivery, _ pgx.Tx) error {
+	return r.repo.Update(ctx, d, r.tx)
+}
+
+func (r webhookDeliveryRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.WebhookDelivery, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r webhookDeliveryRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.WebhookDelivery, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r webhookDeliveryRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r webhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration, _ pgx.Tx) ([]*models.WebhookDelivery, error) {
+	return r.repo.ClaimDue(ctx, limit, lease, r.tx)
+}
+
+func (u *UnitOfWork) GetWebhookEndpointRepository() repositories.IWebhookEndpointRepository {
+	return webhookEndpointRepository{u.IConfig.GetWebhookEndpointRepository(), u.Tx}
+}
+
+type webhookEndpointRepository struct {
+	repo repositories.IWebhookEndpointRepository
+	tx   pgx.Tx
+}
+
+func (r webhookEndpointRepository) Create(ctx context.Context, e *models.WebhookEndpoint, _ pgx.Tx) error {
+	return r.repo.Create(ctx, e, r.tx)
+}
+
+func (r webhookEndpointRepository) Update(ctx context.Context, e *models.WebhookEndpoint, _ pgx.Tx) error {
+	return r.repo.Update(ctx, e, r.tx)
+}
+
+func (r webhookEndpointRepository) GetById(ctx context.Context, id string, _ pgx.Tx) (*models.WebhookEndpoint, error) {
+	return r.repo.GetById(ctx, id, r.tx)
+}
+
+func (r webhookEndpointRepository) GetMany(ctx context.Context, f *filter.Filter, _ pgx.Tx) ([]*models.WebhookEndpoint, error) {
+	return r.repo.GetMany(ctx, f, r.tx)
+}
+
+func (r webhookEndpointRepository) Count(ctx context.Context, f *filter.Filter, _ pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, r.tx)
+}
+
+func (r webhookEndpointRepository) GetSubscribed(ctx context.Context, businessId, eventType string, _ pgx.Tx) ([]*models.WebhookEndpoint, error) {
+	return r.repo.GetSubscribed(ctx, businessId, eventType, r.tx)
+}
+
+func (r webhookEndpointRepository) Delete(ctx context.Context, id string, _ pgx.Tx) error {
+	return r.repo.Delete(ctx, id, r.tx)
+}
@@ -0,0 +1,103 @@
+// Package uow runs work spanning several repositories in one db transaction.
+package uow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/princecee/escrow-api/config"
+)
+
+type Options struct {
+	TxOptions pgx.TxOptions // isolation level and access mode of the transaction
+	Retries   int           // times a transaction that failed to serialize is run again
+}
+
+var DefaultOptions = Options{}
+
+// Serializable runs work moving money at the serializable isolation level, so
+// concurrent runs cannot both act on what they read, and retries it when it
+// loses to one of them.
+var Serializable = Options{TxOptions: pgx.TxOptions{IsoLevel: pgx.Serializable}, Retries: 3}
+
+// UnitOfWork is a db transaction and the repositories to run in it. Its
+// repositories are bound to Tx, anything else comes from the config.
+type UnitOfWork struct {
+	config.IConfig
+	Tx pgx.Tx
+}
+
+// WithTx runs fn in a new db transaction, committed when fn returns nil and
+// rolled back when it returns an error or panics. With Retries set, fn is run
+// again from the start when the transaction fails to serialize, so it must not
+// have side effects outside the db.
+func WithTx(ctx context.Context, c config.IConfig, fn func(u *UnitOfWork) error, opts ...Options) error {
+	o := DefaultOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	var err error
+	for attempt := 0; attempt <= o.Retries; attempt++ {
+		err = run(ctx, c, o.TxOptions, fn)
+		if !IsSerializationFailure(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func run(ctx context.Context, c config.IConfig, txOptions pgx.TxOptions, fn func(u *UnitOfWork) error) error {
+	tx, err := c.GetDB().BeginTx(ctx, txOptions)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	return finish(ctx, &UnitOfWork{IConfig: c, Tx: tx}, fn)
+}
+
+// WithTx runs fn in a savepoint of u. The savepoint is released when fn
+// returns nil and rolled back to when it returns an error or panics, leaving
+// the work done in u before it intact.
+func (u *UnitOfWork) WithTx(ctx context.Context, fn func(u *UnitOfWork) error) error {
+	tx, err := u.Tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin savepoint: %w", err)
+	}
+
+	return finish(ctx, &UnitOfWork{IConfig: u.IConfig, Tx: tx}, fn)
+}
+
+// finish runs fn and commits or rolls back the transaction of u depending on
+// its outcome. A panic in fn is rethrown once the transaction is rolled back.
+func finish(ctx context.Context, u *UnitOfWork, fn func(u *UnitOfWork) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			u.Tx.Rollback(ctx)
+			panic(p)
+		}
+	}()
+
+	if err = fn(u); err != nil {
+		u.Tx.Rollback(ctx)
+		return err
+	}
+
+	return u.Tx.Commit(ctx)
+}
+
+// IsSerializationFailure reports whether err is postgres giving up on a
+// transaction because of a concurrent one, after which it can be retried.
+func IsSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	// serialization_failure and deadlock_detected
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
@@ -1,3 +1,5 @@
+DROP TABLE IF EXISTS transaction_timelines;
+DROP TABLE IF EXISTS transactions;
 DROP TABLE IF EXISTS tokens;
 DROP TABLE IF EXISTS events;
 DROP TABLE IF EXISTS auths;
@@ -7,8 +9,6 @@ DROP TABLE IF EXISTS bank_accounts;
 DROP TABLE IF EXISTS wallets;
 DROP TABLE IF EXISTS users;
 DROP TABLE IF EXISTS businesses;
-DROP TABLE IF EXISTS transactions;
-DROP TABLE IF EXISTS transaction_timelines;
 
 DROP TYPE IF EXISTS ACCOUNT_TYPE_ENUM;
 DROP TYPE IF EXISTS EVENT_ENVIRONMENT_ENUM;
@@ -133,7 +133,7 @@ CREATE TABLE IF NOT EXISTS wallet_histories (
   wallet_id UUID REFERENCES wallets NOT NULL,
   type WITHDRAWAL_TYPE_ENUM NOT NULL,
   amount INT NOT NULL,
-  status MODEL_STATUS_ENUM NOT NULL DEFAULT "Pending",
+  status MODEL_STATUS_ENUM NOT NULL DEFAULT 'Pending',
   created_at TIMESTAMPTZ NOT NULL,
 	updated_at TIMESTAMPTZ NOT NULL,
 	deleted_at TIMESTAMPTZ,
@@ -161,7 +161,7 @@ CREATE TABLE IF NOT EXISTS transactions (
 	type TRANSACTION_TYPE_ENUM NOT NULL,
 	created_by TRANSACTION_CREATED_BY_ENUM NOT NULL,
 	buyer_id UUID REFERENCES users NOT NULL,
-	seller_id UUID REFERENCES users NOT NULL,
+	seller_id UUID REFERENCES businesses NOT NULL,
 	delivery_duration INT NOT NULL,
 	currency VARCHAR NOT NULL,
 	charge_configuration JSON NOT NULL,
@@ -173,7 +173,7 @@ CREATE TABLE IF NOT EXISTS transactions (
 	created_at TIMESTAMPTZ NOT NULL,
 	updated_at TIMESTAMPTZ NOT NULL,
 	deleted_at TIMESTAMPTZ,
-	version INT DEFAULT 1,
+	version INT DEFAULT 1
 );
 
 CREATE TABLE IF NOT EXISTS transaction_timelines (
@@ -183,5 +183,5 @@ CREATE TABLE IF NOT EXISTS transaction_timelines (
 	created_at TIMESTAMPTZ NOT NULL,
 	updated_at TIMESTAMPTZ NOT NULL,
 	deleted_at TIMESTAMPTZ,
-	version INT DEFAULT 1,
+	version INT DEFAULT 1
 );
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sign_in_attempts;
+
+DROP TYPE IF EXISTS SIGN_IN_OUTCOME_ENUM;
@@ -0,0 +1,17 @@
+CREATE TYPE SIGN_IN_OUTCOME_ENUM AS ENUM ('success', 'failed', 'locked');
+
+CREATE TABLE IF NOT EXISTS sign_in_attempts (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id UUID REFERENCES users,
+	email VARCHAR(255) NOT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	user_agent TEXT NOT NULL DEFAULT '',
+	outcome SIGN_IN_OUTCOME_ENUM NOT NULL,
+	reason VARCHAR(80) NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	deleted_at TIMESTAMPTZ,
+	version INT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS sign_in_attempts_user_id_idx ON sign_in_attempts (user_id, created_at DESC);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS disputes;
+
+ALTER TABLE IF EXISTS wallet_histories DROP COLUMN IF EXISTS note;
+ALTER TABLE IF EXISTS wallets DROP COLUMN IF EXISTS is_frozen;
+ALTER TABLE IF EXISTS users DROP COLUMN IF EXISTS role;
+
+DROP TYPE IF EXISTS DISPUTE_RESOLUTION_ENUM;
+DROP TYPE IF EXISTS DISPUTE_STATUS_ENUM;
+DROP TYPE IF EXISTS USER_ROLE_ENUM;
@@ -0,0 +1,30 @@
+CREATE TYPE USER_ROLE_ENUM AS ENUM ('user', 'support', 'admin');
+CREATE TYPE DISPUTE_STATUS_ENUM AS ENUM ('Open', 'Resolved');
+CREATE TYPE DISPUTE_RESOLUTION_ENUM AS ENUM ('refund_buyer', 'release_seller');
+
+ALTER TYPE WITHDRAWAL_TYPE_ENUM ADD VALUE IF NOT EXISTS 'Adjustment';
+ALTER TYPE TRANSACTION_STATUS_ENUM ADD VALUE IF NOT EXISTS 'Disputed';
+ALTER TYPE TRANSACTION_TIMELINE_NAME_ENUM ADD VALUE IF NOT EXISTS 'Dispute Opened';
+ALTER TYPE TRANSACTION_TIMELINE_NAME_ENUM ADD VALUE IF NOT EXISTS 'Dispute Resolved';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role USER_ROLE_ENUM NOT NULL DEFAULT 'user';
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS is_frozen BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE wallet_histories ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS disputes (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	transaction_id UUID REFERENCES transactions NOT NULL,
+	raised_by UUID REFERENCES users NOT NULL,
+	reason TEXT NOT NULL,
+	status DISPUTE_STATUS_ENUM NOT NULL DEFAULT 'Open',
+	resolution DISPUTE_RESOLUTION_ENUM,
+	resolution_note TEXT NOT NULL DEFAULT '',
+	resolved_by UUID REFERENCES users,
+	resolved_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	deleted_at TIMESTAMPTZ,
+	version INT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS disputes_transaction_id_idx ON disputes (transaction_id);
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	seq BIGSERIAL NOT NULL UNIQUE,
+	actor_id UUID,
+	actor_type VARCHAR(20) NOT NULL,
+	action VARCHAR(100) NOT NULL,
+	entity_type VARCHAR(50) NOT NULL,
+	entity_id VARCHAR(64) NOT NULL DEFAULT '',
+	changes JSONB NOT NULL DEFAULT '{}',
+	ip_address VARCHAR(45) NOT NULL DEFAULT '',
+	request_id VARCHAR(100) NOT NULL DEFAULT '',
+	prev_hash CHAR(64) NOT NULL,
+	hash CHAR(64) NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_actor_id_idx ON audit_logs (actor_id, seq DESC);
+CREATE INDEX IF NOT EXISTS audit_logs_entity_idx ON audit_logs (entity_type, entity_id, seq DESC);
+
+CREATE OR REPLACE FUNCTION prevent_audit_log_changes() RETURNS TRIGGER AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_no_update_or_delete
+	BEFORE UPDATE OR DELETE ON audit_logs
+	FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_changes();
+
+CREATE TRIGGER audit_logs_no_truncate
+	BEFORE TRUNCATE ON audit_logs
+	FOR EACH STATEMENT EXECUTE FUNCTION prevent_audit_log_changes();
@@ -0,0 +1,20 @@
+CREATE TYPE API_KEY_ENVIRONMENT_ENUM AS ENUM ('test', 'live');
+
+CREATE TABLE IF NOT EXISTS api_keys (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	business_id UUID REFERENCES businesses NOT NULL,
+	created_by UUID REFERENCES users NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	prefix VARCHAR(20) NOT NULL,
+	key_hash CHAR(64) UNIQUE NOT NULL,
+	environment API_KEY_ENVIRONMENT_ENUM NOT NULL,
+	scopes TEXT[] NOT NULL DEFAULT '{}',
+	last_used_at TIMESTAMPTZ,
+	revoked_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	deleted_at TIMESTAMPTZ,
+	version INT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_business_id_idx ON api_keys (business_id);
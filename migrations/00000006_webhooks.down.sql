@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
+DROP TYPE IF EXISTS WEBHOOK_DELIVERY_STATUS_ENUM;
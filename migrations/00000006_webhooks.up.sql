@@ -0,0 +1,39 @@
+CREATE TYPE WEBHOOK_DELIVERY_STATUS_ENUM AS ENUM ('pending', 'succeeded', 'failed');
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	business_id UUID REFERENCES businesses NOT NULL,
+	url TEXT NOT NULL,
+	secret VARCHAR(100) NOT NULL,
+	event_types TEXT[] NOT NULL DEFAULT '{}',
+	description VARCHAR(255) NOT NULL DEFAULT '',
+	is_active BOOLEAN NOT NULL DEFAULT true,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	deleted_at TIMESTAMPTZ,
+	version INT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS webhook_endpoints_business_id_idx ON webhook_endpoints (business_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	endpoint_id UUID REFERENCES webhook_endpoints ON DELETE CASCADE NOT NULL,
+	event_id UUID NOT NULL,
+	event_type VARCHAR(100) NOT NULL,
+	payload JSONB NOT NULL,
+	status WEBHOOK_DELIVERY_STATUS_ENUM NOT NULL DEFAULT 'pending',
+	attempts INT NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ,
+	last_attempt_at TIMESTAMPTZ,
+	response_status INT NOT NULL DEFAULT 0,
+	response_body TEXT NOT NULL DEFAULT '',
+	error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	deleted_at TIMESTAMPTZ,
+	version INT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_endpoint_id_idx ON webhook_deliveries (endpoint_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
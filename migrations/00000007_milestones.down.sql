@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS transaction_timelines DROP COLUMN IF EXISTS milestone_id;
+
+DROP TABLE IF EXISTS milestones;
+
+DROP TYPE IF EXISTS MILESTONE_STATUS_ENUM;
@@ -0,0 +1,28 @@
+CREATE TYPE MILESTONE_STATUS_ENUM AS ENUM ('Pending-Payment', 'Funded', 'Delivered', 'Released');
+
+ALTER TYPE TRANSACTION_TIMELINE_NAME_ENUM ADD VALUE IF NOT EXISTS 'Milestone Funded';
+ALTER TYPE TRANSACTION_TIMELINE_NAME_ENUM ADD VALUE IF NOT EXISTS 'Milestone Delivered';
+ALTER TYPE TRANSACTION_TIMELINE_NAME_ENUM ADD VALUE IF NOT EXISTS 'Milestone Released';
+
+CREATE TABLE IF NOT EXISTS milestones (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	transaction_id UUID REFERENCES transactions NOT NULL,
+	position INT NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	amount INT NOT NULL CHECK (amount > 0),
+	charges INT NOT NULL,
+	receivable_amount INT NOT NULL,
+	due_date TIMESTAMPTZ NOT NULL,
+	status MILESTONE_STATUS_ENUM NOT NULL DEFAULT 'Pending-Payment',
+	funded_at TIMESTAMPTZ,
+	delivered_at TIMESTAMPTZ,
+	released_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	deleted_at TIMESTAMPTZ,
+	version INT NOT NULL DEFAULT 1,
+	UNIQUE (transaction_id, position)
+);
+
+ALTER TABLE transaction_timelines ADD COLUMN IF NOT EXISTS milestone_id UUID REFERENCES milestones;
@@ -0,0 +1 @@
+-- postgres cannot drop values from an enum, the timeline names are left in place
@@ -0,0 +1,2 @@
+ALTER TYPE TRANSACTION_TIMELINE_NAME_ENUM ADD VALUE IF NOT EXISTS 'Items Accepted';
+ALTER TYPE TRANSACTION_TIMELINE_NAME_ENUM ADD VALUE IF NOT EXISTS 'Items Rejected';
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS businesses DROP COLUMN IF EXISTS tier;
+
+DROP TYPE IF EXISTS BUSINESS_TIER_ENUM;
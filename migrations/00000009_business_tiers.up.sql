@@ -0,0 +1,3 @@
+CREATE TYPE BUSINESS_TIER_ENUM AS ENUM ('standard', 'growth', 'enterprise');
+
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS tier BUSINESS_TIER_ENUM NOT NULL DEFAULT 'standard';
@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS wallets DROP CONSTRAINT IF EXISTS unique_identifier_currency;
+ALTER TABLE IF EXISTS wallets DROP COLUMN IF EXISTS currency;
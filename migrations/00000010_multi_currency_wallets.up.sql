@@ -0,0 +1,2 @@
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'NGN';
+ALTER TABLE wallets ADD CONSTRAINT unique_identifier_currency UNIQUE (identifier, currency);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fx_quotes;
+
+DROP TYPE IF EXISTS FX_QUOTE_STATUS_ENUM;
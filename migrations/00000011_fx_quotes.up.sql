@@ -0,0 +1,24 @@
+DROP TYPE IF EXISTS FX_QUOTE_STATUS_ENUM;
+CREATE TYPE FX_QUOTE_STATUS_ENUM AS ENUM ('Pending', 'Executed');
+
+ALTER TYPE WITHDRAWAL_TYPE_ENUM ADD VALUE IF NOT EXISTS 'Conversion';
+
+CREATE TABLE IF NOT EXISTS fx_quotes (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	identifier UUID NOT NULL,
+	account_type ACCOUNT_TYPE_ENUM NOT NULL,
+	from_currency VARCHAR(3) NOT NULL,
+	to_currency VARCHAR(3) NOT NULL,
+	amount INT NOT NULL CHECK (amount > 0),
+	converted_amount INT NOT NULL CHECK (converted_amount > 0),
+	mid_rate BIGINT NOT NULL,
+	rate BIGINT NOT NULL,
+	spread INT NOT NULL,
+	status FX_QUOTE_STATUS_ENUM NOT NULL DEFAULT 'Pending',
+	expires_at TIMESTAMPTZ NOT NULL,
+	executed_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	deleted_at TIMESTAMPTZ,
+	version INT NOT NULL DEFAULT 1
+);
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS wallets DROP CONSTRAINT IF EXISTS wallets_non_negative_balance;
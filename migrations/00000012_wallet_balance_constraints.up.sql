@@ -0,0 +1 @@
+ALTER TABLE wallets ADD CONSTRAINT wallets_non_negative_balance CHECK (balance >= 0 AND receivable_balance >= 0 AND payable_balance >= 0);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS transaction_invites;
+DROP TYPE IF EXISTS INVITE_STATUS_ENUM;
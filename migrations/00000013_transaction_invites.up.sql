@@ -0,0 +1,24 @@
+DROP TYPE IF EXISTS INVITE_STATUS_ENUM;
+CREATE TYPE INVITE_STATUS_ENUM AS ENUM ('Pending', 'Accepted');
+
+CREATE TABLE IF NOT EXISTS transaction_invites (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	transaction_id UUID REFERENCES transactions NOT NULL,
+	inviter_id UUID REFERENCES users NOT NULL,
+	role TRANSACTION_CREATED_BY_ENUM NOT NULL,
+	email VARCHAR(255),
+	phone_number VARCHAR(20),
+	placeholder_id UUID NOT NULL,
+	status INVITE_STATUS_ENUM NOT NULL DEFAULT 'Pending',
+	accepted_by UUID REFERENCES users,
+	expires_at TIMESTAMPTZ NOT NULL,
+	accepted_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	deleted_at TIMESTAMPTZ,
+	version INT NOT NULL DEFAULT 1,
+	CHECK (email IS NOT NULL OR phone_number IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS transaction_invites_email_idx ON transaction_invites (email) WHERE status = 'Pending';
+CREATE INDEX IF NOT EXISTS transaction_invites_phone_number_idx ON transaction_invites (phone_number) WHERE status = 'Pending';
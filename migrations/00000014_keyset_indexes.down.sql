@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS wallet_histories_wallet_id_created_at_id_idx;
+DROP INDEX IF EXISTS transactions_created_at_id_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS transactions_created_at_id_idx ON transactions (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS wallet_histories_wallet_id_created_at_id_idx ON wallet_histories (wallet_id, created_at DESC, id DESC);
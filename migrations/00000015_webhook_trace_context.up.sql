@@ -0,0 +1 @@
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS trace_context JSONB NOT NULL DEFAULT '{}';
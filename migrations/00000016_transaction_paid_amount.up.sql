@@ -0,0 +1,30 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS paid_amount INT NOT NULL DEFAULT 0;
+
+-- transactions paid before the column held their cost and the buyer's share of
+-- the charges, rounded the way ChargeConfiguration.Split rounds it. Those with
+-- milestones are paid milestone by milestone and keep 0.
+UPDATE transactions t
+SET paid_amount = t.total_cost + shares.buyer_fee
+FROM (
+	SELECT
+		id,
+		CASE
+			WHEN b + s = 0 THEN 0
+			WHEN charges - charges * b / (b + s) - charges * s / (b + s) > 0
+				AND charges * b % (b + s) > 0
+				AND charges * b % (b + s) >= charges * s % (b + s)
+				THEN charges * b / (b + s) + 1
+			ELSE charges * b / (b + s)
+		END AS buyer_fee
+	FROM (
+		SELECT
+			id,
+			charges::BIGINT AS charges,
+			(charge_configuration->>'BuyerCharges')::BIGINT AS b,
+			(charge_configuration->>'SellerCharges')::BIGINT AS s
+		FROM transactions
+	) configurations
+) shares
+WHERE shares.id = t.id
+	AND t.status IN ('Pending-Delivery', 'Disputed')
+	AND NOT EXISTS (SELECT 1 FROM milestones m WHERE m.transaction_id = t.id);
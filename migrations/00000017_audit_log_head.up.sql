@@ -0,0 +1,12 @@
+-- the hash of the last entry of the audit log chain, in a single row that
+-- appends lock and move along. A serializable transaction reads audit_logs
+-- from a snapshot taken before it could lock anything, so the head cannot be
+-- found by reading the last entry.
+CREATE TABLE IF NOT EXISTS audit_log_head (
+	id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+	hash CHAR(64) NOT NULL
+);
+
+INSERT INTO audit_log_head (hash)
+SELECT COALESCE((SELECT hash FROM audit_logs ORDER BY seq DESC LIMIT 1), repeat('0', 64))
+ON CONFLICT DO NOTHING;
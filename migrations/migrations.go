@@ -0,0 +1,354 @@
+// Package migrations holds the sql migrations of the db and applies them.
+// The migrations are embedded in the binary, so the app, the migrate command
+// and the tests all run the same schema.
+//
+// The applied version is kept in schema_migrations the way the migrate CLI
+// keeps it, so dbs migrated with the CLI carry on where they are.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+var (
+	ErrDirty          = errors.New("the db is dirty, a migration failed halfway and has to be fixed by hand")
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+// lockKey is the key of the advisory lock held while migrating, so migrations
+// started at the same time, say by several instances of the app, run one at a
+// time.
+const lockKey int64 = 7_306_428_917_112_001
+
+var fileRegex = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a version of the schema and the sql moving to and from it.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load reads the migrations in fsys, in ascending version order.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, file := range files {
+		match := fileRegex.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", file)
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", file, err)
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up file", m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []*Migration
+}
+
+// New returns a migrator of db running the embedded migrations.
+func New(db *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := Load(FS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the version of the last migration, 0 when there are none.
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies all the migrations not applied yet.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the last steps applied migrations, or all of them when steps
+// is 0.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *pgx.Conn) error {
+		current, err := m.current(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		target := uint(0)
+		if steps > 0 {
+			applied := []uint{}
+			for _, mig := range m.migrations {
+				if mig.Version <= current {
+					applied = append(applied, mig.Version)
+				}
+			}
+
+			if steps < len(applied) {
+				target = applied[len(applied)-steps-1]
+			}
+		}
+
+		return m.migrate(ctx, conn, current, target)
+	})
+}
+
+// To applies or reverts migrations until the db is at version. Version 0 is
+// the db before any migration.
+func (m *Migrator) To(ctx context.Context, version uint) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *pgx.Conn) error {
+		current, err := m.current(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+// Force records version as the version of the db without running anything,
+// clearing a dirty state once the db has been fixed by hand.
+func (m *Migrator) Force(ctx context.Context, version uint) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *pgx.Conn) error {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := setVersion(ctx, tx, version); err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	})
+}
+
+type MigrationStatus struct {
+	*Migration
+	Applied bool
+}
+
+type Status struct {
+	Version    uint // 0 when no migration is applied
+	Dirty      bool
+	Migrations []MigrationStatus
+}
+
+// Status returns the version of the db and which migrations are applied.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	s := &Status{}
+	err := m.withLock(ctx, func(conn *pgx.Conn) error {
+		var err error
+		s.Version, s.Dirty, err = readVersion(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mig := range m.migrations {
+		s.Migrations = append(s.Migrations, MigrationStatus{mig, mig.Version <= s.Version})
+	}
+
+	return s, nil
+}
+
+func (m *Migrator) find(version uint) *Migration {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig
+		}
+	}
+
+	return nil
+}
+
+// withLock runs fn on a connection holding the migration lock, once the
+// version table exists.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn.Conn())
+}
+
+// current returns the version of the db, failing when it is dirty.
+func (m *Migrator) current(ctx context.Context, conn *pgx.Conn) (uint, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	if dirty {
+		return 0, fmt.Errorf("%w: version %d", ErrDirty, version)
+	}
+
+	return version, nil
+}
+
+// migrate moves the db from version current to target, one migration at a
+// time. Each migration runs in a transaction with the update of the version,
+// so a failed one leaves the db at the version before it.
+func (m *Migrator) migrate(ctx context.Context, conn *pgx.Conn, current, target uint) error {
+	if target >= current {
+		for _, mig := range m.migrations {
+			if mig.Version <= current || mig.Version > target {
+				continue
+			}
+
+			if err := apply(ctx, conn, mig.Up, mig.Version); err != nil {
+				return fmt.Errorf("migrate up to %d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+
+		return nil
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.Version > current || mig.Version <= target {
+			continue
+		}
+
+		previous := uint(0)
+		if i > 0 {
+			previous = m.migrations[i-1].Version
+		}
+
+		if err := apply(ctx, conn, mig.Down, previous); err != nil {
+			return fmt.Errorf("migrate down from %d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func apply(ctx context.Context, conn *pgx.Conn, sql string, version uint) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// run as a simple query, which takes several statements
+	if _, err := tx.Exec(ctx, sql, pgx.QueryExecModeSimpleProtocol); err != nil {
+		return err
+	}
+
+	if err := setVersion(ctx, tx, version); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func readVersion(ctx context.Context, conn *pgx.Conn) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return uint(version), dirty, nil
+}
+
+func setVersion(ctx context.Context, tx pgx.Tx, version uint) error {
+	if _, err := tx.Exec(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, int64(version))
+	return err
+}
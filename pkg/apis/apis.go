@@ -6,12 +6,14 @@ type IAPIs interface {
 	GetPaystack() paystack.IPaystack
 }
 
-type apis struct{}
+type apis struct {
+	paystack paystack.IPaystack
+}
 
-func NewAPIs() *apis {
-	return &apis{}
+func NewAPIs(paystack paystack.IPaystack) *apis {
+	return &apis{paystack: paystack}
 }
 
 func (a *apis) GetPaystack() paystack.IPaystack {
-	return paystack.NewPaystackAPI()
+	return a.paystack
 }
@@ -0,0 +1,61 @@
+package paystack
+
+import (
+	"errors"
+
+	"github.com/princecee/escrow-api/pkg/money"
+)
+
+var ErrNoFeeSchedule = errors.New("card payments are not priced in this currency")
+
+// feeSchedule is what paystack keeps from a card payment in a currency, in
+// its minor unit. The flat fee is waived below flatFloor and a zero cap
+// leaves the fee uncapped.
+type feeSchedule struct {
+	rate      int64 // in basis points
+	flat      int64
+	flatFloor int64
+	cap       int64
+}
+
+var feeSchedules = map[string]feeSchedule{
+	// 1.5% plus ₦100, with the ₦100 waived under ₦2,500 and the total capped
+	// at ₦2,000
+	money.NGN: {rate: 150, flat: 10000, flatFloor: 250000, cap: 200000},
+	// 1.95% on local cards
+	money.GHS: {rate: 195},
+	// 2.9% on local cards
+	money.KES: {rate: 290},
+	// 3.9% on international cards
+	money.USD: {rate: 390},
+}
+
+// Fee is what paystack keeps from a card payment of amount, in the minor unit
+// of currency.
+func Fee(amount int, currency string) (int, error) {
+	schedule, ok := feeSchedules[currency]
+	if !ok {
+		return 0, ErrNoFeeSchedule
+	}
+
+	if amount <= 0 {
+		return 0, nil
+	}
+
+	fee, err := money.MulDiv(int64(amount), schedule.rate, 10000, money.RoundUp)
+	if err != nil {
+		if schedule.cap > 0 {
+			return int(schedule.cap), nil
+		}
+		return 0, err
+	}
+
+	if schedule.flat > 0 && amount >= int(schedule.flatFloor) {
+		fee += schedule.flat
+	}
+	if schedule.cap > 0 && fee > schedule.cap {
+		fee = schedule.cap
+	}
+
+	return int(fee), nil
+}
@@ -2,35 +2,61 @@ package paystack
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
-	"os"
+	"time"
+
+	"github.com/princecee/escrow-api/pkg/metrics"
+	"github.com/princecee/escrow-api/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type IPaystack interface {
-	InitiateTransaction(InitiateTransactionDto) (*InitiateTransactionResponse, error)
+	InitiateTransaction(context.Context, InitiateTransactionDto) (*InitiateTransactionResponse, error)
 }
 
-type paystack struct{}
+type paystack struct {
+	baseUrl   string
+	secretKey string
+}
 
-func NewPaystackAPI() *paystack {
-	return &paystack{}
+func NewPaystackAPI(baseUrl, secretKey string) *paystack {
+	return &paystack{baseUrl: baseUrl, secretKey: secretKey}
 }
 
-func sendRequest(method string, url string, body io.Reader) ([]byte, error) {
-	baseUrl := os.Getenv("PAYSTACK_BASE_URL")
+func (p *paystack) sendRequest(ctx context.Context, method string, url string, body io.Reader) (_ []byte, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "paystack "+method+" "+url,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(method), semconv.URLPath(url)),
+	)
+	defer func() { tracing.End(span, err) }()
 
-	req, _ := http.NewRequest(method, baseUrl+url, body)
-	req.Header.Add("Authorization", os.Getenv("PAYSTACK_SECRET_KEY"))
+	req, err := http.NewRequestWithContext(ctx, method, p.baseUrl+url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", p.secretKey)
 	client := &http.Client{}
 
+	start := time.Now()
 	response, err := client.Do(req)
 	if err != nil {
+		metrics.ObservePaystack(url, time.Since(start), true)
 		return nil, err
 	}
 	defer response.Body.Close()
 
+	metrics.ObservePaystack(url, time.Since(start), response.StatusCode >= http.StatusBadRequest)
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+	if response.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+	}
+
 	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
 		return nil, err
@@ -42,6 +68,7 @@ func sendRequest(method string, url string, body io.Reader) ([]byte, error) {
 type InitiateTransactionDto struct {
 	Email     string `json:"email"`
 	Amount    string `json:"amount"`
+	Currency  string `json:"currency,omitempty"`
 	Reference string `json:"reference"`
 	MetaData  any    `json:"metadata"`
 }
@@ -52,9 +79,9 @@ type InitiateTransactionResponse struct {
 	Reference        string
 }
 
-func (p *paystack) InitiateTransaction(data InitiateTransactionDto) (*InitiateTransactionResponse, error) {
+func (p *paystack) InitiateTransaction(ctx context.Context, data InitiateTransactionDto) (*InitiateTransactionResponse, error) {
 	body, _ := json.Marshal(data)
-	resp, err := sendRequest(http.MethodPost, "/transaction/initialize", bytes.NewBuffer(body))
+	resp, err := p.sendRequest(ctx, http.MethodPost, "/transaction/initialize", bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,205 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/utils"
+)
+
+const (
+	ErrRecordingMsg = "error recording audit log"
+)
+
+const (
+	EntityUser        = "user"
+	EntityWallet      = "wallet"
+	EntityTransaction = "transaction"
+	EntityBankAccount = "bank_account"
+	EntityDispute     = "dispute"
+	EntityAPIKey      = "api_key"
+	EntityWebhook     = "webhook_endpoint"
+	EntityMilestone   = "milestone"
+	EntityBusiness    = "business"
+)
+
+const (
+	ActionSignIn          = "auth.sign_in"
+	ActionSignInFailed    = "auth.sign_in_failed"
+	ActionSignInLocked    = "auth.sign_in_locked"
+	ActionSignUp          = "auth.sign_up"
+	ActionPasswordChanged = "auth.password_changed"
+
+	ActionTransactionStatusChanged  = "transaction.status_changed"
+	ActionTransactionInviteAccepted = "transaction.invite_accepted"
+
+	ActionWalletCredited = "wallet.credited"
+	ActionWalletDebited  = "wallet.debited"
+
+	ActionBankAccountAdded   = "bank_account.added"
+	ActionBankAccountDeleted = "bank_account.deleted"
+
+	ActionDisputeOpened = "dispute.opened"
+
+	ActionMilestoneFunded    = "milestone.funded"
+	ActionMilestoneDelivered = "milestone.delivered"
+	ActionMilestoneReleased  = "milestone.released"
+
+	ActionAPIKeyCreated = "api_key.created"
+	ActionAPIKeyRotated = "api_key.rotated"
+	ActionAPIKeyRevoked = "api_key.revoked"
+
+	ActionWebhookCreated = "webhook_endpoint.created"
+	ActionWebhookUpdated = "webhook_endpoint.updated"
+	ActionWebhookDeleted = "webhook_endpoint.deleted"
+
+	ActionAdminRoleChanged     = "admin.role_changed"
+	ActionAdminWalletFrozen    = "admin.wallet_frozen"
+	ActionAdminWalletUnfrozen  = "admin.wallet_unfrozen"
+	ActionAdminWalletAdjusted  = "admin.wallet_adjusted"
+	ActionAdminDisputeResolved = "admin.dispute_resolved"
+	ActionAdminTierChanged     = "admin.business_tier_changed"
+)
+
+// ignoredFields are bookkeeping columns and embedded relations that would only
+// add noise to a diff.
+var ignoredFields = map[string]bool{
+	"id":          true,
+	"created_at":  true,
+	"updated_at":  true,
+	"deleted_at":  true,
+	"version":     true,
+	"user":        true,
+	"business":    true,
+	"wallet":      true,
+	"buyer":       true,
+	"seller":      true,
+	"transaction": true,
+	"Timeline":    true,
+	"milestones":  true,
+}
+
+var redactedFields = map[string]bool{
+	"password":         true,
+	"password_history": true,
+	"bvn":              true,
+	"hash":             true,
+}
+
+const redacted = "[redacted]"
+
+// New returns an entry for action on the given entity, attributed to the user
+// or api key authenticated on r. Requests without either, such as provider
+// webhooks, are attributed to the system.
+func New(r *http.Request, action, entityType, entityId string) *models.AuditLog {
+	l := FromContext(r.Context(), action, entityType, entityId)
+	l.IPAddress = utils.GetIPAddress(r)
+
+	return l
+}
+
+// FromContext is New for code that only has the context of the request, like
+// the services. The ip address is the one ClientIPMiddleware put in ctx.
+func FromContext(ctx context.Context, action, entityType, entityId string) *models.AuditLog {
+	l := &models.AuditLog{
+		ActorType:  models.AuditActorSystem,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityId,
+		RequestID:  middleware.GetReqID(ctx),
+	}
+
+	if ip, ok := ctx.Value(utils.IPAddressContextKey{}).(string); ok {
+		l.IPAddress = ip
+	}
+
+	if apiKey, ok := ctx.Value(utils.APIKeyContextKey{}).(*models.APIKey); ok {
+		l.ActorID = &apiKey.ID
+		l.ActorType = models.AuditActorAPIKey
+	} else if user, ok := ctx.Value(utils.ContextKey{}).(*models.User); ok {
+		l.ActorID = &user.ID
+		l.ActorType = models.AuditActorUser
+	}
+
+	return l
+}
+
+// WithChanges sets the changes of l to the diff between before and after.
+// Either side may be nil for creations and deletions.
+func WithChanges(l *models.AuditLog, before, after any) *models.AuditLog {
+	l.Changes = Diff(before, after)
+	return l
+}
+
+// Diff compares the json representation of two models and returns the fields
+// that differ.
+func Diff(before, after any) map[string]models.AuditChange {
+	b := toMap(before)
+	a := toMap(after)
+
+	changes := map[string]models.AuditChange{}
+	for k, v := range b {
+		if ignoredFields[k] || reflect.DeepEqual(v, a[k]) {
+			continue
+		}
+
+		changes[k] = change(k, v, a[k])
+	}
+
+	for k, v := range a {
+		if _, ok := b[k]; ok || ignoredFields[k] || v == nil {
+			continue
+		}
+
+		changes[k] = change(k, nil, v)
+	}
+
+	return changes
+}
+
+func change(field string, from, to any) models.AuditChange {
+	if redactedFields[field] {
+		if from != nil {
+			from = redacted
+		}
+		if to != nil {
+			to = redacted
+		}
+	}
+
+	return models.AuditChange{From: from, To: to}
+}
+
+func toMap(v any) map[string]any {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return map[string]any{}
+	}
+
+	if m, ok := v.(map[string]any); ok {
+		return m
+	}
+
+	m, err := utils.StructToMap(v)
+	if err != nil {
+		return map[string]any{}
+	}
+
+	return m
+}
+
+// Verify checks that logs continue the chain ending in prevHash. It returns
+// the hash to continue from and the first entry that does not match, if any.
+func Verify(prevHash string, logs []*models.AuditLog) (string, *models.AuditLog) {
+	for _, l := range logs {
+		if l.PrevHash != prevHash || l.ComputeHash() != l.Hash {
+			return prevHash, l
+		}
+
+		prevHash = l.Hash
+	}
+
+	return prevHash, nil
+}
@@ -0,0 +1,237 @@
+package fees
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/money"
+)
+
+var (
+	ErrNoSchedule    = errors.New("no fee schedule applies to this transaction")
+	ErrInvalidSplit  = errors.New("buyer and seller charges must add up to 100")
+	ErrInvalidAmount = errors.New("amount cannot be negative")
+)
+
+// Band charges Rate on the part of an amount that falls inside it. Bands are
+// consecutive, each starting where the previous one ends.
+type Band struct {
+	UpTo int `json:"up_to"` // upper bound of the band, 0 for the last unbounded band
+	Rate int `json:"rate"`  // in basis points
+}
+
+// Schedule describes how the charges of a transaction are computed. The
+// match fields left empty apply to any value, and the engine picks the
+// schedule matching the most fields.
+type Schedule struct {
+	TransactionType string `json:"transaction_type"`
+	Currency        string `json:"currency"`
+	Tier            string `json:"tier"`
+	Rate            int    `json:"rate"` // in basis points, 300 is 3%
+	Flat            int    `json:"flat"` // added on top of the rate
+	Min             int    `json:"min"`
+	Max             int    `json:"max"`   // 0 for no cap
+	Bands           []Band `json:"bands"` // replaces Rate when set
+}
+
+// DefaultSchedules charge a flat 3% on everything.
+var DefaultSchedules = []Schedule{{Rate: 300}}
+
+func (s Schedule) matches(transactionType, currency, tier string) bool {
+	return (s.TransactionType == "" || s.TransactionType == transactionType) &&
+		(s.Currency == "" || s.Currency == currency) &&
+		(s.Tier == "" || s.Tier == tier)
+}
+
+func (s Schedule) specificity() int {
+	n := 0
+	for _, v := range []string{s.TransactionType, s.Currency, s.Tier} {
+		if v != "" {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (s Schedule) validate() error {
+	if s.Rate < 0 || s.Flat < 0 || s.Min < 0 || s.Max < 0 {
+		return errors.New("fee schedule values cannot be negative")
+	}
+
+	if s.Max > 0 && s.Max < s.Min {
+		return errors.New("fee schedule max cannot be lower than its min")
+	}
+
+	for i, b := range s.Bands {
+		if b.Rate < 0 {
+			return errors.New("fee band rates cannot be negative")
+		}
+
+		last := i == len(s.Bands)-1
+		if b.UpTo == 0 && !last {
+			return errors.New("only the last fee band can be unbounded")
+		}
+
+		if i > 0 && b.UpTo != 0 && b.UpTo <= s.Bands[i-1].UpTo {
+			return errors.New("fee bands must be in increasing order")
+		}
+	}
+
+	return nil
+}
+
+// Fee returns the charges on amount. Rates are rounded up to the next unit.
+func (s Schedule) Fee(amount int) (int, error) {
+	var fee int64
+	if len(s.Bands) > 0 {
+		lower := 0
+		for _, b := range s.Bands {
+			upper := b.UpTo
+			if upper == 0 || upper > amount {
+				upper = amount
+			}
+
+			if upper > lower {
+				v, err := money.MulDiv(int64(upper-lower), int64(b.Rate), 10000, money.RoundUp)
+				if err != nil {
+					return 0, err
+				}
+
+				if fee, err = money.Add(fee, v); err != nil {
+					return 0, err
+				}
+			}
+
+			lower = upper
+			if lower >= amount {
+				break
+			}
+		}
+	} else {
+		v, err := money.MulDiv(int64(amount), int64(s.Rate), 10000, money.RoundUp)
+		if err != nil {
+			return 0, err
+		}
+
+		fee = v
+	}
+
+	fee, err := money.Add(fee, int64(s.Flat))
+	if err != nil {
+		return 0, err
+	}
+
+	if fee < int64(s.Min) {
+		fee = int64(s.Min)
+	}
+	if s.Max > 0 && fee > int64(s.Max) {
+		fee = int64(s.Max)
+	}
+
+	return int(fee), nil
+}
+
+// Engine picks the fee schedule of a transaction and prices it.
+type Engine struct {
+	schedules []Schedule
+}
+
+func NewEngine(schedules []Schedule) (*Engine, error) {
+	for i, s := range schedules {
+		if err := s.validate(); err != nil {
+			return nil, fmt.Errorf("fee schedule %d: %w", i, err)
+		}
+	}
+
+	return &Engine{schedules: schedules}, nil
+}
+
+// LoadSchedules reads a json array of schedules from path.
+func LoadSchedules(path string) ([]Schedule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := []Schedule{}
+	if err := json.Unmarshal(b, &schedules); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// Schedule returns the most specific schedule for a transaction. The first
+// one listed wins a tie.
+func (e *Engine) Schedule(transactionType, currency, tier string) (Schedule, error) {
+	best := -1
+	for i, s := range e.schedules {
+		if !s.matches(transactionType, currency, tier) {
+			continue
+		}
+
+		if best == -1 || s.specificity() > e.schedules[best].specificity() {
+			best = i
+		}
+	}
+
+	if best == -1 {
+		return Schedule{}, ErrNoSchedule
+	}
+
+	return e.schedules[best], nil
+}
+
+type Params struct {
+	TransactionType     string
+	Currency            string
+	Tier                string // tier of the seller's business
+	Amount              int
+	ChargeConfiguration models.ChargeConfiguration
+}
+
+// Quote is the price of an amount once charges are added and split.
+type Quote struct {
+	Amount           int `json:"amount"`
+	Charges          int `json:"charges"`
+	BuyerFee         int `json:"buyer_fee"`
+	SellerFee        int `json:"seller_fee"`
+	BuyerPayable     int `json:"buyer_payable"`
+	SellerReceivable int `json:"seller_receivable"`
+}
+
+func (e *Engine) Quote(p Params) (*Quote, error) {
+	if p.Amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	cc := p.ChargeConfiguration
+	if cc.BuyerCharges < 0 || cc.SellerCharges < 0 || cc.BuyerCharges+cc.SellerCharges != 100 {
+		return nil, ErrInvalidSplit
+	}
+
+	schedule, err := e.Schedule(p.TransactionType, p.Currency, p.Tier)
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := schedule.Fee(p.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	buyerFee, sellerFee := cc.Split(charges)
+
+	return &Quote{
+		Amount:           p.Amount,
+		Charges:          charges,
+		BuyerFee:         buyerFee,
+		SellerFee:        sellerFee,
+		BuyerPayable:     p.Amount + buyerFee,
+		SellerReceivable: p.Amount - sellerFee,
+	}, nil
+}
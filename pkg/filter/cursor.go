@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of a row in keyset order, newest first. Pages fetched
+// by cursor are not shifted by rows added or removed before them the way
+// offset pages are, and don't have to skip over the rows before them.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+	Backward  bool      `json:"b,omitempty"` // the page wanted is the one before the row
+}
+
+// Encode returns c as the opaque token handed to clients, signed with key so
+// clients can not craft cursors of their own. A nil cursor encodes to an
+// empty token.
+func (c *Cursor) Encode(key []byte) string {
+	if c == nil {
+		return ""
+	}
+
+	data, _ := json.Marshal(c)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+
+	return payload + "." + computeSignature(key, payload)
+}
+
+// DecodeCursor checks the signature of a token returned by Encode against key
+// and returns its cursor. An empty token is the first page and returns nil.
+func DecodeCursor(key []byte, token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(computeSignature(key, payload)), []byte(signature)) {
+		return nil, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &Cursor{}
+	if err := json.Unmarshal(data, c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+func computeSignature(key []byte, payload string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte("cursor."))
+	h.Write([]byte(payload))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cursors are the cursors of the pages next to a page fetched by cursor. A
+// cursor is nil when there is no page on that side.
+type Cursors struct {
+	Next *Cursor
+	Prev *Cursor
+}
+
+// SeekPage trims the rows GetMany returned for a filter paged with Seek to
+// the page asked for, in newest first order, and returns the cursors of the
+// pages around it. key returns the created_at and id of a row.
+func SeekPage[T any](f *Filter, rows []T, key func(T) (time.Time, string)) ([]T, *Cursors) {
+	cursors := &Cursors{}
+	if f == nil || f.seek == nil {
+		return rows, cursors
+	}
+
+	s := f.seek
+	more := len(rows) > s.limit
+	if more {
+		rows = rows[:s.limit]
+	}
+
+	backward := s.cursor != nil && s.cursor.Backward
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	if len(rows) == 0 {
+		return rows, cursors
+	}
+
+	cursorOf := func(row T, backward bool) *Cursor {
+		createdAt, id := key(row)
+		return &Cursor{CreatedAt: createdAt, ID: id, Backward: backward}
+	}
+
+	if more || backward {
+		cursors.Next = cursorOf(rows[len(rows)-1], false)
+	}
+	if s.cursor != nil && (more || !backward) {
+		cursors.Prev = cursorOf(rows[0], true)
+	}
+
+	return rows, cursors
+}
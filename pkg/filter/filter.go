@@ -0,0 +1,360 @@
+// Package filter builds the WHERE, ORDER BY and paging clauses of list
+// queries. Values are only ever bound as parameters and columns are looked up
+// in the Columns of the repository, so nothing taken from a request is written
+// into the sql.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownColumn = errors.New("unknown column")
+	ErrSortedCursor  = errors.New("a cursor can not be combined with a sort")
+)
+
+// Columns maps the names a filter refers to columns by to their sql
+// expressions. Only these can be filtered and sorted on.
+type Columns map[string]string
+
+// Condition is one clause of a filter. Conditions on a nil or empty value are
+// left out, so optional query parameters can be passed as they are.
+type Condition interface {
+	build(b *builder) (string, error)
+}
+
+type comparison struct {
+	column string
+	op     string
+	value  any
+}
+
+// Eq matches rows where column equals value.
+func Eq(column string, value any) Condition {
+	return comparison{column, "=", value}
+}
+
+// Ne matches rows where column differs from value.
+func Ne(column string, value any) Condition {
+	return comparison{column, "<>", value}
+}
+
+// Gte matches rows where column is at least value.
+func Gte(column string, value any) Condition {
+	return comparison{column, ">=", value}
+}
+
+// Lte matches rows where column is at most value.
+func Lte(column string, value any) Condition {
+	return comparison{column, "<=", value}
+}
+
+// Range matches rows where column is between from and to, both included.
+// Either bound may be nil to leave that side open.
+func Range(column string, from, to any) Condition {
+	return And(Gte(column, from), Lte(column, to))
+}
+
+// ILike matches rows where column contains value, ignoring case.
+func ILike(column, value string) Condition {
+	if value == "" {
+		return comparison{column, "ILIKE", nil}
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	return comparison{column, "ILIKE", "%" + escaped + "%"}
+}
+
+func (c comparison) build(b *builder) (string, error) {
+	if isEmpty(c.value) {
+		return "", nil
+	}
+
+	column, err := b.column(c.column)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s %s %s", column, c.op, b.bind(c.value)), nil
+}
+
+type in struct {
+	column string
+	values []any
+}
+
+// In matches rows where column is one of values.
+func In(column string, values ...any) Condition {
+	return in{column, values}
+}
+
+func (c in) build(b *builder) (string, error) {
+	if len(c.values) == 0 {
+		return "", nil
+	}
+
+	column, err := b.column(c.column)
+	if err != nil {
+		return "", err
+	}
+
+	params := make([]string, len(c.values))
+	for i, v := range c.values {
+		params[i] = b.bind(v)
+	}
+
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(params, ", ")), nil
+}
+
+type group struct {
+	op    string
+	conds []Condition
+}
+
+// And matches rows matching all of conds.
+func And(conds ...Condition) Condition {
+	return group{"AND", conds}
+}
+
+// Or matches rows matching any of conds.
+func Or(conds ...Condition) Condition {
+	return group{"OR", conds}
+}
+
+func (g group) build(b *builder) (string, error) {
+	clauses := []string{}
+	for _, c := range g.conds {
+		clause, err := c.build(b)
+		if err != nil {
+			return "", err
+		}
+
+		if clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+
+	switch len(clauses) {
+	case 0:
+		return "", nil
+	case 1:
+		return clauses[0], nil
+	default:
+		return "(" + strings.Join(clauses, " "+g.op+" ") + ")", nil
+	}
+}
+
+// Filter is the conditions, order and page of a list query. The zero value
+// and nil match everything.
+type Filter struct {
+	conds  []Condition
+	sorts  []string
+	offset int
+	limit  int
+	seek   *seek
+}
+
+type seek struct {
+	cursor *Cursor
+	limit  int
+}
+
+func New(conds ...Condition) *Filter {
+	return &Filter{conds: conds}
+}
+
+// Where adds conds to f. Rows have to match all the conditions of a filter.
+func (f *Filter) Where(conds ...Condition) *Filter {
+	f.conds = append(f.conds, conds...)
+	return f
+}
+
+// Sort orders the rows by fields, each a column name optionally prefixed by
+// "-" for descending order. Empty fields are skipped, so a comma separated
+// sort parameter can be split and passed as it is.
+func (f *Filter) Sort(fields ...string) *Filter {
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			f.sorts = append(f.sorts, field)
+		}
+	}
+
+	return f
+}
+
+// Paginate limits the rows to limit of them after skipping offset.
+func (f *Filter) Paginate(offset, limit int) *Filter {
+	f.offset = offset
+	f.limit = limit
+	return f
+}
+
+// Seek pages by keyset instead of offset, fetching the limit rows after
+// cursor in newest first order, or before it for a backward cursor. A nil
+// cursor is the first page. One row over limit is fetched to tell whether
+// there is a next page; SeekPage trims it off.
+func (f *Filter) Seek(cursor *Cursor, limit int) *Filter {
+	f.seek = &seek{cursor, limit}
+	return f
+}
+
+// Unpaged returns a copy of f with its conditions alone, for counting the rows
+// it matches across all pages.
+func (f *Filter) Unpaged() *Filter {
+	if f == nil {
+		return nil
+	}
+
+	return &Filter{conds: f.conds}
+}
+
+// SQL is a built filter. Its clauses are empty when there is nothing to put in
+// them, so they can be written into a query as they are.
+type SQL struct {
+	Where   string // WHERE clause
+	OrderBy string // ORDER BY clause
+	Page    string // OFFSET and LIMIT clauses
+	Args    []any  // parameters of Where then Page
+}
+
+// Build returns the clauses of f on columns. defaultSort is used when f has
+// no sort of its own. Conditions and sorts on a column not in columns fail
+// with ErrUnknownColumn. A filter paged with Seek is ordered by created_at and
+// id, which columns must have, and fails with ErrSortedCursor if sorted.
+func (f *Filter) Build(columns Columns, defaultSort ...string) (*SQL, error) {
+	if f == nil {
+		f = &Filter{}
+	}
+
+	b := &builder{columns: columns}
+	s := &SQL{}
+
+	where, err := And(f.conds...).build(b)
+	if err != nil {
+		return nil, err
+	}
+	if where != "" {
+		s.Where = "WHERE " + where
+	}
+
+	sorts := f.sorts
+	if len(sorts) == 0 {
+		sorts = defaultSort
+	}
+
+	if f.seek != nil {
+		return f.buildSeek(b, s)
+	}
+
+	orderBy := []string{}
+	for _, field := range sorts {
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			field = field[1:]
+			direction = "DESC"
+		}
+
+		column, err := b.column(field)
+		if err != nil {
+			return nil, err
+		}
+
+		orderBy = append(orderBy, column+" "+direction)
+	}
+	if len(orderBy) > 0 {
+		s.OrderBy = "ORDER BY " + strings.Join(orderBy, ", ")
+	}
+
+	if f.limit > 0 {
+		s.Page = fmt.Sprintf("OFFSET %s LIMIT %s", b.bind(f.offset), b.bind(f.limit))
+	}
+
+	s.Args = b.args
+	return s, nil
+}
+
+// buildSeek adds the keyset clauses of f to s.
+func (f *Filter) buildSeek(b *builder, s *SQL) (*SQL, error) {
+	if len(f.sorts) > 0 {
+		return nil, ErrSortedCursor
+	}
+
+	createdAt, err := b.column("created_at")
+	if err != nil {
+		return nil, err
+	}
+	id, err := b.column("id")
+	if err != nil {
+		return nil, err
+	}
+
+	op, direction := "<", "DESC"
+	if c := f.seek.cursor; c != nil && c.Backward {
+		op, direction = ">", "ASC"
+	}
+
+	if c := f.seek.cursor; c != nil {
+		clause := fmt.Sprintf("(%s, %s) %s (%s, %s)", createdAt, id, op, b.bind(c.CreatedAt), b.bind(c.ID))
+		if s.Where == "" {
+			s.Where = "WHERE " + clause
+		} else {
+			s.Where += " AND " + clause
+		}
+	}
+
+	s.OrderBy = fmt.Sprintf("ORDER BY %s %s, %s %s", createdAt, direction, id, direction)
+	s.Page = "LIMIT " + b.bind(f.seek.limit+1)
+	s.Args = b.args
+
+	return s, nil
+}
+
+type builder struct {
+	columns Columns
+	args    []any
+}
+
+func (b *builder) column(name string) (string, error) {
+	column, ok := b.columns[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+	}
+
+	return column, nil
+}
+
+// bind adds v to the parameters and returns its placeholder.
+func (b *builder) bind(v any) string {
+	b.args = append(b.args, deref(v))
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func isEmpty(v any) bool {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return true
+	}
+
+	switch v := deref(v).(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case time.Time:
+		return v.IsZero()
+	}
+
+	return false
+}
+
+func deref(v any) any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		return rv.Elem().Interface()
+	}
+
+	return v
+}
@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// ParseTime parses a bound of a date range given in a query string, either a
+// date or a time in RFC 3339. A date given as the end of a range stands for
+// the whole day. An empty string is a missing bound and returns nil.
+func ParseTime(s string, end bool) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return &t, nil
+	}
+
+	t, err = time.Parse(dateLayout, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", s)
+	}
+
+	if end {
+		t = t.Add(24*time.Hour - time.Microsecond)
+	}
+
+	return &t, nil
+}
+
+// ParseInt parses a bound of an amount range given in a query string. An
+// empty string is a missing bound and returns nil.
+func ParseInt(s string) (*int, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+
+	return &v, nil
+}
+
+// Ranges are the date and amount bounds list endpoints take in their query
+// string as created_from, created_to, min_amount and max_amount.
+type Ranges struct {
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MinAmount   *int
+	MaxAmount   *int
+}
+
+// ParseRanges parses the range parameters of query. Missing ones are left nil.
+func ParseRanges(query url.Values) (*Ranges, error) {
+	var (
+		r   Ranges
+		err error
+	)
+
+	if r.CreatedFrom, err = ParseTime(query.Get("created_from"), false); err != nil {
+		return nil, err
+	}
+	if r.CreatedTo, err = ParseTime(query.Get("created_to"), true); err != nil {
+		return nil, err
+	}
+	if r.MinAmount, err = ParseInt(query.Get("min_amount")); err != nil {
+		return nil, err
+	}
+	if r.MaxAmount, err = ParseInt(query.Get("max_amount")); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,226 @@
+package fx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/princecee/escrow-api/pkg/money"
+)
+
+var (
+	ErrNoRate        = errors.New("no exchange rate for this currency pair")
+	ErrSameCurrency  = errors.New("cannot convert a currency to itself")
+	ErrInvalidAmount = errors.New("amount must be positive")
+	ErrAmountTooLow  = errors.New("amount is too low to convert")
+	ErrQuoteExpired  = errors.New("quote has expired")
+)
+
+// RateScale is the number of units in 1 of a Rate. Rates are fixed point
+// with eight decimal places.
+const RateScale = 100_000_000
+
+// Rate is the number of units of one currency bought by one unit of another,
+// multiplied by RateScale.
+type Rate int64
+
+func RateFromFloat(f float64) Rate {
+	return Rate(math.Round(f * RateScale))
+}
+
+func (r Rate) String() string {
+	return fmt.Sprintf("%d.%08d", r/RateScale, r%RateScale)
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// Convert returns m in the currency to bought at r, rounded down.
+func (r Rate) Convert(m money.Money, to string) (money.Money, error) {
+	amount, err := money.MulDiv(m.Amount, int64(r), RateScale, money.RoundDown)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	if amount > math.MaxInt32 {
+		return money.Money{}, errors.New("converted amount is too large")
+	}
+
+	return money.New(amount, to), nil
+}
+
+// mul returns r*o, the rate of a conversion through a third currency.
+func (r Rate) mul(o Rate) (Rate, error) {
+	v, err := money.MulDiv(int64(r), int64(o), RateScale, money.RoundDown)
+	return Rate(v), err
+}
+
+func (r Rate) inverse() Rate {
+	return Rate(RateScale * RateScale / int64(r))
+}
+
+// RateProvider returns the mid market rate of a currency pair.
+type RateProvider interface {
+	Rate(from, to string) (Rate, error)
+}
+
+// DefaultRates are indicative rates to run the app locally.
+var DefaultRates = map[string]float64{
+	"USD/NGN": 1500,
+	"USD/GHS": 15,
+	"USD/KES": 130,
+}
+
+// StaticProvider serves a fixed set of rates keyed by pair, like "USD/NGN".
+// A missing pair is derived from its inverse or through a currency shared
+// by two listed pairs.
+type StaticProvider struct {
+	rates map[string]Rate
+}
+
+func NewStaticProvider(rates map[string]float64) (*StaticProvider, error) {
+	p := &StaticProvider{rates: map[string]Rate{}}
+	for pair, f := range rates {
+		from, to, ok := strings.Cut(pair, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid currency pair %q", pair)
+		}
+
+		if !money.IsSupported(from) || !money.IsSupported(to) {
+			return nil, fmt.Errorf("currency pair %q: %w", pair, money.ErrUnsupportedCurrency)
+		}
+
+		r := RateFromFloat(f)
+		if r <= 0 {
+			return nil, fmt.Errorf("currency pair %q: rate must be positive", pair)
+		}
+
+		p.rates[pair] = r
+	}
+
+	return p, nil
+}
+
+// LoadStaticProvider reads a json object of rates keyed by pair from path.
+func LoadStaticProvider(path string) (*StaticProvider, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := map[string]float64{}
+	if err := json.Unmarshal(b, &rates); err != nil {
+		return nil, err
+	}
+
+	return NewStaticProvider(rates)
+}
+
+func (p *StaticProvider) direct(from, to string) (Rate, bool) {
+	if r, ok := p.rates[from+"/"+to]; ok {
+		return r, true
+	}
+
+	if r, ok := p.rates[to+"/"+from]; ok {
+		return r.inverse(), true
+	}
+
+	return 0, false
+}
+
+func (p *StaticProvider) Rate(from, to string) (Rate, error) {
+	if from == to {
+		return 0, ErrSameCurrency
+	}
+
+	if r, ok := p.direct(from, to); ok {
+		return r, nil
+	}
+
+	for _, via := range money.Currencies {
+		if via == from || via == to {
+			continue
+		}
+
+		first, ok := p.direct(from, via)
+		if !ok {
+			continue
+		}
+
+		if second, ok := p.direct(via, to); ok {
+			return first.mul(second)
+		}
+	}
+
+	return 0, ErrNoRate
+}
+
+// Quote is an offer to convert From into To at Rate until ExpiresAt. Rate is
+// the mid market rate less the spread.
+type Quote struct {
+	From      money.Money
+	To        money.Money
+	MidRate   Rate
+	Rate      Rate
+	Spread    int // in basis points
+	ExpiresAt time.Time
+}
+
+// Engine prices conversions off the rates of its provider.
+type Engine struct {
+	provider RateProvider
+	spread   int
+	ttl      time.Duration
+}
+
+func NewEngine(provider RateProvider, spread int, ttl time.Duration) (*Engine, error) {
+	if spread < 0 || spread >= 10000 {
+		return nil, errors.New("fx spread must be between 0 and 9999 basis points")
+	}
+
+	if ttl <= 0 {
+		return nil, errors.New("fx quote ttl must be positive")
+	}
+
+	return &Engine{provider: provider, spread: spread, ttl: ttl}, nil
+}
+
+func (e *Engine) Quote(from money.Money, to string) (*Quote, error) {
+	if from.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	mid, err := e.provider.Rate(from.Currency, to)
+	if err != nil {
+		return nil, err
+	}
+
+	less, err := money.MulDiv(int64(mid), int64(10000-e.spread), 10000, money.RoundDown)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := Rate(less)
+	converted, err := rate.Convert(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if converted.Amount == 0 {
+		return nil, ErrAmountTooLow
+	}
+
+	return &Quote{
+		From:      from,
+		To:        converted,
+		MidRate:   mid,
+		Rate:      rate,
+		Spread:    e.spread,
+		ExpiresAt: time.Now().UTC().Add(e.ttl),
+	}, nil
+}
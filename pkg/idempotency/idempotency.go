@@ -0,0 +1,187 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on responses served from a stored record.
+const ReplayedHeader = "Idempotent-Replayed"
+
+const MaxKeyLength = 255
+
+type Options struct {
+	TTL     time.Duration // how long a completed response is kept for replays
+	LockTTL time.Duration // how long a request in progress holds its key without a refresh
+}
+
+var DefaultOptions = Options{
+	TTL:     24 * time.Hour,
+	LockTTL: 1 * time.Minute,
+}
+
+var (
+	ErrKeyReused  = errors.New("idempotency key was already used with a different request")
+	ErrInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+// Record is what is kept for a key. It is saved without a response when the
+// request starts and completed with the response once it is handled.
+type Record struct {
+	RequestHash string `json:"request_hash"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Store keeps idempotency records in redis. Keys are namespaced by owner so
+// two clients cannot collide or read each other's responses.
+type Store struct {
+	rdb  redis.Cmdable
+	opts Options
+}
+
+func NewStore(rdb redis.Cmdable, opts ...Options) *Store {
+	o := DefaultOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	return &Store{rdb: rdb, opts: o}
+}
+
+func recordKey(owner, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", owner, key)
+}
+
+// HashRequest identifies a request by its method, path and body.
+func HashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin claims key for a request with the given hash. It returns a nil record
+// when the request is new and should be handled, and the completed record
+// when it should be replayed instead. ErrKeyReused and ErrInProgress are
+// returned when the key belongs to another request or is still being handled.
+func (s *Store) Begin(ctx context.Context, owner, key, requestHash string) (*Record, error) {
+	data, err := json.Marshal(Record{RequestHash: requestHash})
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.rdb.SetNX(ctx, recordKey(owner, key), data, s.opts.LockTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		return nil, nil
+	}
+
+	stored, err := s.rdb.Get(ctx, recordKey(owner, key)).Bytes()
+	if err != nil {
+		// the lock expired between both calls, so the caller can simply retry
+		if err == redis.Nil {
+			return nil, ErrInProgress
+		}
+
+		return nil, err
+	}
+
+	record := new(Record)
+	if err := json.Unmarshal(stored, record); err != nil {
+		return nil, err
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, ErrKeyReused
+	}
+
+	if !record.Completed {
+		return nil, ErrInProgress
+	}
+
+	return record, nil
+}
+
+// holdScript extends the lock of a key only while it still holds the record
+// of the request in progress, so a completed record keeps its TTL.
+var holdScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Hold keeps the key claimed by Begin locked for as long as the request is
+// handled, refreshing the lock before it expires so a retry can not run the
+// request a second time alongside a slow first one. Call the returned
+// function once the request is handled, before Complete or Release. It can
+// be called more than once.
+func (s *Store) Hold(owner, key, requestHash string) (stop func()) {
+	data, _ := json.Marshal(Record{RequestHash: requestHash})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(s.opts.LockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// a failed refresh is retried on the next tick, the lock still
+				// has two thirds of its TTL left
+				_ = holdScript.Run(ctx, s.rdb, []string{recordKey(owner, key)}, data, s.opts.LockTTL.Milliseconds()).Err()
+			}
+		}
+	}()
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// Complete stores the response of the request that claimed key.
+func (s *Store) Complete(ctx context.Context, owner, key string, record *Record) error {
+	record.Completed = true
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return s.rdb.Set(ctx, recordKey(owner, key), data, s.opts.TTL).Err()
+}
+
+// Release frees key so the request can be retried with it, for instance after
+// a server error rolled back its changes.
+func (s *Store) Release(ctx context.Context, owner, key string) error {
+	return s.rdb.Del(ctx, recordKey(owner, key)).Err()
+}
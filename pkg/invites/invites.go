@@ -0,0 +1,213 @@
+// Package invites brings counterparties without an account into
+// transactions. A transaction made for an email or phone number points at a
+// placeholder until the invitee signs up and the invite attaches it to their
+// real account.
+package invites
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+)
+
+var (
+	ErrInvalidToken      = errors.New("invalid invite token")
+	ErrExpired           = errors.New("invite has expired")
+	ErrAccepted          = errors.New("invite has already been accepted")
+	ErrOwnInvite         = errors.New("you cannot accept your own invite")
+	ErrSellerNotBusiness = errors.New("only business accounts can join a transaction as the seller")
+	ErrNotInvitee        = errors.New("this invite was sent to another email or phone number")
+)
+
+// placeholderDomain is never delivered to, so a placeholder can not clash
+// with an address someone signs up with.
+const placeholderDomain = "invites.invalid"
+
+// Sign returns the token of the invite link of id, valid until expiresAt,
+// signed with key.
+func Sign(key []byte, id string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return fmt.Sprintf("%s.%s.%s", id, expires, computeSignature(key, id, expires))
+}
+
+// Verify checks the signature and expiry of token against key and returns
+// the invite id it was signed for.
+func Verify(key []byte, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidToken
+	}
+
+	id, expires, signature := parts[0], parts[1], parts[2]
+	if !hmac.Equal([]byte(computeSignature(key, id, expires)), []byte(signature)) {
+		return "", ErrInvalidToken
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	if time.Now().After(time.Unix(unix, 0)) {
+		return "", ErrExpired
+	}
+
+	return id, nil
+}
+
+func computeSignature(key []byte, id, expires string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(id))
+	h.Write([]byte("."))
+	h.Write([]byte(expires))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Link is the sign up link sent to the invitee. The invite url setting points
+// at the page of the client app that signs the invitee up and accepts the
+// invite.
+func Link(c config.IConfig, token string) string {
+	return fmt.Sprintf("%s?token=%s", c.GetSettings().InviteURL, url.QueryEscape(token))
+}
+
+// CreatePlaceholder creates the stand-in for an invitee joining as role: a
+// user for a buyer, a business for a seller. It returns the placeholder's id.
+func CreatePlaceholder(ctx context.Context, c config.IConfig, tx pgx.Tx, role, name string) (string, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+	email := fmt.Sprintf("%s@%s", id, placeholderDomain)
+
+	if role == models.TransactionCreatedBySeller {
+		if name == "" {
+			name = "Invited seller"
+		}
+
+		business := &models.Business{Name: name, Email: email}
+		if err := c.GetBusinessRepository().Create(ctx, business, tx); err != nil {
+			return "", err
+		}
+
+		return business.ID, nil
+	}
+
+	user := &models.User{
+		Email:       email,
+		AccountType: models.PersonalAccountType,
+		RegStage:    1,
+	}
+	if err := c.GetUserRepository().Create(ctx, user, tx); err != nil {
+		return "", err
+	}
+
+	return user.ID, nil
+}
+
+// SentTo reports whether invite was sent to the verified email or phone
+// number of user.
+func SentTo(invite *models.Invite, user *models.User) bool {
+	if invite.Email.Valid && user.IsEmailVerified && strings.EqualFold(invite.Email.String, user.Email) {
+		return true
+	}
+
+	return invite.PhoneNumber.Valid && user.IsPhoneNumberVerified && user.PhoneNumber.Valid &&
+		invite.PhoneNumber.String == user.PhoneNumber.String
+}
+
+// Accept attaches the transaction of invite to user in place of the
+// placeholder and removes the placeholder. The seller is paid out at the end
+// of the transaction, so seller invites can only be accepted by the holder of
+// the email or phone number they were sent to, not by anyone the link was
+// passed on to.
+func Accept(ctx context.Context, c config.IConfig, tx pgx.Tx, invite *models.Invite, user *models.User) (*models.Transaction, error) {
+	switch {
+	case invite.Status != models.InvitePending:
+		return nil, ErrAccepted
+	case invite.IsExpired():
+		return nil, ErrExpired
+	case invite.InviterID == user.ID:
+		return nil, ErrOwnInvite
+	case invite.Role == models.TransactionCreatedBySeller && user.BusinessID == nil:
+		return nil, ErrSellerNotBusiness
+	case invite.Role == models.TransactionCreatedBySeller && !SentTo(invite, user):
+		return nil, ErrNotInvitee
+	}
+
+	transactionRepo := c.GetTransactionRepository()
+	transaction, err := transactionRepo.GetById(ctx, invite.TransactionID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if invite.Role == models.TransactionCreatedBySeller {
+		transaction.SellerID = *user.BusinessID
+		err = c.GetBusinessRepository().SoftDelete(ctx, invite.PlaceholderID, tx)
+	} else {
+		transaction.BuyerID = user.ID
+		err = c.GetUserRepository().SoftDelete(ctx, invite.PlaceholderID, tx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = transactionRepo.Update(ctx, transaction, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	invite.Status = models.InviteAccepted
+	invite.AcceptedBy = models.NullString{NullString: sql.NullString{String: user.ID, Valid: true}}
+	invite.AcceptedAt = models.NullTime{NullTime: sql.NullTime{Time: time.Now().UTC(), Valid: true}}
+	err = c.GetInviteRepository().Update(ctx, invite, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// AcceptPending accepts every pending invite sent to the email or phone
+// number of user, which is how a transaction finds its invitee once they
+// sign up. Invites user can not accept, like a seller invite to a personal
+// account, are left for them to accept by link later.
+func AcceptPending(ctx context.Context, c config.IConfig, tx pgx.Tx, user *models.User) ([]*models.Transaction, error) {
+	phone := ""
+	if user.IsPhoneNumberVerified {
+		phone = user.PhoneNumber.String
+	}
+
+	pending, err := c.GetInviteRepository().GetPendingByContact(ctx, user.Email, phone, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := []*models.Transaction{}
+	for _, invite := range pending {
+		transaction, err := Accept(ctx, c, tx, invite, user)
+		if errors.Is(err, ErrOwnInvite) || errors.Is(err, ErrSellerNotBusiness) || errors.Is(err, ErrNotInvitee) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
@@ -1,7 +1,6 @@
 package jwt
 
 import (
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,8 +13,16 @@ type TokenClaims struct {
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(t *TokenClaims) (string, error) {
-	key := []byte(os.Getenv("JWT_KEY"))
+// JWT signs and verifies the tokens of users with key.
+type JWT struct {
+	key []byte
+}
+
+func New(key string) *JWT {
+	return &JWT{key: []byte(key)}
+}
+
+func (j *JWT) GenerateToken(t *TokenClaims) (string, error) {
 	t.IssuedAt = jwt.NewNumericDate(time.Now())
 	t.Issuer = "escrowAPI"
 	t.Subject = t.UserID
@@ -27,14 +34,12 @@ func GenerateToken(t *TokenClaims) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, t)
-	return token.SignedString(key)
+	return token.SignedString(j.key)
 }
 
-func VerifyToken(tokenStr string) (*TokenClaims, error) {
-	key := []byte(os.Getenv("JWT_KEY"))
-
+func (j *JWT) VerifyToken(tokenStr string) (*TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &TokenClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return key, nil
+		return j.key, nil
 	})
 
 	if err != nil {
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reports the stats of a pgx pool at scrape time.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_connections", "Connections of the pool in use."),
+		idleConns:            desc("idle_connections", "Connections of the pool waiting to be used."),
+		totalConns:           desc("connections", "Connections open in the pool."),
+		maxConns:             desc("max_connections", "Most connections the pool opens."),
+		acquireCount:         desc("acquires_total", "Connections acquired from the pool."),
+		emptyAcquireCount:    desc("empty_acquires_total", "Acquires that waited for a connection because the pool had none idle."),
+		canceledAcquireCount: desc("canceled_acquires_total", "Acquires canceled before getting a connection."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Time spent acquiring connections."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+}
+
+// RegisterPool reports the stats of pool. Registering a second pool is a no
+// op, the app runs on one.
+func RegisterPool(pool *pgxpool.Pool) error {
+	return register(newPoolCollector(pool))
+}
+
+// RegisterOutbox reports the number of webhook deliveries waiting to be sent,
+// counted by pending at scrape time.
+func RegisterOutbox(pending func(ctx context.Context) (int, error)) error {
+	return register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "webhook_outbox_pending",
+		Help:      "Webhook deliveries waiting to be sent, retries included.",
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		n, err := pending(ctx)
+		if err != nil {
+			return math.NaN()
+		}
+
+		return float64(n)
+	}))
+}
+
+func register(c prometheus.Collector) error {
+	err := prometheus.Register(c)
+
+	are := prometheus.AlreadyRegisteredError{}
+	if errors.As(err, &are) {
+		return nil
+	}
+
+	return err
+}
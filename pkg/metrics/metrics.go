@@ -0,0 +1,180 @@
+// Package metrics holds the prometheus metrics of the app and exposes them on
+// /metrics. Metrics are registered with the default registry, which also
+// carries the go runtime and process metrics.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "escrow"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time taken by db queries, by the repository method running them.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"repository", "method"})
+
+	queryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Failed db queries, by the repository method running them.",
+	}, []string{"repository", "method"})
+
+	paystackDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "paystack_request_duration_seconds",
+		Help:      "Time taken by calls to the paystack api, by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	paystackErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "paystack_request_errors_total",
+		Help:      "Calls to the paystack api that failed or were answered with an error status, by endpoint.",
+	}, []string{"endpoint"})
+
+	paystackWebhooks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "paystack_webhooks_total",
+		Help:      "Webhooks received from paystack, by outcome.",
+	}, []string{"outcome"})
+
+	webhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_delivery_attempts_total",
+		Help:      "Attempts at delivering webhooks to business endpoints, by event type and outcome.",
+	}, []string{"event", "outcome"})
+
+	transactionsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_created_total",
+		Help:      "Transactions created, by type and currency.",
+	}, []string{"type", "currency"})
+
+	escrowedVolume = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "escrowed_volume_minor_units_total",
+		Help:      "Payments into escrow, in the minor units of their currency.",
+	}, []string{"currency"})
+
+	withdrawals = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "withdrawals_total",
+		Help:      "Withdrawals requested and settled, by currency and status.",
+	}, []string{"currency", "status"})
+)
+
+// Handler serves the metrics in the prometheus text format. They carry
+// business volumes, so it is only served on the internal metrics port.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware records the count and latency of requests by chi route pattern,
+// so /transactions/{id} is one series whatever the id. Requests matching no
+// route are recorded under "unmatched".
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		// the pattern is only complete once the request went through every
+		// router mounted on the way
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveQuery records a db query run by method of repository.
+func ObserveQuery(repository, method string, d time.Duration, err error) {
+	queryDuration.WithLabelValues(repository, method).Observe(d.Seconds())
+	if err != nil {
+		queryErrors.WithLabelValues(repository, method).Inc()
+	}
+}
+
+// ObservePaystack records a call to endpoint of the paystack api. failed is
+// set for calls that errored or were answered with an error status.
+func ObservePaystack(endpoint string, d time.Duration, failed bool) {
+	paystackDuration.WithLabelValues(endpoint).Observe(d.Seconds())
+	if failed {
+		paystackErrors.WithLabelValues(endpoint).Inc()
+	}
+}
+
+// PaystackWebhooks records the outcome of the paystack webhooks handled by
+// next, told by the status they are answered with.
+func PaystackWebhooks(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		outcome := "processed"
+		switch status := ww.Status(); {
+		case status == http.StatusForbidden:
+			outcome = "invalid_signature"
+		case status >= http.StatusInternalServerError:
+			outcome = "failed"
+		case status >= http.StatusBadRequest:
+			outcome = "rejected"
+		}
+
+		paystackWebhooks.WithLabelValues(outcome).Inc()
+	})
+}
+
+// WebhookDelivery records an attempt at delivering an event to a business
+// endpoint, whose outcome is the status the delivery was left in.
+func WebhookDelivery(eventType, outcome string) {
+	webhookDeliveries.WithLabelValues(eventType, outcome).Inc()
+}
+
+func TransactionCreated(transactionType, currency string) {
+	transactionsCreated.WithLabelValues(transactionType, currency).Inc()
+}
+
+// Escrowed records amount, in minor units of currency, paid into escrow.
+func Escrowed(currency string, amount int64) {
+	escrowedVolume.WithLabelValues(currency).Add(float64(amount))
+}
+
+func Withdrawal(currency, status string) {
+	withdrawals.WithLabelValues(currency, status).Inc()
+}
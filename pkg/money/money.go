@@ -0,0 +1,329 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const (
+	NGN = "NGN"
+	USD = "USD"
+	GHS = "GHS"
+	KES = "KES"
+)
+
+// Currencies lists the currencies wallets and transactions can be held in.
+var Currencies = []string{NGN, USD, GHS, KES}
+
+// exponents are the number of minor unit digits of each currency, 2 for
+// kobo in a naira.
+var exponents = map[string]int{
+	NGN: 2,
+	USD: 2,
+	GHS: 2,
+	KES: 2,
+}
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("currencies do not match")
+	ErrOverflow            = errors.New("amount is out of range")
+	ErrInvalidRatios       = errors.New("ratios must be positive and add up to more than 0")
+)
+
+func IsSupported(currency string) bool {
+	for _, c := range Currencies {
+		if c == currency {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Exponent returns the number of minor unit digits of currency.
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+
+	return 2
+}
+
+// RoundingMode says what happens to the fraction of a minor unit left by a
+// division.
+type RoundingMode int
+
+const (
+	RoundDown     RoundingMode = iota // toward zero
+	RoundUp                           // away from zero
+	RoundHalfUp                       // to the nearest, halves away from zero
+	RoundHalfEven                     // to the nearest, halves to the even neighbour
+)
+
+// Div returns num/den rounded with mode. den must not be 0.
+func Div(num, den int64, mode RoundingMode) int64 {
+	q, r := num/den, num%den
+	if r == 0 {
+		return q
+	}
+
+	// sign of the result, to round away from zero
+	sign := int64(1)
+	if (num < 0) != (den < 0) {
+		sign = -1
+	}
+
+	ar, ad := abs(r), abs(den)
+	switch mode {
+	case RoundUp:
+		return q + sign
+	case RoundHalfUp:
+		if 2*ar >= ad {
+			return q + sign
+		}
+	case RoundHalfEven:
+		if 2*ar > ad || (2*ar == ad && q%2 != 0) {
+			return q + sign
+		}
+	}
+
+	return q
+}
+
+// MulDiv returns amount*num/den rounded with mode, without overflowing on the
+// product.
+func MulDiv(amount, num, den int64, mode RoundingMode) (int64, error) {
+	if den == 0 {
+		return 0, errors.New("division by zero")
+	}
+
+	p := new(big.Int).Mul(big.NewInt(amount), big.NewInt(num))
+	if p.IsInt64() {
+		return Div(p.Int64(), den, mode), nil
+	}
+
+	q, r := new(big.Int).QuoRem(p, big.NewInt(den), new(big.Int))
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+
+	// the remainder is smaller than den, so it fits and can be rounded the
+	// same way as a small product
+	return Add(q.Int64(), Div(r.Int64(), den, mode))
+}
+
+// Add returns a+b, or ErrOverflow if it does not fit in an int64.
+func Add(a, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, ErrOverflow
+	}
+
+	return a + b, nil
+}
+
+// Sub returns a-b, or ErrOverflow if it does not fit in an int64.
+func Sub(a, b int64) (int64, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, ErrOverflow
+	}
+
+	return a - b, nil
+}
+
+// Mul returns a*b, or ErrOverflow if it does not fit in an int64.
+func Mul(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, ErrOverflow
+	}
+
+	return c, nil
+}
+
+// Allocate splits amount into parts proportional to ratios. Parts are rounded
+// down and the units left are handed out one at a time to the parts with the
+// largest remainders, earlier parts winning ties, so the parts always add up
+// to amount.
+func Allocate(amount int64, ratios ...int64) ([]int64, error) {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, ErrInvalidRatios
+		}
+
+		var err error
+		if total, err = Add(total, r); err != nil {
+			return nil, err
+		}
+	}
+
+	if total == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	parts := make([]int64, len(ratios))
+	remainders := make([]*big.Int, len(ratios))
+	left := amount
+	for i, r := range ratios {
+		p := new(big.Int).Mul(big.NewInt(amount), big.NewInt(r))
+		q, rem := new(big.Int).QuoRem(p, big.NewInt(total), new(big.Int))
+
+		parts[i] = q.Int64()
+		remainders[i] = rem.Abs(rem)
+		left -= parts[i]
+	}
+
+	step := int64(1)
+	if left < 0 {
+		step = -1
+	}
+
+	for ; left != 0; left -= step {
+		best := -1
+		for i, rem := range remainders {
+			if rem.Sign() > 0 && (best == -1 || rem.Cmp(remainders[best]) > 0) {
+				best = i
+			}
+		}
+
+		parts[best] += step
+		remainders[best].SetInt64(0)
+	}
+
+	return parts, nil
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+// Money is an amount in the minor unit of its currency, kobo for NGN.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Int returns the amount as an int, the type amounts are stored as.
+func (m Money) Int() int {
+	return int(m.Amount)
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	amount, err := Add(m.Amount, o.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return New(amount, m.Currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	amount, err := Sub(m.Amount, o.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return New(amount, m.Currency), nil
+}
+
+// Mul returns m times n, like the price of n items.
+func (m Money) Mul(n int64) (Money, error) {
+	amount, err := Mul(m.Amount, n)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return New(amount, m.Currency), nil
+}
+
+// MulDiv returns m*num/den rounded to the minor unit with mode.
+func (m Money) MulDiv(num, den int64, mode RoundingMode) (Money, error) {
+	amount, err := MulDiv(m.Amount, num, den, mode)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return New(amount, m.Currency), nil
+}
+
+// Percent returns bps basis points of m, rounded to the minor unit with mode.
+func (m Money) Percent(bps int64, mode RoundingMode) (Money, error) {
+	return m.MulDiv(bps, 10000, mode)
+}
+
+// Allocate splits m into parts proportional to ratios without losing a minor
+// unit. See Allocate.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	amounts, err := Allocate(m.Amount, ratios...)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]Money, len(amounts))
+	for i, a := range amounts {
+		parts[i] = New(a, m.Currency)
+	}
+
+	return parts, nil
+}
+
+// Cmp compares m and o like strings.Compare. Both must be in the same
+// currency.
+func (m Money) Cmp(o Money) (int, error) {
+	if m.Currency != o.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) String() string {
+	sign := ""
+	a := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		a = uint64(-m.Amount)
+	}
+
+	e := Exponent(m.Currency)
+	digits := fmt.Sprintf("%0*d", e+1, a)
+	if e == 0 {
+		return fmt.Sprintf("%s %s%s", m.Currency, sign, digits)
+	}
+
+	return fmt.Sprintf("%s %s%s.%s", m.Currency, sign, digits[:len(digits)-e], digits[len(digits)-e:])
+}
@@ -1,19 +1,37 @@
 package push
 
 import (
+	"context"
 	"fmt"
 	"net/smtp"
-	"os"
 
 	"github.com/jordan-wright/email"
+	"github.com/princecee/escrow-api/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type IPush interface {
-	SendSMS(data *Sms)
-	SendEmail(data *Email) error
+	SendSMS(ctx context.Context, data *Sms)
+	SendEmail(ctx context.Context, data *Email) error
 }
 
-type Push struct{}
+// SMTP is the server emails are sent through.
+type SMTP struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type Push struct {
+	smtp SMTP
+}
+
+func NewPush(smtp SMTP) *Push {
+	return &Push{smtp: smtp}
+}
 
 const (
 	ErrSendingEmailMsg = "error sending email"
@@ -27,18 +45,24 @@ type Email struct {
 	Html    string
 }
 
-func (p *Push) SendEmail(data *Email) error {
-	username := os.Getenv("EMAIL_USERNAME")
-	password := os.Getenv("EMAIL_PASSWORD")
-	from := os.Getenv("EMAIL_FROM")
-	port := os.Getenv("EMAIL_PORT")
-	host := os.Getenv("EMAIL_HOST")
+// SendEmail sends data over SMTP. net/smtp can not be interrupted, so ctx is
+// only checked before dialing.
+func (p *Push) SendEmail(ctx context.Context, data *Email) (err error) {
+	_, span := tracing.Tracer().Start(ctx, "push.SendEmail",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("email.recipients", len(data.To))),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	e := email.NewEmail()
 	if data.From != "" {
 		e.From = data.From
 	} else {
-		e.From = from
+		e.From = p.smtp.From
 	}
 
 	e.To = data.To
@@ -46,13 +70,13 @@ func (p *Push) SendEmail(data *Email) error {
 	e.Text = []byte(data.Text)
 	e.HTML = []byte(data.Html)
 
-	err := e.Send(
-		fmt.Sprintf("%s:%s", host, port),
+	err = e.Send(
+		fmt.Sprintf("%s:%s", p.smtp.Host, p.smtp.Port),
 		smtp.PlainAuth(
 			"",
-			username,
-			password,
-			host,
+			p.smtp.Username,
+			p.smtp.Password,
+			p.smtp.Host,
 		),
 	)
 
@@ -68,4 +92,7 @@ type Sms struct {
 	Message string
 }
 
-func (p *Push) SendSMS(data *Sms) {}
+func (p *Push) SendSMS(ctx context.Context, data *Sms) {
+	_, span := tracing.Tracer().Start(ctx, "push.SendSMS", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+}
@@ -0,0 +1,145 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Options struct {
+	MaxAccountFailures int           // failures on one account before it is locked
+	MaxIPFailures      int           // failures from one ip before it is locked
+	Window             time.Duration // how long a failure is remembered
+	BaseLockout        time.Duration // lockout applied when a limit is first reached
+	MaxLockout         time.Duration // ceiling for the exponential backoff
+}
+
+var DefaultOptions = Options{
+	MaxAccountFailures: 5,
+	MaxIPFailures:      20,
+	Window:             30 * time.Minute,
+	BaseLockout:        1 * time.Minute,
+	MaxLockout:         1 * time.Hour,
+}
+
+// LoginThrottle keeps failed sign in counters per account and per ip in redis.
+// Once a counter reaches its limit, the key is locked for BaseLockout and every
+// further failure doubles the lockout up to MaxLockout.
+type LoginThrottle struct {
+	rdb  redis.Cmdable
+	opts Options
+}
+
+func NewLoginThrottle(rdb redis.Cmdable, opts ...Options) *LoginThrottle {
+	o := DefaultOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	return &LoginThrottle{rdb: rdb, opts: o}
+}
+
+func accountKey(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+func failureKey(kind, value string) string {
+	return fmt.Sprintf("login:failures:%s:%s", kind, value)
+}
+
+func lockKey(kind, value string) string {
+	return fmt.Sprintf("login:lock:%s:%s", kind, value)
+}
+
+// Check returns how long the account or ip is still locked for. A zero
+// duration means the sign in attempt may proceed.
+func (t *LoginThrottle) Check(ctx context.Context, account, ip string) (time.Duration, error) {
+	pipe := t.rdb.Pipeline()
+	accountTTL := pipe.PTTL(ctx, lockKey("account", accountKey(account)))
+	ipTTL := pipe.PTTL(ctx, lockKey("ip", ip))
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, err
+	}
+
+	return longest(accountTTL.Val(), ipTTL.Val()), nil
+}
+
+// RegisterFailure records a failed attempt and returns the lockout it caused,
+// if any.
+func (t *LoginThrottle) RegisterFailure(ctx context.Context, account, ip string) (time.Duration, error) {
+	accountLock, err := t.registerFailure(ctx, "account", accountKey(account), t.opts.MaxAccountFailures)
+	if err != nil {
+		return 0, err
+	}
+
+	ipLock, err := t.registerFailure(ctx, "ip", ip, t.opts.MaxIPFailures)
+	if err != nil {
+		return 0, err
+	}
+
+	return longest(accountLock, ipLock), nil
+}
+
+func (t *LoginThrottle) registerFailure(ctx context.Context, kind, value string, limit int) (time.Duration, error) {
+	key := failureKey(kind, value)
+
+	pipe := t.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, t.opts.Window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	lockout := t.lockoutFor(int(incr.Val()), limit)
+	if lockout == 0 {
+		return 0, nil
+	}
+
+	if err := t.rdb.Set(ctx, lockKey(kind, value), incr.Val(), lockout).Err(); err != nil {
+		return 0, err
+	}
+
+	return lockout, nil
+}
+
+func (t *LoginThrottle) lockoutFor(failures, limit int) time.Duration {
+	if failures < limit {
+		return 0
+	}
+
+	lockout := t.opts.BaseLockout
+	for i := limit; i < failures; i++ {
+		lockout *= 2
+		if lockout >= t.opts.MaxLockout {
+			return t.opts.MaxLockout
+		}
+	}
+
+	return lockout
+}
+
+// Reset clears the failure counter and lock of an account after a successful
+// sign in. The ip counter is left alone so that spraying many accounts from a
+// single address is still throttled.
+func (t *LoginThrottle) Reset(ctx context.Context, account string) error {
+	key := accountKey(account)
+	return t.rdb.Del(ctx, failureKey("account", key), lockKey("account", key)).Err()
+}
+
+// longest returns the largest positive duration, treating the negative values
+// redis uses for missing keys as no lockout.
+func longest(durations ...time.Duration) time.Duration {
+	var d time.Duration
+	for _, v := range durations {
+		if v > d {
+			d = v
+		}
+	}
+
+	return d
+}
@@ -1,15 +1,20 @@
 package utils
 
 import (
+	"context"
 	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"math"
 	"math/rand"
-	"os"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -25,6 +30,13 @@ const (
 
 type ContextKey struct{}
 
+// APIKeyContextKey holds the *models.APIKey of requests authenticated with an
+// api key rather than a user token.
+type APIKeyContextKey struct{}
+
+// IPAddressContextKey holds the ip address of the client of a request.
+type IPAddressContextKey struct{}
+
 type Pagination struct {
 	Offset int
 	Limit  int
@@ -120,12 +132,55 @@ func GenerateRandomNumber() string {
 	return fmt.Sprintf("%04d", v)
 }
 
-func Background(fn func()) {
+// background tracks the goroutines started by Background, for
+// WaitBackground.
+var background sync.WaitGroup
+
+// Background runs fn in a goroutine with a context that keeps the values of
+// ctx, like its trace, but is not canceled with it, so work started by a
+// request outlives the response.
+func Background(ctx context.Context, fn func(ctx context.Context)) {
+	ctx = withoutCancel{ctx}
+
+	background.Add(1)
 	go func() {
-		fn()
+		defer background.Done()
+		fn(ctx)
 	}()
 }
 
+// WaitBackground blocks until the work started by Background is done, or ctx
+// is done, in which case it returns the error of ctx. Call it on shutdown,
+// once no more requests are served.
+func WaitBackground(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withoutCancel is a context that is never canceled but carries the values
+// of its parent.
+type withoutCancel struct {
+	parent context.Context
+}
+
+func (withoutCancel) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (withoutCancel) Done() <-chan struct{}       { return nil }
+func (withoutCancel) Err() error                  { return nil }
+
+func (c withoutCancel) Value(key any) any {
+	return c.parent.Value(key)
+}
+
 func GetPage(v int) int {
 	if v == 0 {
 		return 1
@@ -142,56 +197,88 @@ func GetPageSize(v int) int {
 	return v
 }
 
-func ComputeHMAC(b io.ReadCloser) (string, error) {
-	jsonData, err := json.Marshal(b)
-	if err != nil {
-		return "", err
-	}
+// ComputeHMAC returns the hex encoded HMAC-SHA512 of data, which is how
+// paystack signs the raw body of its webhooks.
+func ComputeHMAC(key, data []byte) string {
+	h := hmac.New(sha512.New, key)
+	h.Write(data)
 
-	h := hmac.New(sha512.New, []byte(os.Getenv("PAYSTACK_SECRET_KEY")))
-	_, err = h.Write(jsonData)
+	return hex.EncodeToString(h.Sum(nil))
+}
 
+func GetTotalPages(total, pageSize int) int {
+	return int(math.Ceil((float64(total) / float64(pageSize))))
+}
+
+// GetIPAddress returns the client ip of a request. RemoteAddr is only
+// rewritten from proxy headers when the peer is a trusted proxy, see
+// middlewares.RealIPMiddleware.
+func GetIPAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		return "", err
+		return r.RemoteAddr
 	}
 
-	return hex.EncodeToString(h.Sum(nil)), nil
+	return host
 }
 
-type WhereArgs struct {
-	Name  string
-	Value any
-}
+// ParseNetworks parses a list of CIDRs, where a plain ip is a network of
+// its own.
+func ParseNetworks(list []string) ([]*net.IPNet, error) {
+	networks := []*net.IPNet{}
+	for _, v := range list {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip address %q", v)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
 
-func GenerateANDWhereFromArgs(args []WhereArgs) (string, []any) {
-	newArgs := []WhereArgs{}
-	_args := []any{}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
 
-	for _, v := range args {
-		v := v
-		if v.Value != nil && v.Value.(string) != "" {
-			newArgs = append(newArgs, v)
-			_args = append(_args, v.Value)
+		_, network, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q", v)
 		}
+		networks = append(networks, network)
 	}
 
-	where := ""
-	for i, v := range newArgs {
-		v := v
-		if i == 0 {
-			where += fmt.Sprintf(" %s = $%d", v.Name, i+1)
-		} else {
-			where += fmt.Sprintf(" AND %s = $%d", v.Name, i+1)
+	return networks, nil
+}
+
+// ContainsIP reports whether ip is in one of networks.
+func ContainsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, n := range networks {
+		if n.Contains(ip) {
+			return true
 		}
 	}
 
-	if where != "" {
-		where = "WHERE " + where
+	return false
+}
+
+// GenerateAPIKey returns a new random api key starting with prefix.
+func GenerateAPIKey(prefix string) (string, error) {
+	b := make([]byte, 24)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
 	}
 
-	return where, _args
+	return prefix + hex.EncodeToString(b), nil
 }
 
-func GetTotalPages(total, pageSize int) int {
-	return int(math.Ceil((float64(total) / float64(pageSize))))
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
 }
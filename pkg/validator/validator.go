@@ -2,9 +2,21 @@ package validator
 
 import (
 	"github.com/go-playground/validator/v10"
+	"github.com/princecee/escrow-api/pkg/money"
 )
 
-var v = validator.New(validator.WithRequiredStructEnabled())
+var v = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// currency accepts the currencies wallets and transactions can be held in
+	_ = v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return money.IsSupported(fl.Field().String())
+	})
+
+	return v
+}
 
 func ValidateData(data any) map[string]any {
 	err := v.Struct(data)
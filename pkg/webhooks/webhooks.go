@@ -0,0 +1,218 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/tracing"
+)
+
+const (
+	ErrEnqueueingMsg = "error enqueueing webhook"
+)
+
+const (
+	EventTransactionCreated   = "transaction.created"
+	EventTransactionPaid      = "transaction.paid"
+	EventTransactionCompleted = "transaction.completed"
+	EventTransactionCanceled  = "transaction.canceled"
+	EventDisputeOpened        = "dispute.opened"
+	EventDisputeResolved      = "dispute.resolved"
+	EventMilestoneFunded      = "milestone.funded"
+	EventMilestoneDelivered   = "milestone.delivered"
+	EventMilestoneReleased    = "milestone.released"
+	EventWalletFunded         = "wallet.funded"
+	EventWalletConverted      = "wallet.converted"
+	EventWithdrawalSettled    = "withdrawal.settled"
+	EventWithdrawalFailed     = "withdrawal.failed"
+	EventPing                 = "ping"
+)
+
+// EventTypes are the events endpoints can subscribe to. "*" subscribes to all
+// of them, including ones added later.
+var EventTypes = []string{
+	EventTransactionCreated,
+	EventTransactionPaid,
+	EventTransactionCompleted,
+	EventTransactionCanceled,
+	EventDisputeOpened,
+	EventDisputeResolved,
+	EventMilestoneFunded,
+	EventMilestoneDelivered,
+	EventMilestoneReleased,
+	EventWalletFunded,
+	EventWalletConverted,
+	EventWithdrawalSettled,
+	EventWithdrawalFailed,
+}
+
+const (
+	SignatureHeader = "X-Escrow-Signature"
+	EventHeader     = "X-Escrow-Event"
+	DeliveryHeader  = "X-Escrow-Delivery"
+)
+
+// MaxAttempts is how many times a delivery is tried before it is marked as
+// failed. With the backoff below the last retry happens about four hours
+// after the event.
+const MaxAttempts = 10
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Event is the body posted to endpoints.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Enqueue records a delivery of eventType to every endpoint of the business
+// subscribed to it. Run it in the same transaction as the change it announces
+// so an event is only sent when that change is committed.
+func Enqueue(ctx context.Context, c config.IConfig, tx pgx.Tx, businessId, eventType string, data any) error {
+	endpoints, err := c.GetWebhookEndpointRepository().GetSubscribed(ctx, businessId, eventType, tx)
+	if err != nil {
+		return err
+	}
+
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	return enqueue(ctx, c, tx, endpoints, eventType, data)
+}
+
+// EnqueueTo records a delivery of eventType to endpoint whether or not it is
+// subscribed to it. It is used to send test events.
+func EnqueueTo(ctx context.Context, c config.IConfig, tx pgx.Tx, endpoint *models.WebhookEndpoint, eventType string, data any) error {
+	return enqueue(ctx, c, tx, []*models.WebhookEndpoint{endpoint}, eventType, data)
+}
+
+func enqueue(ctx context.Context, c config.IConfig, tx pgx.Tx, endpoints []*models.WebhookEndpoint, eventType string, data any) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(Event{
+		ID:        id.String(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	// deliveries are sent later by the worker, which carries on the trace of
+	// the request raising the event
+	traceContext := tracing.Inject(ctx)
+
+	for _, e := range endpoints {
+		d := &models.WebhookDelivery{
+			EndpointID:   e.ID,
+			EventID:      id.String(),
+			EventType:    eventType,
+			Payload:      payload,
+			Status:       models.WebhookDeliveryPending,
+			TraceContext: traceContext,
+		}
+		d.NextAttemptAt.Time = time.Now().UTC()
+		d.NextAttemptAt.Valid = true
+
+		if err := c.GetWebhookDeliveryRepository().Create(ctx, d, tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GenerateSecret returns a new signing secret for an endpoint.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header for body sent at timestamp. The timestamp
+// is part of the signed content so a captured request cannot be replayed
+// later with a fresh one.
+func Sign(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, computeSignature(secret, timestamp, body))
+}
+
+// Verify checks a signature header produced by Sign and rejects it when it is
+// older than tolerance. Receivers can use it as is.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var timestamp int64
+	var signature string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			timestamp, _ = strconv.ParseInt(v, 10, 64)
+		case "v1":
+			signature = v
+		}
+	}
+
+	if timestamp == 0 || signature == "" {
+		return ErrInvalidSignature
+	}
+
+	if time.Since(time.Unix(timestamp, 0)) > tolerance {
+		return ErrInvalidSignature
+	}
+
+	expected := computeSignature(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func computeSignature(secret string, timestamp int64, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Backoff returns how long to wait after the given failed attempt: 30 seconds
+// doubling every time, capped at six hours.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := 30 * time.Second
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= 6*time.Hour {
+			return 6 * time.Hour
+		}
+	}
+
+	return d
+}
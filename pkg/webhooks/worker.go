@@ -0,0 +1,174 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/metrics"
+	"github.com/princecee/escrow-api/pkg/tracing"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	batchSize       = 50
+	claimLease      = 2 * time.Minute
+	requestTimeout  = 10 * time.Second
+	maxResponseBody = 1024
+)
+
+// Worker sends pending deliveries. Any number of workers can run side by side
+// since deliveries are claimed before they are sent.
+type Worker struct {
+	c      config.IConfig
+	client *http.Client
+}
+
+func NewWorker(c config.IConfig) *Worker {
+	return &Worker{
+		c: c,
+		client: newClient(func() bool {
+			return c.GetSettings().AllowPrivateWebhooks
+		}),
+	}
+}
+
+// ProcessDue sends every delivery that is due and returns how many were
+// attempted.
+func (w *Worker) ProcessDue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		deliveries, err := w.c.GetWebhookDeliveryRepository().ClaimDue(ctx, batchSize, claimLease, nil)
+		if err != nil {
+			return total, err
+		}
+
+		for _, d := range deliveries {
+			if err := w.Deliver(ctx, d); err != nil {
+				w.c.GetLogger().Log(zerolog.InfoLevel, "error delivering webhook", map[string]any{"delivery_id": d.ID}, err)
+			}
+		}
+
+		total += len(deliveries)
+		if len(deliveries) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// Deliver makes one attempt at d and records the outcome. Failed attempts are
+// rescheduled with Backoff until MaxAttempts is reached. The returned error is
+// only set when the outcome could not be saved.
+func (w *Worker) Deliver(ctx context.Context, d *models.WebhookDelivery) (err error) {
+	ctx, span := startSpan(ctx, d)
+	defer func() { tracing.End(span, err) }()
+
+	endpoint, err := w.c.GetWebhookEndpointRepository().GetById(ctx, d.EndpointID, nil)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	d.Attempts++
+	d.LastAttemptAt.Time = now
+	d.LastAttemptAt.Valid = true
+	d.ResponseStatus = 0
+	d.ResponseBody = ""
+	d.Error = ""
+
+	if !endpoint.IsActive {
+		d.Error = "endpoint is disabled"
+	} else {
+		w.send(ctx, endpoint, d, now)
+	}
+
+	switch {
+	case d.Error == "":
+		d.Status = models.WebhookDeliverySucceeded
+		d.NextAttemptAt.Valid = false
+	case d.Attempts >= MaxAttempts || !endpoint.IsActive:
+		d.Status = models.WebhookDeliveryFailed
+		d.NextAttemptAt.Valid = false
+	default:
+		d.Status = models.WebhookDeliveryPending
+		d.NextAttemptAt.Time = now.Add(Backoff(d.Attempts))
+		d.NextAttemptAt.Valid = true
+	}
+
+	span.SetAttributes(attribute.String("webhook.outcome", d.Status), attribute.Int("webhook.attempt", d.Attempts))
+	if d.Error != "" {
+		span.SetStatus(codes.Error, d.Error)
+	}
+
+	if err := w.c.GetWebhookDeliveryRepository().Update(ctx, d, nil); err != nil {
+		return err
+	}
+
+	metrics.WebhookDelivery(d.EventType, d.Status)
+	return nil
+}
+
+// startSpan starts the span of an attempt at d. Attempts made by the worker
+// carry on the trace of the request that raised the event, while those made
+// within a request, like redeliveries, stay in its trace and link to the
+// original one.
+func startSpan(ctx context.Context, d *models.WebhookDelivery) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("webhook.event_type", d.EventType),
+			attribute.String("webhook.delivery_id", d.ID),
+		),
+	}
+
+	origin := trace.SpanContextFromContext(tracing.Extract(context.Background(), d.TraceContext))
+	if origin.IsValid() {
+		if trace.SpanContextFromContext(ctx).IsValid() {
+			opts = append(opts, trace.WithLinks(trace.Link{SpanContext: origin}))
+		} else {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, origin)
+		}
+	}
+
+	return tracing.Tracer().Start(ctx, "webhook.deliver "+d.EventType, opts...)
+}
+
+func (w *Worker) send(ctx context.Context, endpoint *models.WebhookEndpoint, d *models.WebhookDelivery, now time.Time) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		d.Error = err.Error()
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "escrow-api-webhooks")
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, now.Unix(), d.Payload))
+	req.Header.Set(EventHeader, d.EventType)
+	req.Header.Set(DeliveryHeader, d.ID)
+	tracing.InjectHeaders(ctx, req.Header)
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		d.Error = err.Error()
+		return
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	d.ResponseStatus = res.StatusCode
+	// postgres text columns reject invalid utf8 and null bytes
+	d.ResponseBody = strings.ToValidUTF8(strings.ReplaceAll(string(body), "\x00", ""), "")
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		d.Error = fmt.Sprintf("endpoint responded with %d", res.StatusCode)
+	}
+}
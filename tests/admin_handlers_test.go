@@ -0,0 +1,332 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/princecee/escrow-api/pkg/json"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminHandlerTestSuite struct {
+	suite.Suite
+	ts          *test_utils.TestServer
+	user        test_utils.TestUser
+	accessToken string
+}
+
+func (s *AdminHandlerTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+
+	user, token := test_utils.SignupPersonalUser(s.ts)
+	s.user = user
+	s.accessToken = token
+}
+
+func (s *AdminHandlerTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+func (s *AdminHandlerTestSuite) request(method, url string, body io.Reader) *http.Request {
+	req, _ := http.NewRequest(method, url, body)
+	req.Header = map[string][]string{
+		"Authorization": {fmt.Sprintf("Bearer %s", s.accessToken)},
+		"Content-Type":  {test_utils.ContentType},
+	}
+
+	return req
+}
+
+func (s *AdminHandlerTestSuite) setRole(role string) {
+	_, err := s.ts.Config.GetDB().Exec(
+		context.Background(),
+		`UPDATE users SET role = $1 WHERE email = $2`,
+		role,
+		s.user.Email,
+	)
+	s.NoError(err)
+}
+
+func (s *AdminHandlerTestSuite) TestAdminHandler() {
+	client := s.ts.Server.Client()
+	url := s.ts.Server.URL + "/api/v1/admin"
+
+	s.Run("forbid users without the permission", func() {
+		req := s.request(http.MethodGet, url+"/users", nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusForbidden, res.StatusCode)
+	})
+
+	s.setRole("admin")
+
+	s.Run("search users", func() {
+		req := s.request(http.MethodGet, url+"/users?q=testuser", nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			Users []test_utils.TestUser `json:"users"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal(1, respBody.Meta.Total)
+		s.Len(respBody.Data.Users, 1)
+		s.Equal(s.user.Email, respBody.Data.Users[0].Email)
+		s.Equal("admin", respBody.Data.Users[0].Role)
+	})
+
+	var walletID string
+	s.Run("get wallet", func() {
+		req := s.request(http.MethodGet, s.ts.Server.URL+"/api/v1/wallets", nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			Wallet test_utils.TestWallet `json:"wallet"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		walletID = respBody.Data.Wallet.ID
+	})
+
+	s.Run("freeze wallet", func() {
+		req := s.request(http.MethodPost, url+"/wallets/"+walletID+"/freeze", nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			Wallet test_utils.TestWallet `json:"wallet"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal(true, respBody.Data.Wallet.IsFrozen)
+
+		data, _ := json.Marshal(map[string]any{
+			"amount":          5000,
+			"bank_account_id": "00000000-0000-0000-0000-000000000000",
+		})
+		req = s.request(http.MethodPost, s.ts.Server.URL+"/api/v1/wallets/withdraw-funds", bytes.NewBuffer(data))
+		res, err = client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusForbidden, res.StatusCode)
+	})
+
+	s.Run("unfreeze wallet", func() {
+		req := s.request(http.MethodPost, url+"/wallets/"+walletID+"/unfreeze", nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			Wallet test_utils.TestWallet `json:"wallet"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal(false, respBody.Data.Wallet.IsFrozen)
+	})
+
+	s.Run("adjust balance", func() {
+		s.Run("require a reason", func() {
+			data, _ := json.Marshal(map[string]any{"amount": 10000})
+			req := s.request(http.MethodPost, url+"/wallets/"+walletID+"/adjust", bytes.NewBuffer(data))
+			res, err := client.Do(req)
+			s.NoError(err)
+			defer res.Body.Close()
+
+			s.Equal(http.StatusBadRequest, res.StatusCode)
+		})
+
+		s.Run("credit wallet", func() {
+			data, _ := json.Marshal(map[string]any{
+				"amount": 10000,
+				"reason": "refund for failed paystack charge",
+			})
+			req := s.request(http.MethodPost, url+"/wallets/"+walletID+"/adjust", bytes.NewBuffer(data))
+			res, err := client.Do(req)
+			s.NoError(err)
+
+			respBody := new(test_utils.Response[struct {
+				Wallet        test_utils.TestWallet        `json:"wallet"`
+				WalletHistory test_utils.TestWalletHistory `json:"wallet_history"`
+			}])
+			_ = json.ReadJSON(res.Body, respBody)
+			defer res.Body.Close()
+
+			s.Equal(true, respBody.Success)
+			s.Equal(10000, respBody.Data.Wallet.Balance)
+			s.Equal("Adjustment", respBody.Data.WalletHistory.Type)
+			s.Contains(respBody.Data.WalletHistory.Note, "refund for failed paystack charge")
+		})
+
+		s.Run("reject negative balances", func() {
+			data, _ := json.Marshal(map[string]any{
+				"amount": -20000,
+				"reason": "reverse duplicated credit",
+			})
+			req := s.request(http.MethodPost, url+"/wallets/"+walletID+"/adjust", bytes.NewBuffer(data))
+			res, err := client.Do(req)
+			s.NoError(err)
+			defer res.Body.Close()
+
+			s.Equal(http.StatusBadRequest, res.StatusCode)
+		})
+	})
+
+	s.Run("audit logs", func() {
+		s.Run("filter by action", func() {
+			req := s.request(http.MethodGet, url+"/audit-logs?action=admin.wallet_frozen&entity_id="+walletID, nil)
+			res, err := client.Do(req)
+			s.NoError(err)
+
+			respBody := new(test_utils.Response[struct {
+				AuditLogs []test_utils.TestAuditLog `json:"audit_logs"`
+			}])
+			_ = json.ReadJSON(res.Body, respBody)
+			defer res.Body.Close()
+
+			s.Equal(true, respBody.Success)
+			s.Equal(1, respBody.Meta.Total)
+			s.Len(respBody.Data.AuditLogs, 1)
+			s.Equal("user", respBody.Data.AuditLogs[0].ActorType)
+			s.Contains(respBody.Data.AuditLogs[0].Changes, "is_frozen")
+		})
+
+		s.Run("record sign ups", func() {
+			req := s.request(http.MethodGet, url+"/audit-logs?action=auth.sign_up", nil)
+			res, err := client.Do(req)
+			s.NoError(err)
+
+			respBody := new(test_utils.Response[struct {
+				AuditLogs []test_utils.TestAuditLog `json:"audit_logs"`
+			}])
+			_ = json.ReadJSON(res.Body, respBody)
+			defer res.Body.Close()
+
+			s.Equal(true, respBody.Success)
+			s.NotEmpty(respBody.Data.AuditLogs)
+		})
+
+		s.Run("verify the chain", func() {
+			req := s.request(http.MethodGet, url+"/audit-logs/verify", nil)
+			res, err := client.Do(req)
+			s.NoError(err)
+
+			respBody := new(test_utils.Response[struct {
+				Valid   bool `json:"valid"`
+				Checked int  `json:"checked"`
+			}])
+			_ = json.ReadJSON(res.Body, respBody)
+			defer res.Body.Close()
+
+			s.Equal(true, respBody.Success)
+			s.Equal(true, respBody.Data.Valid)
+			s.NotZero(respBody.Data.Checked)
+		})
+
+		s.Run("reject changes to entries", func() {
+			_, err := s.ts.Config.GetDB().Exec(
+				context.Background(),
+				`UPDATE audit_logs SET action = 'tampered'`,
+			)
+			s.Error(err)
+
+			_, err = s.ts.Config.GetDB().Exec(context.Background(), `DELETE FROM audit_logs`)
+			s.Error(err)
+		})
+	})
+
+	s.Run("business tiers", func() {
+		business, _ := test_utils.SignupBusinessUser(s.ts)
+
+		s.Run("quote fees for the seller", func() {
+			req := s.request(http.MethodGet, s.ts.Server.URL+"/api/v1/transactions/fees?type=Product&currency=NGN&amount=10001&buyer_charges=50&seller_charges=50&seller_id="+*business.BusinessID, nil)
+			res, err := client.Do(req)
+			s.NoError(err)
+
+			respBody := new(test_utils.Response[struct {
+				Quote test_utils.TestFeeQuote `json:"quote"`
+			}])
+			_ = json.ReadJSON(res.Body, respBody)
+			defer res.Body.Close()
+
+			s.Equal(true, respBody.Success)
+			s.Equal(301, respBody.Data.Quote.Charges)
+			s.Equal(151, respBody.Data.Quote.BuyerFee)
+			s.Equal(150, respBody.Data.Quote.SellerFee)
+			s.Equal(10152, respBody.Data.Quote.BuyerPayable)
+			s.Equal(9851, respBody.Data.Quote.SellerReceivable)
+		})
+
+		s.Run("reject a split that does not add up to 100", func() {
+			req := s.request(http.MethodGet, s.ts.Server.URL+"/api/v1/transactions/fees?type=Product&currency=NGN&amount=10000&buyer_charges=50&seller_charges=40", nil)
+			res, err := client.Do(req)
+			s.NoError(err)
+			defer res.Body.Close()
+
+			s.Equal(http.StatusBadRequest, res.StatusCode)
+		})
+
+		s.Run("set the tier", func() {
+			data, _ := json.Marshal(map[string]any{"tier": "growth"})
+			req := s.request(http.MethodPut, url+"/businesses/"+*business.BusinessID+"/tier", bytes.NewBuffer(data))
+			res, err := client.Do(req)
+			s.NoError(err)
+
+			respBody := new(test_utils.Response[struct {
+				Business test_utils.TestBusiness `json:"business"`
+			}])
+			_ = json.ReadJSON(res.Body, respBody)
+			defer res.Body.Close()
+
+			s.Equal(true, respBody.Success)
+			s.Equal("growth", respBody.Data.Business.Tier)
+		})
+
+		s.Run("reject unknown tiers", func() {
+			data, _ := json.Marshal(map[string]any{"tier": "platinum"})
+			req := s.request(http.MethodPut, url+"/businesses/"+*business.BusinessID+"/tier", bytes.NewBuffer(data))
+			res, err := client.Do(req)
+			s.NoError(err)
+			defer res.Body.Close()
+
+			s.Equal(http.StatusBadRequest, res.StatusCode)
+		})
+	})
+
+	s.Run("support cannot adjust balances", func() {
+		s.setRole("support")
+
+		data, _ := json.Marshal(map[string]any{
+			"amount": 10000,
+			"reason": "refund for failed paystack charge",
+		})
+		req := s.request(http.MethodPost, url+"/wallets/"+walletID+"/adjust", bytes.NewBuffer(data))
+		res, err := client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusForbidden, res.StatusCode)
+	})
+}
+
+func TestAdminHandlerSuite(t *testing.T) {
+	suite.Run(t, new(AdminHandlerTestSuite))
+}
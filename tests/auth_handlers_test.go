@@ -173,6 +173,26 @@ func (s *AuthHandlerTestSuite) TestAuthHandler() {
 			s.Equal("account already exists", respBody.Message)
 		})
 
+		s.Run("expects not found for a later stage without an account", func() {
+			payload, _ := json.WriteJSON(map[string]any{
+				"email":        "nobody@escrow.app",
+				"phone_number": "08012345678",
+				"reg_stage":    2,
+			})
+
+			res, err := post(url+"/sign-up", test_utils.ContentType, bytes.NewBuffer(payload))
+
+			s.NoError(err)
+			s.Equal(http.StatusNotFound, res.StatusCode)
+
+			defer res.Body.Close()
+			respBody := new(test_utils.Response[test_utils.SignupDataResponse])
+			_ = json.ReadJSON(res.Body, respBody)
+
+			s.Equal(false, respBody.Success)
+			s.Equal("not found", respBody.Message)
+		})
+
 		s.Run("phase 1 sign up for business", func() {
 			payload, _ := json.WriteJSON(map[string]any{
 				"email":         s.testBusiness.Email,
@@ -237,6 +257,33 @@ func (s *AuthHandlerTestSuite) TestAuthHandler() {
 			s.Empty(respBody.Meta)
 		})
 
+		s.Run("lock account after repeated failed sign ins", func() {
+			payload, _ := json.WriteJSON(map[string]any{
+				"email":    "unknown@user.com",
+				"password": "Pa55word",
+			})
+
+			for i := 0; i < 5; i++ {
+				res, err := post(url+"/sign-in", test_utils.ContentType, bytes.NewBuffer(payload))
+				s.NoError(err)
+				s.Equal(http.StatusBadRequest, res.StatusCode)
+				res.Body.Close()
+			}
+
+			res, err := post(url+"/sign-in", test_utils.ContentType, bytes.NewBuffer(payload))
+			s.NoError(err)
+			s.Equal(http.StatusTooManyRequests, res.StatusCode)
+			s.NotEmpty(res.Header.Get("Retry-After"))
+
+			defer res.Body.Close()
+
+			respBody := new(test_utils.Response[any])
+			_ = json.ReadJSON(res.Body, respBody)
+
+			s.Equal(false, respBody.Success)
+			s.Contains(respBody.Message, "too many failed sign in attempts")
+		})
+
 		s.Run("sign in unverified email account", func() {
 			payload, _ := json.WriteJSON(map[string]any{
 				"email":    s.testBusiness.Email,
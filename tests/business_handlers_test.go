@@ -0,0 +1,447 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/princecee/escrow-api/pkg/json"
+	"github.com/princecee/escrow-api/pkg/webhooks"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type BusinessHandlerTestSuite struct {
+	suite.Suite
+	ts          *test_utils.TestServer
+	user        test_utils.TestUser
+	accessToken string
+}
+
+func (s *BusinessHandlerTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+
+	user, token := test_utils.SignupBusinessUser(s.ts)
+	s.user = user
+	s.accessToken = token
+}
+
+func (s *BusinessHandlerTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+func (s *BusinessHandlerTestSuite) request(method, url, token string, body io.Reader) *http.Request {
+	req, _ := http.NewRequest(method, url, body)
+	req.Header = map[string][]string{
+		"Authorization": {fmt.Sprintf("Bearer %s", token)},
+		"Content-Type":  {test_utils.ContentType},
+	}
+
+	return req
+}
+
+type apiKeyResponse struct {
+	APIKey test_utils.TestAPIKey `json:"api_key"`
+	Key    string                `json:"key"`
+}
+
+func (s *BusinessHandlerTestSuite) TestAPIKeys() {
+	client := s.ts.Server.Client()
+	url := s.ts.Server.URL + "/api/v1/businesses/api-keys"
+
+	s.Run("reject unknown scopes", func() {
+		data, _ := json.Marshal(map[string]any{
+			"name":        "checkout",
+			"environment": "test",
+			"scopes":      []string{"wallet:drain"},
+		})
+		req := s.request(http.MethodPost, url, s.accessToken, bytes.NewBuffer(data))
+		res, err := client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+
+	var apiKey apiKeyResponse
+	s.Run("create api key", func() {
+		data, _ := json.Marshal(map[string]any{
+			"name":        "checkout",
+			"environment": "test",
+			"scopes":      []string{"wallet:read"},
+		})
+		req := s.request(http.MethodPost, url, s.accessToken, bytes.NewBuffer(data))
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[apiKeyResponse])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.True(strings.HasPrefix(respBody.Data.Key, "esk_test_"))
+		s.True(strings.HasPrefix(respBody.Data.Key, respBody.Data.APIKey.Prefix))
+		s.Equal(*s.user.BusinessID, respBody.Data.APIKey.BusinessID)
+		apiKey = respBody.Data
+	})
+
+	s.Run("authenticate with the api key", func() {
+		req := s.request(http.MethodGet, s.ts.Server.URL+"/api/v1/wallets", apiKey.Key, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusOK, res.StatusCode)
+	})
+
+	s.Run("require the scope", func() {
+		req := s.request(http.MethodGet, s.ts.Server.URL+"/api/v1/transactions", apiKey.Key, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusForbidden, res.StatusCode)
+	})
+
+	s.Run("refuse api keys on user endpoints", func() {
+		req := s.request(http.MethodGet, s.ts.Server.URL+"/api/v1/users/me", apiKey.Key, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusForbidden, res.StatusCode)
+
+		req = s.request(http.MethodGet, url, apiKey.Key, nil)
+		res, err = client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusForbidden, res.StatusCode)
+	})
+
+	var rotated apiKeyResponse
+	s.Run("rotate api key", func() {
+		req := s.request(http.MethodPost, url+"/"+apiKey.APIKey.ID+"/rotate", s.accessToken, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[apiKeyResponse])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.NotEqual(apiKey.Key, respBody.Data.Key)
+		s.Equal(apiKey.APIKey.Scopes, respBody.Data.APIKey.Scopes)
+		rotated = respBody.Data
+
+		req = s.request(http.MethodGet, s.ts.Server.URL+"/api/v1/wallets", apiKey.Key, nil)
+		res, err = client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusUnauthorized, res.StatusCode)
+	})
+
+	s.Run("list api keys", func() {
+		req := s.request(http.MethodGet, url, s.accessToken, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			APIKeys []test_utils.TestAPIKey `json:"api_keys"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal(2, respBody.Meta.Total)
+	})
+
+	s.Run("revoke api key", func() {
+		req := s.request(http.MethodDelete, url+"/"+rotated.APIKey.ID, s.accessToken, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[apiKeyResponse])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.NotNil(respBody.Data.APIKey.RevokedAt)
+
+		req = s.request(http.MethodGet, s.ts.Server.URL+"/api/v1/wallets", rotated.Key, nil)
+		res, err = client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+type webhookEndpointResponse struct {
+	WebhookEndpoint test_utils.TestWebhookEndpoint `json:"webhook_endpoint"`
+	Secret          string                         `json:"secret"`
+}
+
+// webhookReceiver records the requests posted to it and answers with status.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	rv.requests = append(rv.requests, r)
+	rv.bodies = append(rv.bodies, body)
+	w.WriteHeader(rv.status)
+}
+
+func (rv *webhookReceiver) setStatus(status int) {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	rv.status = status
+}
+
+func (rv *webhookReceiver) last() (*http.Request, []byte) {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	if len(rv.requests) == 0 {
+		return nil, nil
+	}
+
+	return rv.requests[len(rv.requests)-1], rv.bodies[len(rv.bodies)-1]
+}
+
+func (s *BusinessHandlerTestSuite) TestWebhooks() {
+	client := s.ts.Server.Client()
+	url := s.ts.Server.URL + "/api/v1/businesses/webhooks"
+
+	receiver := &webhookReceiver{status: http.StatusOK}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+
+	worker := webhooks.NewWorker(s.ts.Config)
+
+	getDeliveries := func(endpointId string) []test_utils.TestWebhookDelivery {
+		req := s.request(http.MethodGet, url+"/"+endpointId+"/deliveries", s.accessToken, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			WebhookDeliveries []test_utils.TestWebhookDelivery `json:"webhook_deliveries"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		return respBody.Data.WebhookDeliveries
+	}
+
+	sendPing := func(endpointId string) {
+		req := s.request(http.MethodPost, url+"/"+endpointId+"/test", s.accessToken, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusOK, res.StatusCode)
+
+		_, err = worker.ProcessDue(context.Background())
+		s.NoError(err)
+	}
+
+	s.Run("reject unknown event types", func() {
+		data, _ := json.Marshal(map[string]any{
+			"url":         receiverServer.URL,
+			"event_types": []string{"wallet.drained"},
+		})
+		req := s.request(http.MethodPost, url, s.accessToken, bytes.NewBuffer(data))
+		res, err := client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+
+	s.Run("refuse webhook urls reaching private addresses", func() {
+		s.ts.Config.GetSettings().AllowPrivateWebhooks = false
+		defer func() { s.ts.Config.GetSettings().AllowPrivateWebhooks = true }()
+
+		for _, target := range []string{receiverServer.URL, "http://169.254.169.254/latest/meta-data", "http://10.0.0.1/hooks", "http://[::1]/hooks"} {
+			data, _ := json.Marshal(map[string]any{
+				"url":         target,
+				"event_types": []string{"*"},
+			})
+			req := s.request(http.MethodPost, url, s.accessToken, bytes.NewBuffer(data))
+			res, err := client.Do(req)
+			s.NoError(err)
+			res.Body.Close()
+
+			s.Equal(http.StatusBadRequest, res.StatusCode, target)
+		}
+	})
+
+	var endpoint webhookEndpointResponse
+	s.Run("create webhook endpoint", func() {
+		data, _ := json.Marshal(map[string]any{
+			"url":         receiverServer.URL,
+			"event_types": []string{"*"},
+			"description": "orders service",
+		})
+		req := s.request(http.MethodPost, url, s.accessToken, bytes.NewBuffer(data))
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[webhookEndpointResponse])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.True(strings.HasPrefix(respBody.Data.Secret, "whsec_"))
+		s.Equal(true, respBody.Data.WebhookEndpoint.IsActive)
+
+		endpoint = respBody.Data
+	})
+
+	s.Run("deliver a signed test event", func() {
+		sendPing(endpoint.WebhookEndpoint.ID)
+
+		req, body := receiver.last()
+		s.NotNil(req)
+		s.Equal(webhooks.EventPing, req.Header.Get(webhooks.EventHeader))
+		s.NotEmpty(req.Header.Get("traceparent"))
+		s.NoError(webhooks.Verify(endpoint.Secret, req.Header.Get(webhooks.SignatureHeader), body, time.Minute))
+		s.ErrorIs(webhooks.Verify("whsec_wrong", req.Header.Get(webhooks.SignatureHeader), body, time.Minute), webhooks.ErrInvalidSignature)
+
+		deliveries := getDeliveries(endpoint.WebhookEndpoint.ID)
+		s.Equal(1, len(deliveries))
+		s.Equal("succeeded", deliveries[0].Status)
+		s.Equal(1, deliveries[0].Attempts)
+	})
+
+	s.Run("refuse private addresses when delivering", func() {
+		// the url was accepted when it was registered, what it resolves to
+		// is checked again on every attempt
+		s.ts.Config.GetSettings().AllowPrivateWebhooks = false
+		sendPing(endpoint.WebhookEndpoint.ID)
+		s.ts.Config.GetSettings().AllowPrivateWebhooks = true
+
+		deliveries := getDeliveries(endpoint.WebhookEndpoint.ID)
+		s.Equal("pending", deliveries[0].Status)
+		s.Zero(deliveries[0].ResponseStatus)
+		s.Contains(deliveries[0].Error, webhooks.ErrPrivateAddress.Error())
+	})
+
+	var failed test_utils.TestWebhookDelivery
+	s.Run("retry failed deliveries", func() {
+		receiver.setStatus(http.StatusInternalServerError)
+		sendPing(endpoint.WebhookEndpoint.ID)
+
+		deliveries := getDeliveries(endpoint.WebhookEndpoint.ID)
+		s.Equal(3, len(deliveries))
+
+		failed = deliveries[0]
+		s.Equal("pending", failed.Status)
+		s.Equal(1, failed.Attempts)
+		s.Equal(http.StatusInternalServerError, failed.ResponseStatus)
+
+		// the retry is scheduled with a backoff so it is not due yet
+		n, err := worker.ProcessDue(context.Background())
+		s.NoError(err)
+		s.Equal(0, n)
+	})
+
+	s.Run("redeliver", func() {
+		receiver.setStatus(http.StatusOK)
+
+		req := s.request(http.MethodPost, url+"/"+endpoint.WebhookEndpoint.ID+"/deliveries/"+failed.ID+"/redeliver", s.accessToken, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			WebhookDelivery test_utils.TestWebhookDelivery `json:"webhook_delivery"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal("succeeded", respBody.Data.WebhookDelivery.Status)
+		s.Equal(2, respBody.Data.WebhookDelivery.Attempts)
+	})
+
+	s.Run("do not follow redirects", func() {
+		redirector := httptest.NewServer(http.RedirectHandler(receiverServer.URL, http.StatusFound))
+		defer redirector.Close()
+
+		data, _ := json.Marshal(map[string]any{
+			"url":         redirector.URL,
+			"event_types": []string{"*"},
+		})
+		req := s.request(http.MethodPost, url, s.accessToken, bytes.NewBuffer(data))
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[webhookEndpointResponse])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+
+		before, _ := receiver.last()
+		sendPing(respBody.Data.WebhookEndpoint.ID)
+		after, _ := receiver.last()
+		s.Same(before, after)
+
+		deliveries := getDeliveries(respBody.Data.WebhookEndpoint.ID)
+		s.Equal(http.StatusFound, deliveries[0].ResponseStatus)
+		s.Equal("pending", deliveries[0].Status)
+	})
+
+	s.Run("disable webhook endpoint", func() {
+		data, _ := json.Marshal(map[string]any{"is_active": false})
+		req := s.request(http.MethodPut, url+"/"+endpoint.WebhookEndpoint.ID, s.accessToken, bytes.NewBuffer(data))
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			WebhookEndpoint test_utils.TestWebhookEndpoint `json:"webhook_endpoint"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal(false, respBody.Data.WebhookEndpoint.IsActive)
+	})
+
+	s.Run("delete webhook endpoint", func() {
+		req := s.request(http.MethodDelete, url+"/"+endpoint.WebhookEndpoint.ID, s.accessToken, nil)
+		res, err := client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusOK, res.StatusCode)
+
+		req = s.request(http.MethodGet, url+"/"+endpoint.WebhookEndpoint.ID+"/deliveries", s.accessToken, nil)
+		res, err = client.Do(req)
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestBusinessHandlerSuite(t *testing.T) {
+	suite.Run(t, new(BusinessHandlerTestSuite))
+}
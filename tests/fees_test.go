@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/apis/paystack"
+	"github.com/princecee/escrow-api/pkg/fees"
+	"github.com/stretchr/testify/suite"
+)
+
+// FeesTestSuite prices amounts against fee schedules. It needs no server.
+type FeesTestSuite struct {
+	suite.Suite
+}
+
+func (s *FeesTestSuite) TestBands() {
+	schedule := fees.Schedule{Bands: []fees.Band{
+		{UpTo: 100000, Rate: 500},
+		{UpTo: 1000000, Rate: 300},
+		{Rate: 100},
+	}}
+
+	cases := []struct {
+		name   string
+		amount int
+		fee    int
+	}{
+		{"nothing", 0, 0},
+		{"inside the first band", 50000, 2500},
+		{"on the first edge", 100000, 5000},
+		{"just past the first edge", 100001, 5001},
+		{"on the second edge", 1000000, 32000},
+		{"just past the second edge", 1000001, 32001},
+		{"deep in the unbounded band", 2000000, 42000},
+	}
+
+	for _, c := range cases {
+		s.Run(c.name, func() {
+			fee, err := schedule.Fee(c.amount)
+			s.NoError(err)
+			s.Equal(c.fee, fee)
+		})
+	}
+}
+
+func (s *FeesTestSuite) TestClamping() {
+	cases := []struct {
+		name     string
+		schedule fees.Schedule
+		amount   int
+		fee      int
+	}{
+		{"round a rate up", fees.Schedule{Rate: 300}, 1, 1},
+		{"round just under a unit up", fees.Schedule{Rate: 300}, 33, 1},
+		{"round just over a unit up", fees.Schedule{Rate: 300}, 34, 2},
+		{"raise to the min", fees.Schedule{Rate: 300, Flat: 100, Min: 1000, Max: 20000}, 0, 1000},
+		{"keep a fee on the min", fees.Schedule{Rate: 300, Flat: 100, Min: 1000, Max: 20000}, 30000, 1000},
+		{"add the flat fee", fees.Schedule{Rate: 300, Flat: 100, Min: 1000, Max: 20000}, 100000, 3100},
+		{"keep a fee on the max", fees.Schedule{Rate: 300, Flat: 100, Min: 1000, Max: 20000}, 663333, 20000},
+		{"cap at the max", fees.Schedule{Rate: 300, Flat: 100, Min: 1000, Max: 20000}, 1000000, 20000},
+		{"leave a zero max uncapped", fees.Schedule{Rate: 300}, 100000000, 3000000},
+		{"clamp banded fees", fees.Schedule{Max: 6000, Bands: []fees.Band{{UpTo: 100000, Rate: 500}, {Rate: 300}}}, 1000000, 6000},
+	}
+
+	for _, c := range cases {
+		s.Run(c.name, func() {
+			fee, err := c.schedule.Fee(c.amount)
+			s.NoError(err)
+			s.Equal(c.fee, fee)
+		})
+	}
+}
+
+func (s *FeesTestSuite) TestPrecedence() {
+	engine, err := fees.NewEngine([]fees.Schedule{
+		{Rate: 300},
+		{Currency: "USD", Rate: 350},
+		{Tier: "gold", Rate: 200},
+		{Tier: "gold", Currency: "USD", Rate: 150},
+		{TransactionType: "Service", Rate: 250},
+		{Currency: "USD", Rate: 999},
+	})
+	s.NoError(err)
+
+	cases := []struct {
+		name            string
+		transactionType string
+		currency        string
+		tier            string
+		rate            int
+	}{
+		{"fall back to the catch-all", "Product", "NGN", "", 300},
+		{"prefer a currency match", "Product", "USD", "", 350},
+		{"prefer a tier match", "Product", "NGN", "gold", 200},
+		{"prefer matching tier and currency", "Product", "USD", "gold", 150},
+		{"prefer a type match", "Service", "NGN", "", 250},
+		{"let the first listed win a tie", "Service", "NGN", "gold", 200},
+	}
+
+	for _, c := range cases {
+		s.Run(c.name, func() {
+			schedule, err := engine.Schedule(c.transactionType, c.currency, c.tier)
+			s.NoError(err)
+			s.Equal(c.rate, schedule.Rate)
+		})
+	}
+
+	s.Run("fail without a matching schedule", func() {
+		engine, err := fees.NewEngine([]fees.Schedule{{Currency: "USD", Rate: 350}})
+		s.NoError(err)
+
+		_, err = engine.Schedule("Product", "NGN", "")
+		s.ErrorIs(err, fees.ErrNoSchedule)
+	})
+}
+
+func (s *FeesTestSuite) TestInvalidSchedules() {
+	cases := []struct {
+		name     string
+		schedule fees.Schedule
+	}{
+		{"negative rate", fees.Schedule{Rate: -1}},
+		{"max below min", fees.Schedule{Min: 1000, Max: 500}},
+		{"unbounded band before the last", fees.Schedule{Bands: []fees.Band{{Rate: 500}, {UpTo: 100000, Rate: 300}}}},
+		{"bands out of order", fees.Schedule{Bands: []fees.Band{{UpTo: 100000, Rate: 500}, {UpTo: 50000, Rate: 300}, {Rate: 100}}}},
+	}
+
+	for _, c := range cases {
+		s.Run(c.name, func() {
+			_, err := fees.NewEngine([]fees.Schedule{c.schedule})
+			s.Error(err)
+		})
+	}
+}
+
+func (s *FeesTestSuite) TestQuote() {
+	engine, err := fees.NewEngine(fees.DefaultSchedules)
+	s.NoError(err)
+
+	s.Run("split the charges between both parties", func() {
+		quote, err := engine.Quote(fees.Params{
+			TransactionType:     "Product",
+			Currency:            "NGN",
+			Amount:              1000001,
+			ChargeConfiguration: models.ChargeConfiguration{BuyerCharges: 33, SellerCharges: 67},
+		})
+		s.NoError(err)
+		s.Equal(30001, quote.Charges)
+		s.Equal(9900, quote.BuyerFee)
+		s.Equal(20101, quote.SellerFee)
+		s.Equal(1009901, quote.BuyerPayable)
+		s.Equal(979900, quote.SellerReceivable)
+	})
+
+	s.Run("reject a split not adding up to 100", func() {
+		_, err := engine.Quote(fees.Params{
+			Amount:              1000,
+			ChargeConfiguration: models.ChargeConfiguration{BuyerCharges: 60, SellerCharges: 60},
+		})
+		s.ErrorIs(err, fees.ErrInvalidSplit)
+	})
+
+	s.Run("reject a negative amount", func() {
+		_, err := engine.Quote(fees.Params{
+			Amount:              -1,
+			ChargeConfiguration: models.ChargeConfiguration{BuyerCharges: 50, SellerCharges: 50},
+		})
+		s.ErrorIs(err, fees.ErrInvalidAmount)
+	})
+}
+
+func (s *FeesTestSuite) TestSplit() {
+	cases := []struct {
+		name   string
+		config models.ChargeConfiguration
+		amount int
+		buyer  int
+		seller int
+	}{
+		{"give the leftover unit to the larger remainder", models.ChargeConfiguration{BuyerCharges: 33, SellerCharges: 67}, 30001, 9900, 20101},
+		{"give a single unit to the buyer on a tie", models.ChargeConfiguration{BuyerCharges: 50, SellerCharges: 50}, 1, 1, 0},
+		{"split an odd amount evenly but for one unit", models.ChargeConfiguration{BuyerCharges: 50, SellerCharges: 50}, 3, 2, 1},
+		{"leave everything to the buyer", models.ChargeConfiguration{BuyerCharges: 100}, 10, 10, 0},
+		{"leave everything to the seller", models.ChargeConfiguration{SellerCharges: 100}, 100, 0, 100},
+		{"leave everything to the seller without shares", models.ChargeConfiguration{}, 7, 0, 7},
+		{"split nothing", models.ChargeConfiguration{BuyerCharges: 33, SellerCharges: 67}, 0, 0, 0},
+	}
+
+	for _, c := range cases {
+		s.Run(c.name, func() {
+			buyer, seller := c.config.Split(c.amount)
+			s.Equal(c.buyer, buyer)
+			s.Equal(c.seller, seller)
+			s.Equal(c.amount, buyer+seller)
+		})
+	}
+}
+
+func (s *FeesTestSuite) TestGatewayFee() {
+	cases := []struct {
+		name     string
+		currency string
+		amount   int
+		fee      int
+	}{
+		{"charge nothing on nothing", "NGN", 0, 0},
+		{"waive the naira flat fee under its floor", "NGN", 100000, 1500},
+		{"add the naira flat fee from its floor", "NGN", 250000, 13750},
+		{"cap naira fees", "NGN", 20000000, 200000},
+		{"charge cedis their own rate", "GHS", 100000, 1950},
+		{"round a cedi fee up", "GHS", 1, 1},
+		{"charge shillings their own rate", "KES", 100000, 2900},
+		{"charge dollars without a naira flat fee", "USD", 250000, 9750},
+		{"leave dollar fees uncapped", "USD", 20000000, 780000},
+	}
+
+	for _, c := range cases {
+		s.Run(c.name, func() {
+			fee, err := paystack.Fee(c.amount, c.currency)
+			s.NoError(err)
+			s.Equal(c.fee, fee)
+		})
+	}
+
+	s.Run("reject a currency without a schedule", func() {
+		_, err := paystack.Fee(100000, "EUR")
+		s.ErrorIs(err, paystack.ErrNoFeeSchedule)
+	})
+}
+
+func TestFeesSuite(t *testing.T) {
+	suite.Run(t, new(FeesTestSuite))
+}
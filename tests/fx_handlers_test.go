@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/princecee/escrow-api/pkg/json"
+	"github.com/princecee/escrow-api/pkg/money"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type FXHandlerTestSuite struct {
+	suite.Suite
+	ts          *test_utils.TestServer
+	user        test_utils.TestUser
+	accessToken string
+}
+
+func (s *FXHandlerTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+
+	user, token := test_utils.SignupPersonalUser(s.ts)
+	s.user = user
+	s.accessToken = token
+}
+
+func (s *FXHandlerTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+func (s *FXHandlerTestSuite) post(url string, body io.Reader) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, url, body)
+	req.Header = map[string][]string{
+		"Authorization": {fmt.Sprintf("Bearer %s", s.accessToken)},
+		"Content-Type":  {test_utils.ContentType},
+	}
+
+	return req
+}
+
+func (s *FXHandlerTestSuite) createQuote(body map[string]any) (*http.Response, test_utils.TestFXQuote) {
+	data, _ := json.Marshal(body)
+	res, err := s.ts.Server.Client().Do(s.post(s.ts.Server.URL+"/api/v1/wallets/fx/quotes", bytes.NewBuffer(data)))
+	s.NoError(err)
+
+	respBody := new(test_utils.Response[struct {
+		Quote test_utils.TestFXQuote `json:"quote"`
+	}])
+	_ = json.ReadJSON(res.Body, respBody)
+	res.Body.Close()
+
+	return res, respBody.Data.Quote
+}
+
+func (s *FXHandlerTestSuite) execute(quoteId string) *http.Response {
+	url := fmt.Sprintf("%s/api/v1/wallets/fx/quotes/%s/execute", s.ts.Server.URL, quoteId)
+	res, err := s.ts.Server.Client().Do(s.post(url, nil))
+	s.NoError(err)
+
+	return res
+}
+
+func (s *FXHandlerTestSuite) TestConversion() {
+	walletRepo := s.ts.Config.GetWalletRepository()
+
+	var quoteId string
+	s.Run("quote a conversion", func() {
+		res, quote := s.createQuote(map[string]any{
+			"amount":        1500000,
+			"from_currency": "NGN",
+			"to_currency":   "USD",
+		})
+
+		s.Equal(http.StatusOK, res.StatusCode)
+		s.Equal("0.00066666", quote.MidRate)
+		s.Equal("0.00065999", quote.Rate)
+		s.Equal(100, quote.Spread)
+		s.Equal(989, quote.ConvertedAmount)
+		s.Equal("Pending", quote.Status)
+		quoteId = quote.ID
+	})
+
+	s.Run("reject converting a currency to itself", func() {
+		res, _ := s.createQuote(map[string]any{
+			"amount":        1500000,
+			"from_currency": "NGN",
+			"to_currency":   "NGN",
+		})
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+
+	s.Run("reject an unfunded conversion", func() {
+		res := s.execute(quoteId)
+		respBody := new(test_utils.Response[any])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+		s.Equal("insufficient balance", respBody.Message)
+	})
+
+	s.Run("execute a quote", func() {
+		wallet, err := walletRepo.GetOrCreate(context.Background(), s.user.ID, "personal", money.NGN, nil)
+		s.NoError(err)
+		_, err = walletRepo.Credit(context.Background(), wallet.ID, money.New(2000000, money.NGN), nil)
+		s.NoError(err)
+
+		res := s.execute(quoteId)
+		respBody := new(test_utils.Response[struct {
+			Quote           test_utils.TestFXQuote         `json:"quote"`
+			WalletHistories []test_utils.TestWalletHistory `json:"wallet_histories"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal("Executed", respBody.Data.Quote.Status)
+		s.NotNil(respBody.Data.Quote.ExecutedAt)
+		s.Len(respBody.Data.WalletHistories, 2)
+		s.Equal("Conversion", respBody.Data.WalletHistories[0].Type)
+
+		ngn, err := walletRepo.GetByIdentifier(context.Background(), s.user.ID, money.NGN, nil)
+		s.NoError(err)
+		s.Equal(500000, ngn.Balance)
+
+		usd, err := walletRepo.GetByIdentifier(context.Background(), s.user.ID, money.USD, nil)
+		s.NoError(err)
+		s.Equal(989, usd.Balance)
+		s.Equal(989, usd.Receivable)
+	})
+
+	s.Run("execute a quote only once", func() {
+		res := s.execute(quoteId)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusConflict, res.StatusCode)
+	})
+
+	s.Run("reject an expired quote", func() {
+		_, quote := s.createQuote(map[string]any{
+			"amount":        100,
+			"from_currency": "USD",
+			"to_currency":   "NGN",
+		})
+		s.Equal(148500, quote.ConvertedAmount)
+
+		_, err := s.ts.Config.GetDB().Exec(
+			context.Background(),
+			`UPDATE fx_quotes SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1`,
+			quote.ID,
+		)
+		s.NoError(err)
+
+		res := s.execute(quote.ID)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestFXHandlerSuite(t *testing.T) {
+	suite.Run(t, new(FXHandlerTestSuite))
+}
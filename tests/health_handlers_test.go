@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/princecee/escrow-api/pkg/json"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthTestSuite struct {
+	suite.Suite
+	ts *test_utils.TestServer
+}
+
+func (s *HealthTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+}
+
+func (s *HealthTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+func (s *HealthTestSuite) get(path string) (*http.Response, map[string]string) {
+	res, err := s.ts.Server.Client().Get(s.ts.Server.URL + path)
+	s.NoError(err)
+	defer res.Body.Close()
+
+	respBody := new(test_utils.Response[map[string]string])
+	_ = json.ReadJSON(res.Body, respBody)
+
+	return res, respBody.Data
+}
+
+func (s *HealthTestSuite) TestProbes() {
+	s.Run("report the app alive", func() {
+		res, _ := s.get("/healthz")
+		s.Equal(http.StatusOK, res.StatusCode)
+	})
+
+	s.Run("report the app ready when its dependencies are up", func() {
+		res, checks := s.get("/readyz")
+		s.Equal(http.StatusOK, res.StatusCode)
+		s.Equal("ok", checks["postgres"])
+		s.Equal("ok", checks["redis"])
+		s.NotContains(checks, "smtp")
+	})
+
+	s.Run("report the app not ready when a dependency is down", func() {
+		settings := s.ts.Config.GetSettings()
+		email := settings.Email
+		defer func() { settings.Email = email }()
+
+		// nothing listens on port 1
+		settings.Email.Host = "127.0.0.1"
+		settings.Email.Port = "1"
+
+		res, checks := s.get("/readyz")
+		s.Equal(http.StatusServiceUnavailable, res.StatusCode)
+		s.Equal("ok", checks["postgres"])
+		s.NotEqual("ok", checks["smtp"])
+
+		res, _ = s.get("/healthz")
+		s.Equal(http.StatusOK, res.StatusCode)
+	})
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/princecee/escrow-api/pkg/idempotency"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type IdempotencyTestSuite struct {
+	suite.Suite
+	ts *test_utils.TestServer
+}
+
+func (s *IdempotencyTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+}
+
+func (s *IdempotencyTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+func (s *IdempotencyTestSuite) TestHold() {
+	ctx := context.Background()
+	store := idempotency.NewStore(s.ts.Config.GetRedisClient().DB, idempotency.Options{
+		TTL:     time.Hour,
+		LockTTL: 300 * time.Millisecond,
+	})
+	hash := idempotency.HashRequest("POST", "/withdraw", []byte(`{}`))
+
+	s.Run("keep the key locked past its lock ttl while held", func() {
+		record, err := store.Begin(ctx, "user-1", "slow", hash)
+		s.NoError(err)
+		s.Nil(record)
+
+		stop := store.Hold("user-1", "slow", hash)
+		time.Sleep(time.Second)
+
+		_, err = store.Begin(ctx, "user-1", "slow", hash)
+		s.ErrorIs(err, idempotency.ErrInProgress)
+
+		stop()
+		s.NoError(store.Complete(ctx, "user-1", "slow", &idempotency.Record{RequestHash: hash, StatusCode: 200}))
+
+		record, err = store.Begin(ctx, "user-1", "slow", hash)
+		s.NoError(err)
+		s.Equal(200, record.StatusCode)
+	})
+
+	s.Run("leave a completed record to its own ttl", func() {
+		ttl, err := s.ts.Config.GetRedisClient().DB.TTL(ctx, "idempotency:user-1:slow").Result()
+		s.NoError(err)
+		s.Greater(ttl, time.Minute)
+	})
+
+	s.Run("free the key once its lock expires unheld", func() {
+		_, err := store.Begin(ctx, "user-1", "crashed", hash)
+		s.NoError(err)
+
+		time.Sleep(500 * time.Millisecond)
+
+		record, err := store.Begin(ctx, "user-1", "crashed", hash)
+		s.NoError(err)
+		s.Nil(record)
+	})
+}
+
+func TestIdempotencySuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyTestSuite))
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/princecee/escrow-api/pkg/invites"
+	"github.com/princecee/escrow-api/pkg/json"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// InviteTestSuite runs invites from start to end: a buyer creates a
+// transaction for a seller without an account, who then joins it by signing
+// up or by following the invite link.
+type InviteTestSuite struct {
+	suite.Suite
+	ts          *test_utils.TestServer
+	user        test_utils.TestUser
+	accessToken string
+}
+
+func (s *InviteTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+
+	user, token := test_utils.SignupPersonalUser(s.ts)
+	s.user = user
+	s.accessToken = token
+}
+
+func (s *InviteTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+func (s *InviteTestSuite) post(url, token string, body io.Reader) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, url, body)
+	req.Header = map[string][]string{
+		"Authorization": {fmt.Sprintf("Bearer %s", token)},
+		"Content-Type":  {test_utils.ContentType},
+	}
+
+	return req
+}
+
+// invite creates a transaction for a seller reached at email and returns the
+// transaction and its invite.
+func (s *InviteTestSuite) invite(email string) (map[string]any, map[string]any) {
+	return s.inviteAs(s.accessToken, "Buyer", email)
+}
+
+// inviteAs creates, with token, a transaction on the createdBy side for a
+// counterparty reached at email.
+func (s *InviteTestSuite) inviteAs(token, createdBy, email string) (map[string]any, map[string]any) {
+	data, _ := json.Marshal(map[string]any{
+		"type":              "Product",
+		"created_by":        createdBy,
+		"delivery_duration": 3,
+		"currency":          "NGN",
+		"charge_configuration": map[string]any{
+			"buyer_charges":  50,
+			"seller_charges": 50,
+		},
+		"counterparty": map[string]any{"name": "Ada's Phones", "email": email},
+		"product_details": []map[string]any{
+			{"name": "Phone", "description": "Blue", "quantity": 1, "price": 500000},
+		},
+	})
+	res, err := s.ts.Server.Client().Do(s.post(s.ts.Server.URL+"/api/v1/transactions/create", token, bytes.NewBuffer(data)))
+	s.NoError(err)
+	defer res.Body.Close()
+
+	respBody := new(test_utils.Response[map[string]map[string]any])
+	_ = json.ReadJSON(res.Body, respBody)
+
+	s.Equal(http.StatusOK, res.StatusCode)
+	s.Equal("Pending", respBody.Data["invite"]["status"])
+
+	return respBody.Data["transaction"], respBody.Data["invite"]
+}
+
+func (s *InviteTestSuite) sellerOf(transactionId any) string {
+	var sellerId string
+	err := s.ts.Config.GetDB().QueryRow(context.Background(), `SELECT seller_id FROM transactions WHERE id = $1`, transactionId).Scan(&sellerId)
+	s.NoError(err)
+
+	return sellerId
+}
+
+func (s *InviteTestSuite) buyerOf(transactionId any) string {
+	var buyerId string
+	err := s.ts.Config.GetDB().QueryRow(context.Background(), `SELECT buyer_id FROM transactions WHERE id = $1`, transactionId).Scan(&buyerId)
+	s.NoError(err)
+
+	return buyerId
+}
+
+func (s *InviteTestSuite) statusOf(inviteId any) string {
+	var status string
+	err := s.ts.Config.GetDB().QueryRow(context.Background(), `SELECT status FROM transaction_invites WHERE id = $1`, inviteId).Scan(&status)
+	s.NoError(err)
+
+	return status
+}
+
+func (s *InviteTestSuite) TestInviteFlow() {
+	client := s.ts.Server.Client()
+	acceptUrl := s.ts.Server.URL + "/api/v1/transactions/invites/accept"
+
+	accept := func(token, inviteId string) int {
+		data, _ := json.Marshal(map[string]any{"token": invites.Sign([]byte(s.ts.Config.GetSettings().InviteKey), inviteId, time.Now().Add(time.Hour))})
+		res, err := client.Do(s.post(acceptUrl, token, bytes.NewBuffer(data)))
+		s.NoError(err)
+		res.Body.Close()
+
+		return res.StatusCode
+	}
+
+	// the seller signs up with the email of the first invite and is passed
+	// the link of the second
+	signedUp, signedUpInvite := s.invite("testbusiness@user.com")
+	linked, linkedInvite := s.invite("sales@elsewhere.com")
+
+	s.NotEqual(s.user.ID, signedUp["seller_id"])
+
+	business, businessToken := test_utils.SignupBusinessUser(s.ts)
+	s.NotNil(business.BusinessID)
+
+	s.Run("attach invites to the account signed up with their email", func() {
+		s.Equal(*business.BusinessID, s.sellerOf(signedUp["id"]))
+		s.Equal("Accepted", s.statusOf(signedUpInvite["id"]))
+	})
+
+	s.Run("leave invites to other emails pending", func() {
+		s.Equal(linked["seller_id"], s.sellerOf(linked["id"]))
+		s.Equal("Pending", s.statusOf(linkedInvite["id"]))
+	})
+
+	s.Run("forbid accepting your own invite", func() {
+		s.Equal(http.StatusForbidden, accept(s.accessToken, linkedInvite["id"].(string)))
+	})
+
+	s.Run("forbid accepting a seller invite sent to someone else", func() {
+		s.Equal(http.StatusForbidden, accept(businessToken, linkedInvite["id"].(string)))
+		s.Equal(linked["seller_id"], s.sellerOf(linked["id"]))
+		s.Equal("Pending", s.statusOf(linkedInvite["id"]))
+	})
+
+	s.Run("accept a buyer invite by link", func() {
+		sold, soldInvite := s.inviteAs(businessToken, "Seller", "buyer@elsewhere.com")
+
+		s.Equal(http.StatusOK, accept(s.accessToken, soldInvite["id"].(string)))
+		s.Equal(s.user.ID, s.buyerOf(sold["id"]))
+		s.Equal("Accepted", s.statusOf(soldInvite["id"]))
+	})
+
+	s.Run("reject an invite accepted already", func() {
+		s.Equal(http.StatusConflict, accept(businessToken, signedUpInvite["id"].(string)))
+	})
+}
+
+func TestInviteSuite(t *testing.T) {
+	suite.Run(t, new(InviteTestSuite))
+}
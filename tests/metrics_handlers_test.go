@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/princecee/escrow-api/pkg/metrics"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+	ts *test_utils.TestServer
+}
+
+func (s *MetricsTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+}
+
+func (s *MetricsTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+// scrape reads the metrics as served on the internal metrics port.
+func (s *MetricsTestSuite) scrape() string {
+	srv := httptest.NewServer(metrics.Handler())
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL)
+	s.NoError(err)
+	defer res.Body.Close()
+
+	s.Equal(http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	s.NoError(err)
+
+	return string(body)
+}
+
+func (s *MetricsTestSuite) TestMetrics() {
+	test_utils.SignupPersonalUser(s.ts)
+
+	res, err := s.ts.Server.Client().Get(s.ts.Server.URL + "/api/v1/transactions/some-id")
+	s.NoError(err)
+	res.Body.Close()
+
+	metrics := s.scrape()
+
+	s.Run("record requests by route pattern", func() {
+		s.Contains(metrics, `escrow_http_requests_total{method="POST",route="/api/v1/auth/sign-up"`)
+		s.Contains(metrics, `route="/api/v1/transactions/{transaction_id}"`)
+		s.NotContains(metrics, "some-id")
+	})
+
+	s.Run("record queries by repository method", func() {
+		s.Contains(metrics, `escrow_db_query_duration_seconds_count{method="Create",repository="UserRepository"}`)
+	})
+
+	s.Run("keep metrics off the api listener", func() {
+		res, err := s.ts.Server.Client().Get(s.ts.Server.URL + "/metrics")
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestMetricsSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
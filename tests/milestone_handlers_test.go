@@ -0,0 +1,296 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/princecee/escrow-api/pkg/money"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// MilestoneTestSuite funds, releases and disputes service transactions paid
+// for milestone by milestone.
+type MilestoneTestSuite struct {
+	suite.Suite
+	ts *test_utils.TestServer
+	test_utils.Parties
+	adminToken string
+}
+
+func (s *MilestoneTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+	s.Parties = test_utils.SignupParties(s.ts)
+	_, s.adminToken = test_utils.SignupAdminUser(s.ts)
+}
+
+func (s *MilestoneTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+// accepted creates a service transaction of the buyer with the business in two
+// milestones and moves it to pending payment.
+func (s *MilestoneTestSuite) accepted() string {
+	status, respBody := s.ts.Request(http.MethodPost, "/api/v1/transactions/create", s.BuyerToken, map[string]any{
+		"type":              "Service",
+		"created_by":        "Buyer",
+		"delivery_duration": 30,
+		"currency":          "NGN",
+		"charge_configuration": map[string]any{
+			"buyer_charges":  50,
+			"seller_charges": 50,
+		},
+		"counterparty": map[string]any{"name": "Test Business", "email": s.Business.Email},
+		"milestones": []map[string]any{
+			{"title": "Design", "amount": 100000, "due_date": time.Now().Add(7 * 24 * time.Hour)},
+			{"title": "Build", "amount": 300000, "due_date": time.Now().Add(21 * 24 * time.Hour)},
+		},
+	})
+	s.Equal(http.StatusOK, status)
+
+	id := respBody.Data["transaction"]["id"].(string)
+	status, _ = s.ts.Request(http.MethodPut, "/api/v1/transactions/"+id, s.BusinessToken, map[string]any{"status": "Pending-Payment"})
+	s.Equal(http.StatusOK, status)
+
+	return id
+}
+
+func (s *MilestoneTestSuite) milestones(id string) []*models.Milestone {
+	milestones, err := s.ts.Config.GetMilestoneRepository().GetByTransactionId(context.Background(), id, nil)
+	s.NoError(err)
+	s.Len(milestones, 2)
+
+	return milestones
+}
+
+func (s *MilestoneTestSuite) transaction(id string) *models.Transaction {
+	transaction, err := s.ts.Config.GetTransactionRepository().GetById(context.Background(), id, nil)
+	s.NoError(err)
+
+	return transaction
+}
+
+func (s *MilestoneTestSuite) balance(identifier string) int {
+	wallet, err := s.ts.Config.GetWalletRepository().GetByIdentifier(context.Background(), identifier, money.NGN, nil)
+	if err != nil {
+		return 0
+	}
+
+	return wallet.Balance
+}
+
+func (s *MilestoneTestSuite) pay(id, milestoneID string) int {
+	status, _ := s.ts.Request(http.MethodPost, "/api/v1/transactions/pay", s.BuyerToken, map[string]any{
+		"transaction_id": id,
+		"is_use_wallet":  true,
+		"milestone_id":   milestoneID,
+	})
+	return status
+}
+
+func (s *MilestoneTestSuite) release(id, milestoneID string) int {
+	status, _ := s.ts.Request(http.MethodPost, "/api/v1/transactions/"+id+"/milestones/"+milestoneID+"/release", s.BuyerToken, nil)
+	return status
+}
+
+// dispute opens a dispute on the transaction and returns its id.
+func (s *MilestoneTestSuite) dispute(id string) string {
+	status, respBody := s.ts.Request(http.MethodPost, "/api/v1/transactions/"+id+"/disputes", s.BuyerToken, map[string]any{
+		"reason": "the second milestone was never delivered",
+	})
+	s.Equal(http.StatusOK, status)
+
+	return respBody.Data["dispute"]["id"].(string)
+}
+
+func (s *MilestoneTestSuite) resolve(disputeID, resolution string) int {
+	status, _ := s.ts.Request(http.MethodPost, "/api/v1/admin/disputes/"+disputeID+"/resolve", s.adminToken, map[string]any{
+		"resolution": resolution,
+		"note":       "paid out what is still held in escrow",
+	})
+	return status
+}
+
+func (s *MilestoneTestSuite) TestFundAndRelease() {
+	sellerID := *s.Business.BusinessID
+	id := s.accepted()
+	milestones := s.milestones(id)
+	cc := s.transaction(id).ChargeConfiguration
+
+	s.Run("fund one milestone", func() {
+		before := s.balance(s.Buyer.ID)
+
+		s.Equal(http.StatusOK, s.pay(id, milestones[0].ID))
+		s.Equal(before-milestones[0].BuyerPayable(cc), s.balance(s.Buyer.ID))
+
+		funded := s.milestones(id)
+		s.Equal(models.MilestoneStatusFunded, funded[0].Status)
+		s.Equal(models.MilestoneStatusPendingPayment, funded[1].Status)
+		s.Equal(models.TransactionStatusPendingDelivery, s.transaction(id).Status)
+	})
+
+	s.Run("fund the remaining milestones", func() {
+		before := s.balance(s.Buyer.ID)
+
+		s.Equal(http.StatusOK, s.pay(id, ""))
+		s.Equal(before-milestones[1].BuyerPayable(cc), s.balance(s.Buyer.ID))
+
+		for _, m := range s.milestones(id) {
+			s.Equal(models.MilestoneStatusFunded, m.Status)
+		}
+	})
+
+	s.Run("refuse to fund a funded transaction again", func() {
+		before := s.balance(s.Buyer.ID)
+
+		s.Equal(http.StatusBadRequest, s.pay(id, ""))
+		s.Equal(before, s.balance(s.Buyer.ID))
+	})
+
+	s.Run("deliver a milestone", func() {
+		status, _ := s.ts.Request(http.MethodPost, "/api/v1/transactions/"+id+"/milestones/"+milestones[0].ID+"/deliver", s.BuyerToken, nil)
+		s.Equal(http.StatusForbidden, status)
+
+		status, _ = s.ts.Request(http.MethodPost, "/api/v1/transactions/"+id+"/milestones/"+milestones[0].ID+"/deliver", s.BusinessToken, nil)
+		s.Equal(http.StatusOK, status)
+		s.Equal(models.MilestoneStatusDelivered, s.milestones(id)[0].Status)
+	})
+
+	s.Run("forbid the seller releasing a milestone", func() {
+		status, _ := s.ts.Request(http.MethodPost, "/api/v1/transactions/"+id+"/milestones/"+milestones[0].ID+"/release", s.BusinessToken, nil)
+		s.Equal(http.StatusForbidden, status)
+	})
+
+	s.Run("release a milestone", func() {
+		before := s.balance(sellerID)
+
+		s.Equal(http.StatusOK, s.release(id, milestones[0].ID))
+		s.Equal(before+milestones[0].ReceivableAmount, s.balance(sellerID))
+
+		s.Equal(models.MilestoneStatusReleased, s.milestones(id)[0].Status)
+		s.Equal(models.TransactionStatusPendingDelivery, s.transaction(id).Status)
+	})
+
+	s.Run("refuse to release a milestone twice", func() {
+		before := s.balance(sellerID)
+
+		s.Equal(http.StatusBadRequest, s.release(id, milestones[0].ID))
+		s.Equal(before, s.balance(sellerID))
+	})
+
+	s.Run("complete the transaction with the last release", func() {
+		before := s.balance(sellerID)
+
+		s.Equal(http.StatusOK, s.release(id, milestones[1].ID))
+		s.Equal(before+milestones[1].ReceivableAmount, s.balance(sellerID))
+		s.Equal(models.TransactionStatusCompleted, s.transaction(id).Status)
+	})
+}
+
+// charge delivers paystack's charge.success webhook for a card payment of the
+// milestone with milestoneID.
+func (s *MilestoneTestSuite) charge(id, milestoneID string, amount int) int {
+	status, _ := s.ts.Request(http.MethodPost, "/api/v1/wallets/paystack-webhook", "", map[string]any{
+		"event": "charge.success",
+		"data": map[string]any{
+			"reference": milestoneID,
+			"amount":    strconv.Itoa(amount),
+			"currency":  "NGN",
+			"metadata": map[string]any{
+				"is_for_transaction": true,
+				"transaction_id":     id,
+				"milestone_ids":      []string{milestoneID},
+			},
+		},
+	})
+	return status
+}
+
+func (s *MilestoneTestSuite) TestFundByCard() {
+	id := s.accepted()
+	milestones := s.milestones(id)
+	owed := milestones[0].BuyerPayable(s.transaction(id).ChargeConfiguration)
+
+	s.Run("refuse a charge short of the milestone", func() {
+		s.Equal(http.StatusBadRequest, s.charge(id, milestones[0].ID, owed-1))
+		s.Equal(models.MilestoneStatusPendingPayment, s.milestones(id)[0].Status)
+	})
+
+	s.Run("fund the milestone", func() {
+		s.Equal(http.StatusOK, s.charge(id, milestones[0].ID, owed))
+		s.Equal(models.MilestoneStatusFunded, s.milestones(id)[0].Status)
+		s.Equal(models.TransactionStatusPendingDelivery, s.transaction(id).Status)
+	})
+
+	s.Run("ignore a retry once the milestone is released", func() {
+		s.Equal(http.StatusOK, s.release(id, milestones[0].ID))
+
+		s.Equal(http.StatusOK, s.charge(id, milestones[0].ID, owed))
+		s.Equal(models.MilestoneStatusReleased, s.milestones(id)[0].Status)
+
+		count, err := s.ts.Config.GetTransactionTimelineRepository().Count(context.Background(), filter.New(
+			filter.Eq("milestone_id", milestones[0].ID),
+			filter.Eq("name", models.TimelineMilestoneFunded),
+		), nil)
+		s.NoError(err)
+		s.Equal(1, count)
+	})
+}
+
+func (s *MilestoneTestSuite) TestDisputeHalfReleased() {
+	sellerID := *s.Business.BusinessID
+
+	s.Run("release only the milestones still held", func() {
+		id := s.accepted()
+		s.Equal(http.StatusOK, s.pay(id, ""))
+
+		milestones := s.milestones(id)
+		s.Equal(http.StatusOK, s.release(id, milestones[0].ID))
+
+		before := s.balance(sellerID)
+		s.Equal(http.StatusOK, s.resolve(s.dispute(id), models.DisputeResolutionReleaseSeller))
+		s.Equal(before+milestones[1].ReceivableAmount, s.balance(sellerID))
+
+		s.Equal(models.TransactionStatusCompleted, s.transaction(id).Status)
+		for _, m := range s.milestones(id) {
+			s.Equal(models.MilestoneStatusReleased, m.Status)
+		}
+	})
+
+	s.Run("refund only the milestones still held", func() {
+		id := s.accepted()
+		s.Equal(http.StatusOK, s.pay(id, ""))
+
+		milestones := s.milestones(id)
+		s.Equal(http.StatusOK, s.release(id, milestones[0].ID))
+
+		transaction := s.transaction(id)
+		before := s.balance(s.Buyer.ID)
+		s.Equal(http.StatusOK, s.resolve(s.dispute(id), models.DisputeResolutionRefundBuyer))
+		s.Equal(before+milestones[1].BuyerPayable(transaction.ChargeConfiguration), s.balance(s.Buyer.ID))
+
+		s.Equal(models.TransactionStatusCanceled, s.transaction(id).Status)
+	})
+
+	s.Run("leave unfunded milestones out of the refund", func() {
+		id := s.accepted()
+
+		milestones := s.milestones(id)
+		s.Equal(http.StatusOK, s.pay(id, milestones[0].ID))
+
+		transaction := s.transaction(id)
+		before := s.balance(s.Buyer.ID)
+		s.Equal(http.StatusOK, s.resolve(s.dispute(id), models.DisputeResolutionRefundBuyer))
+		s.Equal(before+milestones[0].BuyerPayable(transaction.ChargeConfiguration), s.balance(s.Buyer.ID))
+	})
+}
+
+func TestMilestoneSuite(t *testing.T) {
+	suite.Run(t, new(MilestoneTestSuite))
+}
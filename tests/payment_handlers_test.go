@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/princecee/escrow-api/pkg/money"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// PaymentTestSuite pays for transactions from the wallet of the buyer.
+type PaymentTestSuite struct {
+	suite.Suite
+	ts *test_utils.TestServer
+	test_utils.Parties
+}
+
+func (s *PaymentTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+	s.Parties = test_utils.SignupParties(s.ts)
+}
+
+func (s *PaymentTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+// accepted creates a transaction of the buyer with the business and moves it
+// to pending payment.
+func (s *PaymentTestSuite) accepted() string {
+	status, respBody := s.ts.Request(http.MethodPost, "/api/v1/transactions/create", s.BuyerToken, map[string]any{
+		"type":              "Product",
+		"created_by":        "Buyer",
+		"delivery_duration": 3,
+		"currency":          "NGN",
+		"charge_configuration": map[string]any{
+			"buyer_charges":  50,
+			"seller_charges": 50,
+		},
+		"counterparty": map[string]any{"name": "Test Business", "email": s.Business.Email},
+		"product_details": []map[string]any{
+			{"name": "Phone", "description": "Blue", "quantity": 1, "price": 500000},
+		},
+	})
+	s.Equal(http.StatusOK, status)
+
+	id := respBody.Data["transaction"]["id"].(string)
+	status, _ = s.ts.Request(http.MethodPut, "/api/v1/transactions/"+id, s.BusinessToken, map[string]any{"status": "Pending-Payment"})
+	s.Equal(http.StatusOK, status)
+
+	return id
+}
+
+func (s *PaymentTestSuite) balance() int64 {
+	wallet, err := s.ts.Config.GetWalletRepository().GetByIdentifier(context.Background(), s.Buyer.ID, money.NGN, nil)
+	s.NoError(err)
+
+	return int64(wallet.Balance)
+}
+
+func (s *PaymentTestSuite) TestPayFromWallet() {
+	pay := func(token, id string) int {
+		status, _ := s.ts.Request(http.MethodPost, "/api/v1/transactions/pay", token, map[string]any{"transaction_id": id, "is_use_wallet": true})
+		return status
+	}
+
+	id := s.accepted()
+
+	s.Run("forbid paying for the transaction of another buyer", func() {
+		before := s.balance()
+
+		s.Equal(http.StatusForbidden, pay(s.BusinessToken, id))
+		s.Equal(before, s.balance())
+	})
+
+	s.Run("pay from the wallet", func() {
+		before := s.balance()
+
+		s.Equal(http.StatusOK, pay(s.BuyerToken, id))
+		s.Less(s.balance(), before)
+
+		status, respBody := s.ts.Request(http.MethodGet, "/api/v1/transactions/"+id, s.BuyerToken, nil)
+		s.Equal(http.StatusOK, status)
+		s.Equal("Pending-Delivery", respBody.Data["transaction"]["status"])
+	})
+
+	s.Run("refuse to pay twice", func() {
+		before := s.balance()
+
+		s.Equal(http.StatusConflict, pay(s.BuyerToken, id))
+		s.Equal(before, s.balance())
+	})
+
+	s.Run("refuse to pay before the seller accepts", func() {
+		status, respBody := s.ts.Request(http.MethodPost, "/api/v1/transactions/create", s.BuyerToken, map[string]any{
+			"type":              "Product",
+			"created_by":        "Buyer",
+			"delivery_duration": 3,
+			"currency":          "NGN",
+			"charge_configuration": map[string]any{
+				"buyer_charges":  50,
+				"seller_charges": 50,
+			},
+			"counterparty": map[string]any{"name": "Test Business", "email": s.Business.Email},
+			"product_details": []map[string]any{
+				{"name": "Case", "description": "Black", "quantity": 1, "price": 100000},
+			},
+		})
+		s.Equal(http.StatusOK, status)
+
+		s.Equal(http.StatusConflict, pay(s.BuyerToken, respBody.Data["transaction"]["id"].(string)))
+	})
+}
+
+// charge delivers paystack's charge.success webhook for a card payment of the
+// transaction with id.
+func (s *PaymentTestSuite) charge(id string, amount int, currency string) int {
+	status, _ := s.ts.Request(http.MethodPost, "/api/v1/wallets/paystack-webhook", "", map[string]any{
+		"event": "charge.success",
+		"data": map[string]any{
+			"reference": id,
+			"amount":    strconv.Itoa(amount),
+			"currency":  currency,
+			"metadata":  map[string]any{"is_for_transaction": true},
+		},
+	})
+	return status
+}
+
+func (s *PaymentTestSuite) transaction(id string) *models.Transaction {
+	transaction, err := s.ts.Config.GetTransactionRepository().GetById(context.Background(), id, nil)
+	s.NoError(err)
+
+	return transaction
+}
+
+func (s *PaymentTestSuite) TestPayByCard() {
+	id := s.accepted()
+	owed := s.transaction(id).BuyerPayable()
+
+	s.Run("refuse a charge short of what is owed", func() {
+		s.Equal(http.StatusBadRequest, s.charge(id, owed-1, "NGN"))
+		s.Equal(models.TransactionStatusPendingPayment, s.transaction(id).Status)
+	})
+
+	s.Run("refuse a charge in another currency", func() {
+		s.Equal(http.StatusBadRequest, s.charge(id, owed, "USD"))
+		s.Equal(models.TransactionStatusPendingPayment, s.transaction(id).Status)
+	})
+
+	s.Run("mark the transaction paid", func() {
+		s.Equal(http.StatusOK, s.charge(id, owed, "NGN"))
+
+		transaction := s.transaction(id)
+		s.Equal(models.TransactionStatusPendingDelivery, transaction.Status)
+		s.Equal(owed, transaction.PaidAmount)
+	})
+
+	s.Run("ignore a retry of the charge", func() {
+		s.Equal(http.StatusOK, s.charge(id, owed, "NGN"))
+
+		count, err := s.ts.Config.GetTransactionTimelineRepository().Count(context.Background(), filter.New(
+			filter.Eq("transaction_id", id),
+			filter.Eq("name", models.TimelinePaymentSubmitted),
+		), nil)
+		s.NoError(err)
+		s.Equal(1, count)
+	})
+
+	s.Run("leave a settled transaction settled on a retry", func() {
+		status, _ := s.ts.Request(http.MethodPost, "/api/v1/transactions/"+id+"/settle", s.BuyerToken, map[string]any{
+			"accepted_items": []int{},
+			"rejected_items": []int{0},
+		})
+		s.Equal(http.StatusOK, status)
+		before := s.balance()
+
+		s.Equal(http.StatusOK, s.charge(id, owed, "NGN"))
+		s.Equal(models.TransactionStatusCanceled, s.transaction(id).Status)
+
+		status, _ = s.ts.Request(http.MethodPost, "/api/v1/transactions/"+id+"/settle", s.BuyerToken, map[string]any{
+			"accepted_items": []int{},
+			"rejected_items": []int{0},
+		})
+		s.Equal(http.StatusBadRequest, status)
+		s.Equal(before, s.balance())
+	})
+}
+
+func TestPaymentSuite(t *testing.T) {
+	suite.Run(t, new(PaymentTestSuite))
+}
@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/pkg/money"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// SettlementTestSuite settles paid product transactions line by line.
+type SettlementTestSuite struct {
+	suite.Suite
+	ts *test_utils.TestServer
+	test_utils.Parties
+	adminToken string
+}
+
+func (s *SettlementTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+	s.Parties = test_utils.SignupParties(s.ts)
+	_, s.adminToken = test_utils.SignupAdminUser(s.ts)
+}
+
+func (s *SettlementTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+// paid creates a product transaction of the buyer with the business in two
+// lines, has the business accept it and pays for it from the buyer's wallet.
+// The charges are split unevenly so the shares have to be rounded.
+func (s *SettlementTestSuite) paid() *models.Transaction {
+	status, respBody := s.ts.Request(http.MethodPost, "/api/v1/transactions/create", s.BuyerToken, map[string]any{
+		"type":              "Product",
+		"created_by":        "Buyer",
+		"delivery_duration": 3,
+		"currency":          "NGN",
+		"charge_configuration": map[string]any{
+			"buyer_charges":  33,
+			"seller_charges": 67,
+		},
+		"counterparty": map[string]any{"name": "Test Business", "email": s.Business.Email},
+		"product_details": []map[string]any{
+			{"name": "Phone", "description": "Blue", "quantity": 1, "price": 300001},
+			{"name": "Case", "description": "Black", "quantity": 2, "price": 50000},
+		},
+	})
+	s.Equal(http.StatusOK, status)
+
+	id := respBody.Data["transaction"]["id"].(string)
+	status, _ = s.ts.Request(http.MethodPut, "/api/v1/transactions/"+id, s.BusinessToken, map[string]any{"status": "Pending-Payment"})
+	s.Equal(http.StatusOK, status)
+
+	status, _ = s.ts.Request(http.MethodPost, "/api/v1/transactions/pay", s.BuyerToken, map[string]any{"transaction_id": id, "is_use_wallet": true})
+	s.Equal(http.StatusOK, status)
+
+	return s.transaction(id)
+}
+
+func (s *SettlementTestSuite) transaction(id string) *models.Transaction {
+	transaction, err := s.ts.Config.GetTransactionRepository().GetById(context.Background(), id, nil)
+	s.NoError(err)
+
+	return transaction
+}
+
+func (s *SettlementTestSuite) balance(identifier string) int {
+	wallet, err := s.ts.Config.GetWalletRepository().GetByIdentifier(context.Background(), identifier, money.NGN, nil)
+	if err != nil {
+		return 0
+	}
+
+	return wallet.Balance
+}
+
+func (s *SettlementTestSuite) settle(token, id string, accepted, rejected []int) int {
+	status, _ := s.ts.Request(http.MethodPost, "/api/v1/transactions/"+id+"/settle", token, map[string]any{
+		"accepted_items": accepted,
+		"rejected_items": rejected,
+	})
+	return status
+}
+
+func (s *SettlementTestSuite) TestSettle() {
+	sellerID := *s.Business.BusinessID
+	transaction := s.paid()
+
+	s.Run("forbid anyone but the buyer settling", func() {
+		s.Equal(http.StatusForbidden, s.settle(s.BusinessToken, transaction.ID, []int{0, 1}, []int{}))
+	})
+
+	s.Run("require every item to be decided", func() {
+		s.Equal(http.StatusBadRequest, s.settle(s.BuyerToken, transaction.ID, []int{0}, []int{}))
+	})
+
+	s.Run("refuse an item both accepted and rejected", func() {
+		s.Equal(http.StatusBadRequest, s.settle(s.BuyerToken, transaction.ID, []int{0, 1}, []int{1}))
+	})
+
+	s.Run("refuse an item that does not exist", func() {
+		s.Equal(http.StatusBadRequest, s.settle(s.BuyerToken, transaction.ID, []int{0, 2}, []int{1}))
+	})
+
+	s.Run("accept some items and refund the rest", func() {
+		buyerBefore := s.balance(s.Buyer.ID)
+		sellerBefore := s.balance(sellerID)
+		paid := transaction.PaidAmount
+		s.Equal(transaction.BuyerPayable(), paid)
+
+		s.Equal(http.StatusOK, s.settle(s.BuyerToken, transaction.ID, []int{0}, []int{1}))
+
+		// the charges shrink with the accepted cost, rounded down
+		acceptedCost := 300001
+		acceptedCharges := transaction.Charges * acceptedCost / transaction.TotalCost
+		buyerFee, sellerFee := transaction.ChargeConfiguration.Split(acceptedCharges)
+		s.Equal(acceptedCharges, buyerFee+sellerFee)
+
+		refund := s.balance(s.Buyer.ID) - buyerBefore
+		receivable := s.balance(sellerID) - sellerBefore
+		s.Equal(paid-acceptedCost-buyerFee, refund)
+		s.Equal(acceptedCost-sellerFee, receivable)
+
+		// every kobo the buyer paid went back to them, to the seller or to the
+		// charges on the accepted items
+		s.Equal(paid, refund+receivable+acceptedCharges)
+
+		settled := s.transaction(transaction.ID)
+		s.Equal(models.TransactionStatusCompleted, settled.Status)
+		s.Equal(acceptedCost, settled.TotalCost)
+		s.Equal(acceptedCharges, settled.Charges)
+		s.Equal(receivable, settled.ReceivableAmount)
+		s.Equal(models.ProductDetailAccepted, settled.ProductDetails[0].Status)
+		s.Equal(models.ProductDetailRejected, settled.ProductDetails[1].Status)
+	})
+
+	s.Run("refuse to settle twice", func() {
+		buyerBefore := s.balance(s.Buyer.ID)
+
+		s.Equal(http.StatusBadRequest, s.settle(s.BuyerToken, transaction.ID, []int{}, []int{0, 1}))
+		s.Equal(buyerBefore, s.balance(s.Buyer.ID))
+	})
+
+	s.Run("cancel and refund everything when every item is rejected", func() {
+		rejected := s.paid()
+		buyerBefore := s.balance(s.Buyer.ID)
+		sellerBefore := s.balance(sellerID)
+
+		s.Equal(http.StatusOK, s.settle(s.BuyerToken, rejected.ID, []int{}, []int{0, 1}))
+		s.Equal(buyerBefore+rejected.BuyerPayable(), s.balance(s.Buyer.ID))
+		s.Equal(sellerBefore, s.balance(sellerID))
+
+		settled := s.transaction(rejected.ID)
+		s.Equal(models.TransactionStatusCanceled, settled.Status)
+		s.Equal(0, settled.Charges)
+	})
+}
+
+func (s *SettlementTestSuite) TestUpdatePaid() {
+	update := func(token, id string, body map[string]any) int {
+		status, _ := s.ts.Request(http.MethodPut, "/api/v1/transactions/"+id, token, body)
+		return status
+	}
+
+	s.Run("refuse to mark an unpaid transaction paid", func() {
+		status, respBody := s.ts.Request(http.MethodPost, "/api/v1/transactions/create", s.BuyerToken, map[string]any{
+			"type":              "Product",
+			"created_by":        "Buyer",
+			"delivery_duration": 3,
+			"currency":          "NGN",
+			"charge_configuration": map[string]any{
+				"buyer_charges":  50,
+				"seller_charges": 50,
+			},
+			"counterparty": map[string]any{"name": "Test Business", "email": s.Business.Email},
+			"product_details": []map[string]any{
+				{"name": "Phone", "description": "Blue", "quantity": 1, "price": 300000},
+			},
+		})
+		s.Equal(http.StatusOK, status)
+
+		id := respBody.Data["transaction"]["id"].(string)
+		s.Equal(http.StatusOK, update(s.BusinessToken, id, map[string]any{"status": "Pending-Payment"}))
+
+		for _, to := range []string{"Pending-Delivery", "Completed", "Disputed", "Sent-Awaiting"} {
+			s.Equal(http.StatusConflict, update(s.BuyerToken, id, map[string]any{"status": to}), to)
+		}
+		s.Equal(models.TransactionStatusPendingPayment, s.transaction(id).Status)
+	})
+
+	transaction := s.paid()
+
+	s.Run("refuse to change the items once paid", func() {
+		status := update(s.BusinessToken, transaction.ID, map[string]any{
+			"product_details": []map[string]any{
+				{"name": "Phone", "description": "Blue", "quantity": 1, "price": 900000},
+			},
+		})
+		s.Equal(http.StatusBadRequest, status)
+	})
+
+	s.Run("refuse to change the charges once paid", func() {
+		status := update(s.BusinessToken, transaction.ID, map[string]any{
+			"charge_configuration": map[string]any{"buyer_charges": 100, "seller_charges": 0},
+		})
+		s.Equal(http.StatusBadRequest, status)
+	})
+
+	s.Run("refuse to move a paid transaction by hand", func() {
+		for _, to := range []string{"Completed", "Canceled", "Disputed", "Pending-Payment", "Sent-Awaiting"} {
+			s.Equal(http.StatusConflict, update(s.BuyerToken, transaction.ID, map[string]any{"status": to}), to)
+			s.Equal(http.StatusConflict, update(s.BusinessToken, transaction.ID, map[string]any{"status": to}), to)
+		}
+	})
+
+	s.Run("keep what was paid", func() {
+		updated := s.transaction(transaction.ID)
+		s.Equal(models.TransactionStatusPendingDelivery, updated.Status)
+		s.Equal(transaction.ProductDetails, updated.ProductDetails)
+		s.Equal(transaction.BuyerPayable(), updated.PaidAmount)
+	})
+}
+
+func (s *SettlementTestSuite) TestDispute() {
+	transaction := s.paid()
+
+	status, respBody := s.ts.Request(http.MethodPost, "/api/v1/transactions/"+transaction.ID+"/disputes", s.BuyerToken, map[string]any{
+		"reason": "the phone never arrived",
+	})
+	s.Equal(http.StatusOK, status)
+	disputeID := respBody.Data["dispute"]["id"].(string)
+
+	s.Run("refuse to move a disputed transaction out of dispute by hand", func() {
+		for _, to := range []string{"Pending-Delivery", "Completed", "Canceled"} {
+			status, _ := s.ts.Request(http.MethodPut, "/api/v1/transactions/"+transaction.ID, s.BuyerToken, map[string]any{"status": to})
+			s.Equal(http.StatusConflict, status, to)
+		}
+		s.Equal(models.TransactionStatusDisputed, s.transaction(transaction.ID).Status)
+	})
+
+	s.Run("refund what the buyer paid", func() {
+		before := s.balance(s.Buyer.ID)
+
+		status, _ := s.ts.Request(http.MethodPost, "/api/v1/admin/disputes/"+disputeID+"/resolve", s.adminToken, map[string]any{
+			"resolution": models.DisputeResolutionRefundBuyer,
+			"note":       "the seller could not show it was delivered",
+		})
+		s.Equal(http.StatusOK, status)
+		s.Equal(before+transaction.PaidAmount, s.balance(s.Buyer.ID))
+		s.Equal(models.TransactionStatusCanceled, s.transaction(transaction.ID).Status)
+	})
+}
+
+func TestSettlementSuite(t *testing.T) {
+	suite.Run(t, new(SettlementTestSuite))
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/princecee/escrow-api/pkg/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// SignatureTestSuite checks webhook signatures against ones computed outside
+// the api. It needs no server.
+type SignatureTestSuite struct {
+	suite.Suite
+}
+
+func (s *SignatureTestSuite) TestComputeHMAC() {
+	key := []byte("sk_test_secret")
+
+	cases := []struct {
+		name      string
+		body      string
+		signature string
+	}{
+		{
+			"sign a paystack event",
+			`{"event":"charge.success","data":{"reference":"ref_1","amount":"50000"}}`,
+			"869d98f5560f428bac2d48f5dbac04202cd9f626bdfcce8f7a9cc259dd08bfa02d59e15b626f424b9afe71b725de5cd01af10c6a116636b0b20f6f79a3b12a3c",
+		},
+		{
+			"sign an empty body",
+			"",
+			"d3b70468a9eedaeedd4037c5e55728362e41bf8c83ae04f353dd45f2cbcc2360d47f8182c791e7a166232894dfc8917c0c2fbba52294a51743edab46181d5e8b",
+		},
+	}
+
+	for _, c := range cases {
+		s.Run(c.name, func() {
+			s.Equal(c.signature, utils.ComputeHMAC(key, []byte(c.body)))
+		})
+	}
+
+	s.Run("change with the body", func() {
+		s.NotEqual(cases[0].signature, utils.ComputeHMAC(key, []byte(cases[0].body+" ")))
+	})
+}
+
+func TestSignatureSuite(t *testing.T) {
+	suite.Run(t, new(SignatureTestSuite))
+}
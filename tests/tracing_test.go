@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/princecee/escrow-api/pkg/json"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TracingTestSuite struct {
+	suite.Suite
+	ts *test_utils.TestServer
+}
+
+func (s *TracingTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+}
+
+func (s *TracingTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+// spansOf returns the spans of the trace with id traceId.
+func (s *TracingTestSuite) spansOf(traceId string) map[string]tracetest.SpanStub {
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range s.ts.Spans() {
+		if span.SpanContext.TraceID().String() == traceId {
+			spans[span.Name] = span
+		}
+	}
+
+	return spans
+}
+
+func (s *TracingTestSuite) TestTracing() {
+	traceId := "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentId := "00f067aa0ba902b7"
+
+	data, _ := json.Marshal(map[string]any{"email": "nobody@user.com", "password": "password"})
+	req, _ := http.NewRequest(http.MethodPost, s.ts.Server.URL+"/api/v1/auth/sign-in", bytes.NewBuffer(data))
+	req.Header.Set("Content-Type", test_utils.ContentType)
+	req.Header.Set("traceparent", "00-"+traceId+"-"+parentId+"-01")
+
+	res, err := s.ts.Server.Client().Do(req)
+	s.NoError(err)
+	res.Body.Close()
+
+	spans := s.spansOf(traceId)
+
+	var server tracetest.SpanStub
+	s.Run("continue the trace of the caller", func() {
+		var ok bool
+		server, ok = spans["POST /api/v1/auth/sign-in"]
+		s.True(ok)
+		s.Equal(trace.SpanKindServer, server.SpanKind)
+		s.Equal(parentId, server.Parent.SpanID().String())
+	})
+
+	s.Run("trace repository calls within the request", func() {
+		query, ok := spans["UserRepository.GetByEmail"]
+		s.True(ok)
+		s.Equal(server.SpanContext.SpanID(), query.Parent.SpanID())
+	})
+
+	s.Run("trace redis commands", func() {
+		found := false
+		for name := range spans {
+			found = found || strings.HasPrefix(name, "redis ")
+		}
+		s.True(found)
+	})
+}
+
+func TestTracingSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
@@ -0,0 +1,406 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/princecee/escrow-api/pkg/invites"
+	"github.com/princecee/escrow-api/pkg/json"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransactionHandlerTestSuite struct {
+	suite.Suite
+	ts          *test_utils.TestServer
+	user        test_utils.TestUser
+	accessToken string
+}
+
+func (s *TransactionHandlerTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+
+	user, token := test_utils.SignupPersonalUser(s.ts)
+	s.user = user
+	s.accessToken = token
+}
+
+func (s *TransactionHandlerTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+func (s *TransactionHandlerTestSuite) post(url string, body io.Reader) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, url, body)
+	req.Header = map[string][]string{
+		"Authorization": {fmt.Sprintf("Bearer %s", s.accessToken)},
+		"Content-Type":  {test_utils.ContentType},
+	}
+
+	return req
+}
+
+func (s *TransactionHandlerTestSuite) TestQuote() {
+	client := s.ts.Server.Client()
+	url := s.ts.Server.URL + "/api/v1/transactions/quote"
+
+	s.Run("quote a product transaction", func() {
+		data, _ := json.Marshal(map[string]any{
+			"type":              "Product",
+			"created_by":        "Buyer",
+			"delivery_duration": 3,
+			"currency":          "NGN",
+			"charge_configuration": map[string]any{
+				"buyer_charges":  50,
+				"seller_charges": 50,
+			},
+			"product_details": []map[string]any{
+				{"name": "Phone", "description": "Blue", "quantity": 2, "price": 500000},
+				{"name": "Case", "description": "Leather", "quantity": 1, "price": 250000},
+			},
+		})
+		res, err := client.Do(s.post(url, bytes.NewBuffer(data)))
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			Quote test_utils.TestTransactionQuote `json:"quote"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal(1250000, respBody.Data.Quote.Subtotal)
+		s.Equal(37500, respBody.Data.Quote.Charges)
+		s.Equal(18750, respBody.Data.Quote.BuyerFee)
+		s.Equal(18750, respBody.Data.Quote.SellerFee)
+		s.Equal(29032, respBody.Data.Quote.GatewayFee)
+		s.Equal(1268750, respBody.Data.Quote.Payable)
+		s.Equal(1231250, respBody.Data.Quote.Receivable)
+
+		var count int
+		err = s.ts.Config.GetDB().QueryRow(context.Background(), `SELECT COUNT(*) FROM transactions`).Scan(&count)
+		s.NoError(err)
+		s.Equal(0, count)
+	})
+
+	s.Run("quote each milestone of a service transaction", func() {
+		data, _ := json.Marshal(map[string]any{
+			"type":              "Service",
+			"created_by":        "Buyer",
+			"delivery_duration": 30,
+			"currency":          "NGN",
+			"charge_configuration": map[string]any{
+				"buyer_charges":  100,
+				"seller_charges": 0,
+			},
+			"milestones": []map[string]any{
+				{"title": "Design", "amount": 100000, "due_date": time.Now().Add(7 * 24 * time.Hour)},
+				{"title": "Build", "amount": 300001, "due_date": time.Now().Add(21 * 24 * time.Hour)},
+			},
+		})
+		res, err := client.Do(s.post(url, bytes.NewBuffer(data)))
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			Quote test_utils.TestTransactionQuote `json:"quote"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal(400001, respBody.Data.Quote.Subtotal)
+		s.Equal(12001, respBody.Data.Quote.BuyerFee)
+		s.Equal(0, respBody.Data.Quote.SellerFee)
+		s.Equal(16181, respBody.Data.Quote.GatewayFee)
+		s.Equal(412002, respBody.Data.Quote.Payable)
+		s.Equal(400001, respBody.Data.Quote.Receivable)
+		s.Len(respBody.Data.Quote.Milestones, 2)
+		s.Equal(9001, respBody.Data.Quote.Milestones[1].Charges)
+	})
+
+	s.Run("split charges without losing a kobo", func() {
+		data, _ := json.Marshal(map[string]any{
+			"type":              "Product",
+			"created_by":        "Buyer",
+			"delivery_duration": 3,
+			"currency":          "NGN",
+			"charge_configuration": map[string]any{
+				"buyer_charges":  33,
+				"seller_charges": 67,
+			},
+			"product_details": []map[string]any{
+				{"name": "Laptop", "description": "Silver", "quantity": 1, "price": 1000001},
+			},
+		})
+		res, err := client.Do(s.post(url, bytes.NewBuffer(data)))
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			Quote test_utils.TestTransactionQuote `json:"quote"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal(30001, respBody.Data.Quote.Charges)
+		s.Equal(9900, respBody.Data.Quote.BuyerFee)
+		s.Equal(20101, respBody.Data.Quote.SellerFee)
+		s.Equal(1009901, respBody.Data.Quote.Payable)
+		s.Equal(979900, respBody.Data.Quote.Receivable)
+	})
+
+	s.Run("reject milestones on products", func() {
+		data, _ := json.Marshal(map[string]any{
+			"type":              "Product",
+			"created_by":        "Buyer",
+			"delivery_duration": 3,
+			"currency":          "NGN",
+			"charge_configuration": map[string]any{
+				"buyer_charges":  50,
+				"seller_charges": 50,
+			},
+			"milestones": []map[string]any{
+				{"title": "Design", "amount": 100000, "due_date": time.Now().Add(24 * time.Hour)},
+			},
+		})
+		res, err := client.Do(s.post(url, bytes.NewBuffer(data)))
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func (s *TransactionHandlerTestSuite) TestInvites() {
+	client := s.ts.Server.Client()
+
+	s.Run("require an email or phone number for a counterparty", func() {
+		data, _ := json.Marshal(map[string]any{
+			"type":              "Product",
+			"created_by":        "Buyer",
+			"delivery_duration": 3,
+			"currency":          "NGN",
+			"charge_configuration": map[string]any{
+				"buyer_charges":  50,
+				"seller_charges": 50,
+			},
+			"counterparty": map[string]any{"name": "Ada's Phones"},
+			"product_details": []map[string]any{
+				{"name": "Phone", "description": "Blue", "quantity": 1, "price": 500000},
+			},
+		})
+		res, err := client.Do(s.post(s.ts.Server.URL+"/api/v1/transactions/create", bytes.NewBuffer(data)))
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+
+		var count int
+		err = s.ts.Config.GetDB().QueryRow(context.Background(), `SELECT COUNT(*) FROM transaction_invites`).Scan(&count)
+		s.NoError(err)
+		s.Equal(0, count)
+	})
+
+	s.Run("reject a tampered invite token", func() {
+		id, _ := uuid.NewV4()
+		token := invites.Sign([]byte(s.ts.Config.GetSettings().InviteKey), id.String(), time.Now().Add(time.Hour))
+
+		data, _ := json.Marshal(map[string]any{"token": token + "0"})
+		res, err := client.Do(s.post(s.ts.Server.URL+"/api/v1/transactions/invites/accept", bytes.NewBuffer(data)))
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[any])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+		s.Equal(invites.ErrInvalidToken.Error(), respBody.Message)
+	})
+
+	s.Run("reject an invite token signed with another key", func() {
+		id, _ := uuid.NewV4()
+		token := invites.Sign([]byte(s.ts.Config.GetSettings().JWTKey), id.String(), time.Now().Add(time.Hour))
+
+		data, _ := json.Marshal(map[string]any{"token": token})
+		res, err := client.Do(s.post(s.ts.Server.URL+"/api/v1/transactions/invites/accept", bytes.NewBuffer(data)))
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[any])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+		s.Equal(invites.ErrInvalidToken.Error(), respBody.Message)
+	})
+
+	s.Run("reject an expired invite token", func() {
+		id, _ := uuid.NewV4()
+		token := invites.Sign([]byte(s.ts.Config.GetSettings().InviteKey), id.String(), time.Now().Add(-time.Minute))
+
+		data, _ := json.Marshal(map[string]any{"token": token})
+		res, err := client.Do(s.post(s.ts.Server.URL+"/api/v1/transactions/invites/accept", bytes.NewBuffer(data)))
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[any])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode)
+		s.Equal(invites.ErrExpired.Error(), respBody.Message)
+	})
+
+	s.Run("return 404 for an invite that does not exist", func() {
+		id, _ := uuid.NewV4()
+		token := invites.Sign([]byte(s.ts.Config.GetSettings().InviteKey), id.String(), time.Now().Add(time.Hour))
+
+		data, _ := json.Marshal(map[string]any{"token": token})
+		res, err := client.Do(s.post(s.ts.Server.URL+"/api/v1/transactions/invites/accept", bytes.NewBuffer(data)))
+		s.NoError(err)
+		defer res.Body.Close()
+
+		s.Equal(http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func (s *TransactionHandlerTestSuite) get(url string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header = map[string][]string{
+		"Authorization": {fmt.Sprintf("Bearer %s", s.accessToken)},
+	}
+
+	return req
+}
+
+func (s *TransactionHandlerTestSuite) TestList() {
+	client := s.ts.Server.Client()
+	url := s.ts.Server.URL + "/api/v1/transactions"
+
+	create := func() {
+		data, _ := json.Marshal(map[string]any{
+			"type":              "Product",
+			"created_by":        "Buyer",
+			"delivery_duration": 3,
+			"currency":          "NGN",
+			"charge_configuration": map[string]any{
+				"buyer_charges":  50,
+				"seller_charges": 50,
+			},
+			"counterparty": map[string]any{"name": "Ada's Phones", "email": "ada@example.com"},
+			"product_details": []map[string]any{
+				{"name": "Phone", "description": "Blue", "quantity": 1, "price": 500000},
+			},
+		})
+		res, err := client.Do(s.post(url+"/create", bytes.NewBuffer(data)))
+		s.NoError(err)
+		res.Body.Close()
+		s.Equal(http.StatusOK, res.StatusCode)
+	}
+
+	create()
+
+	list := func(query string) (int, *test_utils.Response[map[string][]any]) {
+		res, err := client.Do(s.get(url + "?" + query))
+		s.NoError(err)
+		defer res.Body.Close()
+
+		respBody := new(test_utils.Response[map[string][]any])
+		_ = json.ReadJSON(res.Body, respBody)
+
+		return res.StatusCode, respBody
+	}
+
+	s.Run("filter by amount range", func() {
+		status, respBody := list("min_amount=100000&max_amount=1000000")
+		s.Equal(http.StatusOK, status)
+		s.Len(respBody.Data["transactions"], 1)
+		s.Equal(1, respBody.Meta.Total)
+
+		status, respBody = list("max_amount=100000")
+		s.Equal(http.StatusOK, status)
+		s.Empty(respBody.Data["transactions"])
+	})
+
+	s.Run("filter by date range", func() {
+		today := time.Now().Format("2006-01-02")
+		status, respBody := list("created_from=" + today + "&created_to=" + today)
+		s.Equal(http.StatusOK, status)
+		s.Len(respBody.Data["transactions"], 1)
+
+		tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+		status, respBody = list("created_from=" + tomorrow)
+		s.Equal(http.StatusOK, status)
+		s.Empty(respBody.Data["transactions"])
+	})
+
+	s.Run("sort by a known column", func() {
+		status, respBody := list("sort=-total_amount,created_at")
+		s.Equal(http.StatusOK, status)
+		s.Len(respBody.Data["transactions"], 1)
+	})
+
+	s.Run("reject an unknown sort column", func() {
+		status, _ := list("sort=password")
+		s.Equal(http.StatusBadRequest, status)
+	})
+
+	s.Run("reject an invalid date", func() {
+		status, _ := list("created_from=yesterday")
+		s.Equal(http.StatusBadRequest, status)
+	})
+
+	s.Run("page by cursor", func() {
+		create()
+		create()
+
+		status, first := list("cursor=&page_size=2&count=false")
+		s.Equal(http.StatusOK, status)
+		s.Len(first.Data["transactions"], 2)
+		s.Zero(first.Meta.Total)
+		s.NotEmpty(first.Meta.NextCursor)
+		s.Empty(first.Meta.PrevCursor)
+
+		status, second := list("page_size=2&cursor=" + first.Meta.NextCursor)
+		s.Equal(http.StatusOK, status)
+		s.Len(second.Data["transactions"], 1)
+		s.Equal(3, second.Meta.Total)
+		s.Empty(second.Meta.NextCursor)
+		s.NotEmpty(second.Meta.PrevCursor)
+
+		status, back := list("page_size=2&cursor=" + second.Meta.PrevCursor)
+		s.Equal(http.StatusOK, status)
+		s.Equal(first.Data["transactions"], back.Data["transactions"])
+		s.Empty(back.Meta.PrevCursor)
+		s.NotEmpty(back.Meta.NextCursor)
+	})
+
+	s.Run("reject a tampered cursor", func() {
+		_, first := list("cursor=&page_size=1")
+		status, _ := list("cursor=0" + first.Meta.NextCursor)
+		s.Equal(http.StatusBadRequest, status)
+	})
+
+	s.Run("reject a cursor signed with another key", func() {
+		_, first := list("cursor=&page_size=1")
+		id, _ := first.Data["transactions"][0].(map[string]any)["id"].(string)
+		cursor := &filter.Cursor{ID: id, CreatedAt: time.Now()}
+
+		status, _ := list("cursor=" + cursor.Encode([]byte(s.ts.Config.GetSettings().JWTKey)))
+		s.Equal(http.StatusBadRequest, status)
+	})
+
+	s.Run("reject a sorted cursor", func() {
+		status, _ := list("cursor=&sort=total_amount")
+		s.Equal(http.StatusBadRequest, status)
+	})
+}
+
+func TestTransactionHandlerSuite(t *testing.T) {
+	suite.Run(t, new(TransactionHandlerTestSuite))
+}
@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/princecee/escrow-api/cmd/app/pkg/response"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/internal/uow"
+	"github.com/princecee/escrow-api/pkg/audit"
+	"github.com/princecee/escrow-api/pkg/money"
+	test_utils "github.com/princecee/escrow-api/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type UnitOfWorkTestSuite struct {
+	suite.Suite
+	ts *test_utils.TestServer
+}
+
+func (s *UnitOfWorkTestSuite) SetupSuite() {
+	s.ts = test_utils.NewTestServer()
+}
+
+func (s *UnitOfWorkTestSuite) TearDownSuite() {
+	s.ts.DropTablesAndTypes()
+	s.ts.Config.GetDB().Close()
+}
+
+// createQuote saves a quote in the transaction of u, standing in for any
+// repository write.
+func (s *UnitOfWorkTestSuite) createQuote(u *uow.UnitOfWork) *models.FXQuote {
+	identifier, _ := uuid.NewV4()
+	quote := &models.FXQuote{
+		Identifier:      identifier.String(),
+		AccountType:     models.PersonalAccountType,
+		FromCurrency:    money.NGN,
+		ToCurrency:      money.USD,
+		Amount:          100000,
+		ConvertedAmount: 65,
+		MidRate:         1,
+		Rate:            1,
+		Status:          models.FXQuotePending,
+		ExpiresAt:       time.Now().Add(time.Minute),
+	}
+
+	err := u.GetFXQuoteRepository().Create(context.Background(), quote, u.Tx)
+	s.NoError(err)
+
+	return quote
+}
+
+func (s *UnitOfWorkTestSuite) exists(quote *models.FXQuote) bool {
+	_, err := s.ts.Config.GetFXQuoteRepository().GetById(context.Background(), quote.ID, nil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	s.NoError(err)
+
+	return true
+}
+
+func (s *UnitOfWorkTestSuite) TestWithTx() {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+
+	s.Run("commits when fn succeeds", func() {
+		var quote *models.FXQuote
+		err := uow.WithTx(ctx, s.ts.Config, func(u *uow.UnitOfWork) error {
+			quote = s.createQuote(u)
+			return nil
+		})
+
+		s.NoError(err)
+		s.True(s.exists(quote))
+	})
+
+	s.Run("rolls back when fn fails", func() {
+		var quote *models.FXQuote
+		err := uow.WithTx(ctx, s.ts.Config, func(u *uow.UnitOfWork) error {
+			quote = s.createQuote(u)
+			return errFailed
+		})
+
+		s.ErrorIs(err, errFailed)
+		s.False(s.exists(quote))
+	})
+
+	s.Run("rolls back and rethrows when fn panics", func() {
+		var quote *models.FXQuote
+		s.PanicsWithValue("boom", func() {
+			_ = uow.WithTx(ctx, s.ts.Config, func(u *uow.UnitOfWork) error {
+				quote = s.createQuote(u)
+				panic("boom")
+			})
+		})
+
+		s.False(s.exists(quote))
+	})
+
+	s.Run("rolls back a failed savepoint only", func() {
+		var outer, inner *models.FXQuote
+		err := uow.WithTx(ctx, s.ts.Config, func(u *uow.UnitOfWork) error {
+			outer = s.createQuote(u)
+
+			err := u.WithTx(ctx, func(u *uow.UnitOfWork) error {
+				inner = s.createQuote(u)
+				return errFailed
+			})
+			s.ErrorIs(err, errFailed)
+
+			return nil
+		})
+
+		s.NoError(err)
+		s.True(s.exists(outer))
+		s.False(s.exists(inner))
+	})
+
+	s.Run("retries serialization failures", func() {
+		attempts := 0
+		err := uow.WithTx(ctx, s.ts.Config, func(u *uow.UnitOfWork) error {
+			attempts++
+			if attempts == 1 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+
+			return nil
+		}, uow.Options{TxOptions: pgx.TxOptions{IsoLevel: pgx.Serializable}, Retries: 2})
+
+		s.NoError(err)
+		s.Equal(2, attempts)
+	})
+
+	s.Run("reports a stale update as a conflict", func() {
+		identifier, _ := uuid.NewV4()
+		walletRepo := s.ts.Config.GetWalletRepository()
+		wallet, err := walletRepo.GetOrCreate(ctx, identifier.String(), models.PersonalAccountType, money.NGN, nil)
+		s.NoError(err)
+		stale := *wallet
+
+		err = walletRepo.Update(ctx, wallet, nil)
+		s.NoError(err)
+
+		err = uow.WithTx(ctx, s.ts.Config, func(u *uow.UnitOfWork) error {
+			return u.GetWalletRepository().Update(ctx, &stale, u.Tx)
+		})
+		s.ErrorIs(err, repositories.ErrConflict)
+		s.Equal(http.StatusConflict, response.StatusOf(err))
+	})
+
+	s.Run("binds its repositories to the transaction", func() {
+		var quote *models.FXQuote
+		err := uow.WithTx(ctx, s.ts.Config, func(u *uow.UnitOfWork) error {
+			identifier, _ := uuid.NewV4()
+			quote = &models.FXQuote{
+				Identifier:   identifier.String(),
+				AccountType:  models.PersonalAccountType,
+				FromCurrency: money.NGN,
+				ToCurrency:   money.USD,
+				Status:       models.FXQuotePending,
+				ExpiresAt:    time.Now().Add(time.Minute),
+			}
+
+			// passed no transaction, the write still joins the one of u
+			s.NoError(u.GetFXQuoteRepository().Create(ctx, quote, nil))
+			return errFailed
+		})
+
+		s.ErrorIs(err, errFailed)
+		s.False(s.exists(quote))
+	})
+
+	s.Run("chains audit entries appended from serializable transactions", func() {
+		attempts := 0
+		err := uow.WithTx(ctx, s.ts.Config, func(u *uow.UnitOfWork) error {
+			attempts++
+
+			// the snapshot is taken before an entry is appended elsewhere
+			_, err := u.Tx.Exec(ctx, `SELECT 1`)
+			s.NoError(err)
+			if attempts == 1 {
+				entry := &models.AuditLog{ActorType: models.AuditActorSystem, Action: "test.appended_elsewhere", EntityType: "test"}
+				s.NoError(s.ts.Config.GetAuditLogRepository().Create(ctx, entry, nil))
+			}
+
+			entry := &models.AuditLog{ActorType: models.AuditActorSystem, Action: "test.appended_serializable", EntityType: "test"}
+			return u.GetAuditLogRepository().Create(ctx, entry, u.Tx)
+		}, uow.Serializable)
+
+		s.NoError(err)
+		s.Equal(2, attempts)
+
+		logs, err := s.ts.Config.GetAuditLogRepository().GetAfterSeq(ctx, 0, 1000, nil)
+		s.NoError(err)
+		_, broken := audit.Verify(models.GenesisHash, logs)
+		s.Nil(broken)
+	})
+
+	s.Run("does not retry by default", func() {
+		attempts := 0
+		err := uow.WithTx(ctx, s.ts.Config, func(u *uow.UnitOfWork) error {
+			attempts++
+			return &pgconn.PgError{Code: "40001"}
+		})
+
+		s.True(uow.IsSerializationFailure(err))
+		s.Equal(1, attempts)
+	})
+}
+
+func TestUnitOfWorkSuite(t *testing.T) {
+	suite.Run(t, new(UnitOfWorkTestSuite))
+}
@@ -159,7 +159,11 @@ func (s *UserHandlerTestSuite) TestUserHandler() {
 			}
 
 			data, _ := json.Marshal(signInDto)
-			res, err := s.ts.Server.Client().Post(url, test_utils.ContentType, bytes.NewBuffer(data))
+			req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+			req.Header.Set("Content-Type", test_utils.ContentType)
+			// no proxy is trusted, the header must not change the ip recorded
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			res, err := s.ts.Server.Client().Do(req)
 			s.NoError(err)
 
 			respBody := new(test_utils.Response[any])
@@ -172,6 +176,33 @@ func (s *UserHandlerTestSuite) TestUserHandler() {
 			s.Empty(respBody.Meta.RefreshToken)
 		})
 	})
+
+	s.Run("get security log", func() {
+		req := s.get(url + "/me/security-log")
+		res, err := client.Do(req)
+		s.NoError(err)
+
+		respBody := new(test_utils.Response[struct {
+			SignInAttempts []struct {
+				Outcome   string `json:"outcome"`
+				Reason    string `json:"reason"`
+				IPAddress string `json:"ip_address"`
+			} `json:"sign_in_attempts"`
+		}])
+		_ = json.ReadJSON(res.Body, respBody)
+		defer res.Body.Close()
+
+		s.Equal(true, respBody.Success)
+		s.Equal("security log fetched successfully", respBody.Message)
+		s.Len(respBody.Data.SignInAttempts, 2)
+		s.Equal(2, respBody.Meta.Total)
+
+		// newest attempts come first
+		s.Equal("failed", respBody.Data.SignInAttempts[0].Outcome)
+		s.Equal("invalid_credentials", respBody.Data.SignInAttempts[0].Reason)
+		s.Equal("127.0.0.1", respBody.Data.SignInAttempts[0].IPAddress)
+		s.Equal("success", respBody.Data.SignInAttempts[1].Outcome)
+	})
 }
 
 func TestUserHandlerSuite(t *testing.T) {
@@ -11,13 +11,23 @@ import (
 	"github.com/princecee/escrow-api/internal/repositories"
 	"github.com/princecee/escrow-api/pkg/apis"
 	"github.com/princecee/escrow-api/pkg/apis/paystack"
+	"github.com/princecee/escrow-api/pkg/fees"
+	"github.com/princecee/escrow-api/pkg/fx"
+	"github.com/princecee/escrow-api/pkg/jwt"
 	"github.com/princecee/escrow-api/pkg/push"
+	"github.com/princecee/escrow-api/pkg/tracing"
 	"github.com/princecee/escrow-api/tests/utils/mocks/test_repositories"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/mock"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 type TestConfig struct {
+	Settings                      *config.Settings
+	JWT                           *jwt.JWT
+	TracerProvider                *sdktrace.TracerProvider
+	Spans                         *tracetest.InMemoryExporter
 	AuthRepository                repositories.IAuthRepository
 	BusinessRepository            repositories.IBusinessRepository
 	EventRepository               repositories.IEventRepository
@@ -29,21 +39,32 @@ type TestConfig struct {
 	BankAccountRepository         repositories.IBankAccountRepository
 	TransactionRepository         repositories.ITransactionRepository
 	TransactionTimelineRepository repositories.ITransactionTimelineRepository
+	SignInAttemptRepository       repositories.ISignInAttemptRepository
+	DisputeRepository             repositories.IDisputeRepository
+	AuditLogRepository            repositories.IAuditLogRepository
+	APIKeyRepository              repositories.IAPIKeyRepository
+	WebhookEndpointRepository     repositories.IWebhookEndpointRepository
+	WebhookDeliveryRepository     repositories.IWebhookDeliveryRepository
+	MilestoneRepository           repositories.IMilestoneRepository
+	FXQuoteRepository             repositories.IFXQuoteRepository
+	InviteRepository              repositories.IInviteRepository
 	DB                            *pgxpool.Pool
 	RedisClient                   *config.RedisClient
 	Logger                        *config.Logger
 	Push                          push.IPush
 	Apis                          apis.IAPIs
+	FeeEngine                     *fees.Engine
+	FXEngine                      *fx.Engine
 	mock.Mock
 }
 
 type TestPush struct{ mock.Mock }
 
-func (p *TestPush) SendEmail(data *push.Email) error {
+func (p *TestPush) SendEmail(ctx context.Context, data *push.Email) error {
 	return nil
 }
 
-func (p *TestPush) SendSMS(data *push.Sms) {}
+func (p *TestPush) SendSMS(ctx context.Context, data *push.Sms) {}
 
 type TestAPIs struct{ mock.Mock }
 
@@ -53,28 +74,71 @@ func (a *TestAPIs) GetPaystack() paystack.IPaystack {
 	return &TestPaystackAPI{}
 }
 
-func (p *TestPaystackAPI) InitiateTransaction(data paystack.InitiateTransactionDto) (*paystack.InitiateTransactionResponse, error) {
+func (p *TestPaystackAPI) InitiateTransaction(ctx context.Context, data paystack.InitiateTransactionDto) (*paystack.InitiateTransactionResponse, error) {
 	return &paystack.InitiateTransactionResponse{}, nil
 }
 
 func NewTestConfig() *TestConfig {
-	dbConfig, err := pgxpool.ParseConfig(os.Getenv("DSN"))
+	settings := config.DefaultSettings
+	settings.Environment = "test"
+	settings.DSN = config.Secret(os.Getenv("DSN"))
+	settings.RedisURL = config.Secret(os.Getenv("REDIS_URL"))
+	settings.JWTKey = "somerandomjwtkey"
+	settings.CursorKey = "somerandomcursorkey"
+	settings.InviteKey = "somerandominvitekey"
+	// webhook receivers of the tests listen on localhost
+	settings.AllowPrivateWebhooks = true
+	if err := settings.Validate(); err != nil {
+		panic(err)
+	}
+
+	dbConfig, err := pgxpool.ParseConfig(string(settings.DSN))
 	if err != nil {
 		panic(err)
 	}
 
+	dbConfig.ConnConfig.Tracer = repositories.NewQueryTracer()
+
 	pool, err := pgxpool.NewWithConfig(context.Background(), dbConfig)
 	if err != nil {
 		panic(err)
 	}
 
+	rclient, err := config.NewRedisClient(string(settings.RedisURL))
+	if err != nil {
+		panic(err)
+	}
+
+	feeEngine, err := fees.NewEngine(fees.DefaultSchedules)
+	if err != nil {
+		panic(err)
+	}
+
+	rates, err := fx.NewStaticProvider(fx.DefaultRates)
+	if err != nil {
+		panic(err)
+	}
+
+	fxEngine, err := fx.NewEngine(rates, 100, time.Minute)
+	if err != nil {
+		panic(err)
+	}
+
+	// spans are kept in memory for tests to look at
+	spans := tracetest.NewInMemoryExporter()
+	tracerProvider := tracing.Setup(spans, "escrow-api-test", 1)
+
 	timeout := 10 * time.Second
 	return &TestConfig{
+		Settings:       &settings,
+		JWT:            jwt.New(string(settings.JWTKey)),
+		TracerProvider: tracerProvider,
+		Spans:          spans,
 		Logger: config.NewLogger(
 			zerolog.New(io.Discard).Level(zerolog.DebugLevel).With().Timestamp().Logger(),
 			zerolog.DebugLevel,
 		),
-		RedisClient:                   &config.RedisClient{},
+		RedisClient:                   rclient,
 		DB:                            pool,
 		AuthRepository:                test_repositories.NewAuthRepository(pool, timeout),
 		BusinessRepository:            test_repositories.NewBusinessRepository(pool, timeout),
@@ -87,19 +151,27 @@ func NewTestConfig() *TestConfig {
 		BankAccountRepository:         test_repositories.NewBankAccountRepository(pool, timeout),
 		TransactionRepository:         test_repositories.NewTransactionRepository(pool, timeout),
 		TransactionTimelineRepository: test_repositories.NewTransactionTimelineRepository(pool, timeout),
+		SignInAttemptRepository:       test_repositories.NewSignInAttemptRepository(pool, timeout),
+		DisputeRepository:             test_repositories.NewDisputeRepository(pool, timeout),
+		AuditLogRepository:            test_repositories.NewAuditLogRepository(pool, timeout),
+		APIKeyRepository:              test_repositories.NewAPIKeyRepository(pool, timeout),
+		WebhookEndpointRepository:     test_repositories.NewWebhookEndpointRepository(pool, timeout),
+		WebhookDeliveryRepository:     test_repositories.NewWebhookDeliveryRepository(pool, timeout),
+		MilestoneRepository:           test_repositories.NewMilestoneRepository(pool, timeout),
+		FXQuoteRepository:             test_repositories.NewFXQuoteRepository(pool, timeout),
+		InviteRepository:              test_repositories.NewInviteRepository(pool, timeout),
 		Push:                          &TestPush{},
+		FeeEngine:                     feeEngine,
+		FXEngine:                      fxEngine,
 	}
 }
 
-func (c *TestConfig) Getenv(key string) string {
-	switch key {
-	case "ENVIRONMENT":
-		return "test"
-	case "JWT_KEY":
-		return "somerandomjwtkey"
-	default:
-		return ""
-	}
+func (c *TestConfig) GetSettings() *config.Settings {
+	return c.Settings
+}
+
+func (c *TestConfig) GetJWT() *jwt.JWT {
+	return c.JWT
 }
 
 func (c *TestConfig) GetAuthRepository() repositories.IAuthRepository {
@@ -146,6 +218,42 @@ func (c *TestConfig) GetTransactionTimelineRepository() repositories.ITransactio
 	return c.TransactionTimelineRepository
 }
 
+func (c *TestConfig) GetSignInAttemptRepository() repositories.ISignInAttemptRepository {
+	return c.SignInAttemptRepository
+}
+
+func (c *TestConfig) GetDisputeRepository() repositories.IDisputeRepository {
+	return c.DisputeRepository
+}
+
+func (c *TestConfig) GetAuditLogRepository() repositories.IAuditLogRepository {
+	return c.AuditLogRepository
+}
+
+func (c *TestConfig) GetAPIKeyRepository() repositories.IAPIKeyRepository {
+	return c.APIKeyRepository
+}
+
+func (c *TestConfig) GetWebhookEndpointRepository() repositories.IWebhookEndpointRepository {
+	return c.WebhookEndpointRepository
+}
+
+func (c *TestConfig) GetWebhookDeliveryRepository() repositories.IWebhookDeliveryRepository {
+	return c.WebhookDeliveryRepository
+}
+
+func (c *TestConfig) GetMilestoneRepository() repositories.IMilestoneRepository {
+	return c.MilestoneRepository
+}
+
+func (c *TestConfig) GetFXQuoteRepository() repositories.IFXQuoteRepository {
+	return c.FXQuoteRepository
+}
+
+func (c *TestConfig) GetInviteRepository() repositories.IInviteRepository {
+	return c.InviteRepository
+}
+
 func (c *TestConfig) GetDB() *pgxpool.Pool {
 	return c.DB
 }
@@ -165,3 +273,11 @@ func (c *TestConfig) GetPush() push.IPush {
 func (c *TestConfig) GetAPIs() apis.IAPIs {
 	return &TestAPIs{}
 }
+
+func (c *TestConfig) GetFeeEngine() *fees.Engine {
+	return c.FeeEngine
+}
+
+func (c *TestConfig) GetFXEngine() *fx.Engine {
+	return c.FXEngine
+}
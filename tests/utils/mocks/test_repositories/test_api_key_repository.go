@@ -0,0 +1,50 @@
+package test_repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestAPIKeyRepository struct {
+	repo *repositories.APIKeyRepository
+	mock.Mock
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool, timeout time.Duration) *TestAPIKeyRepository {
+	return &TestAPIKeyRepository{repo: repositories.NewAPIKeyRepository(db, timeout)}
+}
+
+func (r *TestAPIKeyRepository) Create(ctx context.Context, k *models.APIKey, tx pgx.Tx) error {
+	return r.repo.Create(ctx, k, tx)
+}
+
+func (r *TestAPIKeyRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.APIKey, error) {
+	return r.repo.GetById(ctx, id, tx)
+}
+
+func (r *TestAPIKeyRepository) GetByHash(ctx context.Context, hash string, tx pgx.Tx) (*models.APIKey, error) {
+	return r.repo.GetByHash(ctx, hash, tx)
+}
+
+func (r *TestAPIKeyRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.APIKey, error) {
+	return r.repo.GetMany(ctx, f, tx)
+}
+
+func (r *TestAPIKeyRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
+}
+
+func (r *TestAPIKeyRepository) Revoke(ctx context.Context, k *models.APIKey, tx pgx.Tx) error {
+	return r.repo.Revoke(ctx, k, tx)
+}
+
+func (r *TestAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.TouchLastUsed(ctx, id, tx)
+}
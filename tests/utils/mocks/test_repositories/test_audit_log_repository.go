@@ -0,0 +1,42 @@
+package test_repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestAuditLogRepository struct {
+	repo *repositories.AuditLogRepository
+	mock.Mock
+}
+
+func NewAuditLogRepository(db *pgxpool.Pool, timeout time.Duration) *TestAuditLogRepository {
+	return &TestAuditLogRepository{repo: repositories.NewAuditLogRepository(db, timeout)}
+}
+
+func (r *TestAuditLogRepository) Create(ctx context.Context, l *models.AuditLog, tx pgx.Tx) error {
+	return r.repo.Create(ctx, l, tx)
+}
+
+func (r *TestAuditLogRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.AuditLog, error) {
+	return r.repo.GetById(ctx, id, tx)
+}
+
+func (r *TestAuditLogRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.AuditLog, error) {
+	return r.repo.GetMany(ctx, f, tx)
+}
+
+func (r *TestAuditLogRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
+}
+
+func (r *TestAuditLogRepository) GetAfterSeq(ctx context.Context, seq int64, limit int, tx pgx.Tx) ([]*models.AuditLog, error) {
+	return r.repo.GetAfterSeq(ctx, seq, limit, tx)
+}
@@ -1,6 +1,7 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -19,26 +20,26 @@ func NewAuthRepository(db *pgxpool.Pool, timeout time.Duration) *TestAuthReposit
 	return &TestAuthRepository{repo: repositories.NewAuthRepository(db, timeout)}
 }
 
-func (r *TestAuthRepository) Create(a *models.Auth, tx pgx.Tx) error {
-	return r.repo.Create(a, tx)
+func (r *TestAuthRepository) Create(ctx context.Context, a *models.Auth, tx pgx.Tx) error {
+	return r.repo.Create(ctx, a, tx)
 }
 
-func (r *TestAuthRepository) Update(a *models.Auth, tx pgx.Tx) error {
-	return r.repo.Update(a, tx)
+func (r *TestAuthRepository) Update(ctx context.Context, a *models.Auth, tx pgx.Tx) error {
+	return r.repo.Update(ctx, a, tx)
 }
 
-func (r *TestAuthRepository) GetById(id string, tx pgx.Tx) (*models.Auth, error) {
-	return r.repo.GetById(id, tx)
+func (r *TestAuthRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Auth, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *TestAuthRepository) GetByUserId(id string, tx pgx.Tx) (*models.Auth, error) {
-	return r.repo.GetByUserId(id, tx)
+func (r *TestAuthRepository) GetByUserId(ctx context.Context, id string, tx pgx.Tx) (*models.Auth, error) {
+	return r.repo.GetByUserId(ctx, id, tx)
 }
 
-func (r *TestAuthRepository) Delete(id string, tx pgx.Tx) (err error) {
-	return r.repo.Delete(id, tx)
+func (r *TestAuthRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	return r.repo.Delete(ctx, id, tx)
 }
 
-func (r *TestAuthRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *TestAuthRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
@@ -1,6 +1,7 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -20,26 +21,26 @@ func NewBankAccountRepository(db *pgxpool.Pool, timeout time.Duration) *TestBank
 	return &TestBankAccountRepository{repo: repositories.NewBankAccountRepository(db, timeout)}
 }
 
-func (r *TestBankAccountRepository) Create(a *models.BankAccount, tx pgx.Tx) error {
-	return r.repo.Create(a, tx)
+func (r *TestBankAccountRepository) Create(ctx context.Context, a *models.BankAccount, tx pgx.Tx) error {
+	return r.repo.Create(ctx, a, tx)
 }
 
-func (r *TestBankAccountRepository) Update(a *models.BankAccount, tx pgx.Tx) error {
-	return r.repo.Update(a, tx)
+func (r *TestBankAccountRepository) Update(ctx context.Context, a *models.BankAccount, tx pgx.Tx) error {
+	return r.repo.Update(ctx, a, tx)
 }
 
-func (r *TestBankAccountRepository) GetById(id string, tx pgx.Tx) (*models.BankAccount, error) {
-	return r.repo.GetById(id, tx)
+func (r *TestBankAccountRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.BankAccount, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *TestBankAccountRepository) Delete(id string, tx pgx.Tx) (err error) {
-	return r.repo.Delete(id, tx)
+func (r *TestBankAccountRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	return r.repo.Delete(ctx, id, tx)
 }
 
-func (r *TestBankAccountRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *TestBankAccountRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
 
-func (r *TestBankAccountRepository) GetByWalletId(id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.BankAccount, error) {
-	return r.repo.GetByWalletId(id, pagination, tx)
+func (r *TestBankAccountRepository) GetByWalletId(ctx context.Context, id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.BankAccount, error) {
+	return r.repo.GetByWalletId(ctx, id, pagination, tx)
 }
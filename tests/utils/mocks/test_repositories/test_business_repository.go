@@ -1,6 +1,7 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -19,22 +20,22 @@ func NewBusinessRepository(db *pgxpool.Pool, timeout time.Duration) *TestBusines
 	return &TestBusinessRepository{repo: repositories.NewBusinessRepository(db, timeout)}
 }
 
-func (r *TestBusinessRepository) Create(b *models.Business, tx pgx.Tx) error {
-	return r.repo.Create(b, tx)
+func (r *TestBusinessRepository) Create(ctx context.Context, b *models.Business, tx pgx.Tx) error {
+	return r.repo.Create(ctx, b, tx)
 }
 
-func (r *TestBusinessRepository) Update(b *models.Business, tx pgx.Tx) error {
-	return r.repo.Update(b, tx)
+func (r *TestBusinessRepository) Update(ctx context.Context, b *models.Business, tx pgx.Tx) error {
+	return r.repo.Update(ctx, b, tx)
 }
 
-func (r *TestBusinessRepository) GetById(id string, tx pgx.Tx) (*models.Business, error) {
-	return r.repo.GetById(id, tx)
+func (r *TestBusinessRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Business, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *TestBusinessRepository) Delete(id string, tx pgx.Tx) error {
-	return r.repo.Delete(id, tx)
+func (r *TestBusinessRepository) Delete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.Delete(ctx, id, tx)
 }
 
-func (r *TestBusinessRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *TestBusinessRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
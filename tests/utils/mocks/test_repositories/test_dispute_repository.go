@@ -0,0 +1,46 @@
+package test_repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestDisputeRepository struct {
+	repo *repositories.DisputeRepository
+	mock.Mock
+}
+
+func NewDisputeRepository(db *pgxpool.Pool, timeout time.Duration) *TestDisputeRepository {
+	return &TestDisputeRepository{repo: repositories.NewDisputeRepository(db, timeout)}
+}
+
+func (r *TestDisputeRepository) Create(ctx context.Context, d *models.Dispute, tx pgx.Tx) error {
+	return r.repo.Create(ctx, d, tx)
+}
+
+func (r *TestDisputeRepository) Update(ctx context.Context, d *models.Dispute, tx pgx.Tx) error {
+	return r.repo.Update(ctx, d, tx)
+}
+
+func (r *TestDisputeRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Dispute, error) {
+	return r.repo.GetById(ctx, id, tx)
+}
+
+func (r *TestDisputeRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.Dispute, error) {
+	return r.repo.GetMany(ctx, f, tx)
+}
+
+func (r *TestDisputeRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
+}
+
+func (r *TestDisputeRepository) Delete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.Delete(ctx, id, tx)
+}
@@ -1,6 +1,7 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -19,22 +20,22 @@ func NewEventRepository(db *pgxpool.Pool, timeout time.Duration) *TestEventRepos
 	return &TestEventRepository{repo: repositories.NewEventRepository(db, timeout)}
 }
 
-func (r *TestEventRepository) Create(e *models.Event, tx pgx.Tx) error {
-	return r.repo.Create(e, tx)
+func (r *TestEventRepository) Create(ctx context.Context, e *models.Event, tx pgx.Tx) error {
+	return r.repo.Create(ctx, e, tx)
 }
 
-func (r *TestEventRepository) Update(e *models.Event, tx pgx.Tx) error {
-	return r.repo.Update(e, tx)
+func (r *TestEventRepository) Update(ctx context.Context, e *models.Event, tx pgx.Tx) error {
+	return r.repo.Update(ctx, e, tx)
 }
 
-func (r *TestEventRepository) GetById(id string, tx pgx.Tx) (*models.Event, error) {
-	return r.repo.GetById(id, tx)
+func (r *TestEventRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Event, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *TestEventRepository) Delete(id string, tx pgx.Tx) error {
-	return r.repo.Delete(id, tx)
+func (r *TestEventRepository) Delete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.Delete(ctx, id, tx)
 }
 
-func (r *TestEventRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *TestEventRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
@@ -0,0 +1,33 @@
+package test_repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestFXQuoteRepository struct {
+	repo *repositories.FXQuoteRepository
+	mock.Mock
+}
+
+func NewFXQuoteRepository(db *pgxpool.Pool, timeout time.Duration) *TestFXQuoteRepository {
+	return &TestFXQuoteRepository{repo: repositories.NewFXQuoteRepository(db, timeout)}
+}
+
+func (r *TestFXQuoteRepository) Create(ctx context.Context, q *models.FXQuote, tx pgx.Tx) error {
+	return r.repo.Create(ctx, q, tx)
+}
+
+func (r *TestFXQuoteRepository) Update(ctx context.Context, q *models.FXQuote, tx pgx.Tx) error {
+	return r.repo.Update(ctx, q, tx)
+}
+
+func (r *TestFXQuoteRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.FXQuote, error) {
+	return r.repo.GetById(ctx, id, tx)
+}
@@ -0,0 +1,37 @@
+package test_repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestInviteRepository struct {
+	repo *repositories.InviteRepository
+	mock.Mock
+}
+
+func NewInviteRepository(db *pgxpool.Pool, timeout time.Duration) *TestInviteRepository {
+	return &TestInviteRepository{repo: repositories.NewInviteRepository(db, timeout)}
+}
+
+func (r *TestInviteRepository) Create(ctx context.Context, i *models.Invite, tx pgx.Tx) error {
+	return r.repo.Create(ctx, i, tx)
+}
+
+func (r *TestInviteRepository) Update(ctx context.Context, i *models.Invite, tx pgx.Tx) error {
+	return r.repo.Update(ctx, i, tx)
+}
+
+func (r *TestInviteRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Invite, error) {
+	return r.repo.GetById(ctx, id, tx)
+}
+
+func (r *TestInviteRepository) GetPendingByContact(ctx context.Context, email, phone string, tx pgx.Tx) ([]*models.Invite, error) {
+	return r.repo.GetPendingByContact(ctx, email, phone, tx)
+}
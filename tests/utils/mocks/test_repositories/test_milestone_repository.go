@@ -0,0 +1,37 @@
+package test_repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestMilestoneRepository struct {
+	repo *repositories.MilestoneRepository
+	mock.Mock
+}
+
+func NewMilestoneRepository(db *pgxpool.Pool, timeout time.Duration) *TestMilestoneRepository {
+	return &TestMilestoneRepository{repo: repositories.NewMilestoneRepository(db, timeout)}
+}
+
+func (r *TestMilestoneRepository) Create(ctx context.Context, m *models.Milestone, tx pgx.Tx) error {
+	return r.repo.Create(ctx, m, tx)
+}
+
+func (r *TestMilestoneRepository) Update(ctx context.Context, m *models.Milestone, tx pgx.Tx) error {
+	return r.repo.Update(ctx, m, tx)
+}
+
+func (r *TestMilestoneRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Milestone, error) {
+	return r.repo.GetById(ctx, id, tx)
+}
+
+func (r *TestMilestoneRepository) GetByTransactionId(ctx context.Context, transactionId string, tx pgx.Tx) ([]*models.Milestone, error) {
+	return r.repo.GetByTransactionId(ctx, transactionId, tx)
+}
@@ -1,6 +1,7 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -19,26 +20,26 @@ func NewOtpRepository(db *pgxpool.Pool, timeout time.Duration) *OtpRepository {
 	return &OtpRepository{repo: repositories.NewOtpRepository(db, timeout)}
 }
 
-func (r *OtpRepository) Create(otp *models.Otp, tx pgx.Tx) error {
-	return r.repo.Create(otp, tx)
+func (r *OtpRepository) Create(ctx context.Context, otp *models.Otp, tx pgx.Tx) error {
+	return r.repo.Create(ctx, otp, tx)
 }
 
-func (r *OtpRepository) Update(otp *models.Otp, tx pgx.Tx) error {
-	return r.repo.Update(otp, tx)
+func (r *OtpRepository) Update(ctx context.Context, otp *models.Otp, tx pgx.Tx) error {
+	return r.repo.Update(ctx, otp, tx)
 }
 
-func (r *OtpRepository) GetById(id string, tx pgx.Tx) (*models.Otp, error) {
-	return r.repo.GetById(id, tx)
+func (r *OtpRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Otp, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *OtpRepository) GetOneByWhere(where string, args []any, tx pgx.Tx) (*models.Otp, error) {
-	return r.repo.GetOneByWhere(where, args, tx)
+func (r *OtpRepository) GetOneByWhere(ctx context.Context, where string, args []any, tx pgx.Tx) (*models.Otp, error) {
+	return r.repo.GetOneByWhere(ctx, where, args, tx)
 }
 
-func (r *OtpRepository) Delete(id string, tx pgx.Tx) error {
-	return r.repo.Delete(id, tx)
+func (r *OtpRepository) Delete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.Delete(ctx, id, tx)
 }
 
-func (r *OtpRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *OtpRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
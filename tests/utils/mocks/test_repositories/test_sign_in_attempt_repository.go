@@ -0,0 +1,46 @@
+package test_repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestSignInAttemptRepository struct {
+	repo *repositories.SignInAttemptRepository
+	mock.Mock
+}
+
+func NewSignInAttemptRepository(db *pgxpool.Pool, timeout time.Duration) *TestSignInAttemptRepository {
+	return &TestSignInAttemptRepository{repo: repositories.NewSignInAttemptRepository(db, timeout)}
+}
+
+func (r *TestSignInAttemptRepository) Create(ctx context.Context, a *models.SignInAttempt, tx pgx.Tx) error {
+	return r.repo.Create(ctx, a, tx)
+}
+
+func (r *TestSignInAttemptRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.SignInAttempt, error) {
+	return r.repo.GetById(ctx, id, tx)
+}
+
+func (r *TestSignInAttemptRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.SignInAttempt, error) {
+	return r.repo.GetMany(ctx, f, tx)
+}
+
+func (r *TestSignInAttemptRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
+}
+
+func (r *TestSignInAttemptRepository) Exists(ctx context.Context, where string, args []any, tx pgx.Tx) (bool, error) {
+	return r.repo.Exists(ctx, where, args, tx)
+}
+
+func (r *TestSignInAttemptRepository) Delete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.Delete(ctx, id, tx)
+}
@@ -1,6 +1,7 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -19,22 +20,22 @@ func NewTokenRepository(db *pgxpool.Pool, timeout time.Duration) *TokenRepositor
 	return &TokenRepository{repo: repositories.NewTokenRepository(db, timeout)}
 }
 
-func (r *TokenRepository) Create(t *models.Token, tx pgx.Tx) error {
-	return r.repo.Create(t, tx)
+func (r *TokenRepository) Create(ctx context.Context, t *models.Token, tx pgx.Tx) error {
+	return r.repo.Create(ctx, t, tx)
 }
 
-func (r *TokenRepository) Update(t *models.Token, tx pgx.Tx) error {
-	return r.repo.Update(t, tx)
+func (r *TokenRepository) Update(ctx context.Context, t *models.Token, tx pgx.Tx) error {
+	return r.repo.Update(ctx, t, tx)
 }
 
-func (r *TokenRepository) GetById(id string, tx pgx.Tx) (*models.Token, error) {
-	return r.repo.GetById(id, tx)
+func (r *TokenRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Token, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *TokenRepository) Delete(id string, tx pgx.Tx) error {
-	return r.repo.Delete(id, tx)
+func (r *TokenRepository) Delete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.Delete(ctx, id, tx)
 }
 
-func (r *TokenRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *TokenRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
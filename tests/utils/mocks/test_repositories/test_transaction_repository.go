@@ -1,12 +1,14 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
 	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,26 +21,34 @@ func NewTransactionRepository(db *pgxpool.Pool, timeout time.Duration) *TestTran
 	return &TestTransactionRepository{repo: repositories.NewTransactionRepository(db, timeout)}
 }
 
-func (r *TestTransactionRepository) Create(t *models.Transaction, tx pgx.Tx) error {
-	return r.repo.Create(t, tx)
+func (r *TestTransactionRepository) Create(ctx context.Context, t *models.Transaction, tx pgx.Tx) error {
+	return r.repo.Create(ctx, t, tx)
 }
 
-func (r *TestTransactionRepository) Update(t *models.Transaction, tx pgx.Tx) error {
-	return r.repo.Update(t, tx)
+func (r *TestTransactionRepository) Update(ctx context.Context, t *models.Transaction, tx pgx.Tx) error {
+	return r.repo.Update(ctx, t, tx)
 }
 
-func (r *TestTransactionRepository) GetById(id string, tx pgx.Tx) (*models.Transaction, error) {
-	return r.repo.GetById(id, tx)
+func (r *TestTransactionRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Transaction, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *TestTransactionRepository) GetMany(args []any, where string, tx pgx.Tx) ([]*models.Transaction, error) {
-	return r.repo.GetMany(args, where, tx)
+func (r *TestTransactionRepository) GetByIdForUpdate(ctx context.Context, id string, tx pgx.Tx) (*models.Transaction, error) {
+	return r.repo.GetByIdForUpdate(ctx, id, tx)
 }
 
-func (r *TestTransactionRepository) Delete(id string, tx pgx.Tx) (err error) {
-	return r.repo.Delete(id, tx)
+func (r *TestTransactionRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.Transaction, error) {
+	return r.repo.GetMany(ctx, f, tx)
 }
 
-func (r *TestTransactionRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *TestTransactionRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
+}
+
+func (r *TestTransactionRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	return r.repo.Delete(ctx, id, tx)
+}
+
+func (r *TestTransactionRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
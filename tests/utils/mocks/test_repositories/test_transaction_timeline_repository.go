@@ -1,12 +1,14 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
 	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,26 +21,30 @@ func NewTransactionTimelineRepository(db *pgxpool.Pool, timeout time.Duration) *
 	return &TestTransactionTimelineRepository{repo: repositories.NewTransactionTimelineRepository(db, timeout)}
 }
 
-func (r *TestTransactionTimelineRepository) Create(tt *models.TransactionTimeline, tx pgx.Tx) error {
-	return r.repo.Create(tt, tx)
+func (r *TestTransactionTimelineRepository) Create(ctx context.Context, tt *models.TransactionTimeline, tx pgx.Tx) error {
+	return r.repo.Create(ctx, tt, tx)
 }
 
-func (r *TestTransactionTimelineRepository) Update(tt *models.TransactionTimeline, tx pgx.Tx) error {
-	return r.repo.Update(tt, tx)
+func (r *TestTransactionTimelineRepository) Update(ctx context.Context, tt *models.TransactionTimeline, tx pgx.Tx) error {
+	return r.repo.Update(ctx, tt, tx)
 }
 
-func (r *TestTransactionTimelineRepository) GetById(id string, tx pgx.Tx) (*models.TransactionTimeline, error) {
-	return r.repo.GetById(id, tx)
+func (r *TestTransactionTimelineRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.TransactionTimeline, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *TestTransactionTimelineRepository) GetMany(args []any, where string, tx pgx.Tx) ([]*models.TransactionTimeline, error) {
-	return r.repo.GetMany(args, where, tx)
+func (r *TestTransactionTimelineRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.TransactionTimeline, error) {
+	return r.repo.GetMany(ctx, f, tx)
 }
 
-func (r *TestTransactionTimelineRepository) Delete(id string, tx pgx.Tx) (err error) {
-	return r.repo.Delete(id, tx)
+func (r *TestTransactionTimelineRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
 }
 
-func (r *TestTransactionTimelineRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *TestTransactionTimelineRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	return r.repo.Delete(ctx, id, tx)
+}
+
+func (r *TestTransactionTimelineRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
@@ -1,12 +1,14 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
 	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,34 +21,42 @@ func NewUserRepository(db *pgxpool.Pool, timeout time.Duration) *UserRepository
 	return &UserRepository{repo: repositories.NewUserRepository(db, timeout)}
 }
 
-func (r *UserRepository) Create(u *models.User, tx pgx.Tx) error {
-	return r.repo.Create(u, tx)
+func (r *UserRepository) Create(ctx context.Context, u *models.User, tx pgx.Tx) error {
+	return r.repo.Create(ctx, u, tx)
 }
 
-func (r *UserRepository) Update(u *models.User, tx pgx.Tx) error {
-	return r.repo.Update(u, tx)
+func (r *UserRepository) Update(ctx context.Context, u *models.User, tx pgx.Tx) error {
+	return r.repo.Update(ctx, u, tx)
 }
 
-func (r *UserRepository) GetById(id string, tx pgx.Tx) (*models.User, error) {
-	return r.repo.GetById(id, tx)
+func (r *UserRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.User, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *UserRepository) GetByBusinessId(id string, tx pgx.Tx) (*models.User, error) {
-	return r.repo.GetByBusinessId(id, tx)
+func (r *UserRepository) GetByBusinessId(ctx context.Context, id string, tx pgx.Tx) (*models.User, error) {
+	return r.repo.GetByBusinessId(ctx, id, tx)
 }
 
-func (r *UserRepository) GetByEmail(email string, tx pgx.Tx) (*models.User, error) {
-	return r.repo.GetByEmail(email, tx)
+func (r *UserRepository) GetByEmail(ctx context.Context, email string, tx pgx.Tx) (*models.User, error) {
+	return r.repo.GetByEmail(ctx, email, tx)
 }
 
-func (r *UserRepository) GetByPhoneNumber(phone string, tx pgx.Tx) (*models.User, error) {
-	return r.repo.GetByPhoneNumber(phone, tx)
+func (r *UserRepository) GetByPhoneNumber(ctx context.Context, phone string, tx pgx.Tx) (*models.User, error) {
+	return r.repo.GetByPhoneNumber(ctx, phone, tx)
 }
 
-func (r *UserRepository) Delete(id string, tx pgx.Tx) error {
-	return r.repo.Delete(id, tx)
+func (r *UserRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.User, error) {
+	return r.repo.GetMany(ctx, f, tx)
 }
 
-func (r *UserRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *UserRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.Delete(ctx, id, tx)
+}
+
+func (r *UserRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
@@ -1,12 +1,14 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
 	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
 	"github.com/princecee/escrow-api/pkg/utils"
 	"github.com/stretchr/testify/mock"
 )
@@ -30,30 +32,34 @@ func NewWalletHistoryRepository(db *pgxpool.Pool, timeout time.Duration) *TestWa
 	return &TestWalletHistoryRepository{repo: repositories.NewWalletHistoryRepository(db, timeout)}
 }
 
-func (r *TestWalletHistoryRepository) Create(h *models.WalletHistory, tx pgx.Tx) error {
-	return r.repo.Create(h, tx)
+func (r *TestWalletHistoryRepository) Create(ctx context.Context, h *models.WalletHistory, tx pgx.Tx) error {
+	return r.repo.Create(ctx, h, tx)
 }
 
-func (r *TestWalletHistoryRepository) Update(h *models.WalletHistory, tx pgx.Tx) error {
-	return r.repo.Update(h, tx)
+func (r *TestWalletHistoryRepository) Update(ctx context.Context, h *models.WalletHistory, tx pgx.Tx) error {
+	return r.repo.Update(ctx, h, tx)
 }
 
-func (r *TestWalletHistoryRepository) GetById(id string, tx pgx.Tx) (*models.WalletHistory, error) {
-	return r.repo.GetById(id, tx)
+func (r *TestWalletHistoryRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.WalletHistory, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *TestWalletHistoryRepository) Delete(id string, tx pgx.Tx) (err error) {
-	return r.repo.Delete(id, tx)
+func (r *TestWalletHistoryRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	return r.repo.Delete(ctx, id, tx)
 }
 
-func (r *TestWalletHistoryRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *TestWalletHistoryRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
 
-func (r *TestWalletHistoryRepository) GetByWalletId(id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.WalletHistory, error) {
-	return r.repo.GetByWalletId(id, pagination, tx)
+func (r *TestWalletHistoryRepository) GetByWalletId(ctx context.Context, id string, pagination utils.Pagination, tx pgx.Tx) ([]*models.WalletHistory, error) {
+	return r.repo.GetByWalletId(ctx, id, pagination, tx)
 }
 
-func (r *TestWalletHistoryRepository) GetMany(args []any, where string, tx pgx.Tx) ([]*models.WalletHistory, error) {
-	return r.repo.GetMany(args, where, tx)
+func (r *TestWalletHistoryRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.WalletHistory, error) {
+	return r.repo.GetMany(ctx, f, tx)
+}
+
+func (r *TestWalletHistoryRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
 }
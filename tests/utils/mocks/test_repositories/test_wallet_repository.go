@@ -1,12 +1,14 @@
 package test_repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/internal/models"
 	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/money"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,26 +21,50 @@ func NewWalletRepository(db *pgxpool.Pool, timeout time.Duration) *TestWalletRep
 	return &TestWalletRepository{repo: repositories.NewWalletRepository(db, timeout)}
 }
 
-func (r *TestWalletRepository) Create(w *models.Wallet, tx pgx.Tx) error {
-	return r.repo.Create(w, tx)
+func (r *TestWalletRepository) Create(ctx context.Context, w *models.Wallet, tx pgx.Tx) error {
+	return r.repo.Create(ctx, w, tx)
 }
 
-func (r *TestWalletRepository) Update(w *models.Wallet, tx pgx.Tx) error {
-	return r.repo.Update(w, tx)
+func (r *TestWalletRepository) Update(ctx context.Context, w *models.Wallet, tx pgx.Tx) error {
+	return r.repo.Update(ctx, w, tx)
 }
 
-func (r *TestWalletRepository) GetById(id string, tx pgx.Tx) (*models.Wallet, error) {
-	return r.repo.GetById(id, tx)
+func (r *TestWalletRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetById(ctx, id, tx)
 }
 
-func (r *TestWalletRepository) Delete(id string, tx pgx.Tx) (err error) {
-	return r.repo.Delete(id, tx)
+func (r *TestWalletRepository) Delete(ctx context.Context, id string, tx pgx.Tx) (err error) {
+	return r.repo.Delete(ctx, id, tx)
 }
 
-func (r *TestWalletRepository) SoftDelete(id string, tx pgx.Tx) error {
-	return r.repo.SoftDelete(id, tx)
+func (r *TestWalletRepository) SoftDelete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.SoftDelete(ctx, id, tx)
 }
 
-func (r *TestWalletRepository) GetByIdentifier(id string, tx pgx.Tx) (*models.Wallet, error) {
-	return r.repo.GetByIdentifier(id, tx)
+func (r *TestWalletRepository) GetByIdentifier(ctx context.Context, id, currency string, tx pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetByIdentifier(ctx, id, currency, tx)
+}
+
+func (r *TestWalletRepository) GetManyByIdentifier(ctx context.Context, id string, tx pgx.Tx) ([]*models.Wallet, error) {
+	return r.repo.GetManyByIdentifier(ctx, id, tx)
+}
+
+func (r *TestWalletRepository) GetOrCreate(ctx context.Context, identifier, accountType, currency string, tx pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetOrCreate(ctx, identifier, accountType, currency, tx)
+}
+
+func (r *TestWalletRepository) GetByIdForUpdate(ctx context.Context, id string, tx pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetByIdForUpdate(ctx, id, tx)
+}
+
+func (r *TestWalletRepository) GetByIdentifierForUpdate(ctx context.Context, id, currency string, tx pgx.Tx) (*models.Wallet, error) {
+	return r.repo.GetByIdentifierForUpdate(ctx, id, currency, tx)
+}
+
+func (r *TestWalletRepository) Credit(ctx context.Context, id string, m money.Money, tx pgx.Tx) (*models.Wallet, error) {
+	return r.repo.Credit(ctx, id, m, tx)
+}
+
+func (r *TestWalletRepository) Debit(ctx context.Context, id string, m money.Money, tx pgx.Tx) (*models.Wallet, error) {
+	return r.repo.Debit(ctx, id, m, tx)
 }
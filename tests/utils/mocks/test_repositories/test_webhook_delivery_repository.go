@@ -0,0 +1,46 @@
+package test_repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestWebhookDeliveryRepository struct {
+	repo *repositories.WebhookDeliveryRepository
+	mock.Mock
+}
+
+func NewWebhookDeliveryRepository(db *pgxpool.Pool, timeout time.Duration) *TestWebhookDeliveryRepository {
+	return &TestWebhookDeliveryRepository{repo: repositories.NewWebhookDeliveryRepository(db, timeout)}
+}
+
+func (r *TestWebhookDeliveryRepository) Create(ctx context.Context, d *models.WebhookDelivery, tx pgx.Tx) error {
+	return r.repo.Create(ctx, d, tx)
+}
+
+func (r *TestWebhookDeliveryRepository) Update(ctx context.Context, d *models.WebhookDelivery, tx pgx.Tx) error {
+	return r.repo.Update(ctx, d, tx)
+}
+
+func (r *TestWebhookDeliveryRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.WebhookDelivery, error) {
+	return r.repo.GetById(ctx, id, tx)
+}
+
+func (r *TestWebhookDeliveryRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.WebhookDelivery, error) {
+	return r.repo.GetMany(ctx, f, tx)
+}
+
+func (r *TestWebhookDeliveryRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
+}
+
+func (r *TestWebhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration, tx pgx.Tx) ([]*models.WebhookDelivery, error) {
+	return r.repo.ClaimDue(ctx, limit, lease, tx)
+}
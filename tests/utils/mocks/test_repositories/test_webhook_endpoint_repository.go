@@ -0,0 +1,50 @@
+package test_repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/internal/repositories"
+	"github.com/princecee/escrow-api/pkg/filter"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestWebhookEndpointRepository struct {
+	repo *repositories.WebhookEndpointRepository
+	mock.Mock
+}
+
+func NewWebhookEndpointRepository(db *pgxpool.Pool, timeout time.Duration) *TestWebhookEndpointRepository {
+	return &TestWebhookEndpointRepository{repo: repositories.NewWebhookEndpointRepository(db, timeout)}
+}
+
+func (r *TestWebhookEndpointRepository) Create(ctx context.Context, e *models.WebhookEndpoint, tx pgx.Tx) error {
+	return r.repo.Create(ctx, e, tx)
+}
+
+func (r *TestWebhookEndpointRepository) Update(ctx context.Context, e *models.WebhookEndpoint, tx pgx.Tx) error {
+	return r.repo.Update(ctx, e, tx)
+}
+
+func (r *TestWebhookEndpointRepository) GetById(ctx context.Context, id string, tx pgx.Tx) (*models.WebhookEndpoint, error) {
+	return r.repo.GetById(ctx, id, tx)
+}
+
+func (r *TestWebhookEndpointRepository) GetMany(ctx context.Context, f *filter.Filter, tx pgx.Tx) ([]*models.WebhookEndpoint, error) {
+	return r.repo.GetMany(ctx, f, tx)
+}
+
+func (r *TestWebhookEndpointRepository) Count(ctx context.Context, f *filter.Filter, tx pgx.Tx) (int, error) {
+	return r.repo.Count(ctx, f, tx)
+}
+
+func (r *TestWebhookEndpointRepository) GetSubscribed(ctx context.Context, businessId, eventType string, tx pgx.Tx) ([]*models.WebhookEndpoint, error) {
+	return r.repo.GetSubscribed(ctx, businessId, eventType, tx)
+}
+
+func (r *TestWebhookEndpointRepository) Delete(ctx context.Context, id string, tx pgx.Tx) error {
+	return r.repo.Delete(ctx, id, tx)
+}
@@ -10,233 +10,29 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/princecee/escrow-api/cmd/app/pkg/routes"
 	"github.com/princecee/escrow-api/config"
+	"github.com/princecee/escrow-api/internal/models"
+	"github.com/princecee/escrow-api/migrations"
 	"github.com/princecee/escrow-api/pkg/json"
+	"github.com/princecee/escrow-api/pkg/money"
 	"github.com/princecee/escrow-api/tests/utils/mocks/test_config"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 const (
 	ContentType = "application/json"
 )
 
-const setupTypesSql = `
-	CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-	CREATE TYPE ACCOUNT_TYPE_ENUM AS ENUM ('personal', 'business');
-	CREATE TYPE EVENT_ENVIRONMENT_ENUM AS ENUM ('app_environment', 'push_environment', 'job_environment');
-	CREATE TYPE EVENT_TYPE_ENUM AS ENUM ('sms', 'email');
-	CREATE TYPE TOKEN_TYPE_ENUM AS ENUM ('access_token', 'refresh_token');
-	CREATE TYPE OTP_TYPE AS ENUM ('sms', 'email', 'reset_password');
-	CREATE TYPE MODEL_STATUS_ENUM AS ENUM (
-		'Successful',
-		'Canceled',
-		'Pending'
-	);
-	CREATE TYPE WITHDRAWAL_TYPE_ENUM AS ENUM (
-		'Withdrawal',
-		'Deposit'
-	);
-	CREATE TYPE TRANSACTION_TYPE_ENUM AS ENUM (
-		'Product',
-		'Service',
-		'Crypto'
-	);
-	CREATE TYPE TRANSACTION_CREATED_BY_ENUM AS ENUM (
-		'Seller',
-		'Buyer'
-	);
-	CREATE TYPE TRANSACTION_STATUS_ENUM AS ENUM (
-		'Sent-Awaiting',
-		'Pending-Payment',
-		'Pending-Delivery',
-		'Canceled',
-		'Completed'
-	);
-	CREATE TYPE TRANSACTION_TIMELINE_NAME_ENUM AS ENUM (
-		'Transaction Created',
-		'Transaction Approved',
-		'Payment Submitted',
-		'Delivery Done',
-		'Marked As Completed',
-		'Transaction Canceled'
-	);
-
-	CREATE TABLE IF NOT EXISTS businesses (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		name VARCHAR(80) NOT NULL,
-		email VARCHAR(255) NOT NULL UNIQUE,
-		image_url TEXT,
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT NOT NULL DEFAULT 1
-	);
-
-	CREATE TABLE IF NOT EXISTS users (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		email VARCHAR(255) NOT NULL UNIQUE,
-		phone_number VARCHAR(20) UNIQUE,
-		first_name VARCHAR(80),
-		last_name VARCHAR(80),
-		image_url TEXT,
-		is_phone_number_verified BOOLEAN DEFAULT false,
-		is_email_verified BOOLEAN DEFAULT false,
-		reg_stage INT CHECK (reg_stage IN (1, 2, 3)) NOT NULL,
-		account_type ACCOUNT_TYPE_ENUM NOT NULL,
-		business_id UUID REFERENCES businesses,
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT NOT NULL DEFAULT 1
-	);
-
-	CREATE TABLE IF NOT EXISTS auths (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		user_id UUID REFERENCES users NOT NULL UNIQUE,
-		password TEXT NOT NULL,
-		password_history JSON DEFAULT '[]',
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT NOT NULL DEFAULT 1
-	);
-
-	CREATE TABLE IF NOT EXISTS events (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		data JSON,
-		origin_environment EVENT_ENVIRONMENT_ENUM NOT NULL,
-		target_environment EVENT_ENVIRONMENT_ENUM NOT NULL,
-		event_type EVENT_TYPE_ENUM NOT NULL,
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT NOT NULL DEFAULT 1
-	);
-
-	CREATE TABLE IF NOT EXISTS tokens (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		user_id UUID REFERENCES users NOT NULL,
-		hash TEXT NOT NULL,
-		token_type TOKEN_TYPE_ENUM NOT NULL,
-		in_use BOOLEAN DEFAULT true,
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT NOT NULL DEFAULT 1
-	);
-
-	CREATE TABLE IF NOT EXISTS otps (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		user_id UUID NOT NULL REFERENCES users,
-		code CHAR(4) NOT NULL,
-		is_used BOOLEAN NOT NULL,
-		otp_type OTP_TYPE NOT NULL,
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		expires_in TIMESTAMPTZ NOT NULL,
-		version INT DEFAULT 1
-	);
-
-	CREATE TABLE IF NOT EXISTS wallets (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		identifier UUID NOT NULL,
-		balance INT NOT NULL DEFAULT 0,
-		receivable_balance INT NOT NULL DEFAULT 0,
-		payable_balance INT NOT NULL DEFAULT 0,
-		account_type ACCOUNT_TYPE_ENUM NOT NULL,
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT DEFAULT 1
-	);
-
-	CREATE TABLE IF NOT EXISTS wallet_histories (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		wallet_id UUID REFERENCES wallets NOT NULL,
-		type WITHDRAWAL_TYPE_ENUM NOT NULL,
-		amount INT NOT NULL,
-		status MODEL_STATUS_ENUM NOT NULL DEFAULT 'Pending',
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT DEFAULT 1
-	);
-
-	CREATE TABLE IF NOT EXISTS bank_accounts (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		wallet_id UUID NOT NULL,
-		bank_name VARCHAR(255) NOT NULL,
-		account_name VARCHAR(255) NOT NULL,
-		account_number CHAR(10) NOT NULL,
-		bvn CHAR(11) NOT NULL,
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT DEFAULT 1,
-
-		CONSTRAINT unique_bankname_accountnumber UNIQUE(account_number,bank_name)
-	);
-
-	CREATE TABLE IF NOT EXISTS transactions (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		status TRANSACTION_STATUS_ENUM NOT NULL,
-		type TRANSACTION_TYPE_ENUM NOT NULL,
-		created_by TRANSACTION_CREATED_BY_ENUM NOT NULL,
-		buyer_id UUID REFERENCES users NOT NULL,
-		seller_id UUID REFERENCES users NOT NULL,
-		delivery_duration INT NOT NULL,
-		currency VARCHAR NOT NULL,
-		charge_configuration JSON NOT NULL,
-		product_details JSON NOT NULL,
-		total_amount INT NOT NULL,
-		total_cost INT NOT NULL,
-		charges INT NOT NULL,
-		receivable_amount INT NOT NULL,
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT DEFAULT 1,
-	);
-
-	CREATE TABLE IF NOT EXISTS transaction_timelines (
-		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		name TRANSACTION_TIMELINE_NAME_ENUM NOT NULL,
-		transaction_id UUID REFERENCES transactions NOT NULL,
-		created_at TIMESTAMPTZ NOT NULL,
-		updated_at TIMESTAMPTZ NOT NULL,
-		deleted_at TIMESTAMPTZ,
-		version INT DEFAULT 1,
-	);
-`
-
-var tearDownTypesSql = `
-	DROP TABLE IF EXISTS tokens;
-	DROP TABLE IF EXISTS events;
-	DROP TABLE IF EXISTS auths;
-	DROP TABLE IF EXISTS otps;
-	DROP TABLE IF EXISTS wallet_histories;
-	DROP TABLE IF EXISTS bank_accounts;
-	DROP TABLE IF EXISTS wallets;
-	DROP TABLE IF EXISTS users;
-	DROP TABLE IF EXISTS businesses;
-	DROP TABLE IF EXISTS transactions;
-	DROP TABLE IF EXISTS transaction_timelines;
-
-	DROP TYPE IF EXISTS ACCOUNT_TYPE_ENUM;
-	DROP TYPE IF EXISTS EVENT_ENVIRONMENT_ENUM;
-	DROP TYPE IF EXISTS EVENT_TYPE_ENUM;
-	DROP TYPE IF EXISTS TOKEN_TYPE_ENUM;
-	DROP TYPE IF EXISTS OTP_TYPE;
-	DROP TYPE IF EXISTS MODEL_STATUS_ENUM;
-	DROP TYPE IF EXISTS WITHDRAWAL_TYPE_ENUM;
-	DROP TYPE IF EXISTS TRANSACTION_TYPE_ENUM;
-	DROP TYPE IF EXISTS TRANSACTION_CREATED_BY_ENUM;
-	DROP TYPE IF EXISTS TRANSACTION_STATUS_ENUM;
-	DROP TYPE IF EXISTS TRANSACTION_TIMELINE_NAME_ENUM;
-`
-
-func createTablesAndTypes(pool *pgxpool.Pool) error {
-	_, err := pool.Exec(context.Background(), setupTypesSql)
-	return err
+// migrateUp creates the schema with the migrations the app is deployed with.
+func migrateUp(pool *pgxpool.Pool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	m, err := migrations.New(pool)
+	if err != nil {
+		return err
+	}
+
+	return m.Up(ctx)
 }
 
 type TestServer struct {
@@ -253,23 +49,45 @@ func NewTestServer() *TestServer {
 
 	ts := TestServer{s, c}
 	ts.DropTablesAndTypes()
+	ts.FlushRedis()
 
-	if err := createTablesAndTypes(c.DB); err != nil {
+	if err := migrateUp(c.DB); err != nil {
 		panic(err)
 	}
 
 	return &ts
 }
 
+// Spans returns the spans ended so far.
+func (ts *TestServer) Spans() tracetest.SpanStubs {
+	c := ts.Config.(*test_config.TestConfig)
+	_ = c.TracerProvider.ForceFlush(context.Background())
+
+	return c.Spans.GetSpans()
+}
+
+// DropTablesAndTypes reverts all the migrations, leaving an empty db.
 func (ts *TestServer) DropTablesAndTypes() {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
-	_, err := ts.Config.GetDB().Exec(ctx, tearDownTypesSql)
-
+	m, err := migrations.New(ts.Config.GetDB())
 	if err != nil {
 		panic(err)
 	}
+
+	if err := m.Down(ctx, 0); err != nil {
+		panic(err)
+	}
+}
+
+func (ts *TestServer) FlushRedis() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if err := ts.Config.GetRedisClient().DB.FlushDB(ctx).Err(); err != nil {
+		panic(err)
+	}
 }
 
 type TestBankAccount struct {
@@ -286,8 +104,10 @@ type TestWallet struct {
 	Balance     int           `json:"balance"`
 	Receivable  int           `json:"receivable_balance"`
 	Payable     int           `json:"payable_balance"`
+	Currency    string        `json:"currency"`
 	AccountType string        `json:"account_type"`
 	Identifier  string        `json:"identifier"`
+	IsFrozen    bool          `json:"is_frozen"`
 	User        *TestUser     `json:"user,omitempty"`
 	Business    *TestBusiness `json:"business,omitempty"`
 	TestModelMixin
@@ -298,15 +118,61 @@ type TestWalletHistory struct {
 	Type     string      `json:"type"`
 	Amount   int         `json:"amount"`
 	Status   string      `json:"status"`
+	Note     string      `json:"note,omitempty"`
 	Wallet   *TestWallet `json:"wallet,omitempty"`
 	TestModelMixin
 }
 
+type TestAPIKey struct {
+	BusinessID  string   `json:"business_id"`
+	Name        string   `json:"name"`
+	Prefix      string   `json:"prefix"`
+	Environment string   `json:"environment"`
+	Scopes      []string `json:"scopes"`
+	RevokedAt   *string  `json:"revoked_at"`
+	TestModelMixin
+}
+
+type TestWebhookEndpoint struct {
+	BusinessID  string   `json:"business_id"`
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Description string   `json:"description"`
+	IsActive    bool     `json:"is_active"`
+	TestModelMixin
+}
+
+type TestWebhookDelivery struct {
+	EndpointID     string `json:"endpoint_id"`
+	EventID        string `json:"event_id"`
+	EventType      string `json:"event_type"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	ResponseStatus int    `json:"response_status"`
+	Error          string `json:"error"`
+	TestModelMixin
+}
+
+type TestAuditLog struct {
+	ID         string         `json:"id"`
+	Seq        int64          `json:"seq"`
+	ActorID    *string        `json:"actor_id"`
+	ActorType  string         `json:"actor_type"`
+	Action     string         `json:"action"`
+	EntityType string         `json:"entity_type"`
+	EntityID   string         `json:"entity_id"`
+	Changes    map[string]any `json:"changes"`
+	PrevHash   string         `json:"prev_hash"`
+	Hash       string         `json:"hash"`
+}
+
 type MetaResponse struct {
 	Page         int    `json:"page,omitempty"`
 	PageSize     int    `json:"page_size,omitempty"`
 	Total        int    `json:"total,omitempty"`
 	TotalPages   int    `json:"total_pages,omitempty"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+	PrevCursor   string `json:"prev_cursor,omitempty"`
 	AccessToken  string `json:"access_token,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 }
@@ -346,6 +212,7 @@ type TestUser struct {
 	RegStage              int           `json:"reg_stage,omitempty"`
 	AccountType           string        `json:"account_type,omitempty"`
 	ImageUrl              string        `json:"image_url,omitempty"`
+	Role                  string        `json:"role,omitempty"`
 	BusinessID            *string       `json:"business_id,omitempty"`
 	Business              *TestBusiness `json:"business,omitempty"`
 }
@@ -355,20 +222,98 @@ type TestBusiness struct {
 	Name     string `json:"name,omitempty"`
 	Email    string `json:"email,omitempty"`
 	ImageUrl string `json:"image_url,omitempty"`
+	Tier     string `json:"tier,omitempty"`
+	TestModelMixin
+}
+
+type TestMilestone struct {
+	TestModelMixin
+	TransactionID    string `json:"transaction_id"`
+	Position         int    `json:"position"`
+	Title            string `json:"title"`
+	Amount           int    `json:"amount"`
+	Charges          int    `json:"charges"`
+	ReceivableAmount int    `json:"receivable_amount"`
+	Status           string `json:"status"`
+}
+
+type TestTransactionQuote struct {
+	Subtotal   int             `json:"subtotal"`
+	Charges    int             `json:"charges"`
+	BuyerFee   int             `json:"buyer_fee"`
+	SellerFee  int             `json:"seller_fee"`
+	GatewayFee int             `json:"gateway_fee"`
+	Payable    int             `json:"payable"`
+	Receivable int             `json:"receivable"`
+	Currency   string          `json:"currency"`
+	Milestones []TestMilestone `json:"milestones"`
+}
+
+type TestFeeQuote struct {
+	Amount           int `json:"amount"`
+	Charges          int `json:"charges"`
+	BuyerFee         int `json:"buyer_fee"`
+	SellerFee        int `json:"seller_fee"`
+	BuyerPayable     int `json:"buyer_payable"`
+	SellerReceivable int `json:"seller_receivable"`
+}
+
+type TestFXQuote struct {
+	Identifier      string     `json:"identifier"`
+	AccountType     string     `json:"account_type"`
+	FromCurrency    string     `json:"from_currency"`
+	ToCurrency      string     `json:"to_currency"`
+	Amount          int        `json:"amount"`
+	ConvertedAmount int        `json:"converted_amount"`
+	MidRate         string     `json:"mid_rate"`
+	Rate            string     `json:"rate"`
+	Spread          int        `json:"spread"`
+	Status          string     `json:"status"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	ExecutedAt      *time.Time `json:"executed_at"`
 	TestModelMixin
 }
 
 func SignupPersonalUser(ts *TestServer) (TestUser, string) {
+	return signupUser(ts, "testuser2@user.com", "09012345678", "personal", "")
+}
+
+func SignupBusinessUser(ts *TestServer) (TestUser, string) {
+	return signupUser(ts, "testbusiness@user.com", "09087654321", "business", "Test Business")
+}
+
+// SignupAdminUser signs up a personal user with the admin role.
+func SignupAdminUser(ts *TestServer) (TestUser, string) {
+	user, token := signupUser(ts, "testadmin@user.com", "09011112222", "personal", "")
+
+	u, err := ts.Config.GetUserRepository().GetById(context.Background(), user.ID, nil)
+	if err != nil {
+		panic(err)
+	}
+	u.Role = models.RoleAdmin
+	if err := ts.Config.GetUserRepository().Update(context.Background(), u, nil); err != nil {
+		panic(err)
+	}
+
+	user.Role = models.RoleAdmin
+	return user, token
+}
+
+// signupUser signs up and verifies a user. Phone numbers are unique, so each
+// user of a suite needs their own.
+func signupUser(ts *TestServer, email, phoneNumber, accountType, businessName string) (TestUser, string) {
 	url := ts.Server.URL + "/api/v1/auth"
 	post := ts.Server.Client().Post
 	contentType := "application/json"
-	email := "testuser2@user.com"
 
 	// phase 1 sign up
 	phase1SignupDto := map[string]any{
 		"email":        email,
 		"reg_stage":    1,
-		"account_type": "personal",
+		"account_type": accountType,
+	}
+	if businessName != "" {
+		phase1SignupDto["business_name"] = businessName
 	}
 
 	data, _ := json.Marshal(phase1SignupDto)
@@ -409,7 +354,7 @@ func SignupPersonalUser(ts *TestServer) (TestUser, string) {
 	// phase 2 sign up
 	phase2Signup := map[string]any{
 		"email":        email,
-		"phone_number": "09012345678",
+		"phone_number": phoneNumber,
 		"reg_stage":    2,
 	}
 
@@ -473,3 +418,57 @@ func SignupPersonalUser(ts *TestServer) (TestUser, string) {
 
 	return respBody.Data.User, respBody.Meta.AccessToken
 }
+
+// BuyerFunds is what the buyer of a Parties fixture starts with in their NGN
+// wallet.
+const BuyerFunds = 10000000
+
+// Parties is a business selling to a personal buyer who can pay from their
+// wallet.
+type Parties struct {
+	Buyer         TestUser
+	BuyerToken    string
+	Business      TestUser
+	BusinessToken string
+}
+
+// SignupParties signs up the business and the buyer and funds the buyer's NGN
+// wallet with BuyerFunds.
+func SignupParties(ts *TestServer) Parties {
+	var p Parties
+	p.Business, p.BusinessToken = SignupBusinessUser(ts)
+	p.Buyer, p.BuyerToken = SignupPersonalUser(ts)
+
+	walletRepo := ts.Config.GetWalletRepository()
+	wallet, err := walletRepo.GetOrCreate(context.Background(), p.Buyer.ID, "personal", money.NGN, nil)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := walletRepo.Credit(context.Background(), wallet.ID, money.New(BuyerFunds, money.NGN), nil); err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Request sends body as json to path with the access token and decodes the
+// response.
+func (ts *TestServer) Request(method, path, token string, body any) (int, *Response[map[string]map[string]any]) {
+	data, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, ts.Server.URL+path, bytes.NewBuffer(data))
+	req.Header = map[string][]string{
+		"Authorization": {"Bearer " + token},
+		"Content-Type":  {ContentType},
+	}
+
+	res, err := ts.Server.Client().Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer res.Body.Close()
+
+	respBody := new(Response[map[string]map[string]any])
+	_ = json.ReadJSON(res.Body, respBody)
+
+	return res.StatusCode, respBody
+}
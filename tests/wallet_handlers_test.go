@@ -2,14 +2,21 @@ package tests
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"testing"
 
+	"github.com/gofrs/uuid"
 	"github.com/princecee/escrow-api/cmd/app/api/wallets"
+	"github.com/princecee/escrow-api/internal/models"
 	"github.com/princecee/escrow-api/pkg/apis/paystack"
+	"github.com/princecee/escrow-api/pkg/idempotency"
 	"github.com/princecee/escrow-api/pkg/json"
+	"github.com/princecee/escrow-api/pkg/money"
 	test_utils "github.com/princecee/escrow-api/tests/utils"
 	"github.com/princecee/escrow-api/tests/utils/mocks/test_config"
 	"github.com/stretchr/testify/suite"
@@ -230,6 +237,7 @@ func (s *WalletHandlerTestSuite) TestWalletHandler() {
 			s.Equal(true, respBody.Success)
 			s.Equal("wallet fetched successfully", respBody.Message)
 			s.Equal(0, respBody.Data.Wallet.Balance)
+			s.Equal("NGN", respBody.Data.Wallet.Currency)
 			s.Equal(s.user.ID, respBody.Data.Wallet.Identifier)
 		})
 
@@ -296,6 +304,7 @@ func (s *WalletHandlerTestSuite) TestWalletHandler() {
 			webhookDto := new(wallets.WebhookDto[wallets.TransactionData])
 			webhookDto.Event = "charge.success"
 			webhookDto.Data.Amount = fmt.Sprintf("%d", fundAmount)
+			webhookDto.Data.Currency = "NGN"
 			webhookDto.Data.Reference = ref
 
 			data, _ := json.Marshal(webhookDto)
@@ -401,8 +410,185 @@ func (s *WalletHandlerTestSuite) TestWalletHandler() {
 				s.Equal(true, respBody.Success)
 				s.Equal("funds successfully withdrawn", respBody.Message)
 			})
+
+			s.Run("replay withdrawal with the same idempotency key", func() {
+				withdrawFundDto["amount"] = 100000
+				data, _ := json.Marshal(withdrawFundDto)
+
+				var ids []string
+				for i := 0; i < 2; i++ {
+					req := s.post(url+"/withdraw-funds", bytes.NewBuffer(data))
+					req.Header.Set(idempotency.Header, "withdrawal-1")
+					res, err := client.Do(req)
+					s.NoError(err)
+
+					respBody := new(test_utils.Response[struct {
+						WalletHistory test_utils.TestWalletHistory `json:"wallet_history"`
+					}])
+					_ = json.ReadJSON(res.Body, respBody)
+					defer res.Body.Close()
+
+					s.Equal(true, respBody.Success)
+					s.Equal(i == 1, res.Header.Get(idempotency.ReplayedHeader) == "true")
+					ids = append(ids, respBody.Data.WalletHistory.ID)
+				}
+				s.Equal(ids[0], ids[1])
+
+				req := s.get(url)
+				res, err := client.Do(req)
+				s.NoError(err)
+
+				respBody := new(test_utils.Response[struct {
+					Wallet test_utils.TestWallet `json:"wallet"`
+				}])
+				_ = json.ReadJSON(res.Body, respBody)
+				defer res.Body.Close()
+
+				s.Equal(fundAmount-600000, respBody.Data.Wallet.Balance)
+			})
+
+			s.Run("reuse idempotency key with a different body", func() {
+				withdrawFundDto["amount"] = 200000
+				data, _ := json.Marshal(withdrawFundDto)
+
+				req := s.post(url+"/withdraw-funds", bytes.NewBuffer(data))
+				req.Header.Set(idempotency.Header, "withdrawal-1")
+				res, err := client.Do(req)
+				s.NoError(err)
+				defer res.Body.Close()
+
+				s.Equal(http.StatusUnprocessableEntity, res.StatusCode)
+			})
 		})
 	})
+
+	s.Run("hold other currencies", func() {
+		var ref string
+
+		s.Run("add funds in another currency", func() {
+			data, _ := json.Marshal(map[string]any{"amount": 50000, "currency": "USD"})
+			req := s.post(url+"/add-funds", bytes.NewBuffer(data))
+			res, err := client.Do(req)
+			s.NoError(err)
+
+			respBody := new(test_utils.Response[struct {
+				WalletHistory test_utils.TestWalletHistory `json:"wallet_history"`
+			}])
+			_ = json.ReadJSON(res.Body, respBody)
+			defer res.Body.Close()
+
+			s.Equal(true, respBody.Success)
+			s.Equal("USD", respBody.Data.WalletHistory.Wallet.Currency)
+			ref = respBody.Data.WalletHistory.ID
+		})
+
+		s.Run("reject a charge in the wrong currency", func() {
+			webhookDto := new(wallets.WebhookDto[wallets.TransactionData])
+			webhookDto.Event = "charge.success"
+			webhookDto.Data.Amount = "50000"
+			webhookDto.Data.Currency = "NGN"
+			webhookDto.Data.Reference = ref
+
+			data, _ := json.Marshal(webhookDto)
+			res, err := client.Do(s.post(url+"/paystack-webhook", bytes.NewBuffer(data)))
+			s.NoError(err)
+			defer res.Body.Close()
+
+			s.Equal(http.StatusBadRequest, res.StatusCode)
+		})
+
+		s.Run("get wallet by currency", func() {
+			res, err := client.Do(s.get(url + "?currency=USD"))
+			s.NoError(err)
+
+			respBody := new(test_utils.Response[struct {
+				Wallet  test_utils.TestWallet   `json:"wallet"`
+				Wallets []test_utils.TestWallet `json:"wallets"`
+			}])
+			_ = json.ReadJSON(res.Body, respBody)
+			defer res.Body.Close()
+
+			s.Equal(true, respBody.Success)
+			s.Equal("USD", respBody.Data.Wallet.Currency)
+			s.Equal(0, respBody.Data.Wallet.Balance)
+			s.Len(respBody.Data.Wallets, 2)
+
+			res, err = client.Do(s.get(url + "?currency=GHS"))
+			s.NoError(err)
+			defer res.Body.Close()
+
+			s.Equal(http.StatusNotFound, res.StatusCode)
+		})
+
+		s.Run("withdraw from a currency never held", func() {
+			data, _ := json.Marshal(map[string]any{
+				"amount":          5000,
+				"currency":        "GHS",
+				"bank_account_id": "00000000-0000-0000-0000-000000000000",
+			})
+			res, err := client.Do(s.post(url+"/withdraw-funds", bytes.NewBuffer(data)))
+			s.NoError(err)
+
+			respBody := new(test_utils.Response[any])
+			_ = json.ReadJSON(res.Body, respBody)
+			defer res.Body.Close()
+
+			s.Equal(false, respBody.Success)
+			s.Equal("insufficient balance", respBody.Message)
+		})
+
+		s.Run("reject unsupported currencies", func() {
+			data, _ := json.Marshal(map[string]any{"amount": 50000, "currency": "EUR"})
+			res, err := client.Do(s.post(url+"/add-funds", bytes.NewBuffer(data)))
+			s.NoError(err)
+			defer res.Body.Close()
+
+			s.Equal(http.StatusBadRequest, res.StatusCode)
+		})
+	})
+}
+
+func (s *WalletHandlerTestSuite) TestConcurrentDebits() {
+	walletRepo := s.ts.Config.GetWalletRepository()
+
+	wallet, err := walletRepo.GetOrCreate(context.Background(), uuid.Must(uuid.NewV4()).String(), "business", money.NGN, nil)
+	s.NoError(err)
+	_, err = walletRepo.Credit(context.Background(), wallet.ID, money.New(10000, money.NGN), nil)
+	s.NoError(err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := walletRepo.Debit(context.Background(), wallet.ID, money.New(3000, money.NGN), nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var debited, rejected int
+	for err := range errs {
+		switch {
+		case err == nil:
+			debited++
+		case errors.Is(err, models.ErrInsufficientFunds):
+			rejected++
+		}
+	}
+
+	s.Equal(3, debited)
+	s.Equal(7, rejected)
+
+	wallet, err = walletRepo.GetById(context.Background(), wallet.ID, nil)
+	s.NoError(err)
+	s.Equal(1000, wallet.Balance)
+	s.Equal(1000, wallet.Receivable)
+
+	_, err = walletRepo.Debit(context.Background(), wallet.ID, money.New(1000, money.USD), nil)
+	s.ErrorIs(err, money.ErrCurrencyMismatch)
 }
 
 func TestWalletHandlerSuite(t *testing.T) {